@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"karta/internal/config"
+	"karta/internal/database"
+	"karta/internal/export"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	StatsDays       = 7 // Days of daily history shown by the stats command by default
+	TestMessageText = "Test message from Karta Queue Monitor"
+)
+
+// command is a subcommand of the karta binary
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands lists the subcommands; the first one runs when none is given
+var commands = []command{
+	{"serve", "run the bot: monitor the queue and send notifications (default)", runServe},
+	{"migrate", "create or upgrade the database tables and exit", runMigrate},
+	{"export-history", "write stored queue history as CSV or JSON", runExportHistory},
+	{"import-history", "load queue history from a CSV or JSON export", runImportHistory},
+	{"stats", "print users and recent queue history", runStats},
+	{"send-test-message", "send a test message to the admins or a chat", runSendTestMessage},
+	{"fetch", "fetch the queue once and print it, e.g. for scripts", runFetch},
+	{"rotate-key", "re-encrypt usernames, ticket and case numbers with FIELD_ENCRYPTION_KEY", runRotateKey},
+}
+
+// exitError is a command failure with a specific exit status
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func main() {
+	// Flags without a subcommand keep working as before: ./karta --dry-run serves
+	name, args := commands[0].name, os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage(os.Stdout)
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(context.Background(), args); err != nil {
+				var exit *exitError
+				if errors.As(err, &exit) {
+					log.Printf("%s failed: %v", name, err)
+					os.Exit(exit.code)
+				}
+				log.Fatalf("%s failed: %v", name, err)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	os.Exit(2)
+}
+
+// printUsage lists the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: karta [command] [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-18s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun karta <command> -h for the flags of a command.\n")
+}
+
+// newFlagSet creates the flag set of a subcommand
+func newFlagSet(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: karta %s %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// loadConfig loads the configuration from the environment and applies its time zone
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	setTimezone(cfg.Timezone)
+	return cfg, nil
+}
+
+// openDatabase loads the configuration and opens the configured database
+func openDatabase() (*config.Config, *database.Database, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := database.Open(cfg.DatabaseURL, cfg.DatabasePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := configureEncryption(cfg, db); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return cfg, db, nil
+}
+
+// configureEncryption enables the encryption of personal fields when a key is configured
+func configureEncryption(cfg *config.Config, db *database.Database) error {
+	if cfg.EncryptionKey == nil {
+		return nil
+	}
+
+	fieldCipher, err := database.NewFieldCipher(cfg.EncryptionKey, cfg.EncryptionOldKeys)
+	if err != nil {
+		return fmt.Errorf("failed to set up field encryption: %w", err)
+	}
+	db.SetFieldCipher(fieldCipher)
+	return nil
+}
+
+// runMigrate opens the database, which creates missing tables and runs the migrations
+func runMigrate(_ context.Context, args []string) error {
+	newFlagSet("migrate", "").Parse(args)
+
+	_, db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	log.Println("Database is up to date")
+	return nil
+}
+
+// runExportHistory writes the history of a range to a file or stdout
+func runExportHistory(ctx context.Context, args []string) error {
+	flags := newFlagSet("export-history", "[-format csv|json] [-from DATE] [-to DATE] [-o FILE]")
+	formatName := flags.String("format", "csv", "export format: csv or json")
+	from := flags.String("from", "", "start date (YYYY-MM-DD or RFC 3339, default: today)")
+	to := flags.String("to", "", "end date, included (default: today)")
+	output := flags.String("o", "", "output file (default: stdout)")
+	flags.Parse(args)
+
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	_, db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	start, stop, err := export.ParseRange(*from, *to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffered := bufio.NewWriter(w)
+	if err := export.Write(ctx, buffered, format, db, start, stop); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	log.Printf("Exported history from %s to %s", start.Format(time.RFC3339), stop.Format(time.RFC3339))
+	return nil
+}
+
+// runImportHistory loads an export into the history, keeping the recording times
+func runImportHistory(ctx context.Context, args []string) error {
+	flags := newFlagSet("import-history", "[-format csv|json] FILE")
+	formatName := flags.String("format", "", "file format: csv or json (default: from the file extension)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected one file to import")
+	}
+	path := flags.Arg(0)
+
+	if *formatName == "" && strings.HasSuffix(strings.ToLower(path), ".json") {
+		*formatName = string(export.FormatJSON)
+	}
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := export.ReadRecords(bufio.NewReader(file), format)
+	if err != nil {
+		return err
+	}
+
+	_, db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	imported, err := db.ImportQueueHistory(ctx, records)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Imported %d history records from %s, skipped %d already stored", imported, path, len(records)-imported)
+	return nil
+}
+
+// runStats prints the users and a summary of the recent history
+func runStats(ctx context.Context, args []string) error {
+	flags := newFlagSet("stats", "[-days N]")
+	statsDays := flags.Int("days", StatsDays, "days of daily history, older ones from the hourly aggregates")
+	flags.Parse(args)
+	if *statsDays < 1 {
+		return fmt.Errorf("invalid -days %d: must be positive", *statsDays)
+	}
+
+	_, db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	users, err := db.GetActiveUsers(ctx)
+	if err != nil {
+		return err
+	}
+	var withTicket, muted int
+	for _, user := range users {
+		if user.TicketNumber != "" {
+			withTicket++
+		}
+		if user.Muted(time.Now()) {
+			muted++
+		}
+	}
+	fmt.Printf("Active users: %d (%d with a ticket, %d muted)\n", len(users), withTicket, muted)
+
+	latest, err := db.GetLatestQueueData(ctx)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		fmt.Println("No queue history")
+		return nil
+	}
+	fmt.Printf("Latest queue data (%s): %s, %d waiting, %d served, %d tickets left, %d workplaces\n",
+		latest.LastUpdated.Format("2006-01-02 15:04"), latest.Status, latest.WaitingClients,
+		latest.ServedClients, latest.TicketsLeft, latest.Workplaces)
+
+	today := time.Now()
+	since := time.Date(today.Year(), today.Month(), today.Day()-*statsDays+1, 0, 0, 0, 0, time.Local)
+	days, err := db.GetDailyHistory(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%-10s  %7s  %6s  %11s  %7s\n", "Day", "Records", "Served", "Max waiting", "Service")
+	for _, day := range days {
+		service := "-"
+		if day.AvgServiceTime > 0 {
+			service = day.AvgServiceTime.Round(time.Second).String()
+		}
+		fmt.Printf("%-10s  %7d  %6d  %11d  %7s\n", day.Period.Format("2006-01-02"), day.Samples, day.MaxServed, day.MaxWaiting, service)
+	}
+	return nil
+}
+
+// runRotateKey rewrites the personal fields of all users with the current key; afterwards
+// the old keys can be removed from FIELD_ENCRYPTION_OLD_KEYS
+func runRotateKey(ctx context.Context, args []string) error {
+	newFlagSet("rotate-key", "").Parse(args)
+
+	cfg, db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if cfg.EncryptionKey == nil {
+		return fmt.Errorf("FIELD_ENCRYPTION_KEY is not set")
+	}
+
+	rotated, withFields, err := db.RotateEncryption(ctx)
+	if err != nil {
+		return fmt.Errorf("failed after rewriting %d users: %w", rotated, err)
+	}
+	fmt.Printf("Re-encrypted %d of %d users with personal fields\n", rotated, withFields)
+
+	// Nothing to rewrite means every user already has the current key, so it was not changed
+	if rotated == 0 && withFields > 0 {
+		return fmt.Errorf("no users re-encrypted: all %d already use the current key; set the new key as FIELD_ENCRYPTION_KEY and the old one in FIELD_ENCRYPTION_OLD_KEYS", withFields)
+	}
+	return nil
+}
+
+// runSendTestMessage checks the bot token and delivery by sending a plain text message
+func runSendTestMessage(_ context.Context, args []string) error {
+	flags := newFlagSet("send-test-message", "[-chat ID] [-text TEXT]")
+	chatID := flags.Int64("chat", 0, "chat to send to (default: the ADMIN_CHAT_IDS)")
+	text := flags.String("text", TestMessageText, "message text")
+	flags.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	chatIDs := cfg.AdminChatIDs
+	if *chatID != 0 {
+		chatIDs = []int64{*chatID}
+	}
+	if len(chatIDs) == 0 {
+		return fmt.Errorf("no chat given and ADMIN_CHAT_IDS is empty")
+	}
+
+	api, err := tgbotapi.NewBotAPI(cfg.TelegramBotToken)
+	if err != nil {
+		return fmt.Errorf("failed to authorize the bot: %w", err)
+	}
+	log.Printf("Authorized as @%s", api.Self.UserName)
+
+	var failed int
+	for _, id := range chatIDs {
+		if _, err := api.Send(tgbotapi.NewMessage(id, *text)); err != nil {
+			log.Printf("Failed to send test message to %d: %v", id, err)
+			failed++
+			continue
+		}
+		log.Printf("Sent test message to %d", id)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d messages failed", failed, len(chatIDs))
+	}
+	return nil
+}
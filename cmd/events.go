@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"karta/internal/events"
+	"karta/internal/models"
+	"karta/internal/notifier"
+)
+
+// UserTicketNearTickets is the number of tickets ahead at which a user's ticket counts as near
+const UserTicketNearTickets = 5
+
+// subscribe creates the event bus of the application with the built-in subscribers:
+// history recording, queue update broadcasts, alerts and near-ticket reminders.
+// Further subscribers can be added to app.bus afterwards.
+func (app *Application) subscribe() {
+	app.bus = events.NewBus()
+
+	app.bus.Subscribe(app.recordHistory, events.QueueUpdated)
+	app.bus.Subscribe(app.broadcastUpdate, events.QueueUpdated)
+	app.bus.Subscribe(app.sendAlert, events.QueueOpened, events.QueueClosed, events.TicketsDecreased, events.TicketsExhausted, events.TicketCalled)
+	if app.bot != nil {
+		app.bus.Subscribe(app.remindTicketNear, events.UserTicketNear)
+	}
+	app.bus.Subscribe(app.warnQueueMissing, events.QueueMissing)
+	if app.bot != nil {
+		app.bus.Subscribe(app.alertQueueMissing, events.QueueMissing, events.QueueFound)
+	}
+}
+
+// publishEvents publishes QueueUpdated for an accepted poll, followed by the events it
+// implies. Must be called with app.mu held.
+func (app *Application) publishEvents(ctx context.Context, update events.Event) {
+	publish := func(kind events.Kind) {
+		event := update
+		event.Kind = kind
+		app.bus.Publish(ctx, event)
+	}
+
+	publish(events.QueueUpdated)
+
+	previous, current := update.Previous, update.Queue
+	switch models.DetectStatusTransition(previous, current) {
+	case models.TransitionOpened:
+		publish(events.QueueOpened)
+	case models.TransitionClosed:
+		publish(events.QueueClosed)
+	}
+
+	if previous == nil {
+		return
+	}
+
+	if current.TicketsLeft < previous.TicketsLeft {
+		publish(events.TicketsDecreased)
+		if current.TicketsLeft == 0 {
+			publish(events.TicketsExhausted)
+		}
+	}
+
+	if current.LastTicket != previous.LastTicket {
+		publish(events.TicketCalled)
+		app.publishTicketsNear(ctx, update)
+	}
+}
+
+// publishTicketsNear publishes UserTicketNear for every user of the office whose ticket
+// came within UserTicketNearTickets of the current ticket with this poll
+func (app *Application) publishTicketsNear(ctx context.Context, update events.Event) {
+	if app.db == nil || !app.bus.HasSubscribers(events.UserTicketNear) {
+		return // Only the primary office reads the users
+	}
+
+	users, err := app.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		if user.TicketNumber == "" || (user.Office != "" && user.Office != app.office.ID) {
+			continue
+		}
+
+		ahead, err := update.Queue.TicketsAhead(user.TicketNumber)
+		if err != nil || ahead <= 0 || ahead > UserTicketNearTickets {
+			continue
+		}
+		if before, err := update.Previous.TicketsAhead(user.TicketNumber); err == nil && before <= UserTicketNearTickets {
+			continue // Already near at the previous poll
+		}
+
+		event := update
+		event.Kind = events.UserTicketNear
+		event.ChatID, event.Ticket, event.TicketsAhead = user.ChatID, user.TicketNumber, ahead
+		app.bus.Publish(ctx, event)
+	}
+}
+
+// recordHistory stores the polled data in the queue history
+func (app *Application) recordHistory(ctx context.Context, event events.Event) {
+	app.saveHistory(ctx, event.Queue, event.Changes.HasChanges)
+}
+
+// broadcastUpdate sends the queue update to all channels. Outside office hours only real
+// changes are pushed, so users are not woken up by sync-time refreshes.
+func (app *Application) broadcastUpdate(ctx context.Context, event events.Event) {
+	if !event.OfficeOpen && !event.Changes.HasChanges {
+		return
+	}
+
+	if err := app.notifier.Broadcast(ctx, event.Queue, event.Shown); err != nil {
+		log.Printf("Failed to broadcast queue update: %v", err)
+	}
+}
+
+// sendAlert sends the dedicated alert of a queue event. Alerts are held back outside
+// office hours, so night-time API glitches do not wake users up.
+func (app *Application) sendAlert(ctx context.Context, event events.Event) {
+	var alert notifier.Alert
+	switch event.Kind {
+	case events.QueueOpened:
+		alert = notifier.Alert{Kind: notifier.AlertStatus, Transition: models.TransitionOpened}
+	case events.QueueClosed:
+		alert = notifier.Alert{Kind: notifier.AlertStatus, Transition: models.TransitionClosed}
+	case events.TicketsDecreased:
+		if event.Queue.TicketsLeft == 0 {
+			return // The last tickets going is the TicketsExhausted alert
+		}
+		alert = notifier.Alert{Kind: notifier.AlertTickets, PreviousLeft: event.Previous.TicketsLeft, CurrentLeft: event.Queue.TicketsLeft}
+	case events.TicketsExhausted:
+		alert = notifier.Alert{Kind: notifier.AlertTickets, PreviousLeft: event.Previous.TicketsLeft, CurrentLeft: 0}
+	case events.TicketCalled:
+		alert = notifier.Alert{Kind: notifier.AlertCalled, PreviousTicket: event.Previous.LastTicket}
+	default:
+		return
+	}
+
+	if !event.OfficeOpen {
+		log.Printf("Office is closed, skipping %s alert", alert.Kind)
+		return
+	}
+
+	if alert.Kind == notifier.AlertStatus {
+		log.Printf("Queue status changed: %s -> %s", event.Previous.Status, event.Queue.Status)
+	}
+	if err := app.notifier.SendAlert(ctx, event.Queue, alert); err != nil {
+		log.Printf("Failed to broadcast %s alert: %v", alert.Kind, err)
+	}
+}
+
+// remindTicketNear tells a user that their ticket is coming up, during office hours
+func (app *Application) remindTicketNear(ctx context.Context, event events.Event) {
+	if !event.OfficeOpen {
+		return
+	}
+
+	if err := app.bot.NotifyTicketNear(ctx, event.ChatID, event.Ticket, event.TicketsAhead); err != nil {
+		log.Printf("Failed to remind user %d of ticket %s: %v", event.ChatID, event.Ticket, err)
+	}
+}
+
+// warnQueueMissing warns that the monitored queue is gone from the response, usually
+// because DUW renamed it; QUEUE_ALIASES maps the new name to the queue
+func (app *Application) warnQueueMissing(_ context.Context, event events.Event) {
+	log.Printf("WARNING: queue %s disappeared from the response, set QUEUE_ALIASES if it was renamed: %v", app.office.DisplayName(), event.Err)
+}
+
+// alertQueueMissing tells the admins, and with MONITORING_ALERT_USERS the users of the
+// office, that monitoring is degraded while the queue is missing and when it is back
+func (app *Application) alertQueueMissing(ctx context.Context, event events.Event) {
+	app.bot.NotifyQueueMissing(ctx, app.office, event.Kind == events.QueueMissing, event.Err)
+}
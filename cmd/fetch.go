@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"karta/internal/parser"
+)
+
+// Exit statuses of the fetch command besides 0 for success and 2 for invalid flags
+const (
+	FetchExitFailed  = 1 // The queue could not be fetched or parsed
+	FetchExitInvalid = 3 // The queue was fetched but failed validation, e.g. a renamed queue
+)
+
+// runFetch fetches the queue of an office once and prints it to stdout, without a
+// configuration, database or bot
+func runFetch(ctx context.Context, args []string) error {
+	flags := newFlagSet("fetch", "[-json] [-office ID] [-timeout DURATION]")
+	asJSON := flags.Bool("json", false, "print the queue as JSON")
+	officeID := flags.String("office", parser.DefaultOffice, "office to fetch")
+	timeout := flags.Duration("timeout", time.Minute, "give up after this long, including retries")
+	flags.BoolVar(&parser.InsecureTLS, "insecure", false, "skip TLS certificate verification of DUW requests")
+	flags.Parse(args)
+
+	office, ok := parser.FindOffice(*officeID)
+	if !ok {
+		return &exitError{2, fmt.Errorf("unknown office %q", *officeID)}
+	}
+
+	// Renamed queues are found by their aliases, the only setting a fetch reads
+	aliases, err := parser.ParseQueueAliases(os.Getenv("QUEUE_ALIASES"))
+	if err != nil {
+		return &exitError{2, fmt.Errorf("invalid QUEUE_ALIASES: %w", err)}
+	}
+	parser.QueueAliases = aliases
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	// A single fetch never reaches the failure count of the HTML fallback
+	queueData, err := parser.NewQueueParser(office.Source(0)).ParseQueueData(ctx)
+	if err != nil {
+		return &exitError{FetchExitFailed, fmt.Errorf("failed to fetch queue data: %w", err)}
+	}
+	queueData.Office = office.Name
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(queueData); err != nil {
+			return fmt.Errorf("failed to encode queue data: %w", err)
+		}
+	} else {
+		fmt.Printf("Office:        %s\n", queueData.Office)
+		fmt.Printf("Queue:         %s\n", queueData.Name)
+		fmt.Printf("Status:        %s\n", queueData.Status)
+		fmt.Printf("Waiting:       %d\n", queueData.WaitingClients)
+		fmt.Printf("Served:        %d\n", queueData.ServedClients)
+		fmt.Printf("Workplaces:    %d\n", queueData.Workplaces)
+		fmt.Printf("Last ticket:   %s\n", queueData.LastTicket)
+		fmt.Printf("Tickets left:  %d\n", queueData.TicketsLeft)
+		fmt.Printf("Service time:  %v\n", queueData.AvgServiceTime)
+		fmt.Printf("Wait time:     %v\n", queueData.AvgWaitTime)
+	}
+
+	// The data is printed anyway, so a failed check can be inspected
+	if err := parser.ValidateQueueData(queueData, office.Queue); err != nil {
+		return &exitError{FetchExitInvalid, fmt.Errorf("invalid queue data: %w", err)}
+	}
+	return nil
+}
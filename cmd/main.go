@@ -2,116 +2,477 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"karta/internal/backup"
 	"karta/internal/bot"
+	"karta/internal/casestatus"
+	"karta/internal/config"
 	"karta/internal/database"
+	"karta/internal/events"
+	"karta/internal/httpapi"
+	"karta/internal/i18n"
+	"karta/internal/leader"
+	"karta/internal/logging"
 	"karta/internal/models"
+	"karta/internal/notifier"
+	"karta/internal/outbox"
 	"karta/internal/parser"
+	"karta/internal/prediction"
+	"karta/internal/reservation"
+	"karta/internal/retention"
+	"karta/internal/schedule"
+	"karta/internal/state"
+	"karta/internal/supervisor"
+	"karta/internal/systemd"
 )
 
 const (
-	DefaultDatabasePath    = "karta.db"
-	MonitoringInterval     = 11 * time.Second
 	HistoryCleanupInterval = 24 * time.Hour
-	HistoryRetentionPeriod = 7 * 24 * time.Hour // Keep 7 days of history
+	MaintenanceInterval    = 7 * 24 * time.Hour // VACUUM/ANALYZE the database weekly
+	PredictionWindow       = 3 * time.Hour      // History used for wait time predictions
+	StaleCheckInterval     = 30 * time.Second   // How often the watchdog looks for outdated data
 )
 
-// getDatabasePath returns database path from environment or default
-func getDatabasePath() string {
-	if path := os.Getenv("DATABASE_PATH"); path != "" {
-		return path
-	}
-	return DefaultDatabasePath
-}
-
 // Application represents the main application
 type Application struct {
-	db          *database.Database
+	db          database.Store
+	queue       *state.Queue // Current data, last change and highlighted changes of the office
 	bot         *bot.TelegramBot
+	notifier    notifier.Notifier
 	parser      *parser.QueueParser
-	lastData    *models.QueueData
-	lastChanged time.Time
-	lastChanges *models.QueueChanges // Store last changes to show red circles
+	office      parser.Office
+	schedule    *schedule.Schedule
+	comparison  models.Comparison // Fields that count as a change of the queue
+	bus         *events.Bus       // Queue events; see subscribe for the built-in subscribers
+	lastSaved   time.Time         // Last history record, for periodic snapshots
+	heldAnomaly bool              // The last poll looked anomalous and was held back
+	missing     bool              // The last poll did not find the queue in the response
+	stale       bool              // Polls kept failing for longer than STALE_DATA_AFTER
+	failures    int               // Consecutive failed polls, an outage from BreakerThreshold on
+	failedSince time.Time         // Time of the first of the failed polls
 	mu          sync.RWMutex
+
+	snapshotInterval time.Duration    // Unchanged data is stored at most this often
+	now              func() time.Time // Current time; replays use the time of the replayed record
+
+	onReady   func() // Called once after the first accepted poll, nil for none
+	readyOnce sync.Once
 }
 
-func main() {
+// runServe runs the bot until a shutdown signal, or replays history with -replay
+func runServe(ctx context.Context, args []string) error {
+	flags := newFlagSet("serve", "[flags]")
+	restore := flags.String("restore", "", `restore the database from a backup before starting: a file path, a backup name or "latest"`)
+	dryRun := flags.Bool("dry-run", false, "parse, compare and store queue data, but log messages instead of sending them")
+	flags.BoolVar(&parser.InsecureTLS, "insecure", false, "skip TLS certificate verification of DUW requests")
+	var replay replayOptions
+	flags.StringVar(&replay.from, "replay", "", "replay stored queue history from this date (YYYY-MM-DD or RFC 3339) and exit")
+	flags.StringVar(&replay.to, "replay-to", "", "end of the replayed range (default: end of the start day)")
+	flags.Float64Var(&replay.speed, "replay-speed", 60, "replay speed factor, 0 for no delays")
+	flags.Int64Var(&replay.chatID, "replay-chat", 0, "chat receiving replayed messages (default: log them)")
+	flags.StringVar(&replay.ticket, "replay-ticket", "", "ticket number of the replay user, e.g. K123")
+	flags.Parse(args)
+
 	log.Println("Starting Karta Queue Monitor...")
 
-	// Get Telegram bot token from environment
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if botToken == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN environment variable is required")
+	// Load configuration from environment
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	logging.SetLevel(cfg.LogLevel)
+
+	if replay.from != "" {
+		if err := runReplay(ctx, cfg, replay); err != nil {
+			return fmt.Errorf("replay failed: %w", err)
+		}
+		return nil
+	}
+
+	backupStorage, err := newBackupStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize backup storage: %w", err)
+	}
+
+	if backupStorage != nil && cfg.MemoryStorage {
+		log.Println("WARNING: Database backups are disabled with STORAGE=memory")
+		backupStorage = nil
+	}
+
+	if *restore != "" {
+		if cfg.MemoryStorage {
+			return fmt.Errorf("cannot restore a backup with STORAGE=memory")
+		}
+		if err := restoreDatabase(ctx, cfg, backupStorage, *restore); err != nil {
+			return fmt.Errorf("failed to restore database: %w", err)
+		}
 	}
 
 	// Initialize database
-	db, err := database.NewDatabase(getDatabasePath())
+	db, err := openStore(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		return err
 	}
 	defer db.Close()
 
+	// Initialize wait time predictor
+	predictor := prediction.NewPredictor(db, PredictionWindow)
+	forecaster := prediction.NewExhaustionForecaster(db)
+
 	// Initialize Telegram bot
-	telegramBot, err := bot.NewTelegramBot(botToken, db)
+	telegramBot, err := bot.NewTelegramBot(ctx, cfg.TelegramBotToken, db, predictor, forecaster)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Telegram bot: %w", err)
+	}
+	telegramBot.SetAdmins(cfg.AdminChatIDs)
+	telegramBot.SetMonitoringAlertUsers(cfg.MonitoringAlertUsers)
+	telegramBot.SetBroadcastWorkers(cfg.BroadcastWorkers)
+	telegramBot.SetCommandLimit(cfg.CommandsPerMinute, cfg.CommandMute)
+	telegramBot.SetWeeklyReportChats(cfg.WeeklyReportChatIDs, i18n.OrDefault(cfg.WeeklyReportLanguage))
+	telegramBot.SetSchedule(cfg.Schedule)
+	telegramBot.SetDryRun(*dryRun)
+	telegramBot.SetParseMode(cfg.ParseMode)
+	telegramBot.SetSilentMessages(cfg.SilentMessages)
+
+	// The monitor keeps the latest data in memory, so /start and friends do not query the database
+	queueState := state.NewQueue(db)
+	telegramBot.SetQueueState(queueState)
+
+	// The command menu is shown by Telegram clients; startup does not depend on it
+	go func() {
+		if err := telegramBot.RegisterCommands(ctx); err != nil {
+			log.Printf("Failed to register bot commands: %v", err)
+		}
+	}()
+
+	var messageTemplate *models.MessageTemplate
+	if cfg.MessageTemplate != "" {
+		if messageTemplate, err = models.LoadMessageTemplate(cfg.MessageTemplate); err != nil {
+			return fmt.Errorf("invalid MESSAGE_TEMPLATE: %w", err)
+		}
+		telegramBot.SetMessageTemplate(messageTemplate)
+		log.Printf("Using message template %s", cfg.MessageTemplate)
+	}
+
+	offices, err := parser.ParseOffices(cfg.Offices)
 	if err != nil {
-		log.Fatalf("Failed to initialize Telegram bot: %v", err)
+		return fmt.Errorf("invalid OFFICES: %w", err)
+	}
+	telegramBot.SetOffices(offices)
+	telegramBot.SetWebAppURL(cfg.WebAppURL)
+
+	var slotMonitor *reservation.Monitor
+	if len(cfg.ReservationServices) > 0 {
+		slotMonitor = reservation.NewMonitor(reservation.NewClient(cfg.ReservationCalendarURL), cfg.ReservationServices)
+		telegramBot.SetReservations(slotMonitor)
+	}
+	if cfg.CaseStatusURL != "" {
+		telegramBot.SetCaseStatus(casestatus.NewClient(cfg.CaseStatusURL), cfg.CaseStatusInterval)
+	}
+
+	// Telegram is always enabled, other channels are added when configured
+	notifiers := notifier.Multi{telegramBot}
+	templated := []templateSetter{telegramBot} // Channels rendering the status message
+	if *dryRun {
+		// Other channels have no dry-run mode and would publish for real
+		cfg.WebhookURL, cfg.DiscordBotToken, cfg.NtfyURL, cfg.GotifyURL = "", "", "", ""
+		log.Println("Dry run: webhook, Discord, ntfy and Gotify notifications are disabled")
+	}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, notifier.NewWebhookNotifier(cfg.WebhookURL))
+		log.Printf("Webhook notifications enabled")
+	}
+	if cfg.DiscordBotToken != "" {
+		discord := notifier.NewDiscordNotifier(cfg.DiscordBotToken, cfg.DiscordChannelID, i18n.OrDefault(cfg.DiscordLanguage))
+		discord.SetMessageTemplate(messageTemplate)
+		notifiers = append(notifiers, discord)
+		templated = append(templated, discord)
+		log.Printf("Discord notifications enabled for channel %s", cfg.DiscordChannelID)
+	}
+	if cfg.NtfyURL != "" {
+		ntfy := notifier.NewNtfyNotifier(cfg.NtfyURL, cfg.NtfyToken, i18n.OrDefault(cfg.PushLanguage))
+		ntfy.SetMessageTemplate(messageTemplate)
+		notifiers = append(notifiers, ntfy)
+		templated = append(templated, ntfy)
+		log.Printf("ntfy notifications enabled")
+	}
+	if cfg.GotifyURL != "" {
+		gotify := notifier.NewGotifyNotifier(cfg.GotifyURL, cfg.GotifyToken, i18n.OrDefault(cfg.PushLanguage))
+		gotify.SetMessageTemplate(messageTemplate)
+		notifiers = append(notifiers, gotify)
+		templated = append(templated, gotify)
+		log.Printf("Gotify notifications enabled")
+	}
+
+	// Initialize queue parsers, the primary office first
+	parser.QueueAliases = cfg.QueueAliases
+	newQueueParser := func(office parser.Office) *parser.QueueParser {
+		queueParser := parser.NewQueueParser(office.Source(cfg.HTMLFallbackAfter))
+		queueParser.SetPollingPolicy(pollingPolicy(cfg))
+		return queueParser
+	}
+	queueParser := newQueueParser(offices[0])
+	telegramBot.SetIntervalHandler(queueParser.SetInterval, queueParser.Interval, queueParser.EffectiveInterval)
+
+	// Under systemd the monitoring loop pings the watchdog twice per WatchdogSec, so a
+	// stuck loop gets the service restarted
+	if timeout, ok := systemd.WatchdogInterval(); ok {
+		queueParser.SetHeartbeat(timeout/2, func() { notifySystemd(systemd.Watchdog) })
+		log.Printf("systemd watchdog enabled with %v timeout", timeout)
 	}
 
-	// Initialize queue parser
-	queueParser := parser.NewQueueParser()
+	// Updates and alerts are stored as intents and delivered by the outbox dispatcher, so a
+	// channel being down neither blocks polling nor loses alerts across restarts
+	notificationOutbox := outbox.New(db, notifiers...)
 
 	// Create application instance
 	app := &Application{
-		db:          db,
-		bot:         telegramBot,
-		parser:      queueParser,
-		lastChanged: time.Now(),
+		db:         db,
+		queue:      queueState,
+		bot:        telegramBot,
+		notifier:   notificationOutbox.Notifier(),
+		parser:     queueParser,
+		office:     offices[0],
+		schedule:   cfg.Schedule,
+		comparison: cfg.Comparison,
+
+		snapshotInterval: cfg.HistorySnapshotInterval,
+		now:              time.Now,
+
+		// The bot is authorized and the database open by now; the first poll completes readiness
+		onReady: func() {
+			notifySystemd(systemd.Ready, systemd.Status("Monitoring "+offices[0].DisplayName()))
+		},
 	}
+	app.subscribe()
 
 	// Create context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
 	// Start components
 	var wg sync.WaitGroup
 
+	// With several instances sharing the database only the leader runs; the others wait
+	// here until it stops renewing its lease. A leader losing the lease shuts down and
+	// comes back as a standby after the restart.
+	leadershipLost := make(chan struct{})
+	if cfg.LeaderElection {
+		elector := leader.New(db, leader.LeaseName, cfg.InstanceID, cfg.LeaderLease)
+		notifySystemd(systemd.Status("Standing by for the leader lease"))
+
+		elected := make(chan error, 1)
+		go func() {
+			elected <- elector.Wait(ctx)
+		}()
+		select {
+		case <-sigChan:
+			log.Println("Shutdown signal received while standing by")
+			return nil
+		case err := <-elected:
+			// Only a won election may start polling, or two leaders would run at once
+			if err != nil {
+				return fmt.Errorf("failed to acquire the leader lease: %w", err)
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := elector.Keep(ctx); err != nil {
+				log.Printf("Leadership lost: %v", err)
+				close(leadershipLost)
+			}
+		}()
+	}
+
+	// Long-running goroutines are restarted with backoff when they stop or panic before
+	// the shutdown; the admins are told about every restart
+	tasks := supervisor.New(func(ctx context.Context, restart supervisor.Restart) {
+		telegramBot.ReportRestart(ctx, restart.Name, restart.Restarts, restart.Cause, restart.Delay)
+	})
+	telegramBot.SetRestartCounter(tasks.TotalRestarts)
+
 	// Start Telegram bot
+	tasks.Go(ctx, "telegram bot", telegramBot.Start)
+
+	// Deliver notifications from the outbox
+	tasks.Go(ctx, "notification outbox", func(ctx context.Context) error {
+		notificationOutbox.Run(ctx)
+		return nil
+	})
+
+	// Refresh live messages left over from before the restart without waiting for a change
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := telegramBot.Start(ctx); err != nil {
-			log.Printf("Telegram bot error: %v", err)
+		if err := telegramBot.ResyncLiveMessages(ctx); err != nil {
+			log.Printf("Failed to resynchronize live messages: %v", err)
 		}
 	}()
 
-	// Start queue monitoring
+	// Finish an admin announcement interrupted by the last shutdown
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		app.startQueueMonitoring(ctx)
+		if err := telegramBot.ResumeAnnouncement(ctx); err != nil {
+			log.Printf("Failed to resume the announcement: %v", err)
+		}
 	}()
 
+	// Resume rolling updates of users whose /mute ended
+	tasks.Go(ctx, "mute reminders", func(ctx context.Context) error {
+		telegramBot.StartMuteReminders(ctx)
+		return nil
+	})
+
+	// Send the updates held back for users with an update interval
+	tasks.Go(ctx, "throttled updates", func(ctx context.Context) error {
+		telegramBot.StartThrottledUpdates(ctx)
+		return nil
+	})
+
+	// Retry deleting replaced live messages, so each chat keeps a single one
+	tasks.Go(ctx, "stale message cleanup", func(ctx context.Context) error {
+		telegramBot.StartStaleMessageCleanup(ctx)
+		return nil
+	})
+
+	// Send the weekly report every Monday
+	tasks.Go(ctx, "weekly reports", func(ctx context.Context) error {
+		telegramBot.StartWeeklyReports(ctx)
+		return nil
+	})
+
+	// Alert users about new appointment slots on rezerwacje.duw.pl
+	if slotMonitor != nil {
+		tasks.Go(ctx, "slot monitor", func(ctx context.Context) error {
+			slotMonitor.Run(ctx, cfg.ReservationPollInterval, telegramBot.BroadcastNewSlots)
+			return nil
+		})
+	}
+
+	// Notify users about status changes of their registered cases
+	if cfg.CaseStatusURL != "" {
+		tasks.Go(ctx, "case status checks", func(ctx context.Context) error {
+			telegramBot.StartCaseStatusChecks(ctx)
+			return nil
+		})
+	}
+
+	// Start queue monitoring
+	tasks.Go(ctx, "queue monitoring of "+app.office.DisplayName(), func(ctx context.Context) error {
+		app.startQueueMonitoring(ctx, cfg.PollInterval)
+		return nil
+	})
+
+	// Other offices keep no history and only update the Telegram users following them
+	apps := []*Application{app}
+	for _, office := range offices[1:] {
+		officeApp := &Application{
+			bot:        telegramBot,
+			notifier:   notificationOutbox.Notifier(telegramBot.Name()),
+			parser:     newQueueParser(office),
+			office:     office,
+			schedule:   cfg.Schedule,
+			comparison: cfg.Comparison,
+			queue:      state.NewQueue(nil),
+			now:        time.Now,
+		}
+		officeApp.subscribe()
+		apps = append(apps, officeApp)
+
+		tasks.Go(ctx, "queue monitoring of "+office.DisplayName(), func(ctx context.Context) error {
+			officeApp.startQueueMonitoring(ctx, cfg.PollInterval)
+			return nil
+		})
+	}
+
+	// Mark the data of offices whose polls keep failing as outdated
+	if cfg.StaleDataAfter > 0 {
+		for _, officeApp := range apps {
+			tasks.Go(ctx, "stale data watch of "+officeApp.office.DisplayName(), func(ctx context.Context) error {
+				officeApp.watchStaleData(ctx, cfg.StaleDataAfter)
+				return nil
+			})
+		}
+	}
+
+	// Apply polling, office hours, LOG_LEVEL and MESSAGE_TEMPLATE changes on SIGHUP or
+	// when CONFIG_FILE changes, keeping users' live messages and the monitoring state
+	configReloader := &reloader{cfg: cfg, bot: telegramBot, templated: templated, apps: apps}
+	tasks.Go(ctx, "config reloader", func(ctx context.Context) error {
+		configReloader.run(ctx)
+		return nil
+	})
+
+	cleaner := retention.New(db, retentionPolicy(cfg))
+
+	// Start HTTP server (RSS feed, health checks)
+	httpServer := httpapi.NewServer(cfg.HTTPAddr, db)
+	httpServer.SetHealthChecks(httpapi.HealthChecks{
+		Telegram:    telegramBot.Ping,
+		LastAttempt: queueParser.LastAttempt,
+		LastSuccess: queueParser.LastSuccess,
+		Interval:    queueParser.EffectiveInterval,
+		Restarts:    tasks.Restarts,
+		Cleaned:     cleaner.Deleted,
+	})
+	httpServer.SetUpstreamCache(queueParser.CachedResponse)
+	httpServer.SetQueueState(queueState)
+	httpServer.SetPredictor(predictor)
+	httpServer.SetComparison(cfg.Comparison)
+	httpServer.SetImportToken(cfg.ImportToken)
+	httpServer.SetAPIAuth(cfg.APIAuth)
+	if cfg.WebAppURL != "" {
+		httpServer.SetWebApp(cfg.TelegramBotToken, offices[0].ID)
+	}
+	tasks.Go(ctx, "HTTP server", httpServer.Start)
+
 	// Start periodic cleanup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		app.startPeriodicCleanup(ctx)
-	}()
+	tasks.Go(ctx, "periodic cleanup", func(ctx context.Context) error {
+		app.startPeriodicCleanup(ctx, cleaner)
+		return nil
+	})
 
-	// Wait for interrupt signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Start database backups
+	if backupStorage != nil {
+		var backups *backup.Manager
+		if cfg.DatabaseURL != "" {
+			backups = backup.NewPostgresManager(cfg.DatabaseURL, backupStorage, cfg.BackupKeep)
+		} else {
+			backups = backup.NewSQLiteManager(db, backupStorage, cfg.BackupKeep)
+		}
+
+		tasks.Go(ctx, "database backups", func(ctx context.Context) error {
+			backups.Start(ctx, cfg.BackupInterval)
+			return nil
+		})
+	}
 
 	log.Println("Application started successfully. Press Ctrl+C to stop.")
 
-	<-sigChan
-	log.Println("Shutdown signal received, stopping application...")
+	// Wait for interrupt signal
+	select {
+	case <-sigChan:
+		log.Println("Shutdown signal received, stopping application...")
+	case <-leadershipLost:
+		log.Println("Stopping application to hand over to the new leader...")
+	}
+
+	notifySystemd(systemd.Stopping)
 
 	// Cancel context to stop all goroutines
 	cancel()
@@ -123,6 +484,7 @@ func main() {
 	done := make(chan struct{})
 	go func() {
 		wg.Wait()
+		tasks.Wait()
 		close(done)
 	}()
 
@@ -133,86 +495,248 @@ func main() {
 	case <-time.After(10 * time.Second):
 		log.Println("Shutdown timeout, forcing exit")
 	}
+	return nil
 }
 
 // startQueueMonitoring starts the queue monitoring process
-func (app *Application) startQueueMonitoring(ctx context.Context) {
-	log.Printf("Starting queue monitoring with %v interval", MonitoringInterval)
+func (app *Application) startQueueMonitoring(ctx context.Context, interval time.Duration) {
+	log.Printf("Starting queue monitoring of %s with %v interval", app.office.DisplayName(), interval)
 
-	app.parser.StartMonitoring(ctx, MonitoringInterval, func(queueData *models.QueueData, err error) {
-		if err != nil {
-			log.Printf("Failed to parse queue data: %v", err)
-			return
-		}
+	app.parser.StartMonitoring(ctx, interval, func(queueData *models.QueueData, err error) {
+		app.handlePoll(ctx, queueData, err)
+	})
+}
 
-		if err := parser.ValidateQueueData(queueData); err != nil {
-			log.Printf("Invalid queue data: %v", err)
-			return
-		}
+// handlePoll handles the result of a poll: failures and missing queues are tracked,
+// valid data goes on to processQueueUpdate
+func (app *Application) handlePoll(ctx context.Context, queueData *models.QueueData, err error) {
+	if errors.Is(err, parser.ErrQueueMissing) {
+		app.trackOutage(ctx, nil) // The API answered, just without the queue
+		app.processQueueMissing(ctx, err)
+		return
+	}
+	app.trackOutage(ctx, err)
+	if err != nil {
+		log.Printf("Failed to parse queue data: %v", err)
+		return
+	}
 
-		app.processQueueUpdate(queueData)
-	})
+	if err := parser.ValidateQueueData(queueData, app.office.Queue); err != nil {
+		log.Printf("Invalid queue data: %v", err)
+		return
+	}
+	queueData.Office = app.office.Name
+
+	app.processQueueUpdate(ctx, queueData)
+	if app.onReady != nil {
+		app.readyOnce.Do(app.onReady)
+	}
 }
 
 // processQueueUpdate processes new queue data and sends notifications if needed
-func (app *Application) processQueueUpdate(newData *models.QueueData) {
+func (app *Application) processQueueUpdate(ctx context.Context, newData *models.QueueData) {
 	app.mu.Lock()
 	defer app.mu.Unlock()
 
-	log.Printf("Processing queue update: %+v", newData)
+	logging.Debugf("Processing queue update: %+v", newData)
 
-	// Save to database
-	if err := app.db.SaveQueueHistory(newData); err != nil {
-		log.Printf("Failed to save queue history: %v", err)
+	if app.stale {
+		log.Printf("Data of %s is up to date again", app.office.DisplayName())
+		app.stale = false
+		if app.bot != nil {
+			app.bot.MarkFresh(ctx, app.office)
+		}
+	}
+
+	if app.missing {
+		log.Printf("Queue %s is back in the response", app.office.DisplayName())
+		app.missing = false
+		app.bus.Publish(ctx, events.Event{Kind: events.QueueFound, Queue: newData, OfficeOpen: app.schedule.IsOpen(app.now())})
 	}
 
-	// Compare with previous data
-	changes := models.CompareQueues(app.lastData, newData)
+	if app.holdAnomaly(newData) {
+		return
+	}
 
-	if app.lastData == nil {
-		// First run - set initial change time
-		app.lastChanged = time.Now()
-		newData.LastChanged = app.lastChanged
-		app.lastChanges = nil // No changes to highlight on first run
+	// The state sets the change time and keeps the changes highlighted until the next change
+	update := app.queue.Update(newData, app.comparison.Compare(app.queue.Last(), newData), app.now())
+	if update.Previous == nil {
 		log.Printf("First queue data received")
-	} else if changes.HasChanges {
-		// Data changed - update change time and store changes
-		app.lastChanged = time.Now()
-		newData.LastChanged = app.lastChanged
-		app.lastChanges = changes // Store changes to show red circles
-		log.Printf("Queue data changed: %+v", changes.ChangedFields)
-	} else {
-		// No changes - keep previous change time and previous changes for red circles
-		newData.LastChanged = app.lastChanged
-		// Keep showing red circles from last change
+	} else if update.Changes.HasChanges {
+		log.Printf("Queue data changed: %+v", update.Changes.ChangedFields)
 	}
 
-	// Send notifications to users (always update to show sync time)
-	// Use stored changes to keep showing red circles until next change
-	changesToShow := app.lastChanges
-	if changes.HasChanges {
-		changesToShow = changes // Show new changes
+	// History, notifications and alerts are handled by the event subscribers
+	app.publishEvents(ctx, events.Event{
+		Queue:      newData,
+		Previous:   update.Previous,
+		Changes:    update.Changes,
+		Shown:      update.Shown,
+		OfficeOpen: app.schedule.IsOpen(app.now()),
+	})
+
+	// Log statistics
+	if app.bot == nil {
+		return
 	}
+	if stats, err := app.bot.GetStats(ctx); err == nil {
+		logging.Debugf("Bot stats: %+v", stats)
+	}
+}
 
-	if err := app.bot.BroadcastQueueUpdate(newData, changesToShow); err != nil {
-		log.Printf("Failed to broadcast queue update: %v", err)
+// trackOutage records an outage of the DUW API once BreakerThreshold polls in a row failed,
+// starting with the first of them, and ends it with the next successful poll. Only the
+// primary office records outages.
+func (app *Application) trackOutage(ctx context.Context, err error) {
+	if app.db == nil {
+		return
 	}
 
-	// Update last data
-	app.lastData = newData.Clone()
+	app.mu.Lock()
+	defer app.mu.Unlock()
 
-	// Log statistics
-	if stats, err := app.bot.GetStats(); err == nil {
-		log.Printf("Bot stats: %+v", stats)
+	now := app.now()
+	if err == nil {
+		// An outage left open by a restart ends with the first successful poll too
+		if app.failures >= parser.BreakerThreshold || app.queue.Last() == nil {
+			if err := app.db.EndOutage(ctx, now); err != nil {
+				log.Printf("Failed to end outage: %v", err)
+			}
+		}
+		if app.failures >= parser.BreakerThreshold {
+			log.Printf("DUW API outage ended after %v", now.Sub(app.failedSince).Round(time.Second))
+		}
+		app.failures = 0
+		return
+	}
+
+	if app.failures == 0 {
+		app.failedSince = now
+	}
+	app.failures++
+	if app.failures == parser.BreakerThreshold {
+		if err := app.db.StartOutage(ctx, app.failedSince, err.Error()); err != nil {
+			log.Printf("Failed to start outage: %v", err)
+		}
+	}
+}
+
+// processQueueMissing publishes QueueMissing when the queue disappears from the response;
+// the following polls without it are only logged at debug level, not on every poll
+func (app *Application) processQueueMissing(ctx context.Context, err error) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.missing {
+		logging.Debugf("Queue %s is still missing: %v", app.office.DisplayName(), err)
+		return
+	}
+	app.missing = true
+
+	app.bus.Publish(ctx, events.Event{
+		Kind:       events.QueueMissing,
+		Queue:      app.queue.Last(),
+		Err:        err,
+		OfficeOpen: app.schedule.IsOpen(app.now()),
+	})
+}
+
+// watchStaleData checks every StaleCheckInterval whether polls kept failing for longer than
+// after, until the context is cancelled
+func (app *Application) watchStaleData(ctx context.Context, after time.Duration) {
+	ticker := time.NewTicker(StaleCheckInterval)
+	defer ticker.Stop()
+
+	started := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.checkStaleData(ctx, after, started)
+		}
+	}
+}
+
+// checkStaleData marks the data of the office as outdated when no poll succeeded within
+// after, so silent failures (DNS, TLS, API changes) show in the live messages. Polls far
+// apart outside office hours do not count, only a failed last poll does.
+func (app *Application) checkStaleData(ctx context.Context, after time.Duration, started time.Time) {
+	lastSuccess, lastAttempt := app.parser.LastSuccess(), app.parser.LastAttempt()
+	since := lastSuccess
+	if since.IsZero() {
+		since = started
+	}
+	if time.Since(since) < after || !lastAttempt.After(lastSuccess) {
+		return
 	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.stale {
+		return
+	}
+	app.stale = true
+
+	log.Printf("WARNING: no successful poll of %s since %s, marking its data as outdated", app.office.DisplayName(), since.Format(time.RFC3339))
+	app.bot.MarkStale(ctx, app.office, since)
+}
+
+// holdAnomaly reports whether new data looks like a glitch to hold back until the next
+// poll confirms it: a second anomalous poll in a row is accepted, a normal one drops the
+// held data. Must be called with app.mu held.
+func (app *Application) holdAnomaly(newData *models.QueueData) bool {
+	anomalies := models.DetectAnomalies(app.queue.Last(), newData)
+	if len(anomalies) == 0 {
+		if app.heldAnomaly {
+			log.Printf("Anomalous queue data was not confirmed by the next poll, dropped it")
+			app.heldAnomaly = false
+		}
+		return false
+	}
+
+	if app.heldAnomaly {
+		log.Printf("Anomalous queue data confirmed by the next poll: %s", strings.Join(anomalies, "; "))
+		app.heldAnomaly = false
+		return false
+	}
+
+	log.Printf("Holding back anomalous queue data until the next poll: %s", strings.Join(anomalies, "; "))
+	app.heldAnomaly = true
+	return true
 }
 
-// startPeriodicCleanup starts periodic database cleanup
-func (app *Application) startPeriodicCleanup(ctx context.Context) {
+// saveHistory stores changed queue data, and unchanged data only as a periodic snapshot,
+// so identical polls do not fill the history. Must be called with app.mu held.
+func (app *Application) saveHistory(ctx context.Context, newData *models.QueueData, changed bool) {
+	if app.db == nil {
+		return // Only the primary office keeps a history
+	}
+	if !changed && app.now().Sub(app.lastSaved) < app.snapshotInterval {
+		return
+	}
+
+	if err := app.db.SaveQueueHistory(ctx, newData); err != nil {
+		log.Printf("Failed to save queue history: %v", err)
+		return
+	}
+	app.lastSaved = app.now()
+}
+
+// startPeriodicCleanup applies the retention policy on start and daily, and maintains the
+// database weekly
+func (app *Application) startPeriodicCleanup(ctx context.Context, cleaner *retention.Cleaner) {
 	ticker := time.NewTicker(HistoryCleanupInterval)
 	defer ticker.Stop()
 
-	log.Printf("Starting periodic cleanup with %v interval", HistoryCleanupInterval)
+	maintenance := time.NewTicker(MaintenanceInterval)
+	defer maintenance.Stop()
+
+	log.Printf("Starting periodic cleanup with %v interval, maintenance every %v", HistoryCleanupInterval, MaintenanceInterval)
+	if err := cleaner.Clean(ctx); err != nil {
+		log.Printf("Failed to clean old data: %v", err)
+	}
 
 	for {
 		select {
@@ -220,9 +744,101 @@ func (app *Application) startPeriodicCleanup(ctx context.Context) {
 			log.Println("Periodic cleanup stopped")
 			return
 		case <-ticker.C:
-			if err := app.db.CleanOldHistory(HistoryRetentionPeriod); err != nil {
-				log.Printf("Failed to clean old history: %v", err)
+			if err := cleaner.Clean(ctx); err != nil {
+				log.Printf("Failed to clean old data: %v", err)
+			}
+		case <-maintenance.C:
+			if err := app.db.Maintain(ctx); err != nil {
+				log.Printf("Failed to maintain database: %v", err)
 			}
 		}
 	}
 }
+
+// retentionPolicy returns the retention policy of the configuration
+func retentionPolicy(cfg *config.Config) retention.Policy {
+	if cfg.HistoryRetention < config.DefaultHistoryRetention {
+		log.Printf("WARNING: HISTORY_RETENTION of %v is shorter than %v, the weekly report cannot compare with the previous week",
+			cfg.HistoryRetention, config.DefaultHistoryRetention)
+	}
+
+	return retention.Policy{
+		History:        cfg.HistoryRetention,
+		Aggregates:     cfg.AggregateRetention,
+		HistoryMaxRows: cfg.HistoryMaxRows,
+		MaxSize:        cfg.DatabaseMaxSize,
+		Outages:        cfg.OutageRetention,
+		CommandUsage:   cfg.CommandUsageRetention,
+	}
+}
+
+// setTimezone makes the office time zone the local one, so office hours, history days and
+// displayed times are right wherever the bot is hosted
+func setTimezone(location *time.Location) {
+	time.Local = location
+	// SQLite computes local history buckets with the C library, which reads TZ
+	if err := os.Setenv("TZ", location.String()); err != nil {
+		log.Printf("Failed to set TZ: %v", err)
+	}
+	log.Printf("Using time zone %s", location)
+}
+
+// pollingPolicy returns the adaptive polling policy of the configuration, nil when disabled
+func pollingPolicy(cfg *config.Config) *parser.PollingPolicy {
+	if !cfg.PollAdaptive {
+		return nil
+	}
+	return &parser.PollingPolicy{
+		QuietInterval:  cfg.PollQuietInterval,
+		QuietAfter:     cfg.PollQuietAfter,
+		ClosedInterval: cfg.PollClosedInterval,
+		Schedule:       cfg.Schedule,
+	}
+}
+
+// notifySystemd sends states to systemd when running as a Type=notify service
+func notifySystemd(states ...string) {
+	if _, err := systemd.Notify(states...); err != nil {
+		log.Printf("Failed to notify systemd: %v", err)
+	}
+}
+
+// newBackupStorage creates the configured backup storage, or returns nil when backups are disabled
+func newBackupStorage(cfg *config.Config) (backup.Storage, error) {
+	switch {
+	case cfg.BackupS3Bucket != "":
+		return backup.NewS3Storage(cfg.BackupS3Endpoint, cfg.BackupS3Bucket, cfg.BackupS3Prefix,
+			cfg.BackupS3Region, cfg.BackupS3AccessKey, cfg.BackupS3SecretKey)
+	case cfg.BackupDir != "":
+		return backup.NewDirStorage(cfg.BackupDir)
+	default:
+		return nil, nil
+	}
+}
+
+// openStore opens the configured database, or an empty in-memory store with STORAGE=memory
+func openStore(cfg *config.Config) (database.Store, error) {
+	if cfg.MemoryStorage {
+		log.Println("WARNING: STORAGE=memory keeps users and history in memory only, they are lost on restart")
+		return database.NewMemory(), nil
+	}
+
+	db, err := database.Open(cfg.DatabaseURL, cfg.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	if err := configureEncryption(cfg, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// restoreDatabase replaces the configured database with a backup before it is opened
+func restoreDatabase(ctx context.Context, cfg *config.Config, storage backup.Storage, name string) error {
+	if cfg.DatabaseURL != "" {
+		return backup.RestorePostgres(ctx, storage, name, cfg.DatabaseURL)
+	}
+	return backup.RestoreSQLite(ctx, storage, name, cfg.DatabasePath)
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/events"
+	"karta/internal/models"
+	"karta/internal/notifier"
+	"karta/internal/parser"
+	"karta/internal/parser/testserver"
+	"karta/internal/state"
+)
+
+// allKinds are the event kinds recorded by the test application
+var allKinds = []events.Kind{
+	events.QueueUpdated, events.QueueOpened, events.QueueClosed, events.TicketsDecreased,
+	events.TicketsExhausted, events.TicketCalled, events.UserTicketNear, events.QueueMissing, events.QueueFound,
+}
+
+// recorder is a notifier remembering the alerts it was asked to send
+type recorder struct {
+	mu         sync.Mutex
+	broadcasts int
+	alerts     []notifier.Alert
+}
+
+func (r *recorder) Name() string {
+	return "recorder"
+}
+
+func (r *recorder) Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.broadcasts++
+	return nil
+}
+
+func (r *recorder) SendAlert(ctx context.Context, queueData *models.QueueData, alert notifier.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+// testApp is an application polling the mock DUW server into an in-memory store
+type testApp struct {
+	*Application
+	server   *testserver.Server
+	notifier *recorder
+	events   []events.Kind
+}
+
+// newTestApp creates an application for the Wrocław card pickup queue polling a mock
+// server replying with the script
+func newTestApp(t *testing.T, script ...testserver.Response) *testApp {
+	t.Helper()
+
+	server := testserver.New(script...)
+	httpServer := server.Start()
+	t.Cleanup(httpServer.Close)
+	t.Setenv("DUW_STATUS_URL", httpServer.URL)
+
+	db := database.NewMemory()
+	app := &testApp{server: server, notifier: &recorder{}}
+	app.Application = &Application{
+		db:       db,
+		queue:    state.NewQueue(db),
+		notifier: app.notifier,
+		parser:   parser.NewQueueParser(parser.NewDUWSource(testserver.Section, testserver.QueueName)),
+		office:   parser.Offices[0],
+		now:      time.Now,
+	}
+	app.subscribe()
+	app.bus.Subscribe(func(ctx context.Context, event events.Event) {
+		app.events = append(app.events, event.Kind)
+	}, allKinds...)
+	return app
+}
+
+// poll runs one poll of the monitoring loop within timeout and returns its error
+func (app *testApp) poll(t *testing.T, timeout time.Duration) error {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	queueData, err := app.parser.ParseQueueData(ctx)
+	app.handlePoll(context.Background(), queueData, err)
+	return err
+}
+
+// history returns the stored history of the test
+func (app *testApp) history(t *testing.T) []*models.QueueData {
+	t.Helper()
+
+	history, err := app.db.GetQueueDataSince(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	return history
+}
+
+// count returns how often the event kind was published
+func (app *testApp) count(kind events.Kind) int {
+	n := 0
+	for _, published := range app.events {
+		if published == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestProcessQueueUpdateDay(t *testing.T) {
+	script := testserver.Day(20, 2)
+	app := newTestApp(t, script...)
+
+	for range script {
+		if err := app.poll(t, 5*time.Second); err != nil {
+			t.Fatalf("poll failed: %v", err)
+		}
+	}
+
+	if got := app.count(events.QueueUpdated); got != len(script) {
+		t.Errorf("QueueUpdated published %d times, want %d", got, len(script))
+	}
+	if got := app.count(events.TicketsExhausted); got != 1 {
+		t.Errorf("TicketsExhausted published %d times, want 1", got)
+	}
+	if got := app.count(events.QueueClosed); got != 1 {
+		t.Errorf("QueueClosed published %d times, want 1", got)
+	}
+	if got := app.count(events.QueueOpened); got != 0 {
+		t.Errorf("QueueOpened published %d times, want 0 for a queue open from the first poll", got)
+	}
+	if app.count(events.TicketCalled) == 0 || app.count(events.TicketsDecreased) == 0 {
+		t.Errorf("expected TicketCalled and TicketsDecreased events, got %v", app.events)
+	}
+
+	// Every poll of the day changed the queue, so every one is stored
+	history := app.history(t)
+	if len(history) != len(script) {
+		t.Fatalf("stored %d history rows, want %d", len(history), len(script))
+	}
+	last := history[len(history)-1]
+	if last.Status != models.StatusClosed || last.ServedClients != 20 || last.Office != "Wrocław" {
+		t.Errorf("last history row = %+v, want the closed queue of Wrocław with 20 served", last)
+	}
+
+	if app.notifier.broadcasts != len(script) {
+		t.Errorf("broadcast %d updates, want %d", app.notifier.broadcasts, len(script))
+	}
+	closed, exhausted := false, 0
+	for _, alert := range app.notifier.alerts {
+		if alert.Kind == notifier.AlertStatus && alert.Transition == models.TransitionClosed {
+			closed = true
+		}
+		if alert.Kind == notifier.AlertTickets && alert.CurrentLeft == 0 {
+			exhausted++
+		}
+	}
+	if !closed {
+		t.Errorf("no closing status alert in %+v", app.notifier.alerts)
+	}
+	if exhausted != 1 {
+		t.Errorf("sent %d tickets exhausted alerts, want 1 for the TicketsExhausted event", exhausted)
+	}
+}
+
+func TestProcessQueueUpdateMalformedJSON(t *testing.T) {
+	app := newTestApp(t, testserver.JSON(testserver.Queue(10, 5, 2, 30, "K010")), testserver.Malformed())
+
+	if err := app.poll(t, 5*time.Second); err != nil {
+		t.Fatalf("first poll failed: %v", err)
+	}
+	if err := app.poll(t, 10*time.Second); err == nil {
+		t.Fatal("poll of malformed JSON succeeded")
+	}
+
+	if got := app.server.Requests(); got != 1+parser.FetchAttempts {
+		t.Errorf("server got %d requests, want %d", got, 1+parser.FetchAttempts)
+	}
+	if got := app.count(events.QueueUpdated); got != 1 {
+		t.Errorf("QueueUpdated published %d times, want 1", got)
+	}
+	if got := len(app.history(t)); got != 1 {
+		t.Errorf("stored %d history rows, want 1", got)
+	}
+}
+
+func TestProcessQueueUpdateMissingQueue(t *testing.T) {
+	app := newTestApp(t,
+		testserver.JSON(testserver.Queue(10, 5, 2, 30, "K010")),
+		testserver.MissingSection(),
+		testserver.MissingQueue(),
+		testserver.JSON(testserver.Queue(12, 4, 2, 28, "K012")),
+	)
+
+	for i := 0; i < 4; i++ {
+		err := app.poll(t, 5*time.Second)
+		if missing := i == 1 || i == 2; missing != errors.Is(err, parser.ErrQueueMissing) {
+			t.Fatalf("poll %d: got error %v", i+1, err)
+		}
+	}
+
+	// A missing queue is a valid response, so it is neither retried nor an outage
+	if got := app.server.Requests(); got != 4 {
+		t.Errorf("server got %d requests, want 4", got)
+	}
+	if got := app.count(events.QueueMissing); got != 1 {
+		t.Errorf("QueueMissing published %d times, want 1", got)
+	}
+	if got := app.count(events.QueueFound); got != 1 {
+		t.Errorf("QueueFound published %d times, want 1", got)
+	}
+	if got := app.count(events.QueueUpdated); got != 2 {
+		t.Errorf("QueueUpdated published %d times, want 2", got)
+	}
+	if got := len(app.history(t)); got != 2 {
+		t.Errorf("stored %d history rows, want 2", got)
+	}
+	if app.failures != 0 {
+		t.Errorf("counted %d failed polls, want 0", app.failures)
+	}
+}
+
+func TestProcessQueueUpdateServerErrors(t *testing.T) {
+	app := newTestApp(t,
+		testserver.Error(http.StatusServiceUnavailable),
+		testserver.Error(http.StatusBadGateway),
+		testserver.Error(http.StatusInternalServerError),
+		testserver.JSON(testserver.Queue(10, 5, 2, 30, "K010")),
+	)
+
+	if err := app.poll(t, 10*time.Second); err == nil {
+		t.Fatal("poll answered with 5xx succeeded")
+	}
+	if app.count(events.QueueUpdated) != 0 || len(app.history(t)) != 0 {
+		t.Fatalf("failed poll published %v and stored history", app.events)
+	}
+	if app.failures != 1 {
+		t.Errorf("counted %d failed polls, want 1", app.failures)
+	}
+
+	if err := app.poll(t, 5*time.Second); err != nil {
+		t.Fatalf("poll after the errors failed: %v", err)
+	}
+	if got := app.count(events.QueueUpdated); got != 1 {
+		t.Errorf("QueueUpdated published %d times, want 1", got)
+	}
+	if got := len(app.history(t)); got != 1 {
+		t.Errorf("stored %d history rows, want 1", got)
+	}
+	if app.failures != 0 {
+		t.Errorf("failed polls not reset by a successful one: %d", app.failures)
+	}
+}
+
+func TestProcessQueueUpdateSlowResponse(t *testing.T) {
+	slow := testserver.JSON(testserver.Queue(10, 5, 2, 30, "K010"))
+	slow.Delay = 2 * time.Second
+	late := testserver.JSON(testserver.Queue(12, 4, 2, 28, "K012"))
+	late.Delay = 50 * time.Millisecond
+	app := newTestApp(t, slow, late)
+
+	// A response slower than the poll is given up without retrying
+	if err := app.poll(t, 200*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("poll of a slow response returned %v, want a deadline error", err)
+	}
+	if app.count(events.QueueUpdated) != 0 || len(app.history(t)) != 0 {
+		t.Fatalf("timed out poll published %v and stored history", app.events)
+	}
+
+	if err := app.poll(t, 5*time.Second); err != nil {
+		t.Fatalf("poll of a response within the timeout failed: %v", err)
+	}
+	history := app.history(t)
+	if len(history) != 1 || history[0].LastTicket != "K012" {
+		t.Errorf("history = %+v, want the K012 poll only", history)
+	}
+	if got := app.count(events.QueueUpdated); got != 1 {
+		t.Errorf("QueueUpdated published %d times, want 1", got)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"karta/internal/parser/testserver"
+)
+
+// mockduw serves a simulated office day of the DUW status API for local testing:
+//
+//	go run ./cmd/mockduw -addr :8090
+//	DUW_STATUS_URL=http://localhost:8090/ go run ./cmd --dry-run
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	tickets := flag.Int("tickets", 120, "tickets issued during the day")
+	workplaces := flag.Int("workplaces", 4, "open workplaces")
+	failEvery := flag.Int("fail-every", 0, "replace every Nth poll with an error response (500, malformed JSON, missing section); 0 disables")
+	flag.Parse()
+
+	script := testserver.Day(*tickets, *workplaces)
+	if *failEvery > 0 {
+		script = injectFailures(script, *failEvery)
+	}
+
+	log.Printf("Mock DUW API with %d polls listening on %s", len(script), *addr)
+	if err := http.ListenAndServe(*addr, testserver.New(script...)); err != nil {
+		log.Fatalf("Mock DUW server failed: %v", err)
+	}
+}
+
+// injectFailures replaces every nth response with one of the failure responses in turn
+func injectFailures(script []testserver.Response, n int) []testserver.Response {
+	failures := []testserver.Response{
+		testserver.Error(http.StatusInternalServerError),
+		testserver.Malformed(),
+		testserver.MissingSection(),
+		testserver.MissingQueue(),
+	}
+
+	// The final (closed) response is repeated forever and stays intact
+	for i, failure := n-1, 0; i < len(script)-1; i, failure = i+n, failure+1 {
+		script[i] = failures[failure%len(failures)]
+	}
+	return script
+}
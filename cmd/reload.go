@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"karta/internal/bot"
+	"karta/internal/config"
+	"karta/internal/logging"
+	"karta/internal/models"
+	"karta/internal/schedule"
+)
+
+// ConfigWatchInterval is how often CONFIG_FILE is checked for changes
+const ConfigWatchInterval = 10 * time.Second
+
+// templateSetter is a channel rendering the status message with the message template
+type templateSetter interface {
+	SetMessageTemplate(template *models.MessageTemplate)
+}
+
+// reloader applies configuration changes to the running application. Only the polling
+// intervals, office hours, LOG_LEVEL and MESSAGE_TEMPLATE are reloaded; other settings
+// need a restart.
+type reloader struct {
+	cfg       *config.Config // Configuration applied last
+	bot       *bot.TelegramBot
+	templated []templateSetter
+	apps      []*Application // Monitoring of each office, the primary one first
+}
+
+// run reloads the configuration on SIGHUP and when CONFIG_FILE changes, until the
+// context is cancelled
+func (r *reloader) run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Without a file only SIGHUP reloads, e.g. after editing the systemd EnvironmentFile
+	var watch <-chan time.Time
+	modTime := fileModTime(r.cfg.ConfigFile)
+	if r.cfg.ConfigFile != "" {
+		ticker := time.NewTicker(ConfigWatchInterval)
+		defer ticker.Stop()
+		watch = ticker.C
+		log.Printf("Watching %s for configuration changes", r.cfg.ConfigFile)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Println("SIGHUP received, reloading configuration")
+			r.reload()
+		case <-watch:
+			if current := fileModTime(r.cfg.ConfigFile); !current.Equal(modTime) {
+				modTime = current
+				log.Printf("%s changed, reloading configuration", r.cfg.ConfigFile)
+				r.reload()
+			}
+		}
+	}
+}
+
+// reload loads the configuration again and applies the reloadable settings; an invalid
+// configuration is logged and the current one kept
+func (r *reloader) reload() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("Keeping the current configuration: %v", err)
+		return
+	}
+
+	template, err := loadTemplate(cfg.MessageTemplate)
+	if err != nil {
+		log.Printf("Keeping the current configuration: invalid MESSAGE_TEMPLATE: %v", err)
+		return
+	}
+
+	logging.SetLevel(cfg.LogLevel)
+
+	for _, channel := range r.templated {
+		channel.SetMessageTemplate(template)
+	}
+
+	r.bot.SetSchedule(cfg.Schedule)
+	policy := pollingPolicy(cfg)
+	for _, app := range r.apps {
+		app.setSchedule(cfg.Schedule)
+		app.parser.SetPollingPolicy(policy)
+
+		// An interval set with /interval stays until POLL_INTERVAL itself changes
+		if cfg.PollInterval != r.cfg.PollInterval {
+			app.parser.SetInterval(cfg.PollInterval)
+		}
+	}
+
+	r.cfg = cfg
+	log.Printf("Configuration reloaded: polling every %v, log level %s, message template %q", cfg.PollInterval, cfg.LogLevel, cfg.MessageTemplate)
+}
+
+// loadTemplate loads the message template file, nil for the default layout
+func loadTemplate(path string) (*models.MessageTemplate, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return models.LoadMessageTemplate(path)
+}
+
+// setSchedule changes the office hours used for the open state of queue events
+func (app *Application) setSchedule(s *schedule.Schedule) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.schedule = s
+}
+
+// fileModTime returns the modification time of a file, zero when it cannot be read
+func fileModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"karta/internal/bot"
+	"karta/internal/config"
+	"karta/internal/database"
+	"karta/internal/export"
+	"karta/internal/models"
+	"karta/internal/notifier"
+	"karta/internal/prediction"
+	"karta/internal/state"
+)
+
+const (
+	// MaxReplayGap caps the history gap (e.g. overnight) waited for between two replayed records
+	MaxReplayGap      = time.Hour
+	ReplayLogInterval = 100 // Records between progress log lines
+)
+
+// replayOptions configures a replay of stored queue history
+type replayOptions struct {
+	from, to string  // Range as accepted by export.ParseRange
+	speed    float64 // Playback speed factor; 0 replays without delays
+	chatID   int64   // Chat receiving the messages; 0 only logs them
+	ticket   string  // Ticket number of the replay user, for wait estimates
+}
+
+// runReplay feeds stored queue history through processQueueUpdate. Replayed data and the
+// single replay user live in a temporary database, so production history and users are
+// never touched.
+func runReplay(ctx context.Context, cfg *config.Config, opts replayOptions) error {
+	// Ctrl-C stops the replay between records, so the scratch database is still removed
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	source, err := database.Open(cfg.DatabaseURL, cfg.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer source.Close()
+
+	// Without an end the replay covers the rest of the start day
+	end := opts.to
+	if end == "" && len(opts.from) >= len("2006-01-02") {
+		end = opts.from[:len("2006-01-02")]
+	}
+
+	from, to, err := export.ParseRange(opts.from, end, time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid replay range: %w", err)
+	}
+
+	history, err := source.GetQueueDataBetween(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("no queue history between %s and %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	dir, err := os.MkdirTemp("", "karta-replay-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	scratch, err := database.NewDatabase(filepath.Join(dir, "replay.db"))
+	if err != nil {
+		return err
+	}
+	defer scratch.Close()
+
+	if err := scratch.AddUser(ctx, opts.chatID, "replay"); err != nil {
+		return err
+	}
+	if opts.ticket != "" {
+		if err := scratch.SetUserTicketNumber(ctx, opts.chatID, opts.ticket); err != nil {
+			return err
+		}
+	}
+
+	// Estimates are built from the records replayed so far
+	predictor := prediction.NewPredictor(scratch, PredictionWindow)
+	forecaster := prediction.NewExhaustionForecaster(scratch)
+
+	telegramBot, err := bot.NewTelegramBot(ctx, cfg.TelegramBotToken, scratch, predictor, forecaster)
+	if err != nil {
+		return err
+	}
+	telegramBot.SetSchedule(cfg.Schedule)
+	telegramBot.SetDryRun(opts.chatID == 0)
+	telegramBot.SetParseMode(cfg.ParseMode)
+	telegramBot.SetSilentMessages(cfg.SilentMessages)
+	if cfg.MessageTemplate != "" {
+		messageTemplate, err := models.LoadMessageTemplate(cfg.MessageTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid MESSAGE_TEMPLATE: %w", err)
+		}
+		telegramBot.SetMessageTemplate(messageTemplate)
+	}
+
+	queueState := state.NewQueue(scratch)
+	telegramBot.SetQueueState(queueState)
+
+	var replayTime time.Time
+	app := &Application{
+		db:         scratch,
+		queue:      queueState,
+		bot:        telegramBot,
+		notifier:   notifier.Multi{telegramBot},
+		schedule:   cfg.Schedule,
+		comparison: cfg.Comparison,
+
+		snapshotInterval: cfg.HistorySnapshotInterval,
+		now:              func() time.Time { return replayTime },
+	}
+	app.subscribe()
+
+	log.Printf("Replaying %d records from %s to %s", len(history), from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	for i, queueData := range history {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				log.Printf("Replay stopped after %d/%d records", i, len(history))
+				return ctx.Err()
+			case <-time.After(opts.wait(queueData.LastUpdated.Sub(history[i-1].LastUpdated))):
+			}
+		}
+
+		replayTime = queueData.LastUpdated
+		app.processQueueUpdate(ctx, queueData)
+
+		if (i+1)%ReplayLogInterval == 0 {
+			log.Printf("Replayed %d/%d records (%s)", i+1, len(history), replayTime.Format(time.RFC3339))
+		}
+	}
+
+	log.Printf("Replay finished: %d records", len(history))
+	return nil
+}
+
+// wait returns the real time to wait for a gap between two historical records
+func (o replayOptions) wait(gap time.Duration) time.Duration {
+	if o.speed <= 0 || gap <= 0 {
+		return 0
+	}
+	if gap > MaxReplayGap {
+		gap = MaxReplayGap
+	}
+	return time.Duration(float64(gap) / o.speed)
+}
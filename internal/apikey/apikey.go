@@ -0,0 +1,88 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	ScopeRead  = "read"  // Queue data, history exports and the Grafana data source
+	ScopeAdmin = "admin" // Everything, including history imports
+
+	Prefix           = "karta_"
+	DefaultRateLimit = 60 // Requests per minute
+	RateWindow       = time.Minute
+)
+
+// ParseScope validates a scope name
+func ParseScope(value string) (string, error) {
+	switch value {
+	case ScopeRead, ScopeAdmin:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown scope %q, expected %s or %s", value, ScopeRead, ScopeAdmin)
+	}
+}
+
+// Allows reports whether a key of the scope may use endpoints requiring the required scope
+func Allows(scope, required string) bool {
+	return scope == ScopeAdmin || scope == required
+}
+
+// Generate creates a random key and the hash it is stored by. Only the hash is stored, so
+// the key can be shown once and a leaked database does not reveal it.
+func Generate() (key, hash string, err error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key = Prefix + hex.EncodeToString(secret)
+	return key, Hash(key), nil
+}
+
+// Hash returns the stored form of a key
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// window counts the requests of a key in the current RateWindow
+type window struct {
+	start time.Time
+	count int
+}
+
+// Limiter limits the requests of each key to its rate per RateWindow. Safe for concurrent use.
+type Limiter struct {
+	mu      sync.Mutex
+	windows map[int64]*window
+}
+
+// NewLimiter creates an empty limiter
+func NewLimiter() *Limiter {
+	return &Limiter{windows: make(map[int64]*window)}
+}
+
+// Allow records a request of the key and reports whether it is within the limit; otherwise
+// it returns how long until the next window
+func (l *Limiter) Allow(id int64, limit int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := l.windows[id]
+	if current == nil || now.Sub(current.start) >= RateWindow {
+		current = &window{start: now}
+		l.windows[id] = current
+	}
+
+	if current.count >= limit {
+		return false, current.start.Add(RateWindow).Sub(now)
+	}
+	current.count++
+	return true, 0
+}
@@ -0,0 +1,227 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultInterval = 24 * time.Hour
+	DefaultKeep     = 7
+
+	NamePrefix      = "karta-"
+	nameTimeFormat  = "20060102-150405"
+	SQLiteExtension = ".db"
+	PGDumpExtension = ".dump" // pg_dump custom format
+)
+
+// Snapshotter writes a consistent copy of a live SQLite database to a new file
+type Snapshotter interface {
+	Snapshot(ctx context.Context, path string) error
+}
+
+// Manager periodically backs up the database to a storage and keeps the newest backups
+type Manager struct {
+	storage   Storage
+	keep      int
+	extension string
+	dump      func(ctx context.Context, path string) error
+}
+
+// NewSQLiteManager creates a manager backing up a SQLite database with VACUUM INTO
+func NewSQLiteManager(db Snapshotter, storage Storage, keep int) *Manager {
+	return &Manager{
+		storage:   storage,
+		keep:      keep,
+		extension: SQLiteExtension,
+		dump: func(ctx context.Context, path string) error {
+			return db.Snapshot(ctx, path)
+		},
+	}
+}
+
+// NewPostgresManager creates a manager backing up a PostgreSQL database with pg_dump,
+// which must be installed
+func NewPostgresManager(databaseURL string, storage Storage, keep int) *Manager {
+	return &Manager{
+		storage:   storage,
+		keep:      keep,
+		extension: PGDumpExtension,
+		dump: func(ctx context.Context, path string) error {
+			return runCommand(ctx, "pg_dump", "--format=custom", "--no-owner", "--file="+path, "--dbname="+databaseURL)
+		},
+	}
+}
+
+// Start runs a backup every interval until the context is cancelled
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Starting database backups to %s every %v, keeping %d", m.storage, interval, m.keep)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Database backups stopped")
+			return
+		case <-ticker.C:
+			if err := m.Backup(ctx); err != nil {
+				log.Printf("Failed to back up database: %v", err)
+			}
+		}
+	}
+}
+
+// Backup dumps the database, uploads it and deletes backups beyond the retention count
+func (m *Manager) Backup(ctx context.Context) error {
+	start := time.Now()
+
+	dir, err := os.MkdirTemp("", "karta-backup-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := NamePrefix + start.UTC().Format(nameTimeFormat) + m.extension
+	path := filepath.Join(dir, name)
+
+	if err := m.dump(ctx, path); err != nil {
+		return err
+	}
+	if err := m.storage.Upload(ctx, name, path); err != nil {
+		return err
+	}
+
+	log.Printf("Database backed up to %s/%s in %v", strings.TrimRight(m.storage.String(), "/"), name, time.Since(start).Round(time.Millisecond))
+	return m.prune(ctx)
+}
+
+// prune deletes the oldest backups so that at most keep remain
+func (m *Manager) prune(ctx context.Context) error {
+	names, err := backupNames(ctx, m.storage, m.extension)
+	if err != nil {
+		return err
+	}
+
+	for len(names) > m.keep {
+		if err := m.storage.Delete(ctx, names[0]); err != nil {
+			return err
+		}
+		log.Printf("Deleted old backup %s", names[0])
+		names = names[1:]
+	}
+	return nil
+}
+
+// backupNames lists the backups with the given extension, oldest first
+func backupNames(ctx context.Context, storage Storage, extension string) ([]string, error) {
+	all, err := storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range all {
+		if strings.HasPrefix(name, NamePrefix) && strings.HasSuffix(name, extension) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// RestoreSQLite replaces the SQLite database at dbPath with a backup. The backup is a
+// local file path, a name in storage, or "latest". The database must not be open.
+func RestoreSQLite(ctx context.Context, storage Storage, backup, dbPath string) error {
+	source, cleanup, err := fetch(ctx, storage, backup, SQLiteExtension)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := copyFile(source, dbPath); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	// A leftover write-ahead log belongs to the replaced database
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s%s: %w", dbPath, suffix, err)
+		}
+	}
+
+	log.Printf("Database %s restored from %s", dbPath, backup)
+	return nil
+}
+
+// RestorePostgres restores a pg_dump backup into the PostgreSQL database with pg_restore,
+// replacing existing tables. The backup is resolved as in RestoreSQLite.
+func RestorePostgres(ctx context.Context, storage Storage, backup, databaseURL string) error {
+	source, cleanup, err := fetch(ctx, storage, backup, PGDumpExtension)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := runCommand(ctx, "pg_restore", "--clean", "--if-exists", "--no-owner", "--single-transaction", "--dbname="+databaseURL, source); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	log.Printf("Database restored from %s", backup)
+	return nil
+}
+
+// fetch resolves a backup to a local file, downloading it from storage when needed,
+// and returns a cleanup function removing the download
+func fetch(ctx context.Context, storage Storage, backup, extension string) (string, func(), error) {
+	noop := func() {}
+	if info, err := os.Stat(backup); err == nil && info.Mode().IsRegular() {
+		return backup, noop, nil
+	}
+
+	if storage == nil {
+		return "", noop, fmt.Errorf("backup file %s not found and no backup storage is configured", backup)
+	}
+
+	name := backup
+	if backup == "latest" {
+		names, err := backupNames(ctx, storage, extension)
+		if err != nil {
+			return "", noop, err
+		}
+		if len(names) == 0 {
+			return "", noop, fmt.Errorf("no backups found in %s", storage)
+		}
+		name = names[len(names)-1]
+	}
+
+	dir, err := os.MkdirTemp("", "karta-restore-")
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	path := filepath.Join(dir, name)
+	if err := storage.Download(ctx, name, path); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+
+	log.Printf("Downloaded backup %s from %s", name, storage)
+	return path, cleanup, nil
+}
+
+// runCommand runs an external tool and includes its output in the error
+func runCommand(ctx context.Context, name string, args ...string) error {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	S3Timeout       = 10 * time.Minute
+	S3DefaultRegion = "us-east-1"
+)
+
+// S3Storage keeps backups in a bucket of an S3-compatible service (AWS S3, MinIO,
+// Backblaze B2, ...), using path-style URLs and AWS Signature Version 4
+type S3Storage struct {
+	endpoint  *url.URL
+	bucket    string
+	prefix    string // Key prefix, e.g. "karta/"
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3Storage creates a storage for bucket at endpoint (e.g. https://s3.eu-central-1.amazonaws.com)
+func NewS3Storage(endpoint, bucket, prefix, region, accessKey, secretKey string) (*S3Storage, error) {
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if region == "" {
+		region = S3DefaultRegion
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return &S3Storage{
+		endpoint:  parsed,
+		bucket:    bucket,
+		prefix:    prefix,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: S3Timeout},
+	}, nil
+}
+
+// Upload puts the file as an object
+func (s *S3Storage) Upload(ctx context.Context, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	// The payload hash is part of the signature, so the file is read twice
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind %s: %w", path, err)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, s.prefix+name, nil, file, size, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return fmt.Errorf("failed to upload backup: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Download gets an object into the local file at path
+func (s *S3Storage) Download(ctx context.Context, name, path string) error {
+	resp, err := s.do(ctx, http.MethodGet, s.prefix+name, nil, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("failed to download backup: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return writeFile(path, resp.Body)
+}
+
+// listBucketResult is the ListObjectsV2 response
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the object names under the key prefix
+func (s *S3Storage) List(ctx context.Context) ([]string, error) {
+	var names []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, 0, emptyPayloadHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups: %w", err)
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode backup list: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			if name := strings.TrimPrefix(object.Key, s.prefix); name != "" && !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete removes an object
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.prefix+name, nil, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) String() string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, s.prefix)
+}
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// do sends a signed request for key (the bucket itself when empty) and returns the
+// response if its status is 2xx
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	path := s.endpoint.Path + "/" + s.bucket
+	if key != "" {
+		path += "/" + key
+	}
+
+	target := *s.endpoint
+	target.Path = path
+	target.RawPath = escapePath(path)
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s.sign(req, target.RawPath, target.RawQuery, payloadHash, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("S3 returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to req
+func (s *S3Storage) sign(req *http.Request, escapedPath, rawQuery, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapedPath,
+		rawQuery,
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath URI-encodes each path segment as required by Signature Version 4
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escapeRFC3986(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes query parameters sorted by name
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, escapeRFC3986(key)+"="+escapeRFC3986(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapeRFC3986 percent-encodes everything except unreserved characters
+func escapeRFC3986(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
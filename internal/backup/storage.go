@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Storage keeps backup files under flat names
+type Storage interface {
+	// Upload stores the local file at path under name
+	Upload(ctx context.Context, name, path string) error
+	// Download writes the backup name to the local file at path
+	Download(ctx context.Context, name, path string) error
+	// List returns the names of all stored backups in ascending order
+	List(ctx context.Context) ([]string, error)
+	// Delete removes the backup name
+	Delete(ctx context.Context, name string) error
+	// String describes the storage location for logs
+	String() string
+}
+
+// DirStorage keeps backups in a local directory
+type DirStorage struct {
+	dir string
+}
+
+// NewDirStorage creates a storage in dir, creating the directory if needed
+func NewDirStorage(dir string) (*DirStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return &DirStorage{dir: dir}, nil
+}
+
+// Upload copies the file into the backup directory, replacing it atomically
+func (s *DirStorage) Upload(ctx context.Context, name, path string) error {
+	return copyFile(path, filepath.Join(s.dir, name))
+}
+
+// Download copies a backup out of the backup directory
+func (s *DirStorage) Download(ctx context.Context, name, path string) error {
+	return copyFile(filepath.Join(s.dir, name), path)
+}
+
+// List returns the file names in the backup directory
+func (s *DirStorage) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete removes a backup file
+func (s *DirStorage) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	return nil
+}
+
+func (s *DirStorage) String() string {
+	return s.dir
+}
+
+// copyFile copies src to dst through a temporary file, so dst is never left half-written
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	return writeFile(dst, in)
+}
+
+// writeFile writes r to path through a temporary file in the same directory
+func writeFile(path string, r io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", path, err)
+	}
+	return nil
+}
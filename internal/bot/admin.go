@@ -0,0 +1,332 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/export"
+	"karta/internal/i18n"
+	"karta/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	MinMonitoringInterval = 5 * time.Second
+	MaxMonitoringInterval = time.Hour
+	MaxListedUsers        = 30
+	MaxListedCommands     = 10 // Most used commands shown by /botstats
+
+	// AnnouncementProgress is the key of the stored progress of an interrupted /broadcast
+	AnnouncementProgress = "announcement"
+	// AnnouncementResumeWindow is how long after a shutdown an interrupted /broadcast is
+	// resumed; an older one is dropped, as it may no longer be true
+	AnnouncementResumeWindow = 24 * time.Hour
+)
+
+// SetAdmins sets the chat IDs allowed to use admin commands
+func (b *TelegramBot) SetAdmins(chatIDs []int64) {
+	b.admins = make(map[int64]bool, len(chatIDs))
+	for _, chatID := range chatIDs {
+		b.admins[chatID] = true
+	}
+	log.Printf("Configured %d bot admins", len(chatIDs))
+}
+
+// SetIntervalHandler sets the callbacks used by /setinterval to change the polling interval
+// and by /botstats to show the configured and the effective (adaptive) interval
+func (b *TelegramBot) SetIntervalHandler(setInterval func(time.Duration), currentInterval, effectiveInterval func() time.Duration) {
+	b.setInterval = setInterval
+	b.currentInterval = currentInterval
+	b.effectiveInterval = effectiveInterval
+}
+
+// isAdmin checks if the chat belongs to a configured admin
+func (b *TelegramBot) isAdmin(chatID int64) bool {
+	return b.admins[chatID]
+}
+
+// handleAdminStats sends bot statistics to an admin
+func (b *TelegramBot) handleAdminStats(ctx context.Context, chatID int64, lang i18n.Language) {
+	stats, err := b.GetStats(ctx)
+	if err != nil {
+		log.Printf("Failed to get stats: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.stats_error"))
+		return
+	}
+
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "admin.stats_title") + "\n\n")
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", models.EscapeMarkdown(key), models.EscapeMarkdown(fmt.Sprint(stats[key]))))
+	}
+
+	if b.currentInterval != nil {
+		builder.WriteString(fmt.Sprintf("monitoring\\_interval: %s\n", models.EscapeMarkdown(b.currentInterval().String())))
+	}
+	if b.effectiveInterval != nil {
+		builder.WriteString(fmt.Sprintf("effective\\_interval: %s\n", models.EscapeMarkdown(b.effectiveInterval().String())))
+	}
+	builder.WriteString(b.formatActivityStats(ctx, time.Now()))
+
+	b.sendMessage(ctx, chatID, builder.String())
+}
+
+// formatActivityStats formats the engagement lines of /botstats
+func (b *TelegramBot) formatActivityStats(ctx context.Context, now time.Time) string {
+	activity, err := b.db.GetActivityStats(ctx, now)
+	if err != nil {
+		log.Printf("Failed to get activity stats: %v", err)
+		return ""
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("\ndaily\\_active\\_users: %d\n", activity.DailyActive))
+	builder.WriteString(fmt.Sprintf("weekly\\_active\\_users: %d\n", activity.WeeklyActive))
+	builder.WriteString(fmt.Sprintf("churned\\_users: %d\n", activity.Churned))
+	builder.WriteString(fmt.Sprintf("notifications: %d\n", activity.Notifications))
+	builder.WriteString(fmt.Sprintf("notification\\_responses: %d\n", activity.NotificationResponses))
+
+	usage, err := b.db.GetCommandUsage(ctx, now.Add(-database.WeeklyActivePeriod))
+	if err != nil {
+		log.Printf("Failed to get command usage: %v", err)
+		return builder.String()
+	}
+
+	// Weekly usage shows what users need, the least used commands are cut off
+	commands := make([]string, 0, len(usage))
+	for i, command := range usage {
+		if i == MaxListedCommands {
+			break
+		}
+		commands = append(commands, fmt.Sprintf("/%s %d", command.Command, command.Count))
+	}
+	if len(commands) > 0 {
+		builder.WriteString(fmt.Sprintf("commands\\_7d: %s\n", models.EscapeMarkdown(strings.Join(commands, ", "))))
+	}
+	return builder.String()
+}
+
+// handleAdminBroadcast sends a free-form text to all active users
+func (b *TelegramBot) handleAdminBroadcast(ctx context.Context, chatID int64, text string, lang i18n.Language) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.broadcast_usage"))
+		return
+	}
+
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.users_error"))
+		return
+	}
+
+	message := "📢 " + models.EscapeMarkdown(text)
+
+	// Reaching every user takes longer than HandlerTimeout, so only a shutdown stops the
+	// broadcast; the users not reached by then get the announcement after the restart
+	ctx = shutdownContext(ctx)
+	sentCount, err := b.announce(ctx, time.Now(), message, users)
+	if err != nil {
+		log.Printf("Failed to finish the announcement: %v", err)
+		return
+	}
+
+	b.sendMessage(ctx, chatID, i18n.T(lang, "admin.broadcast_done", sentCount, len(users)))
+}
+
+// announce sends an admin announcement to the users and returns the number reached. A
+// broadcast interrupted by a shutdown saves the users it did not reach and fails, so
+// ResumeAnnouncement sends it to those users only after the restart.
+func (b *TelegramBot) announce(ctx context.Context, startedAt time.Time, message string, users []database.User) (int, error) {
+	successCount, errorCount, pending := b.broadcastUntil(ctx, users, func(ctx context.Context, user database.User) error {
+		return b.deliverMessage(ctx, user.ChatID, models.MessageAnnouncement, message)
+	})
+	if len(pending) == 0 {
+		log.Printf("Announcement sent: %d successful, %d errors", successCount, errorCount)
+		return successCount, nil
+	}
+
+	log.Printf("Announcement interrupted: %d successful, %d errors, %d users left", successCount, errorCount, len(pending))
+	progress := database.BroadcastProgress{Office: AnnouncementProgress, PolledAt: startedAt, Message: message, SavedAt: time.Now()}
+	for _, user := range pending {
+		progress.Pending = append(progress.Pending, user.ChatID)
+	}
+	// Saving is not cancelled, as the broadcast was interrupted by a cancelled context
+	if err := b.db.SaveBroadcastProgress(context.WithoutCancel(ctx), progress); err != nil {
+		log.Printf("Failed to save interrupted announcement: %v", err)
+	}
+	return successCount, fmt.Errorf("announcement interrupted with %d users left: %w", len(pending), ctx.Err())
+}
+
+// ResumeAnnouncement sends an announcement interrupted by the last shutdown to the active
+// users it did not reach, unless it was interrupted more than AnnouncementResumeWindow ago
+func (b *TelegramBot) ResumeAnnouncement(ctx context.Context) error {
+	progress, err := b.db.GetBroadcastProgress(ctx, AnnouncementProgress)
+	if err != nil {
+		return fmt.Errorf("failed to get interrupted announcement: %w", err)
+	}
+	if progress == nil {
+		return nil
+	}
+
+	if time.Since(progress.SavedAt) <= AnnouncementResumeWindow {
+		users, err := b.db.GetActiveUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get active users: %w", err)
+		}
+
+		pending := make(map[int64]bool, len(progress.Pending))
+		for _, chatID := range progress.Pending {
+			pending[chatID] = true
+		}
+		var remaining []database.User
+		for _, user := range users {
+			if pending[user.ChatID] {
+				remaining = append(remaining, user)
+			}
+		}
+
+		log.Printf("Resuming announcement of %s for %d users", progress.PolledAt.Format(time.RFC3339), len(remaining))
+		if _, err := b.announce(ctx, progress.PolledAt, progress.Message, remaining); err != nil {
+			return err
+		}
+	} else {
+		log.Printf("Dropping announcement of %s, interrupted at %s", progress.PolledAt.Format(time.RFC3339), progress.SavedAt.Format(time.RFC3339))
+	}
+
+	if err := b.db.DeleteBroadcastProgress(ctx, AnnouncementProgress); err != nil {
+		return fmt.Errorf("failed to delete interrupted announcement: %w", err)
+	}
+	return nil
+}
+
+// handleAdminUsers lists active users
+func (b *TelegramBot) handleAdminUsers(ctx context.Context, chatID int64, lang i18n.Language) {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.users_error"))
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "admin.users_title", len(users)) + "\n\n")
+
+	for i, user := range users {
+		if i == MaxListedUsers {
+			builder.WriteString(i18n.T(lang, "admin.users_more", len(users)-MaxListedUsers))
+			break
+		}
+
+		line := fmt.Sprintf("%d @%s", user.ChatID, user.Username)
+		if user.TicketNumber != "" {
+			line += " " + user.TicketNumber
+		}
+		builder.WriteString(models.EscapeMarkdown(line) + "\n")
+	}
+
+	b.sendMessage(ctx, chatID, builder.String())
+}
+
+// handleAdminBan bans or unbans a user by chat ID
+func (b *TelegramBot) handleAdminBan(ctx context.Context, chatID int64, args string, banned bool, lang i18n.Language) {
+	targetID, err := strconv.ParseInt(strings.TrimSpace(args), 10, 64)
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.ban_usage"))
+		return
+	}
+
+	if err := b.db.SetUserBanned(ctx, targetID, banned); err != nil {
+		log.Printf("Failed to update ban for %d: %v", targetID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.ban_failed", targetID, models.EscapeMarkdown(err.Error())))
+		return
+	}
+
+	if banned {
+		b.forgetMessageID(ctx, targetID)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.banned", targetID))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.unbanned", targetID))
+	}
+}
+
+// handleAdminSetInterval changes the queue polling interval, e.g. "/setinterval 30s"
+func (b *TelegramBot) handleAdminSetInterval(ctx context.Context, chatID int64, args string, lang i18n.Language) {
+	if b.setInterval == nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.interval_unavailable"))
+		return
+	}
+
+	interval, err := time.ParseDuration(strings.TrimSpace(args))
+	if err != nil || interval < MinMonitoringInterval || interval > MaxMonitoringInterval {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.interval_usage",
+			models.EscapeMarkdown(MinMonitoringInterval.String()), models.EscapeMarkdown(MaxMonitoringInterval.String())))
+		return
+	}
+
+	b.setInterval(interval)
+	b.sendMessage(ctx, chatID, i18n.T(lang, "admin.interval_changed", models.EscapeMarkdown(interval.String())))
+}
+
+// handleAdminExport sends queue history as a CSV or JSON file, e.g. "/export json 2024-05-01 2024-05-07"
+func (b *TelegramBot) handleAdminExport(ctx context.Context, chatID int64, args string, lang i18n.Language) {
+	fields := strings.Fields(args)
+
+	format := export.FormatCSV
+	if len(fields) > 0 {
+		if parsed, err := export.ParseFormat(fields[0]); err == nil {
+			format = parsed
+			fields = fields[1:]
+		}
+	}
+
+	var from, to string
+	switch len(fields) {
+	case 0:
+	case 1:
+		from = fields[0]
+	case 2:
+		from, to = fields[0], fields[1]
+	default:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.export_usage"))
+		return
+	}
+
+	start, end, err := export.ParseRange(from, to, time.Now())
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.export_usage"))
+		return
+	}
+
+	var buffer bytes.Buffer
+	if err := export.Write(ctx, &buffer, format, b.db, start, end); err != nil {
+		log.Printf("Failed to export history: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	document := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  export.FileName(format, start, end),
+		Bytes: buffer.Bytes(),
+	})
+	if _, err := b.send(ctx, chatID, document); err != nil {
+		log.Printf("Failed to send export to %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.export_failed", models.EscapeMarkdown(err.Error())))
+	}
+}
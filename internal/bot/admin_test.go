@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"karta/internal/database"
+)
+
+// addUsers registers active users with the given chat IDs
+func addUsers(t *testing.T, db database.Store, chatIDs ...int64) []database.User {
+	t.Helper()
+
+	ctx := context.Background()
+	for _, chatID := range chatIDs {
+		if err := db.AddUser(ctx, chatID, "user"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	users, err := db.GetActiveUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return users
+}
+
+// recipients returns the chat IDs the fake sender sent messages to, sorted
+func (f *fakeSender) recipients() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var chatIDs []string
+	for _, call := range f.calls {
+		if call.method == "sendMessage" {
+			chatIDs = append(chatIDs, call.params.Get("chat_id"))
+		}
+	}
+	sort.Strings(chatIDs)
+	return chatIDs
+}
+
+func TestAnnouncementResumedAfterShutdown(t *testing.T) {
+	b, sender, db := newTestBot(t)
+	users := addUsers(t, db, 1, 2, 3)
+
+	// A shutdown before the first send leaves every user for the restart
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.announce(ctx, time.Now(), "📢 Office closed tomorrow", users); err == nil {
+		t.Fatal("announce succeeded after shutdown")
+	}
+	if got := sender.recipients(); len(got) != 0 {
+		t.Fatalf("sent to %v after shutdown", got)
+	}
+
+	progress, err := db.GetBroadcastProgress(context.Background(), AnnouncementProgress)
+	if err != nil || progress == nil {
+		t.Fatalf("no saved announcement: %v", err)
+	}
+	if progress.Message != "📢 Office closed tomorrow" || len(progress.Pending) != 3 {
+		t.Errorf("saved announcement = %+v, want the message for 3 users", progress)
+	}
+
+	// Users reached before the shutdown are not sent it again
+	progress.Pending = []int64{1, 3}
+	if err := db.SaveBroadcastProgress(context.Background(), *progress); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ResumeAnnouncement(context.Background()); err != nil {
+		t.Fatalf("ResumeAnnouncement failed: %v", err)
+	}
+
+	if got := sender.recipients(); !reflect.DeepEqual(got, []string{"1", "3"}) {
+		t.Errorf("resumed announcement sent to %v, want [1 3]", got)
+	}
+	if texts := sender.texts(); len(texts) != 2 || texts[0] != "📢 Office closed tomorrow" {
+		t.Errorf("resumed announcement texts = %q", texts)
+	}
+	if progress, err := db.GetBroadcastProgress(context.Background(), AnnouncementProgress); err != nil || progress != nil {
+		t.Errorf("announcement progress after resuming = %+v, %v, want nil", progress, err)
+	}
+}
+
+func TestAnnouncementDroppedWhenOld(t *testing.T) {
+	b, sender, db := newTestBot(t)
+	addUsers(t, db, 1)
+
+	progress := database.BroadcastProgress{
+		Office:   AnnouncementProgress,
+		PolledAt: time.Now().Add(-48 * time.Hour),
+		Pending:  []int64{1},
+		Message:  "📢 Office closed today",
+		SavedAt:  time.Now().Add(-AnnouncementResumeWindow - time.Hour),
+	}
+	if err := db.SaveBroadcastProgress(context.Background(), progress); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.ResumeAnnouncement(context.Background()); err != nil {
+		t.Fatalf("ResumeAnnouncement failed: %v", err)
+	}
+	if got := sender.recipients(); len(got) != 0 {
+		t.Errorf("outdated announcement sent to %v", got)
+	}
+	if progress, err := db.GetBroadcastProgress(context.Background(), AnnouncementProgress); err != nil || progress != nil {
+		t.Errorf("outdated announcement kept: %+v, %v", progress, err)
+	}
+}
@@ -0,0 +1,206 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/notifier"
+)
+
+// handleAlertsCommand handles the /alerts on|off command for queue open/close alerts
+func (b *TelegramBot) handleAlertsCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	var enabled bool
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "alerts.usage"))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserStatusAlerts(ctx, chatID, enabled); err != nil {
+		log.Printf("Failed to set status alerts for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if enabled {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "alerts.enabled"))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "alerts.disabled"))
+	}
+}
+
+// BroadcastStatusAlert sends a dedicated open/close notification to users of the office who enabled alerts
+func (b *TelegramBot) BroadcastStatusAlert(ctx context.Context, queueData *models.QueueData, transition models.StatusTransition) error {
+	if transition == models.TransitionNone {
+		return nil
+	}
+	ticketsForecast := b.ticketsForecast(ctx, queueData)
+
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	// Muted users only get the alerts about their own ticket
+	var recipients []database.User
+	for _, user := range unmutedUsers(b.officeUsers(users, queueData), time.Now()) {
+		if user.StatusAlerts {
+			recipients = append(recipients, user)
+		}
+	}
+
+	sentCount, err := b.broadcastAlert(ctx, notifier.AlertStatus, queueData, recipients, func(ctx context.Context, user database.User) error {
+		message := queueData.FormatStatusAlert(i18n.OrDefault(user.Language), transition, ticketsForecast)
+		return b.deliverMessage(ctx, user.ChatID, models.MessageStatusAlert, message)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Status alert sent to %d users", sentCount)
+	return nil
+}
+
+// broadcastAlert sends an alert of the given kind to its recipients and returns the number
+// of users reached. A broadcast interrupted by a shutdown saves the users it did not reach
+// and fails, so the outbox redelivers the alert to those users only.
+func (b *TelegramBot) broadcastAlert(ctx context.Context, alert notifier.AlertKind, queueData *models.QueueData, recipients []database.User, deliver func(ctx context.Context, user database.User) error) (int, error) {
+	recipients, resumed := b.resumeBroadcast(ctx, alert, queueData, recipients)
+	if len(recipients) == 0 {
+		b.finishBroadcast(ctx, alert, queueData, resumed)
+		return 0, nil
+	}
+
+	successCount, errorCount, pending := b.broadcastUntil(ctx, recipients, deliver)
+	if len(pending) > 0 {
+		log.Printf("Broadcast of %s alert interrupted: %d successful, %d errors, %d users left", alert, successCount, errorCount, len(pending))
+		b.saveBroadcast(ctx, alert, queueData, pending)
+		return successCount, fmt.Errorf("%s alert interrupted with %d users left: %w", alert, len(pending), ctx.Err())
+	}
+
+	b.finishBroadcast(ctx, alert, queueData, resumed)
+	return successCount, nil
+}
+
+// handleThresholdCommand handles the /threshold N|off command for tickets-exhausted alerts
+func (b *TelegramBot) handleThresholdCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	args = strings.ToLower(strings.TrimSpace(args))
+
+	threshold := -1
+	if args != "off" {
+		value, err := strconv.Atoi(args)
+		if err != nil || value < 0 {
+			b.sendMessage(ctx, chatID, i18n.T(lang, "threshold.usage"))
+			return
+		}
+		threshold = value
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserTicketsAlert(ctx, chatID, threshold); err != nil {
+		log.Printf("Failed to set tickets alert for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if threshold < 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "threshold.disabled"))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "threshold.enabled", threshold))
+	}
+}
+
+// BroadcastTicketsAlert notifies users whose tickets-left threshold was crossed by the latest update
+func (b *TelegramBot) BroadcastTicketsAlert(ctx context.Context, queueData *models.QueueData, previousLeft, currentLeft int) error {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	var recipients []database.User
+	for _, user := range unmutedUsers(b.officeUsers(users, queueData), time.Now()) {
+		if models.TicketsThresholdCrossed(previousLeft, currentLeft, user.TicketsAlert) {
+			recipients = append(recipients, user)
+		}
+	}
+
+	sentCount, err := b.broadcastAlert(ctx, notifier.AlertTickets, queueData, recipients, func(ctx context.Context, user database.User) error {
+		message := queueData.FormatTicketsAlert(i18n.OrDefault(user.Language), currentLeft)
+		return b.deliverMessage(ctx, user.ChatID, models.MessageTicketsAlert, message)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Tickets alert sent to %d users", sentCount)
+	return nil
+}
+
+// BroadcastCalledTickets tells users following the office of the data that their ticket
+// was called, when the current ticket moved from before their ticket to it or past it
+func (b *TelegramBot) BroadcastCalledTickets(ctx context.Context, queueData *models.QueueData, previousTicket string) error {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	var recipients []database.User
+	for _, user := range b.officeUsers(users, queueData) {
+		if user.TicketNumber != "" && models.TicketCalled(previousTicket, queueData.LastTicket, user.TicketNumber) {
+			recipients = append(recipients, user)
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	sentCount, err := b.broadcastAlert(ctx, notifier.AlertCalled, queueData, recipients, func(ctx context.Context, user database.User) error {
+		message := i18n.T(i18n.OrDefault(user.Language), "alert.ticket_called", models.EscapeMarkdown(user.TicketNumber))
+		return b.deliverMessage(ctx, user.ChatID, models.MessageTicketCalled, message)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Ticket called alert sent to %d users", sentCount)
+	return nil
+}
+
+// NotifyTicketNear tells a user that their ticket is only a few tickets away
+func (b *TelegramBot) NotifyTicketNear(ctx context.Context, chatID int64, ticket string, ticketsAhead int) error {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get user %d: %w", chatID, err)
+	}
+	if user == nil || user.Quarantined(time.Now()) {
+		return nil
+	}
+
+	message := i18n.T(i18n.OrDefault(user.Language), "alert.ticket_near", models.EscapeMarkdown(ticket), ticketsAhead)
+	err = b.deliverMessage(ctx, chatID, models.MessageTicketNear, message)
+	b.recordDelivery(ctx, *user, err)
+	return err
+}
@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"karta/internal/apikey"
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// handleAdminAPIKey manages the keys of the HTTP API, e.g. "/apikey create grafana read 120",
+// "/apikey list" or "/apikey revoke 3"
+func (b *TelegramBot) handleAdminAPIKey(ctx context.Context, chatID int64, args string, lang i18n.Language) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		fields = []string{"list"}
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "list":
+		b.listAPIKeys(ctx, chatID, lang)
+	case "create":
+		b.createAPIKey(ctx, chatID, fields[1:], lang)
+	case "revoke":
+		b.revokeAPIKey(ctx, chatID, fields[1:], lang)
+	default:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_usage"))
+	}
+}
+
+// createAPIKey creates a key from "<name> [read|admin] [requests per minute]" and shows it once
+func (b *TelegramBot) createAPIKey(ctx context.Context, chatID int64, fields []string, lang i18n.Language) {
+	if len(fields) == 0 || len(fields) > 3 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_usage"))
+		return
+	}
+
+	key := database.APIKey{Name: fields[0], Scope: apikey.ScopeRead, RateLimit: apikey.DefaultRateLimit}
+	if len(fields) > 1 {
+		scope, err := apikey.ParseScope(strings.ToLower(fields[1]))
+		if err != nil {
+			b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_usage"))
+			return
+		}
+		key.Scope = scope
+	}
+	if len(fields) > 2 {
+		limit, err := strconv.Atoi(fields[2])
+		if err != nil || limit <= 0 {
+			b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_usage"))
+			return
+		}
+		key.RateLimit = limit
+	}
+
+	secret, hash, err := apikey.Generate()
+	if err != nil {
+		log.Printf("Failed to generate API key: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_error", models.EscapeMarkdown(err.Error())))
+		return
+	}
+	key.Hash = hash
+
+	id, err := b.db.CreateAPIKey(ctx, key)
+	if err != nil {
+		log.Printf("Failed to create API key: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_error", models.EscapeMarkdown(err.Error())))
+		return
+	}
+
+	log.Printf("Admin %d created API key %d %q with %s scope", chatID, id, key.Name, key.Scope)
+	b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_created", id, models.EscapeMarkdown(key.Name), key.Scope, key.RateLimit, secret))
+}
+
+// listAPIKeys lists the keys without their secrets, which are not stored
+func (b *TelegramBot) listAPIKeys(ctx context.Context, chatID int64, lang i18n.Language) {
+	keys, err := b.db.ListAPIKeys(ctx)
+	if err != nil {
+		log.Printf("Failed to list API keys: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_error", models.EscapeMarkdown(err.Error())))
+		return
+	}
+	if len(keys) == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_none"))
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "admin.apikey_title", len(keys)) + "\n\n")
+	for _, key := range keys {
+		line := fmt.Sprintf("%d %s: %s, %d/min, %s", key.ID, key.Name, key.Scope, key.RateLimit, key.CreatedAt.Format("2006-01-02"))
+		builder.WriteString(models.EscapeMarkdown(line) + "\n")
+	}
+
+	b.sendMessage(ctx, chatID, builder.String())
+}
+
+// revokeAPIKey deletes a key by its ID
+func (b *TelegramBot) revokeAPIKey(ctx context.Context, chatID int64, fields []string, lang i18n.Language) {
+	if len(fields) != 1 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_usage"))
+		return
+	}
+	id, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_usage"))
+		return
+	}
+
+	deleted, err := b.db.DeleteAPIKey(ctx, id)
+	if err != nil {
+		log.Printf("Failed to revoke API key %d: %v", id, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_error", models.EscapeMarkdown(err.Error())))
+		return
+	}
+	if !deleted {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_not_found", id))
+		return
+	}
+
+	log.Printf("Admin %d revoked API key %d", chatID, id)
+	b.sendMessage(ctx, chatID, i18n.T(lang, "admin.apikey_revoked", id))
+}
@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/report"
+)
+
+// BestTimeSlots is the number of hours of the week listed by /besttime
+const BestTimeSlots = 3
+
+// handleBestTimeCommand handles /besttime with the hours of the week that usually have the
+// shortest wait, from the hour-of-week profile of the service throughput
+func (b *TelegramBot) handleBestTimeCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	now := time.Now()
+	profile, err := report.LoadProfile(ctx, b.db, now)
+	if err != nil {
+		log.Printf("Failed to build service profile: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	if len(profile) == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "besttime.no_data"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, formatBestTimeMessage(profile, now, lang))
+}
+
+// formatBestTimeMessage lists the best hours of the week and the best hour left today
+func formatBestTimeMessage(profile report.Profile, now time.Time, lang i18n.Language) string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "besttime.title") + "\n\n")
+
+	for i, slot := range profile.Best(BestTimeSlots) {
+		builder.WriteString(fmt.Sprintf("%d\\. %s\n", i+1, formatSlot(slot, lang)))
+	}
+
+	if today := profile.On(now.Weekday(), now.Hour()).Best(1); len(today) > 0 {
+		builder.WriteString("\n" + i18n.T(lang, "besttime.today", formatSlot(today[0], lang)) + "\n")
+	}
+
+	builder.WriteString("\n" + i18n.T(lang, "besttime.note"))
+	return builder.String()
+}
+
+// formatSlot formats a profile slot as its weekday and hour with the expected wait
+func formatSlot(slot report.Slot, lang i18n.Language) string {
+	hours := models.EscapeMarkdown(fmt.Sprintf("%02d:00–%02d:00", slot.Hour, (slot.Hour+1)%24))
+	minutes := int(math.Max(1, math.Round(slot.Wait().Minutes())))
+	return i18n.T(lang, "besttime.slot", i18n.T(lang, fmt.Sprintf("weekday.%d", slot.Weekday)), hours,
+		models.FormatMinutes(lang, minutes), models.EscapeMarkdown(fmt.Sprintf("%.0f", slot.Throughput)))
+}
@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/models"
+)
+
+// DefaultBroadcastWorkers is the default number of concurrent senders per broadcast
+const DefaultBroadcastWorkers = 10
+
+// SetBroadcastWorkers sets the number of concurrent senders used by broadcasts
+func (b *TelegramBot) SetBroadcastWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	b.broadcastWorkers = workers
+	log.Printf("Broadcast concurrency set to %d workers", workers)
+}
+
+// broadcast runs deliver for every user not in delivery quarantine on a bounded worker pool
+// and returns aggregated success and error counts. Sends still go through the rate limiter,
+// and every result updates the user's failure counter. A cancelled context stops handing
+// out users; deliveries already started are finished, except for sends still waiting for
+// the rate limiter.
+func (b *TelegramBot) broadcast(ctx context.Context, users []database.User, deliver func(ctx context.Context, user database.User) error) (successCount, errorCount int) {
+	successCount, errorCount, pending := b.broadcastUntil(ctx, users, deliver)
+	if len(pending) > 0 {
+		log.Printf("Broadcast interrupted with %d users left", len(pending))
+	}
+	return successCount, errorCount
+}
+
+// broadcastUntil is broadcast, also returning the users not reached before the context was
+// cancelled
+func (b *TelegramBot) broadcastUntil(ctx context.Context, users []database.User, deliver func(ctx context.Context, user database.User) error) (successCount, errorCount int, pending []database.User) {
+	now := time.Now()
+	recipients := users[:0:0]
+	for _, user := range users {
+		if !user.Quarantined(now) {
+			recipients = append(recipients, user)
+		}
+	}
+	if skipped := len(users) - len(recipients); skipped > 0 {
+		log.Printf("Skipping %d quarantined users", skipped)
+	}
+	users = recipients
+
+	workers := b.broadcastWorkers
+	if workers < 1 {
+		workers = DefaultBroadcastWorkers
+	}
+	if workers > len(users) {
+		workers = len(users)
+	}
+
+	// A started delivery is not cancelled halfway, so the message ID of a sent message is stored
+	deliveryCtx := detachDelivery(ctx)
+
+	jobs := make(chan database.User)
+	var success, failed atomic.Int64
+	var wg sync.WaitGroup
+	var mu sync.Mutex // Guards interrupted
+	var interrupted []database.User
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for user := range jobs {
+				err := b.runRecovered(deliveryCtx, fmt.Sprintf("broadcast to %d", user.ChatID), func() error {
+					return deliver(deliveryCtx, user)
+				})
+				// A send given up on shutdown is not a failure of the user, it is left for later
+				if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+					mu.Lock()
+					interrupted = append(interrupted, user)
+					mu.Unlock()
+					continue
+				}
+				// A panic is a bug of the bot, not a delivery failure of the user
+				if !errors.Is(err, errRecovered) {
+					b.recordDelivery(deliveryCtx, user, err)
+				}
+				if err == nil {
+					success.Add(1)
+				} else {
+					failed.Add(1)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i, user := range users {
+		if ctx.Err() != nil {
+			pending = users[i:]
+			break
+		}
+		select {
+		case jobs <- user:
+		case <-ctx.Done():
+			pending = users[i:]
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	pending = append(interrupted, pending...)
+	return int(success.Load()), int(failed.Load()), pending
+}
+
+// sendCtxKey is the context key of the cancellable context of a detached delivery
+type sendCtxKey struct{}
+
+// detachDelivery returns a context that is not cancelled with ctx, for deliveries that
+// have to store what they sent, while sends through the rate limiter still stop waiting
+// once ctx is done
+func detachDelivery(ctx context.Context) context.Context {
+	return context.WithValue(context.WithoutCancel(ctx), sendCtxKey{}, ctx)
+}
+
+// sendContext returns the context rate limiter waits are bound to: the original context
+// of a detached delivery, ctx itself otherwise
+func sendContext(ctx context.Context) context.Context {
+	if parent, ok := ctx.Value(sendCtxKey{}).(context.Context); ok {
+		return parent
+	}
+	return ctx
+}
+
+// deliverMessage sends a broadcast message of the given kind, logging and returning the
+// error for failure tracking
+func (b *TelegramBot) deliverMessage(ctx context.Context, chatID int64, kind models.MessageKind, text string) error {
+	if _, err := b.sendText(ctx, chatID, text, b.silent[kind]); err != nil {
+		log.Printf("Failed to send message to %d: %v", chatID, err)
+		return err
+	}
+
+	if err := b.db.RecordNotification(ctx, chatID, time.Now()); err != nil {
+		log.Printf("Failed to record notification of user %d: %v", chatID, err)
+	}
+	return nil
+}
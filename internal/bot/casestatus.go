@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"karta/internal/casestatus"
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// CaseCheckDelay is the pause between the checks of two cases, to go easy on the status page
+const CaseCheckDelay = 2 * time.Second
+
+// SetCaseStatus enables /case, checking the registered cases on the status page every interval
+func (b *TelegramBot) SetCaseStatus(client *casestatus.Client, interval time.Duration) {
+	b.caseStatus = client
+	b.caseInterval = interval
+}
+
+// handleCaseCommand handles /case SO-II.6151.12345.2024: the status of the case is checked
+// right away and then regularly, "off" stops the checks and no arguments show the status
+func (b *TelegramBot) handleCaseCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	if b.caseStatus == nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "case.disabled"))
+		return
+	}
+
+	args = strings.TrimSpace(args)
+	if args == "" {
+		user, err := b.db.GetUser(ctx, chatID)
+		if err != nil {
+			log.Printf("Failed to get user %d: %v", chatID, err)
+		}
+		if user == nil || user.CaseNumber == "" {
+			b.sendMessage(ctx, chatID, i18n.T(lang, "case.usage"))
+			return
+		}
+		b.sendMessage(ctx, chatID, formatCaseStatus("case.current", user.CaseNumber, user.CaseStatus, lang))
+		return
+	}
+
+	caseNumber := args
+	if strings.EqualFold(args, "off") {
+		caseNumber = ""
+	} else if !casestatus.ValidCaseNumber(caseNumber) {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "case.invalid"))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserCaseNumber(ctx, chatID, caseNumber); err != nil {
+		log.Printf("Failed to set case number for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if caseNumber == "" {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "case.removed"))
+		return
+	}
+
+	// The first status confirms the number; when the page is down the next check gets it
+	status, err := b.caseStatus.Status(ctx, caseNumber)
+	if err != nil {
+		log.Printf("Failed to check case status for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "case.registered_unchecked", models.EscapeMarkdown(caseNumber)))
+		return
+	}
+	if err := b.db.SetUserCaseStatus(ctx, chatID, status); err != nil {
+		log.Printf("Failed to store case status for user %d: %v", chatID, err)
+	}
+	b.sendMessage(ctx, chatID, formatCaseStatus("case.registered", caseNumber, status, lang))
+}
+
+// formatCaseStatus formats a message about the status of a case, pointing out a card ready
+// for pickup
+func formatCaseStatus(key, caseNumber, status string, lang i18n.Language) string {
+	shown := i18n.T(lang, "case.unchecked")
+	if status != "" {
+		shown = models.EscapeMarkdown(status)
+	}
+
+	message := i18n.T(lang, key, models.EscapeMarkdown(caseNumber), shown)
+	if casestatus.Ready(status) {
+		message += "\n\n" + i18n.T(lang, "case.ready")
+	}
+	return message
+}
+
+// StartCaseStatusChecks checks the registered cases every interval until the context is
+// cancelled, notifying the users whose case status changed
+func (b *TelegramBot) StartCaseStatusChecks(ctx context.Context) {
+	ticker := time.NewTicker(b.caseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.checkCases(ctx)
+		}
+	}
+}
+
+// checkCases checks the case of every active user who registered one, one after another
+func (b *TelegramBot) checkCases(ctx context.Context) {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users for case checks: %v", err)
+		return
+	}
+
+	checked, changed := 0, 0
+	for _, user := range users {
+		if user.CaseNumber == "" || user.Quarantined(time.Now()) {
+			continue
+		}
+		if checked > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(CaseCheckDelay):
+			}
+		}
+		checked++
+
+		if b.checkCase(ctx, user) {
+			changed++
+		}
+	}
+
+	if checked > 0 {
+		log.Printf("Checked %d cases, %d changed status", checked, changed)
+	}
+}
+
+// checkCase checks the case of a user and reports whether its status changed. Case updates
+// are personal, so muted users get them too, like the alerts about their ticket.
+func (b *TelegramBot) checkCase(ctx context.Context, user database.User) bool {
+	status, err := b.caseStatus.Status(ctx, user.CaseNumber)
+	if err != nil {
+		log.Printf("Failed to check case status for user %d: %v", user.ChatID, err)
+		return false
+	}
+	if status == user.CaseStatus {
+		return false
+	}
+
+	if err := b.db.SetUserCaseStatus(ctx, user.ChatID, status); err != nil {
+		log.Printf("Failed to store case status for user %d: %v", user.ChatID, err)
+		return false // Notified on the next check instead of on every check
+	}
+
+	message := formatCaseStatus("case.changed", user.CaseNumber, status, i18n.OrDefault(user.Language))
+	err = b.deliverMessage(ctx, user.ChatID, models.MessageCaseStatus, message)
+	b.recordDelivery(ctx, user, err)
+	return true
+}
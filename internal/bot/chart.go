@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"karta/internal/chart"
+	"karta/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleChartCommand handles the /chart command with today's waiting clients, with the
+// DUW API outages shaded, and tickets served per hour as images
+func (b *TelegramBot) handleChartCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	photos, err := b.todayCharts(ctx, lang)
+	if err != nil {
+		log.Printf("Failed to render charts: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	if len(photos) == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.today_empty"))
+		return
+	}
+
+	if err := b.sendPhotos(ctx, chatID, photos); err != nil {
+		log.Printf("Failed to send charts to %d: %v", chatID, err)
+	}
+}
+
+// todayCharts renders the charts of today that have enough data
+func (b *TelegramBot) todayCharts(ctx context.Context, lang i18n.Language) ([]tgbotapi.FileBytes, error) {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	history, err := b.db.GetQueueDataSince(ctx, startOfDay)
+	if err != nil {
+		return nil, err
+	}
+	throughput, err := b.stats.HourlyThroughput(ctx, startOfDay, now)
+	if err != nil {
+		return nil, err
+	}
+	outages, err := b.db.GetOutages(ctx, startOfDay, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var photos []tgbotapi.FileBytes
+	if image, err := chart.WaitingClients(history, outages, lang); err == nil {
+		photos = append(photos, tgbotapi.FileBytes{Name: "waiting.png", Bytes: image})
+	} else {
+		log.Printf("Skipping waiting clients chart: %v", err)
+	}
+	if image, err := chart.ServedPerHour(throughput, lang); err == nil {
+		photos = append(photos, tgbotapi.FileBytes{Name: "served.png", Bytes: image})
+	} else {
+		log.Printf("Skipping served per hour chart: %v", err)
+	}
+
+	return photos, nil
+}
+
+// sendPhotos sends images as a single photo or as an album
+func (b *TelegramBot) sendPhotos(ctx context.Context, chatID int64, photos []tgbotapi.FileBytes) error {
+	if len(photos) == 1 {
+		_, err := b.send(ctx, chatID, tgbotapi.NewPhoto(chatID, photos[0]))
+		return err
+	}
+
+	media := make([]interface{}, len(photos))
+	for i, photo := range photos {
+		media[i] = tgbotapi.NewInputMediaPhoto(photo)
+	}
+	return b.request(ctx, chatID, tgbotapi.NewMediaGroup(chatID, media))
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	StaleMessageMaxAge   = 48 * time.Hour  // Telegram does not let bots delete older messages
+	StaleCleanupInterval = 5 * time.Minute // How often failed deletions of replaced messages are retried
+)
+
+// retireLiveMessage deletes a replaced live status message, so the chat keeps a single one.
+// The message is recorded first, so a failed deletion is retried by the cleanup.
+func (b *TelegramBot) retireLiveMessage(ctx context.Context, chatID int64, messageID int) {
+	if err := b.db.AddStaleMessage(ctx, chatID, messageID); err != nil {
+		log.Printf("Failed to record stale message %d of chat %d: %v", messageID, chatID, err)
+	}
+	b.deleteStaleMessage(ctx, chatID, messageID)
+}
+
+// deleteStaleMessage deletes a replaced message and forgets it unless the deletion should be retried
+func (b *TelegramBot) deleteStaleMessage(ctx context.Context, chatID int64, messageID int) {
+	err := b.deleteMessage(ctx, chatID, messageID)
+	if err != nil && !isBlockedError(err) && !isMessageGoneError(err) {
+		return
+	}
+
+	if err := b.db.RemoveStaleMessage(ctx, chatID, messageID); err != nil {
+		log.Printf("Failed to remove stale message %d of chat %d: %v", messageID, chatID, err)
+	}
+}
+
+// StartStaleMessageCleanup retries deleting replaced live messages until the context is cancelled
+func (b *TelegramBot) StartStaleMessageCleanup(ctx context.Context) {
+	ticker := time.NewTicker(StaleCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.cleanStaleMessages(ctx)
+		}
+	}
+}
+
+// cleanStaleMessages deletes the recorded replaced messages that are still young enough
+func (b *TelegramBot) cleanStaleMessages(ctx context.Context) {
+	if err := b.db.CleanStaleMessages(ctx, StaleMessageMaxAge); err != nil {
+		log.Printf("Failed to clean stale messages: %v", err)
+	}
+
+	messages, err := b.db.GetStaleMessages(ctx)
+	if err != nil {
+		log.Printf("Failed to get stale messages: %v", err)
+		return
+	}
+
+	for _, message := range messages {
+		b.deleteStaleMessage(ctx, message.ChatID, message.MessageID)
+	}
+}
+
+// isMessageGoneError reports whether a deletion failed because the message is already
+// deleted or too old to be deleted, so retrying is pointless
+func isMessageGoneError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && (strings.Contains(apiErr.Message, "message to delete not found") ||
+		strings.Contains(apiErr.Message, "message can't be deleted"))
+}
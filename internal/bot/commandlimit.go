@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const (
+	DefaultCommandsPerMinute = 10
+	DefaultCommandMute       = 5 * time.Minute
+	CommandWindow            = time.Minute
+)
+
+// commandLimiter limits the incoming commands of each chat to a number per CommandWindow
+// and mutes chats exceeding it for a while
+type commandLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	mute     time.Duration
+	commands map[int64][]time.Time // Command times within the window, oldest first
+	muted    map[int64]time.Time   // Chats ignored until then
+}
+
+// newCommandLimiter creates a limiter allowing limit commands per chat and minute
+func newCommandLimiter(limit int, mute time.Duration) *commandLimiter {
+	return &commandLimiter{
+		limit:    limit,
+		mute:     mute,
+		commands: make(map[int64][]time.Time),
+		muted:    make(map[int64]time.Time),
+	}
+}
+
+// Allow records a command of the chat and reports whether it may be handled. justMuted is
+// set for the command exceeding the limit, which starts the mute.
+func (l *commandLimiter) Allow(chatID int64, now time.Time) (allowed, justMuted bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.muted[chatID]) {
+		return false, false
+	}
+	delete(l.muted, chatID)
+
+	recent := l.commands[chatID]
+	for len(recent) > 0 && now.Sub(recent[0]) >= CommandWindow {
+		recent = recent[1:]
+	}
+	recent = append(recent, now)
+
+	if len(recent) > l.limit {
+		delete(l.commands, chatID)
+		l.muted[chatID] = now.Add(l.mute)
+		return false, true
+	}
+
+	l.commands[chatID] = recent
+	l.prune(now)
+	return true, false
+}
+
+// prune drops chats without commands in the window and expired mutes once many chats are tracked
+func (l *commandLimiter) prune(now time.Time) {
+	if len(l.commands)+len(l.muted) <= maxTrackedChats {
+		return
+	}
+
+	for chatID, recent := range l.commands {
+		if now.Sub(recent[len(recent)-1]) >= CommandWindow {
+			delete(l.commands, chatID)
+		}
+	}
+	for chatID, until := range l.muted {
+		if !now.Before(until) {
+			delete(l.muted, chatID)
+		}
+	}
+}
+
+// SetCommandLimit sets how many commands a chat may send per minute and how long chats
+// sending more are ignored
+func (b *TelegramBot) SetCommandLimit(perMinute int, mute time.Duration) {
+	b.commandLimiter = newCommandLimiter(perMinute, mute)
+}
+
+// allowCommand reports whether an incoming message of the chat is handled, warning the
+// chat once when it gets muted. Bot admins are never limited.
+func (b *TelegramBot) allowCommand(ctx context.Context, chatID int64, languageCode string) bool {
+	if b.admins[chatID] {
+		return true
+	}
+
+	allowed, justMuted := b.commandLimiter.Allow(chatID, time.Now())
+	if justMuted {
+		log.Printf("Muting chat %d for %s: more than %d commands per minute", chatID, b.commandLimiter.mute, b.commandLimiter.limit)
+		lang := i18n.OrDefault(languageCode)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "commands.muted", models.FormatMinutes(lang, int(b.commandLimiter.mute.Minutes()))))
+	}
+	return allowed
+}
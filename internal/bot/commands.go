@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"karta/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// commandMenu is the command list shown in a scope of chats
+type commandMenu struct {
+	scope    tgbotapi.BotCommandScope
+	commands []string
+}
+
+// newCommandRouter registers the commands in the order of the Telegram command menu; each
+// has a command.<name> description in the catalogs
+func (b *TelegramBot) newCommandRouter() *router {
+	r := newRouter(b.handleText, b.logRequests, b.limitRequests, b.rejectBanned, b.localize, b.groupPolicy, b.trackActivity)
+
+	r.handle(commandRoute{name: "start", groupAdmin: true, handle: func(req *commandRequest) {
+		// Links shared in communities, t.me/<bot>?start=wroclaw_uk, preconfigure the subscription
+		link := b.parseStartLink(req.args())
+		if link.language != "" {
+			req.lang, req.languageChosen = link.language, true
+		}
+		b.handleStartCommand(req.ctx, req.chatID, req.username, link, req.lang)
+	}})
+	r.handle(commandRoute{name: "settings", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleSettingsCommand(req.ctx, req.chatID, req.username, req.group(), req.lang)
+	}})
+
+	r.handle(commandRoute{name: "stats", handle: func(req *commandRequest) {
+		b.handleStatsCommand(req.ctx, req.chatID, req.lang)
+	}}, b.requirePrimary)
+	r.handle(commandRoute{name: "today", handle: func(req *commandRequest) {
+		b.handleTodayCommand(req.ctx, req.chatID, req.lang)
+	}}, b.requirePrimary)
+	r.handle(commandRoute{name: "history", handle: func(req *commandRequest) {
+		b.handleHistoryCommand(req.ctx, req.chatID, req.args(), req.lang)
+	}}, b.requirePrimary)
+	r.handle(commandRoute{name: "chart", handle: func(req *commandRequest) {
+		b.handleChartCommand(req.ctx, req.chatID, req.lang)
+	}}, b.requirePrimary)
+	r.handle(commandRoute{name: "besttime", handle: func(req *commandRequest) {
+		b.handleBestTimeCommand(req.ctx, req.chatID, req.lang)
+	}}, b.requirePrimary)
+	r.handle(commandRoute{name: "eta", handle: func(req *commandRequest) {
+		b.handleEtaCommand(req.ctx, req.chatID, req.lang)
+	}}, b.requirePrimary)
+	r.handle(commandRoute{name: "now", handle: func(req *commandRequest) {
+		b.handleNowCommand(req.ctx, req.chatID, req.lang)
+	}})
+	r.handle(commandRoute{name: "uptime", handle: func(req *commandRequest) {
+		b.handleUptimeCommand(req.ctx, req.chatID, req.lang)
+	}}, b.requirePrimary)
+	r.handle(commandRoute{name: "weekly", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleWeeklyCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}}, b.requirePrimary)
+
+	r.handle(commandRoute{name: "office", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleOfficeCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "alerts", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleAlertsCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "slots", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleSlotsCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "threshold", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleThresholdCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "interval", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleIntervalCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "mute", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleMuteCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "snooze_until", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleSnoozeUntilCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "unmute", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleUnmuteCommand(req.ctx, req.chatID, req.lang)
+	}})
+	r.handle(commandRoute{name: "pin", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handlePinCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "case", personal: true, handle: func(req *commandRequest) {
+		b.handleCaseCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "queuepos", personal: true, handle: func(req *commandRequest) {
+		b.handleQueuePosCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "app", personal: true, handle: func(req *commandRequest) {
+		b.handleAppCommand(req.ctx, req.chatID, req.username, req.lang)
+	}})
+	r.handle(commandRoute{name: "timezone", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleTimezoneCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "language", groupAdmin: true, handle: func(req *commandRequest) {
+		req.languageChosen = true // An explicit choice must not be overwritten by the detected language
+		b.handleLanguageCommand(req.ctx, req.chatID, req.username, req.args(), req.lang)
+	}})
+	r.handle(commandRoute{name: "stop", groupAdmin: true, handle: func(req *commandRequest) {
+		b.handleStopCommand(req.ctx, req.chatID, req.lang)
+	}})
+
+	r.handle(commandRoute{name: "botstats", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminStats(req.ctx, req.chatID, req.lang)
+	}}, b.requireAdmin)
+	r.handle(commandRoute{name: "broadcast", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminBroadcast(req.ctx, req.chatID, req.args(), req.lang)
+	}}, b.requireAdmin)
+	r.handle(commandRoute{name: "users", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminUsers(req.ctx, req.chatID, req.lang)
+	}}, b.requireAdmin)
+	r.handle(commandRoute{name: "ban", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminBan(req.ctx, req.chatID, req.args(), true, req.lang)
+	}}, b.requireAdmin)
+	r.handle(commandRoute{name: "unban", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminBan(req.ctx, req.chatID, req.args(), false, req.lang)
+	}}, b.requireAdmin)
+	r.handle(commandRoute{name: "setinterval", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminSetInterval(req.ctx, req.chatID, req.args(), req.lang)
+	}}, b.requireAdmin)
+	r.handle(commandRoute{name: "export", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminExport(req.ctx, req.chatID, req.args(), req.lang)
+	}}, b.requireAdmin)
+	r.handle(commandRoute{name: "apikey", admin: true, handle: func(req *commandRequest) {
+		b.handleAdminAPIKey(req.ctx, req.chatID, req.args(), req.lang)
+	}}, b.requireAdmin)
+
+	return r
+}
+
+// handleText handles messages without a known command: ticket numbers are saved and
+// anything else gets the help text
+func (b *TelegramBot) handleText(req *commandRequest) {
+	text := req.message.Text
+	if text == "" {
+		return
+	}
+
+	// Check if message matches ticket pattern (K followed by numbers)
+	if b.isTicketNumber(text) {
+		b.handleTicketNumber(req.ctx, req.chatID, req.username, text, req.lang)
+	} else {
+		b.sendMessage(req.ctx, req.chatID, i18n.T(req.lang, "help"))
+	}
+}
+
+// RegisterCommands publishes the command menu in every language: all user commands in
+// private chats, the ones also working there in groups, and the admin commands for the admins
+func (b *TelegramBot) RegisterCommands(ctx context.Context) error {
+	var userCommands, groupCommands, adminCommands []string
+	for _, route := range b.router.routes {
+		switch {
+		case route.admin:
+			adminCommands = append(adminCommands, route.name)
+		case route.personal:
+			userCommands = append(userCommands, route.name)
+		default:
+			userCommands = append(userCommands, route.name)
+			groupCommands = append(groupCommands, route.name)
+		}
+	}
+
+	menus := []commandMenu{
+		{tgbotapi.NewBotCommandScopeAllPrivateChats(), userCommands},
+		{tgbotapi.NewBotCommandScopeAllGroupChats(), groupCommands},
+	}
+	for chatID := range b.admins {
+		menus = append(menus, commandMenu{tgbotapi.NewBotCommandScopeChat(chatID), append(slices.Clone(userCommands), adminCommands...)})
+	}
+
+	// Clients in other languages get the menu of the default language
+	languages := append([]i18n.Language{""}, i18n.Supported()...)
+	for _, menu := range menus {
+		for _, lang := range languages {
+			config := tgbotapi.NewSetMyCommandsWithScopeAndLanguage(menu.scope, string(lang), menuCommands(menu.commands, i18n.OrDefault(string(lang)))...)
+			if err := b.request(ctx, 0, config); err != nil {
+				return fmt.Errorf("failed to register %s commands for language %q: %w", menu.scope.Type, lang, err)
+			}
+		}
+	}
+
+	log.Printf("Registered the command menu for %d scopes in %d languages", len(menus), len(languages))
+	return nil
+}
+
+// menuCommands returns the commands with their descriptions in the language
+func menuCommands(names []string, lang i18n.Language) []tgbotapi.BotCommand {
+	commands := make([]tgbotapi.BotCommand, 0, len(names))
+	for _, name := range names {
+		commands = append(commands, tgbotapi.BotCommand{Command: name, Description: i18n.T(lang, "command."+name)})
+	}
+	return commands
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"karta/internal/i18n"
+	"karta/internal/parser"
+)
+
+// startQueueNames map payload parts naming a queue to the queue, so shared links can
+// spell it out, e.g. "wroclaw_odbior_karty" or "wroclaw_wniosek"
+var startQueueNames = map[string]string{
+	"odbior":   parser.QueueCardPickup,
+	"karty":    parser.QueueCardPickup,
+	"zlozenie": parser.QueueApplication,
+	"wniosek":  parser.QueueApplication,
+	"wniosku":  parser.QueueApplication,
+	"decyzja":  parser.QueueDecision,
+	"decyzji":  parser.QueueDecision,
+}
+
+// startLink is the subscription preconfigured by a t.me/<bot>?start=<payload> link
+type startLink struct {
+	office   *parser.Office // Office to follow, nil to keep the current one
+	language i18n.Language  // Language to use, empty to keep the current one
+}
+
+// parseStartLink parses a /start payload of office IDs or city names, language codes and
+// the queue name separated by underscores, e.g. "wroclaw_odbior" or "jelenia-gora_pl";
+// a queue name picks that queue of the office. Office IDs contain hyphens, so a part is
+// only split on hyphens when it means nothing as a whole, e.g. "legnica-uk". Unknown parts
+// are ignored, so an outdated link still subscribes.
+func (b *TelegramBot) parseStartLink(payload string) startLink {
+	var link startLink
+	payload = strings.ToLower(strings.TrimSpace(payload))
+	if payload == "" {
+		return link
+	}
+
+	offices := b.offices
+	if len(offices) == 0 {
+		offices = []parser.Office{b.primaryOffice()}
+	}
+
+	queue := ""
+	apply := func(part string) bool {
+		if name, ok := startQueueNames[part]; ok {
+			// "odbior" also starts "odbior_decyzji", so a later, more specific part wins
+			if queue == "" || queue == parser.QueueCardPickup {
+				queue = name
+			}
+			return true
+		}
+		if office, ok := parser.MatchOffice(offices, part); ok && link.office == nil {
+			link.office = &office
+			return true
+		}
+		// i18n.Parse reads "uk-legnica" as uk, so hyphenated parts are split first
+		if lang, ok := i18n.Parse(part); ok && link.language == "" && !strings.Contains(part, "-") {
+			link.language = lang
+			return true
+		}
+		return false
+	}
+
+	for _, part := range strings.Split(payload, "_") {
+		if part == "" || apply(part) {
+			continue
+		}
+		for _, word := range strings.Split(part, "-") {
+			if word != "" && !apply(word) {
+				log.Printf("Ignoring unknown start link part %q in %q", word, payload)
+			}
+		}
+	}
+
+	if link.office != nil && queue != "" {
+		if office, ok := parser.MatchQueue(offices, *link.office, queue); ok {
+			link.office = &office
+		} else {
+			log.Printf("Queue %q of start link %q is not monitored in %s", queue, payload, link.office.Name)
+		}
+	}
+	return link
+}
+
+// applyStartLink stores the office and language of a start link for a registered user
+func (b *TelegramBot) applyStartLink(ctx context.Context, chatID int64, link startLink) error {
+	if link.office != nil {
+		if err := b.db.SetUserOffice(ctx, chatID, link.office.ID); err != nil {
+			return err
+		}
+		log.Printf("User %d follows office %s from a start link", chatID, link.office.ID)
+	}
+	if link.language != "" {
+		if err := b.db.SetUserLanguage(ctx, chatID, string(link.language)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
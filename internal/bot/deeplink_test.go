@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"testing"
+
+	"karta/internal/i18n"
+	"karta/internal/parser"
+)
+
+func TestParseStartLink(t *testing.T) {
+	b := &TelegramBot{offices: parser.Offices}
+
+	tests := []struct {
+		payload      string
+		wantOffice   string // Empty for no office
+		wantLanguage i18n.Language
+	}{
+		{payload: "wroclaw_odbior", wantOffice: "wroclaw"},
+		{payload: "legnica-uk", wantOffice: "legnica", wantLanguage: i18n.Ukrainian},
+		{payload: "uk-legnica", wantOffice: "legnica", wantLanguage: i18n.Ukrainian},
+		{payload: "jelenia-gora", wantOffice: "jelenia-gora"},
+		{payload: "jelenia-gora_pl", wantOffice: "jelenia-gora", wantLanguage: i18n.Polish},
+		{payload: "JELENIA-GORA", wantOffice: "jelenia-gora"},
+		{payload: "wroclaw-wniosek", wantOffice: "wroclaw-wniosek"},
+		{payload: "wroclaw_decyzja_en", wantOffice: "wroclaw-decyzja", wantLanguage: i18n.English},
+		{payload: "wroclaw_odbior_decyzji", wantOffice: "wroclaw-decyzja"},
+		{payload: "walbrzych_decyzji", wantOffice: "walbrzych"}, // Queue not monitored there
+		{payload: "gdansk_ua", wantLanguage: i18n.Ukrainian},
+		{payload: "__"},
+		{payload: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			link := b.parseStartLink(tt.payload)
+
+			office := ""
+			if link.office != nil {
+				office = link.office.ID
+			}
+			if office != tt.wantOffice || link.language != tt.wantLanguage {
+				t.Errorf("parseStartLink(%q) = office %q, language %q, want %q, %q",
+					tt.payload, office, link.language, tt.wantOffice, tt.wantLanguage)
+			}
+		})
+	}
+}
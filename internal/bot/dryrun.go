@@ -0,0 +1,28 @@
+package bot
+
+import (
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SetDryRun makes the bot log outgoing Bot API calls instead of making them and stop
+// receiving updates, so a second instance can run next to production with the same token
+func (b *TelegramBot) SetDryRun(enabled bool) {
+	b.dryRun = enabled
+	if enabled {
+		log.Println("Dry run: Telegram messages are logged instead of sent")
+	}
+}
+
+// logDryRun logs a Bot API call skipped in dry-run mode
+func logDryRun(chatID int64, c tgbotapi.Chattable) {
+	switch msg := c.(type) {
+	case tgbotapi.MessageConfig:
+		log.Printf("[dry-run] send to %d:\n%s", chatID, msg.Text)
+	case tgbotapi.EditMessageTextConfig:
+		log.Printf("[dry-run] edit message %d in %d:\n%s", msg.MessageID, chatID, msg.Text)
+	default:
+		log.Printf("[dry-run] %T to %d", c, chatID)
+	}
+}
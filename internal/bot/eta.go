@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// handleEtaCommand handles /eta: when the waiting queue of the primary office should clear
+func (b *TelegramBot) handleEtaCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	queueData, err := b.queue.Current(ctx)
+	if err != nil {
+		log.Printf("Failed to get latest queue data: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	switch {
+	case queueData == nil:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "start.no_data"))
+	case queueData.Status != models.StatusOpen:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "eta.closed"))
+	case queueData.WaitingClients <= 0:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "eta.empty"))
+	default:
+		clearsAt, ok := b.clearEstimate(ctx, queueData)
+		if !ok {
+			b.sendMessage(ctx, chatID, i18n.T(lang, "eta.no_data"))
+			return
+		}
+		b.sendMessage(ctx, chatID, i18n.T(lang, "eta.estimate", queueData.WaitingClients,
+			clearsAt.In(b.chatLocation(ctx, chatID)).Format("15:04")))
+	}
+}
+
+// clearEstimate returns when the waiting clients of the open primary office queue should
+// all be served, by the predictor's service rate or else the recent queue velocity, with
+// the office breaks in between
+func (b *TelegramBot) clearEstimate(ctx context.Context, queueData *models.QueueData) (time.Time, bool) {
+	if !b.isPrimary(queueData) {
+		return time.Time{}, false
+	}
+
+	var throughput float64
+	velocity := b.velocity(ctx, queueData)
+	if velocity != nil {
+		throughput = velocity.PerPeriod * float64(time.Hour/models.VelocityPeriod)
+	}
+
+	clearTime, ok := b.clearTime(ctx, queueData, throughput, velocity != nil)
+	if !ok {
+		return time.Time{}, false
+	}
+	return queueData.CallTime(clearTime, b.schedule.Load()), true
+}
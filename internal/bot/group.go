@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"log"
+	"strings"
+
+	"karta/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// isGroupChat reports whether the chat is a group or supergroup
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// groupPolicy filters group chat messages. Groups share one live status message, so
+// ticket numbers and personal commands are ignored, settings are left to group admins
+// and commands addressed to other bots are skipped.
+func (b *TelegramBot) groupPolicy(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		if !req.group() {
+			next(req)
+			return
+		}
+
+		req.username = req.message.Chat.Title // Group subscriptions are listed by the group name
+		if b.allowGroupMessage(req) {
+			next(req)
+		}
+	}
+}
+
+// allowGroupMessage reports whether a group chat message should be handled
+func (b *TelegramBot) allowGroupMessage(req *commandRequest) bool {
+	message := req.message
+	command := req.command()
+
+	if command == "" {
+		if b.addedToGroup(message) {
+			log.Printf("Added to group %q (ID: %d)", message.Chat.Title, req.chatID)
+			b.sendMessage(req.ctx, req.chatID, i18n.T(req.lang, "group.welcome"))
+		}
+		return false // Members' chatter, including ticket numbers, is not for the bot
+	}
+
+	if _, mention, ok := strings.Cut(message.CommandWithAt(), "@"); ok && !strings.EqualFold(mention, b.api.Self.UserName) {
+		return false
+	}
+
+	route, _ := b.router.route(command)
+	switch {
+	case route.personal:
+		b.sendMessage(req.ctx, req.chatID, i18n.T(req.lang, "group.personal"))
+		return false
+	case route.groupAdmin && !b.isGroupAdmin(message):
+		b.sendMessage(req.ctx, req.chatID, i18n.T(req.lang, "group.admins_only"))
+		return false
+	}
+	return true
+}
+
+// addedToGroup reports whether the message announces the bot joining the group
+func (b *TelegramBot) addedToGroup(message *tgbotapi.Message) bool {
+	for _, member := range message.NewChatMembers {
+		if member.ID == b.api.Self.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// isGroupAdmin reports whether the sender of a group message is an administrator of the group
+func (b *TelegramBot) isGroupAdmin(message *tgbotapi.Message) bool {
+	// Anonymous administrators send messages on behalf of the group itself
+	if message.SenderChat != nil && message.SenderChat.ID == message.Chat.ID {
+		return true
+	}
+	if message.From == nil {
+		return false
+	}
+	return b.isChatAdmin(message.Chat.ID, message.From.ID)
+}
+
+// isChatAdmin reports whether the user is an administrator of the group
+func (b *TelegramBot) isChatAdmin(chatID, userID int64) bool {
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		log.Printf("Failed to get member %d of group %d: %v", userID, chatID, err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/stats"
+)
+
+const (
+	DefaultHistoryDays = 3
+	MaxHistoryDays     = 7 // Longest /history period
+)
+
+// sparklineLevels are the block characters used to draw text sparklines
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// handleTodayCommand handles the /today command with an hourly sparkline of waiting clients
+func (b *TelegramBot) handleTodayCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	buckets, err := b.db.GetHourlyHistory(ctx, startOfDay)
+	if err != nil {
+		log.Printf("Failed to get hourly history: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	if len(buckets) == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.today_empty"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, formatTodayMessage(buckets, lang))
+}
+
+// handleHistoryCommand handles the /history command, e.g. "/history 3d"
+func (b *TelegramBot) handleHistoryCommand(ctx context.Context, chatID int64, args string, lang i18n.Language) {
+	days, err := parseHistoryDays(args)
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.invalid_period", MaxHistoryDays))
+		return
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, time.Local)
+
+	buckets, err := b.db.GetDailyHistory(ctx, since)
+	if err != nil {
+		log.Printf("Failed to get daily history: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	if len(buckets) == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.period_empty"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, formatHistoryMessage(buckets, days, lang))
+}
+
+// parseHistoryDays parses the /history argument ("3d", "3" or empty) into a number of days
+func parseHistoryDays(args string) (int, error) {
+	args = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(args)), "d")
+	if args == "" {
+		return DefaultHistoryDays, nil
+	}
+
+	days, err := strconv.Atoi(args)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number of days: %w", err)
+	}
+
+	if days < 1 || days > MaxHistoryDays {
+		return 0, fmt.Errorf("number of days out of range: %d", days)
+	}
+
+	return days, nil
+}
+
+// formatTodayMessage formats hourly buckets as a sparkline message
+func formatTodayMessage(buckets []database.HistoryBucket, lang i18n.Language) string {
+	var builder strings.Builder
+
+	values := make([]float64, len(buckets))
+	for i, bucket := range buckets {
+		values[i] = bucket.AvgWaiting
+	}
+	peak, _ := stats.PeakOf(buckets)
+
+	first := buckets[0].Period
+	last := buckets[len(buckets)-1].Period
+
+	builder.WriteString(i18n.T(lang, "history.today_title") + "\n\n")
+	builder.WriteString(fmt.Sprintf("`%s`\n", sparkline(values)))
+	builder.WriteString(models.EscapeMarkdown(fmt.Sprintf("%s – %s", first.Format("15:04"), last.Add(time.Hour).Format("15:04"))))
+	builder.WriteString("\n\n")
+	builder.WriteString(i18n.T(lang, "history.peak", models.EscapeMarkdown(fmt.Sprintf("%d (%s)", peak.Waiting, peak.Hour.Format("15:04")))) + "\n")
+	builder.WriteString(i18n.T(lang, "history.served", buckets[len(buckets)-1].MaxServed))
+
+	return builder.String()
+}
+
+// formatHistoryMessage formats daily buckets as a multi-day summary
+func formatHistoryMessage(buckets []database.HistoryBucket, days int, lang i18n.Language) string {
+	var builder strings.Builder
+
+	builder.WriteString(i18n.T(lang, "history.title", days) + "\n\n")
+
+	for _, bucket := range buckets {
+		summary := i18n.T(lang, "history.day_summary",
+			bucket.MaxServed, bucket.MaxWaiting, bucket.AvgWaiting, bucket.MinTicketsLeft)
+		builder.WriteString(fmt.Sprintf("*%s:* %s\n", models.EscapeMarkdown(bucket.Period.Format("02.01")), summary))
+	}
+
+	return builder.String()
+}
+
+// sparkline renders values as a string of block characters scaled to the max value
+func sparkline(values []float64) string {
+	var maxValue float64
+	for _, value := range values {
+		if value > maxValue {
+			maxValue = value
+		}
+	}
+
+	var builder strings.Builder
+	for _, value := range values {
+		level := 0
+		if maxValue > 0 {
+			level = int(value / maxValue * float64(len(sparklineLevels)-1))
+		}
+		builder.WriteRune(sparklineLevels[level])
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"karta/internal/i18n"
+)
+
+// userLanguage returns the stored language of a user, or the language detected from
+// the Telegram client when none is stored yet. stored reports whether it came from the database.
+func (b *TelegramBot) userLanguage(ctx context.Context, chatID int64, languageCode string) (lang i18n.Language, stored bool) {
+	code, err := b.db.GetUserLanguage(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get language for user %d: %v", chatID, err)
+	}
+
+	if lang, ok := i18n.Parse(code); ok {
+		return lang, true
+	}
+	return i18n.OrDefault(languageCode), false
+}
+
+// rememberLanguage persists the detected language so broadcasts use it too
+func (b *TelegramBot) rememberLanguage(ctx context.Context, chatID int64, lang i18n.Language) {
+	if err := b.db.SetUserLanguage(ctx, chatID, string(lang)); err != nil {
+		log.Printf("Failed to save language for user %d: %v", chatID, err)
+	}
+}
+
+// handleLanguageCommand handles the /language command, e.g. "/language pl"
+func (b *TelegramBot) handleLanguageCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	newLang, ok := i18n.Parse(args)
+	if !ok {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "language.usage", i18n.T(lang, "language.name")))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserLanguage(ctx, chatID, string(newLang)); err != nil {
+		log.Printf("Failed to set language for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, i18n.T(newLang, "language.changed", i18n.T(newLang, "language.name")))
+}
@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/parser"
+)
+
+// SetMonitoringAlertUsers makes the alerts about degraded monitoring reach the users
+// following the office too, not only the admins
+func (b *TelegramBot) SetMonitoringAlertUsers(enabled bool) {
+	b.monitoringAlertUsers = enabled
+}
+
+// NotifyQueueMissing tells the admins that the queue of an office disappeared from the DUW
+// response, so its users get no updates, or that it is back when missing is false
+func (b *TelegramBot) NotifyQueueMissing(ctx context.Context, office parser.Office, missing bool, reason error) {
+	name := models.EscapeMarkdown(office.DisplayName())
+
+	b.notifyAdmins(ctx, office, func(chatID int64, lang i18n.Language) string {
+		if missing {
+			return i18n.T(lang, "monitoring.missing_admin", name, models.EscapeMarkdown(reason.Error()))
+		}
+		return i18n.T(lang, "monitoring.found_admin", name)
+	})
+
+	if b.monitoringAlertUsers {
+		b.notifyOfficeUsers(ctx, office, func(lang i18n.Language) string {
+			if missing {
+				return i18n.T(lang, "monitoring.missing", name)
+			}
+			return i18n.T(lang, "monitoring.found", name)
+		})
+	}
+}
+
+// MarkStale marks the data of an office as outdated when polls kept failing since the last
+// successful one at since: the admins are alerted and the live messages of the office's
+// users get a warning until MarkFresh
+func (b *TelegramBot) MarkStale(ctx context.Context, office parser.Office, since time.Time) {
+	b.staleOffices.Store(office.ID, since)
+
+	name := models.EscapeMarkdown(office.DisplayName())
+	b.notifyAdmins(ctx, office, func(chatID int64, lang i18n.Language) string {
+		return i18n.T(lang, "monitoring.stale_admin", name, since.In(b.chatLocation(ctx, chatID)).Format("15:04"))
+	})
+
+	queueData, err := b.latestQueueData(ctx, office)
+	if err != nil {
+		log.Printf("Failed to get latest queue data of office %s: %v", office.ID, err)
+		return
+	}
+	if queueData == nil {
+		return
+	}
+	if err := b.refreshLiveMessages(ctx, queueData); err != nil {
+		log.Printf("Failed to mark live messages of office %s as outdated: %v", office.ID, err)
+	}
+}
+
+// MarkFresh clears the outdated mark of an office after a successful poll and tells the
+// admins; the update of that poll redraws the live messages without the warning
+func (b *TelegramBot) MarkFresh(ctx context.Context, office parser.Office) {
+	if _, stale := b.staleOffices.LoadAndDelete(office.ID); !stale {
+		return
+	}
+
+	name := models.EscapeMarkdown(office.DisplayName())
+	b.notifyAdmins(ctx, office, func(chatID int64, lang i18n.Language) string {
+		return i18n.T(lang, "monitoring.fresh_admin", name)
+	})
+}
+
+// staleSince returns the last successful poll of an office while its data is outdated, or
+// zero time
+func (b *TelegramBot) staleSince(office parser.Office) time.Time {
+	if since, ok := b.staleOffices.Load(office.ID); ok {
+		return since.(time.Time)
+	}
+	return time.Time{}
+}
+
+// notifyAdmins sends a monitoring alert about an office to every admin in their language
+func (b *TelegramBot) notifyAdmins(ctx context.Context, office parser.Office, text func(chatID int64, lang i18n.Language) string) {
+	for chatID := range b.admins {
+		lang, _ := b.userLanguage(ctx, chatID, "")
+		if err := b.deliverMessage(ctx, chatID, models.MessageMonitoring, text(chatID, lang)); err != nil {
+			log.Printf("Failed to alert admin %d about the queue of %s: %v", chatID, office.ID, err)
+		}
+	}
+}
+
+// notifyOfficeUsers sends a monitoring alert to the unmuted users following the office;
+// admins already got the detailed one
+func (b *TelegramBot) notifyOfficeUsers(ctx context.Context, office parser.Office, text func(lang i18n.Language) string) {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users for monitoring alert: %v", err)
+		return
+	}
+
+	var followers []database.User
+	for _, user := range unmutedUsers(users, time.Now()) {
+		if b.userOffice(user.Office).ID == office.ID && !b.isAdmin(user.ChatID) {
+			followers = append(followers, user)
+		}
+	}
+	if len(followers) == 0 {
+		return
+	}
+
+	successCount, errorCount := b.broadcast(ctx, followers, func(ctx context.Context, user database.User) error {
+		return b.deliverMessage(ctx, user.ChatID, models.MessageMonitoring, text(i18n.OrDefault(user.Language)))
+	})
+	log.Printf("Monitoring alert about %s sent: %d successful, %d errors", office.ID, successCount, errorCount)
+}
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const (
+	MaxMute           = 7 * 24 * time.Hour // Longest accepted /mute duration
+	MuteCheckInterval = time.Minute        // How often expired mutes are resumed
+)
+
+// handleMuteCommand handles /mute 2h: rolling updates and open/close and tickets-left
+// alerts are paused for the duration, "off" resumes them and no arguments show the state
+func (b *TelegramBot) handleMuteCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	args = strings.ToLower(strings.TrimSpace(args))
+	switch args {
+	case "":
+		b.sendMuteState(ctx, chatID, lang)
+		return
+	case "off":
+		b.handleUnmuteCommand(ctx, chatID, lang)
+		return
+	}
+
+	duration, err := time.ParseDuration(args)
+	if err != nil || duration < time.Minute || duration > MaxMute {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "mute.usage"))
+		return
+	}
+
+	b.muteUntil(ctx, chatID, username, time.Now().Add(duration), lang)
+}
+
+// handleSnoozeUntilCommand handles /snooze_until 14:00, muting until the next time the
+// clock shows that time in the user's time zone
+func (b *TelegramBot) handleSnoozeUntilCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(args))
+	if err != nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "snooze.usage"))
+		return
+	}
+
+	now := time.Now().In(b.chatLocation(ctx, chatID))
+	until := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !until.After(now) {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	b.muteUntil(ctx, chatID, username, until, lang)
+}
+
+// handleUnmuteCommand handles /unmute, resuming rolling updates right away
+func (b *TelegramBot) handleUnmuteCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+	if user == nil || !user.Muted(time.Now()) {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "mute.not_muted"))
+		return
+	}
+
+	if err := b.db.SetUserMutedUntil(ctx, chatID, time.Time{}); err != nil {
+		log.Printf("Failed to unmute user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, i18n.T(lang, "mute.resumed"))
+}
+
+// muteUntil stores the end of a mute and confirms it in the user's time zone
+func (b *TelegramBot) muteUntil(ctx context.Context, chatID int64, username string, until time.Time, lang i18n.Language) {
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserMutedUntil(ctx, chatID, until); err != nil {
+		log.Printf("Failed to mute user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, i18n.T(lang, "mute.enabled", models.EscapeMarkdown(formatMuteEnd(until.In(b.chatLocation(ctx, chatID))))))
+}
+
+// sendMuteState tells a user whether and until when rolling updates are paused
+func (b *TelegramBot) sendMuteState(ctx context.Context, chatID int64, lang i18n.Language) {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+	}
+
+	if user == nil || !user.Muted(time.Now()) {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "mute.usage"))
+		return
+	}
+
+	until := user.MutedUntil.In(b.userLocation(user))
+	b.sendMessage(ctx, chatID, i18n.T(lang, "mute.state", models.EscapeMarkdown(formatMuteEnd(until))))
+}
+
+// formatMuteEnd formats the end of a mute, with the date when it is not today
+func formatMuteEnd(until time.Time) string {
+	now := time.Now().In(until.Location())
+	if until.YearDay() == now.YearDay() && until.Year() == now.Year() {
+		return until.Format("15:04")
+	}
+	return until.Format("02.01 15:04")
+}
+
+// unmutedUsers returns the users not muted at the given time
+func unmutedUsers(users []database.User, now time.Time) []database.User {
+	var unmuted []database.User
+	for _, user := range users {
+		if !user.Muted(now) {
+			unmuted = append(unmuted, user)
+		}
+	}
+	return unmuted
+}
+
+// StartMuteReminders resumes expired mutes until the context is cancelled, telling each
+// user that rolling updates are back
+func (b *TelegramBot) StartMuteReminders(ctx context.Context) {
+	ticker := time.NewTicker(MuteCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.resumeExpiredMutes(ctx, time.Now())
+		}
+	}
+}
+
+// resumeExpiredMutes clears the mutes that ended before now and sends the reminders
+func (b *TelegramBot) resumeExpiredMutes(ctx context.Context, now time.Time) {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users for mute reminders: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		if user.MutedUntil.IsZero() || user.Muted(now) {
+			continue
+		}
+
+		if err := b.db.SetUserMutedUntil(ctx, user.ChatID, time.Time{}); err != nil {
+			log.Printf("Failed to resume updates of user %d: %v", user.ChatID, err)
+			continue
+		}
+
+		err := b.deliverMessage(ctx, user.ChatID, models.MessageReminder, i18n.T(i18n.OrDefault(user.Language), "mute.expired"))
+		b.recordDelivery(ctx, user, err)
+	}
+}
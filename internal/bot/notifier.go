@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"karta/internal/models"
+	"karta/internal/notifier"
+)
+
+// Name implements notifier.Notifier
+func (b *TelegramBot) Name() string {
+	return "telegram"
+}
+
+// Broadcast implements notifier.Notifier by updating every user's live status message
+func (b *TelegramBot) Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	return b.BroadcastQueueUpdate(ctx, queueData, changes)
+}
+
+// SendAlert implements notifier.Notifier by sending the alert to subscribed users
+func (b *TelegramBot) SendAlert(ctx context.Context, queueData *models.QueueData, alert notifier.Alert) error {
+	switch alert.Kind {
+	case notifier.AlertStatus:
+		return b.BroadcastStatusAlert(ctx, queueData, alert.Transition)
+	case notifier.AlertTickets:
+		return b.BroadcastTicketsAlert(ctx, queueData, alert.PreviousLeft, alert.CurrentLeft)
+	case notifier.AlertCalled:
+		return b.BroadcastCalledTickets(ctx, queueData, alert.PreviousTicket)
+	default:
+		return fmt.Errorf("unknown alert kind: %s", alert.Kind)
+	}
+}
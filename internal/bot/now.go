@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// handleNowCommand handles /now: a one-line summary of the queue the user follows, with the
+// wait for their ticket, e.g. "Open, 23 waiting, 48 tickets left, ~35 min for K250"
+func (b *TelegramBot) handleNowCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+	}
+
+	office := b.primaryOffice()
+	userTicket := ""
+	if user != nil {
+		office = b.userOffice(user.Office)
+		userTicket = user.TicketNumber
+	}
+
+	queueData, err := b.latestQueueData(ctx, office)
+	if err != nil {
+		log.Printf("Failed to get latest queue data: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+	if queueData == nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "start.no_data"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, queueData.FormatSummary(models.MessageOptions{
+		UserTicket: userTicket,
+		Estimate:   b.ticketEstimate(ctx, queueData, userTicket),
+		Language:   lang,
+		Location:   b.userLocation(user),
+		Schedule:   b.schedule.Load(),
+	}))
+}
@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/parser"
+)
+
+// SetOffices sets the monitored offices users can choose from. The first one is the
+// primary office: its data is stored in the history behind estimates and statistics.
+func (b *TelegramBot) SetOffices(offices []parser.Office) {
+	b.offices = offices
+}
+
+// primaryOffice returns the office whose queue data is stored in the history
+func (b *TelegramBot) primaryOffice() parser.Office {
+	if len(b.offices) == 0 {
+		office, _ := parser.FindOffice(parser.DefaultOffice)
+		return office
+	}
+	return b.offices[0]
+}
+
+// userOffice returns the office a user follows; users who never chose one, or chose an
+// office that is no longer monitored, follow the primary office
+func (b *TelegramBot) userOffice(officeID string) parser.Office {
+	for _, office := range b.offices {
+		if office.ID == officeID {
+			return office
+		}
+	}
+	return b.primaryOffice()
+}
+
+// dataOffice returns the monitored office queue data belongs to
+func (b *TelegramBot) dataOffice(queueData *models.QueueData) parser.Office {
+	for _, office := range b.offices {
+		if office.Covers(queueData) {
+			return office
+		}
+	}
+	return b.primaryOffice()
+}
+
+// isPrimary reports whether queue data belongs to the primary office
+func (b *TelegramBot) isPrimary(queueData *models.QueueData) bool {
+	return b.dataOffice(queueData).ID == b.primaryOffice().ID
+}
+
+// officeUsers returns the users following the office given queue data belongs to
+func (b *TelegramBot) officeUsers(users []database.User, queueData *models.QueueData) []database.User {
+	office := b.dataOffice(queueData)
+
+	var followers []database.User
+	for _, user := range users {
+		if b.userOffice(user.Office).ID == office.ID {
+			followers = append(followers, user)
+		}
+	}
+	return followers
+}
+
+// latestQueueData returns the latest queue data of an office: the history keeps the
+// primary office, the other offices are only kept in memory
+func (b *TelegramBot) latestQueueData(ctx context.Context, office parser.Office) (*models.QueueData, error) {
+	if office.ID == b.primaryOffice().ID {
+		return b.queue.Current(ctx)
+	}
+
+	if queueData, ok := b.officeData.Load(office.ID); ok {
+		return queueData.(*models.QueueData).Clone(), nil
+	}
+	return nil, nil
+}
+
+// rememberOfficeData keeps the latest data of an office other than the primary one
+func (b *TelegramBot) rememberOfficeData(queueData *models.QueueData) {
+	if office := b.dataOffice(queueData); office.ID != b.primaryOffice().ID {
+		b.officeData.Store(office.ID, queueData.Clone())
+	}
+}
+
+// requirePrimaryOffice tells users following another office that history-based commands
+// only cover the primary office, and reports whether the command may go on
+func (b *TelegramBot) requirePrimaryOffice(ctx context.Context, chatID int64, lang i18n.Language) bool {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+		return true
+	}
+
+	primary := b.primaryOffice()
+	if user == nil || b.userOffice(user.Office).ID == primary.ID {
+		return true
+	}
+
+	b.sendMessage(ctx, chatID, i18n.T(lang, "office.primary_only", models.EscapeMarkdown(primary.DisplayName())))
+	return false
+}
+
+// handleOfficeCommand handles the /office command, e.g. "/office legnica"; without an
+// argument it lists the monitored offices
+func (b *TelegramBot) handleOfficeCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	offices := b.offices
+	if len(offices) == 0 {
+		offices = []parser.Office{b.primaryOffice()}
+	}
+
+	var names []string
+	for _, office := range offices {
+		names = append(names, models.EscapeMarkdown(office.DisplayName()+" ("+office.ID+")"))
+	}
+	available := strings.Join(names, ", ")
+
+	if strings.TrimSpace(args) == "" {
+		current := b.primaryOffice()
+		if user, err := b.db.GetUser(ctx, chatID); err != nil {
+			log.Printf("Failed to get user %d: %v", chatID, err)
+		} else if user != nil {
+			current = b.userOffice(user.Office)
+		}
+		b.sendMessage(ctx, chatID, i18n.T(lang, "office.usage", models.EscapeMarkdown(current.DisplayName()), available))
+		return
+	}
+
+	office, ok := parser.MatchOffice(offices, args)
+	if !ok {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "office.unknown", available))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserOffice(ctx, chatID, office.ID); err != nil {
+		log.Printf("Failed to set office for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	log.Printf("User %s (ID: %d) follows office %s", username, chatID, office.ID)
+	b.sendMessage(ctx, chatID, i18n.T(lang, "office.changed", models.EscapeMarkdown(office.DisplayName())))
+
+	// Replace the live message with the queue of the new office
+	queueData, err := b.latestQueueData(ctx, office)
+	if err != nil {
+		log.Printf("Failed to get latest queue data of office %s: %v", office.ID, err)
+		return
+	}
+	if queueData == nil {
+		return
+	}
+
+	userTicket, err := b.db.GetUserTicketNumber(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user ticket number: %v", err)
+	}
+	b.replaceLiveMessage(ctx, chatID, b.formatQueueMessage(ctx, queueData, nil, userTicket, lang, b.chatLocation(ctx, chatID)))
+}
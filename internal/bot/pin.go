@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"karta/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handlePinCommand handles the /pin on|off command for pinning the live status message
+func (b *TelegramBot) handlePinCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	var enabled bool
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "pin.usage"))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserPinMessage(ctx, chatID, enabled); err != nil {
+		log.Printf("Failed to set pin message for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	// Apply the setting to the current status message right away
+	if msgIDInterface, exists := b.userMsgs.Load(chatID); exists {
+		if msgID, ok := msgIDInterface.(int); ok {
+			if enabled {
+				b.pinMessage(ctx, chatID, msgID)
+			} else {
+				b.unpinMessage(ctx, chatID, msgID)
+			}
+		}
+	}
+
+	if enabled {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "pin.enabled"))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "pin.disabled"))
+	}
+}
+
+// pinEnabled checks whether the user wants the live status message pinned
+func (b *TelegramBot) pinEnabled(ctx context.Context, chatID int64) bool {
+	enabled, err := b.db.GetUserPinMessage(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get pin setting for %d: %v", chatID, err)
+		return false
+	}
+	return enabled
+}
+
+// pinMessage silently pins a message in the chat
+func (b *TelegramBot) pinMessage(ctx context.Context, chatID int64, messageID int) {
+	pin := tgbotapi.PinChatMessageConfig{
+		ChatID:              chatID,
+		MessageID:           messageID,
+		DisableNotification: true,
+	}
+
+	if err := b.request(ctx, chatID, pin); err != nil {
+		log.Printf("Failed to pin message %d for chat %d: %v", messageID, chatID, err)
+	}
+}
+
+// unpinMessage unpins a message in the chat
+func (b *TelegramBot) unpinMessage(ctx context.Context, chatID int64, messageID int) {
+	unpin := tgbotapi.UnpinChatMessageConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+
+	if err := b.request(ctx, chatID, unpin); err != nil {
+		log.Printf("Failed to unpin message %d for chat %d: %v", messageID, chatID, err)
+	}
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"karta/internal/database"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	QuarantineThreshold = 3                // Consecutive failed deliveries before a user is quarantined
+	QuarantineBase      = 10 * time.Minute // First quarantine, doubled with every further failure
+	QuarantineMax       = 24 * time.Hour
+)
+
+// recordDelivery updates the delivery failure state of a broadcast recipient. Users who
+// blocked the bot are deactivated; other failures quarantine the user with backoff after
+// QuarantineThreshold consecutive errors, and the next broadcast after it expires retries.
+func (b *TelegramBot) recordDelivery(ctx context.Context, user database.User, err error) {
+	if err == nil {
+		if user.SendFailures > 0 {
+			if err := b.db.ResetSendFailures(ctx, user.ChatID); err != nil {
+				log.Printf("Failed to reset send failures of user %d: %v", user.ChatID, err)
+			}
+		}
+		return
+	}
+
+	if isBlockedError(err) {
+		b.forgetMessageID(ctx, user.ChatID)
+		if err := b.db.DeactivateUser(ctx, user.ChatID); err != nil {
+			log.Printf("Failed to deactivate user %d: %v", user.ChatID, err)
+		}
+		return
+	}
+
+	failures, dbErr := b.db.RecordSendFailure(ctx, user.ChatID)
+	if dbErr != nil {
+		log.Printf("Failed to record send failure of user %d: %v", user.ChatID, dbErr)
+		return
+	}
+
+	if failures < QuarantineThreshold {
+		return
+	}
+
+	if err := b.db.QuarantineUser(ctx, user.ChatID, time.Now().Add(quarantineDuration(failures))); err != nil {
+		log.Printf("Failed to quarantine user %d: %v", user.ChatID, err)
+	}
+}
+
+// quarantineDuration returns the backoff for the given number of consecutive failures
+func quarantineDuration(failures int) time.Duration {
+	duration := QuarantineBase
+	for i := QuarantineThreshold; i < failures && duration < QuarantineMax; i++ {
+		duration *= 2
+	}
+
+	if duration > QuarantineMax {
+		return QuarantineMax
+	}
+	return duration
+}
+
+// isBlockedError reports whether Telegram rejected a send because the user blocked the bot
+// or the chat can no longer be written to (403 Forbidden)
+func isBlockedError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
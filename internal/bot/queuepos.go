@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// QueuePosMaxListed is the number of nearest shared tickets listed on each side
+const QueuePosMaxListed = 10
+
+// handleQueuePosCommand handles /queuepos: "on"/"off" share the user's ticket, no
+// arguments show the shared tickets of other bot users ahead of and behind the user
+func (b *TelegramBot) handleQueuePosCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on", "off":
+		b.setShareTicket(ctx, chatID, username, strings.EqualFold(strings.TrimSpace(args), "on"), lang)
+	case "":
+		b.sendQueuePosition(ctx, chatID, lang)
+	default:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "queuepos.usage"))
+	}
+}
+
+// setShareTicket stores whether the user's ticket is counted in other users' positions
+func (b *TelegramBot) setShareTicket(ctx context.Context, chatID int64, username string, enabled bool, lang i18n.Language) {
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserShareTicket(ctx, chatID, enabled); err != nil {
+		log.Printf("Failed to set ticket sharing for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if enabled {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "queuepos.shared"))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "queuepos.unshared"))
+	}
+}
+
+// sendQueuePosition sends how many shared tickets are ahead of and behind the user's
+// ticket. Only users sharing their own ticket see the others.
+func (b *TelegramBot) sendQueuePosition(ctx context.Context, chatID int64, lang i18n.Language) {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+	if user == nil || user.TicketNumber == "" {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "queuepos.no_ticket"))
+		return
+	}
+	if !user.ShareTicket {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "queuepos.not_shared"))
+		return
+	}
+
+	queueData, err := b.latestQueueData(ctx, b.userOffice(user.Office))
+	if err != nil {
+		log.Printf("Failed to get queue data: %v", err)
+	}
+	if queueData == nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "start.no_data"))
+		return
+	}
+
+	ticketsAhead, err := queueData.TicketsAhead(user.TicketNumber)
+	if err != nil || ticketsAhead <= 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "queuepos.called", models.EscapeMarkdown(user.TicketNumber)))
+		return
+	}
+
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	ahead, behind := sharedTicketOffsets(b.officeUsers(users, queueData), queueData, chatID, ticketsAhead)
+	b.sendMessage(ctx, chatID, i18n.T(lang, "queuepos.result", models.EscapeMarkdown(user.TicketNumber), ticketsAhead,
+		len(ahead), formatOffsets(ahead), len(behind), formatOffsets(behind)))
+}
+
+// sharedTicketOffsets returns the distances in tickets from the user's position to the
+// not yet called shared tickets of other users, nearest first. Ahead offsets are negative.
+func sharedTicketOffsets(users []database.User, queueData *models.QueueData, chatID int64, ticketsAhead int) (ahead, behind []int) {
+	for _, other := range users {
+		if other.ChatID == chatID || !other.ShareTicket || other.TicketNumber == "" {
+			continue
+		}
+
+		otherAhead, err := queueData.TicketsAhead(other.TicketNumber)
+		if err != nil || otherAhead <= 0 {
+			continue // Already called
+		}
+
+		switch offset := otherAhead - ticketsAhead; {
+		case offset < 0:
+			ahead = append(ahead, offset)
+		case offset > 0:
+			behind = append(behind, offset)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(ahead)))
+	sort.Ints(behind)
+	return ahead, behind
+}
+
+// formatOffsets formats the nearest offsets as an escaped " (−12, −3)" suffix, empty for none
+func formatOffsets(offsets []int) string {
+	if len(offsets) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for i, offset := range offsets {
+		if i == QueuePosMaxListed {
+			parts = append(parts, "…")
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%+d", offset))
+	}
+	return models.EscapeMarkdown(" (" + strings.Join(parts, ", ") + ")")
+}
@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	GlobalMessagesPerSecond = 25          // Telegram allows ~30 messages/sec overall
+	PerChatInterval         = time.Second // Telegram allows ~1 message/sec per chat
+	MaxSendRetries          = 3
+	maxTrackedChats         = 1000 // Prune per-chat send times above this size
+)
+
+// rateLimiter is a token bucket for global throughput combined with a per-chat minimum interval
+type rateLimiter struct {
+	mu           sync.Mutex
+	rate         float64 // Tokens added per second
+	burst        float64
+	tokens       float64
+	lastRefill   time.Time
+	perChat      time.Duration
+	lastSent     map[int64]time.Time
+	blockedUntil time.Time // Set when Telegram asks to retry later
+}
+
+// newRateLimiter creates a rate limiter allowing perSecond messages overall and one message per chat every perChat
+func newRateLimiter(perSecond int, perChat time.Duration) *rateLimiter {
+	return &rateLimiter{
+		rate:       float64(perSecond),
+		burst:      float64(perSecond),
+		tokens:     float64(perSecond),
+		lastRefill: time.Now(),
+		perChat:    perChat,
+		lastSent:   make(map[int64]time.Time),
+	}
+}
+
+// Wait blocks until a message to the chat may be sent, or until the context is cancelled
+func (l *rateLimiter) Wait(ctx context.Context, chatID int64) error {
+	for {
+		wait := l.reserve(chatID)
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token for the chat if possible, otherwise returns how long to wait
+func (l *rateLimiter) reserve(chatID int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+
+	if next := l.lastSent[chatID].Add(l.perChat); now.Before(next) {
+		return next.Sub(now)
+	}
+
+	if l.tokens < 1 {
+		return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+
+	l.tokens--
+	l.lastSent[chatID] = now
+	return 0
+}
+
+// refill adds tokens for the elapsed time and prunes stale per-chat entries
+func (l *rateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.lastRefill).Seconds()
+	l.tokens = min(l.burst, l.tokens+elapsed*l.rate)
+	l.lastRefill = now
+
+	if len(l.lastSent) > maxTrackedChats {
+		for chatID, sentAt := range l.lastSent {
+			if now.Sub(sentAt) > l.perChat {
+				delete(l.lastSent, chatID)
+			}
+		}
+	}
+}
+
+// BlockFor pauses all sending for the given duration
+func (l *rateLimiter) BlockFor(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// send sends a message through the rate limiter, retrying when Telegram responds with 429
+func (b *TelegramBot) send(ctx context.Context, chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if b.dryRun {
+		logDryRun(chatID, c)
+		return tgbotapi.Message{}, nil
+	}
+
+	var sentMsg tgbotapi.Message
+	err := b.withRetry(ctx, chatID, func() error {
+		var err error
+		sentMsg, err = b.api.Send(c)
+		return err
+	})
+	return sentMsg, err
+}
+
+// request makes a Bot API call that does not return a message (pin, unpin, commands)
+// through the rate limiter, retrying when Telegram responds with 429
+func (b *TelegramBot) request(ctx context.Context, chatID int64, c tgbotapi.Chattable) error {
+	if b.dryRun {
+		logDryRun(chatID, c)
+		return nil
+	}
+
+	return b.withRetry(ctx, chatID, func() error {
+		_, err := b.api.Request(c)
+		return err
+	})
+}
+
+// withRetry runs a rate-limited Bot API call, retrying after the delay requested on 429.
+// A cancelled context stops waiting for the limiter, so a long retry_after does not hold
+// up a shutdown; the last error is returned then.
+func (b *TelegramBot) withRetry(ctx context.Context, chatID int64, call func() error) error {
+	var lastErr error
+
+	for attempt := 0; attempt <= MaxSendRetries; attempt++ {
+		if err := b.limiter.Wait(sendContext(ctx), chatID); err != nil {
+			if lastErr != nil {
+				return fmt.Errorf("%w (gave up retrying: %w)", lastErr, err)
+			}
+			return err
+		}
+
+		err := call()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		retryAfter, ok := retryAfterFromError(err)
+		if !ok {
+			return err
+		}
+
+		log.Printf("Rate limited by Telegram for chat %d, retrying after %v (attempt %d/%d)", chatID, retryAfter, attempt+1, MaxSendRetries)
+		b.limiter.BlockFor(retryAfter)
+	}
+
+	return lastErr
+}
+
+// retryAfterFromError extracts the retry delay from a 429 Bot API error
+func retryAfterFromError(err error) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	return retryAfter, true
+}
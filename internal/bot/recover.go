@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// PanicReportInterval limits panic reports to the admins, so a handler panicking for every
+// message does not flood them; all panics are still logged
+const PanicReportInterval = time.Minute
+
+// RestartReportInterval limits restart reports to the admins, as a goroutine failing over
+// and over is restarted several times a minute at first; all restarts are still logged
+const RestartReportInterval = time.Minute
+
+// errRecovered is returned by runRecovered when the function panicked
+var errRecovered = errors.New("recovered from panic")
+
+// recoverPanic recovers a panic of a bot goroutine, logs it with the stack and reports it
+// to the admins. It must be deferred directly.
+func (b *TelegramBot) recoverPanic(ctx context.Context, what string) {
+	if r := recover(); r != nil {
+		b.reportPanic(ctx, what, r)
+	}
+}
+
+// runRecovered runs fn, turning a panic into a report and an error wrapping errRecovered
+func (b *TelegramBot) runRecovered(ctx context.Context, what string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.reportPanic(ctx, what, r)
+			err = fmt.Errorf("%w in %s: %v", errRecovered, what, r)
+		}
+	}()
+	return fn()
+}
+
+// reportPanic logs a recovered panic and tells the admins about it
+func (b *TelegramBot) reportPanic(ctx context.Context, what string, value interface{}) {
+	b.panics.Add(1)
+	log.Printf("Recovered from panic in %s: %v\n%s", what, value, debug.Stack())
+
+	b.panicMu.Lock()
+	now := time.Now()
+	if now.Sub(b.lastPanicReport) < PanicReportInterval {
+		b.panicMu.Unlock()
+		return
+	}
+	b.lastPanicReport = now
+	b.panicMu.Unlock()
+
+	for chatID := range b.admins {
+		lang, _ := b.userLanguage(ctx, chatID, "")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.panic", models.EscapeMarkdown(what), models.EscapeMarkdown(fmt.Sprint(value))))
+	}
+}
+
+// SetRestartCounter sets the count of restarted goroutines shown in /botstats
+func (b *TelegramBot) SetRestartCounter(restarts func() int) {
+	b.restarts = restarts
+}
+
+// ReportRestart tells the admins that a supervised goroutine stopped and is restarted
+// after the delay
+func (b *TelegramBot) ReportRestart(ctx context.Context, what string, restarts int, cause error, delay time.Duration) {
+	b.restartMu.Lock()
+	now := time.Now()
+	if now.Sub(b.lastRestartReport) < RestartReportInterval {
+		b.restartMu.Unlock()
+		return
+	}
+	b.lastRestartReport = now
+	b.restartMu.Unlock()
+
+	for chatID := range b.admins {
+		lang, _ := b.userLanguage(ctx, chatID, "")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "admin.restart", models.EscapeMarkdown(what), models.EscapeMarkdown(cause.Error()), restarts, models.EscapeMarkdown(delay.String())))
+	}
+}
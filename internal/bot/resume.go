@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/models"
+	"karta/internal/notifier"
+)
+
+// BroadcastResumeWindow is how long after a shutdown an interrupted queue update broadcast is
+// resumed; an older one is skipped, as the live messages are resynchronized on start anyway
+const BroadcastResumeWindow = 10 * time.Minute
+
+// progressKey is the key of the stored progress of a broadcast: the office for queue
+// updates, the office and the kind for alerts
+func progressKey(queueData *models.QueueData, alert notifier.AlertKind) string {
+	if alert == "" {
+		return queueData.Office
+	}
+	return queueData.Office + "/" + string(alert)
+}
+
+// resumeBroadcast narrows the users of a queue update, or of an alert of the given kind, to
+// those an interrupted broadcast of the same data did not reach. It reports whether stored
+// progress was found, which the broadcast drops once it finishes. The progress of older data
+// is superseded by this update.
+func (b *TelegramBot) resumeBroadcast(ctx context.Context, alert notifier.AlertKind, queueData *models.QueueData, users []database.User) ([]database.User, bool) {
+	progress, err := b.db.GetBroadcastProgress(ctx, progressKey(queueData, alert))
+	if err != nil {
+		log.Printf("Failed to get interrupted broadcast: %v", err)
+		return users, false
+	}
+	if progress == nil {
+		return users, false
+	}
+
+	if !progress.PolledAt.Equal(queueData.LastUpdated) {
+		log.Printf("Dropping interrupted broadcast of %s, superseded by a newer update", progress.PolledAt.Format(time.RFC3339))
+		return users, true
+	}
+	if time.Since(progress.SavedAt) > BroadcastResumeWindow {
+		log.Printf("Skipping interrupted broadcast of %s, saved at %s", progress.PolledAt.Format(time.RFC3339), progress.SavedAt.Format(time.RFC3339))
+		return nil, true
+	}
+
+	pending := make(map[int64]bool, len(progress.Pending))
+	for _, chatID := range progress.Pending {
+		pending[chatID] = true
+	}
+
+	var remaining []database.User
+	for _, user := range users {
+		if pending[user.ChatID] {
+			remaining = append(remaining, user)
+		}
+	}
+	log.Printf("Resuming interrupted broadcast of %s for %d users", progress.PolledAt.Format(time.RFC3339), len(remaining))
+	return remaining, true
+}
+
+// saveBroadcast stores the users an interrupted broadcast of the queue data did not reach.
+// Saving is not cancelled, as the broadcast was interrupted by a cancelled context.
+func (b *TelegramBot) saveBroadcast(ctx context.Context, alert notifier.AlertKind, queueData *models.QueueData, pending []database.User) {
+	progress := database.BroadcastProgress{Office: progressKey(queueData, alert), PolledAt: queueData.LastUpdated, SavedAt: time.Now()}
+	for _, user := range pending {
+		progress.Pending = append(progress.Pending, user.ChatID)
+	}
+
+	if err := b.db.SaveBroadcastProgress(context.WithoutCancel(ctx), progress); err != nil {
+		log.Printf("Failed to save interrupted broadcast: %v", err)
+	}
+}
+
+// finishBroadcast drops the progress of an interrupted broadcast once it has been resumed
+func (b *TelegramBot) finishBroadcast(ctx context.Context, alert notifier.AlertKind, queueData *models.QueueData, resumed bool) {
+	if !resumed {
+		return
+	}
+	if err := b.db.DeleteBroadcastProgress(ctx, progressKey(queueData, alert)); err != nil {
+		log.Printf("Failed to delete interrupted broadcast: %v", err)
+	}
+}
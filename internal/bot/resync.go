@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ResyncLiveMessages refreshes the stored live messages of all active users of the primary
+// office with the latest stored queue data, so nobody keeps seeing the data from before a
+// restart until the next change. Users without a live message get no new one.
+func (b *TelegramBot) ResyncLiveMessages(ctx context.Context) error {
+	queueData, err := b.queue.Current(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest queue data: %w", err)
+	}
+	if queueData == nil {
+		log.Println("No stored queue data to resynchronize live messages with")
+		return nil
+	}
+	return b.refreshLiveMessages(ctx, queueData)
+}
+
+// refreshLiveMessages redraws the stored live messages of the active users following the
+// office of the queue data
+func (b *TelegramBot) refreshLiveMessages(ctx context.Context, queueData *models.QueueData) error {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	var stale []database.User
+	for _, user := range b.officeUsers(users, queueData) {
+		if _, exists := b.userMsgs.Load(user.ChatID); exists {
+			stale = append(stale, user)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	log.Printf("Resynchronizing live messages of %d users", len(stale))
+
+	successCount, errorCount := b.broadcast(ctx, stale, func(ctx context.Context, user database.User) error {
+		msgIDInterface, exists := b.userMsgs.Load(user.ChatID)
+		if !exists {
+			return nil
+		}
+		msgID, ok := msgIDInterface.(int)
+		if !ok {
+			return nil
+		}
+
+		message := b.formatQueueMessage(ctx, queueData, nil, user.TicketNumber, i18n.OrDefault(user.Language), b.userLocation(&user))
+		err := b.updateMessage(ctx, user.ChatID, msgID, message)
+		if err == nil || isNotModifiedError(err) {
+			return nil
+		}
+		if !isBlockedError(err) {
+			// The message cannot be edited; it is deleted and the next broadcast sends a new one
+			b.forgetMessageID(ctx, user.ChatID)
+			b.retireLiveMessage(ctx, user.ChatID, msgID)
+		}
+		return err
+	})
+
+	log.Printf("Resynchronization completed: %d successful, %d errors", successCount, errorCount)
+	return nil
+}
+
+// isNotModifiedError reports whether an edit failed only because the text did not change
+func isNotModifiedError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "message is not modified")
+}
@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"karta/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// commandRequest is an incoming message on its way through the middleware to a handler
+type commandRequest struct {
+	ctx      context.Context // Cancelled after HandlerTimeout or on shutdown
+	message  *tgbotapi.Message
+	chatID   int64
+	username string // Sender name, or the group title in groups; empty without a sender
+	lang     i18n.Language
+
+	// languageCode is the client language of the sender, empty without a sender
+	languageCode string
+
+	// languageChosen is set by handlers storing an explicit language, which the language
+	// detected from the client must not overwrite
+	languageChosen bool
+}
+
+// newCommandRequest creates the request of a message. Messages of channels and anonymous
+// group admins have no sender, so they get no username or client language.
+func newCommandRequest(ctx context.Context, message *tgbotapi.Message) *commandRequest {
+	req := &commandRequest{ctx: ctx, message: message, chatID: message.Chat.ID}
+	if message.From != nil {
+		req.username = message.From.UserName
+		req.languageCode = message.From.LanguageCode
+	}
+	return req
+}
+
+// command returns the command without the slash, or "" for plain text
+func (r *commandRequest) command() string {
+	return r.message.Command()
+}
+
+// args returns the text after the command
+func (r *commandRequest) args() string {
+	return r.message.CommandArguments()
+}
+
+// group reports whether the message was sent in a group chat
+func (r *commandRequest) group() bool {
+	return isGroupChat(r.message.Chat)
+}
+
+// commandHandler handles a request
+type commandHandler func(req *commandRequest)
+
+// middleware wraps a handler, e.g. to reject or annotate requests before it runs
+type middleware func(next commandHandler) commandHandler
+
+// commandRoute registers the handler of a command and the restrictions it runs under
+type commandRoute struct {
+	name       string
+	handle     commandHandler
+	admin      bool // Only in the command menu of the ADMIN_CHAT_IDS
+	groupAdmin bool // Changes the shared settings of a group, so only group admins may use it there
+	personal   bool // About a single person's ticket, so it only works in private chats
+}
+
+// router dispatches requests to the handlers of their commands through the middleware;
+// plain text and unknown commands go to the fallback
+type router struct {
+	routes      []commandRoute
+	byName      map[string]commandRoute
+	middlewares []middleware
+	fallback    commandHandler
+}
+
+// newRouter creates a router running the middleware in the given order before every handler
+func newRouter(fallback commandHandler, middlewares ...middleware) *router {
+	return &router{byName: make(map[string]commandRoute), middlewares: middlewares, fallback: fallback}
+}
+
+// handle registers a route, wrapping its handler in the route's own middleware
+func (r *router) handle(route commandRoute, middlewares ...middleware) {
+	route.handle = chain(route.handle, middlewares...)
+	r.routes = append(r.routes, route)
+	r.byName[route.name] = route
+}
+
+// route returns the route of a command
+func (r *router) route(name string) (commandRoute, bool) {
+	route, ok := r.byName[name]
+	return route, ok
+}
+
+// serve runs a request through the middleware and the handler of its command
+func (r *router) serve(req *commandRequest) {
+	chain(r.dispatch, r.middlewares...)(req)
+}
+
+// dispatch calls the handler of the request's command
+func (r *router) dispatch(req *commandRequest) {
+	if route, ok := r.byName[req.command()]; ok {
+		route.handle(req)
+		return
+	}
+	r.fallback(req)
+}
+
+// chain wraps the handler so the middleware runs in the given order before it
+func chain(handler commandHandler, middlewares ...middleware) commandHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// logRequests logs every incoming message
+func (b *TelegramBot) logRequests(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		log.Printf("Received message from %s (ID: %d): %s", req.username, req.chatID, req.message.Text)
+		next(req)
+	}
+}
+
+// limitRequests drops the messages of chats sending too many commands
+func (b *TelegramBot) limitRequests(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		// Group chatter is ignored anyway and does not count against the group's limit
+		if req.message.IsCommand() || !req.group() {
+			if !b.allowCommand(req.ctx, req.chatID, req.languageCode) {
+				return
+			}
+		}
+		next(req)
+	}
+}
+
+// rejectBanned drops the messages of banned users
+func (b *TelegramBot) rejectBanned(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		if banned, err := b.db.IsUserBanned(req.ctx, req.chatID); err != nil {
+			log.Printf("Failed to check ban for user %d: %v", req.chatID, err)
+		} else if banned {
+			log.Printf("Ignoring message from banned user %d", req.chatID)
+			return
+		}
+		next(req)
+	}
+}
+
+// localize sets the language of the request: the stored one, or the one detected from the
+// client, which is persisted after handling so users registered by this message get it too
+func (b *TelegramBot) localize(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		lang, stored := b.userLanguage(req.ctx, req.chatID, req.languageCode)
+		req.lang = lang
+		next(req)
+
+		if !stored && !req.languageChosen {
+			b.rememberLanguage(req.ctx, req.chatID, lang)
+		}
+	}
+}
+
+// trackActivity records the handled messages for the engagement stats of /botstats
+func (b *TelegramBot) trackActivity(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		next(req) // Recorded afterwards so users registered by this message are counted too
+
+		now := time.Now()
+		if err := b.db.RecordActivity(req.ctx, req.chatID, now); err != nil {
+			log.Printf("Failed to record activity of user %d: %v", req.chatID, err)
+		}
+		if _, ok := b.router.route(req.command()); ok {
+			if err := b.db.RecordCommand(req.ctx, req.command(), now); err != nil {
+				log.Printf("Failed to record usage of /%s: %v", req.command(), err)
+			}
+		}
+	}
+}
+
+// requireAdmin lets only the ADMIN_CHAT_IDS run a command; others get the help text as
+// for any unknown command
+func (b *TelegramBot) requireAdmin(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		if !b.isAdmin(req.chatID) {
+			log.Printf("Rejected admin command /%s from non-admin %d", req.command(), req.chatID)
+			b.sendMessage(req.ctx, req.chatID, i18n.T(req.lang, "help"))
+			return
+		}
+
+		log.Printf("Admin %d executed /%s %s", req.chatID, req.command(), req.args())
+		next(req)
+	}
+}
+
+// requirePrimary tells users following another office that the command only covers the
+// primary office
+func (b *TelegramBot) requirePrimary(next commandHandler) commandHandler {
+	return func(req *commandRequest) {
+		if b.requirePrimaryOffice(req.ctx, req.chatID, req.lang) {
+			next(req)
+		}
+	}
+}
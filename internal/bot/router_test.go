@@ -0,0 +1,439 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	testBotName = "karta_test_bot"
+	testChatID  = 1001
+)
+
+// apiCall is a Bot API request received by the fake sender
+type apiCall struct {
+	method string
+	params url.Values
+}
+
+// fakeSender is a Bot API server recording the requests of the bot and answering them
+// like Telegram
+type fakeSender struct {
+	mu    sync.Mutex
+	calls []apiCall
+	next  int // ID of the next sent message
+}
+
+func (f *fakeSender) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case method == "getMe":
+		fmt.Fprintf(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Karta","username":%q}}`, testBotName)
+		return
+	case strings.HasPrefix(method, "send") || strings.HasPrefix(method, "edit"):
+		f.mu.Lock()
+		f.calls = append(f.calls, apiCall{method: method, params: r.PostForm})
+		f.next++
+		id := f.next
+		f.mu.Unlock()
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"date":0,"chat":{"id":%s,"type":"private"}}}`, id, r.PostForm.Get("chat_id"))
+	default:
+		f.mu.Lock()
+		f.calls = append(f.calls, apiCall{method: method, params: r.PostForm})
+		f.mu.Unlock()
+		fmt.Fprint(w, `{"ok":true,"result":true}`)
+	}
+}
+
+// methods returns the Bot API methods called so far, in order
+func (f *fakeSender) methods() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var methods []string
+	for _, call := range f.calls {
+		methods = append(methods, call.method)
+	}
+	return methods
+}
+
+// texts returns the texts of the messages sent and edited so far
+func (f *fakeSender) texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var texts []string
+	for _, call := range f.calls {
+		if text := call.params.Get("text"); text != "" && call.method != "answerCallbackQuery" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// newTestBot creates a bot on an in-memory store, sending through a fake Bot API server
+func newTestBot(t *testing.T) (*TelegramBot, *fakeSender, database.Store) {
+	t.Helper()
+
+	sender := &fakeSender{}
+	server := httptest.NewServer(sender)
+	t.Cleanup(server.Close)
+
+	api, err := tgbotapi.NewBotAPIWithClient("test-token", server.URL+"/bot%s/%s", server.Client())
+	if err != nil {
+		t.Fatalf("failed to create bot API: %v", err)
+	}
+
+	db := database.NewMemory()
+	b := newTelegramBot(context.Background(), api, db, nil, nil)
+	b.limiter = newRateLimiter(1000, 0) // Tests send several messages to one chat at once
+	return b, sender, db
+}
+
+// privateMessage returns a message of the test user in their private chat
+func privateMessage(text string) *tgbotapi.Message {
+	message := &tgbotapi.Message{
+		MessageID: 1,
+		From:      &tgbotapi.User{ID: testChatID, UserName: "tester", LanguageCode: "en"},
+		Chat:      &tgbotapi.Chat{ID: testChatID, Type: "private"},
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+	return message
+}
+
+// groupMessage returns a message of the test user in a group chat
+func groupMessage(text string) *tgbotapi.Message {
+	message := privateMessage(text)
+	message.Chat = &tgbotapi.Chat{ID: -2002, Type: "supergroup", Title: "Karta group"}
+	return message
+}
+
+// getUser returns the stored test user
+func getUser(t *testing.T, db database.Store) *database.User {
+	t.Helper()
+
+	user, err := db.GetUser(context.Background(), testChatID)
+	if err != nil || user == nil {
+		t.Fatalf("failed to get test user: %v, %v", user, err)
+	}
+	return user
+}
+
+func TestRouterDispatch(t *testing.T) {
+	en := i18n.Language("en")
+
+	tests := []struct {
+		name  string
+		text  string
+		reply string
+		check func(t *testing.T, user *database.User)
+	}{
+		{
+			name:  "alerts on",
+			text:  "/alerts on",
+			reply: i18n.T(en, "alerts.enabled"),
+			check: func(t *testing.T, user *database.User) {
+				if !user.StatusAlerts {
+					t.Error("status alerts not enabled")
+				}
+			},
+		},
+		{
+			name:  "alerts usage",
+			text:  "/alerts maybe",
+			reply: i18n.T(en, "alerts.usage"),
+		},
+		{
+			name:  "threshold",
+			text:  "/threshold 15",
+			reply: i18n.T(en, "threshold.enabled", 15),
+			check: func(t *testing.T, user *database.User) {
+				if user.TicketsAlert != 15 {
+					t.Errorf("tickets alert = %d, want 15", user.TicketsAlert)
+				}
+			},
+		},
+		{
+			name:  "command addressed to the bot",
+			text:  "/threshold@" + testBotName + " off",
+			reply: i18n.T(en, "threshold.disabled"),
+			check: func(t *testing.T, user *database.User) {
+				if user.TicketsAlert != -1 {
+					t.Errorf("tickets alert = %d, want -1 (off)", user.TicketsAlert)
+				}
+			},
+		},
+		{
+			name: "ticket number",
+			text: "k222",
+			check: func(t *testing.T, user *database.User) {
+				if user.TicketNumber != "K222" {
+					t.Errorf("ticket = %q, want K222", user.TicketNumber)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, sender, db := newTestBot(t)
+			b.handleMessage(context.Background(), privateMessage(tt.text))
+
+			texts := sender.texts()
+			if tt.reply != "" && (len(texts) != 1 || texts[0] != tt.reply) {
+				t.Errorf("replies = %q, want %q", texts, tt.reply)
+			}
+			if tt.check != nil {
+				tt.check(t, getUser(t, db))
+			}
+		})
+	}
+}
+
+func TestRouterUnknownCommand(t *testing.T) {
+	for _, text := range []string{"/nosuchcommand", "/nosuchcommand with args", "hello there"} {
+		t.Run(text, func(t *testing.T) {
+			b, sender, _ := newTestBot(t)
+			b.handleMessage(context.Background(), privateMessage(text))
+
+			if texts := sender.texts(); !reflect.DeepEqual(texts, []string{i18n.T("en", "help")}) {
+				t.Errorf("replies = %q, want the help text", texts)
+			}
+		})
+	}
+}
+
+func TestRouterAdminCommand(t *testing.T) {
+	b, sender, _ := newTestBot(t)
+
+	var changed time.Duration
+	b.SetIntervalHandler(func(interval time.Duration) { changed = interval }, nil, nil)
+
+	// Non-admins get the help text, as for an unknown command
+	b.handleMessage(context.Background(), privateMessage("/setinterval 30s"))
+	if changed != 0 {
+		t.Fatalf("non-admin changed the interval to %v", changed)
+	}
+	if texts := sender.texts(); len(texts) != 1 || texts[0] != i18n.T("en", "help") {
+		t.Fatalf("replies to a non-admin = %q, want the help text", texts)
+	}
+
+	b.SetAdmins([]int64{testChatID})
+	b.handleMessage(context.Background(), privateMessage("/setinterval 30s"))
+	if changed != 30*time.Second {
+		t.Errorf("interval changed to %v, want 30s", changed)
+	}
+}
+
+func TestRouterGroupPolicy(t *testing.T) {
+	b, sender, _ := newTestBot(t)
+
+	// Personal commands only work in private chats, chatter and other bots' commands are ignored
+	b.handleMessage(context.Background(), groupMessage("/queuepos"))
+	b.handleMessage(context.Background(), groupMessage("K222"))
+	b.handleMessage(context.Background(), groupMessage("/alerts@other_bot on"))
+
+	if texts := sender.texts(); !reflect.DeepEqual(texts, []string{i18n.T("en", "group.personal")}) {
+		t.Errorf("replies = %q, want only the personal command notice", texts)
+	}
+}
+
+func TestRouterWithoutSender(t *testing.T) {
+	b, sender, _ := newTestBot(t)
+
+	// Anonymous group admins and channels send messages without a sender
+	anonymous := func(text string) *tgbotapi.Message {
+		message := groupMessage(text)
+		message.From = nil
+		return message
+	}
+
+	if req := newCommandRequest(context.Background(), anonymous("/queuepos")); req.username != "" || req.languageCode != "" {
+		t.Errorf("request without a sender = %+v, want no username and client language", req)
+	}
+
+	b.handleMessage(context.Background(), anonymous("/queuepos"))
+	b.handleMessage(context.Background(), anonymous("hello"))
+
+	// Without a client language the notice is in the default language
+	if texts := sender.texts(); !reflect.DeepEqual(texts, []string{i18n.T(i18n.DefaultLanguage, "group.personal")}) {
+		t.Errorf("replies = %q, want only the personal command notice", texts)
+	}
+}
+
+func TestRouterMiddlewareOrder(t *testing.T) {
+	var order []string
+	record := func(name string) middleware {
+		return func(next commandHandler) commandHandler {
+			return func(req *commandRequest) {
+				order = append(order, name+" before")
+				next(req)
+				order = append(order, name+" after")
+			}
+		}
+	}
+
+	r := newRouter(func(req *commandRequest) { order = append(order, "fallback") }, record("first"), record("second"))
+	r.handle(commandRoute{name: "ping", handle: func(req *commandRequest) {
+		order = append(order, "ping")
+	}}, record("route"))
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"/ping", []string{"first before", "second before", "route before", "ping", "route after", "second after", "first after"}},
+		{"/pong", []string{"first before", "second before", "fallback", "second after", "first after"}},
+		{"ping", []string{"first before", "second before", "fallback", "second after", "first after"}},
+	}
+
+	for _, tt := range tests {
+		order = nil
+		r.serve(newCommandRequest(context.Background(), privateMessage(tt.text)))
+		if !reflect.DeepEqual(order, tt.want) {
+			t.Errorf("%s ran %q, want %q", tt.text, order, tt.want)
+		}
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	t.Run("banned users are ignored", func(t *testing.T) {
+		b, sender, db := newTestBot(t)
+		if err := db.AddUser(context.Background(), testChatID, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SetUserBanned(context.Background(), testChatID, true); err != nil {
+			t.Fatal(err)
+		}
+
+		b.handleMessage(context.Background(), privateMessage("/alerts on"))
+		if methods := sender.methods(); len(methods) != 0 {
+			t.Errorf("banned user got %v", methods)
+		}
+		if getUser(t, db).StatusAlerts {
+			t.Error("banned user enabled alerts")
+		}
+	})
+
+	t.Run("flooding chats are muted", func(t *testing.T) {
+		b, sender, _ := newTestBot(t)
+		b.SetCommandLimit(2, time.Minute)
+
+		for i := 0; i < 4; i++ {
+			b.handleMessage(context.Background(), privateMessage("/alerts maybe"))
+		}
+
+		usage, muted := i18n.T("en", "alerts.usage"), i18n.T("en", "commands.muted", models.FormatMinutes("en", 1))
+		if texts := sender.texts(); !reflect.DeepEqual(texts, []string{usage, usage, muted}) {
+			t.Errorf("replies = %q, want two usage texts and the mute notice", texts)
+		}
+	})
+
+	t.Run("stored language wins over the client language", func(t *testing.T) {
+		b, sender, db := newTestBot(t)
+		if err := db.AddUser(context.Background(), testChatID, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SetUserLanguage(context.Background(), testChatID, "pl"); err != nil {
+			t.Fatal(err)
+		}
+
+		b.handleMessage(context.Background(), privateMessage("hello"))
+		if texts := sender.texts(); len(texts) != 1 || texts[0] != i18n.T("pl", "help") {
+			t.Errorf("replies = %q, want the Polish help text", texts)
+		}
+	})
+
+	t.Run("detected language and activity are stored", func(t *testing.T) {
+		b, _, db := newTestBot(t)
+
+		b.handleMessage(context.Background(), privateMessage("/alerts on"))
+		user := getUser(t, db)
+		if user.Language != "en" {
+			t.Errorf("language = %q, want the detected en", user.Language)
+		}
+		if user.LastSeen.IsZero() {
+			t.Error("activity not recorded")
+		}
+	})
+}
+
+func TestCallbackRoutes(t *testing.T) {
+	query := func(data string) *tgbotapi.CallbackQuery {
+		message := privateMessage("")
+		message.MessageID = 42
+		return &tgbotapi.CallbackQuery{ID: "q1", From: message.From, Message: message, Data: data}
+	}
+
+	t.Run("settings button", func(t *testing.T) {
+		b, sender, db := newTestBot(t)
+		b.handleCallbackQuery(context.Background(), query(SettingsCallbackPrefix+"alerts:on"))
+
+		if !getUser(t, db).StatusAlerts {
+			t.Error("alerts button did not enable alerts")
+		}
+		if methods := sender.methods(); !reflect.DeepEqual(methods, []string{"answerCallbackQuery", "editMessageText"}) {
+			t.Errorf("called %v, want the answer and the menu update", methods)
+		}
+	})
+
+	t.Run("close button", func(t *testing.T) {
+		b, sender, _ := newTestBot(t)
+		b.handleCallbackQuery(context.Background(), query(SettingsCallbackPrefix+"close"))
+
+		if texts := sender.texts(); !reflect.DeepEqual(texts, []string{i18n.T("en", "settings.closed")}) {
+			t.Errorf("texts = %q, want the closed menu", texts)
+		}
+	})
+
+	t.Run("unknown button", func(t *testing.T) {
+		b, sender, _ := newTestBot(t)
+		b.handleCallbackQuery(context.Background(), query("other:data"))
+
+		if methods := sender.methods(); !reflect.DeepEqual(methods, []string{"answerCallbackQuery"}) {
+			t.Errorf("called %v, want only the answer", methods)
+		}
+	})
+
+	t.Run("banned user", func(t *testing.T) {
+		b, sender, db := newTestBot(t)
+		if err := db.AddUser(context.Background(), testChatID, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.SetUserBanned(context.Background(), testChatID, true); err != nil {
+			t.Fatal(err)
+		}
+
+		b.handleCallbackQuery(context.Background(), query(SettingsCallbackPrefix+"alerts:on"))
+		if getUser(t, db).StatusAlerts {
+			t.Error("banned user enabled alerts")
+		}
+		if methods := sender.methods(); !reflect.DeepEqual(methods, []string{"answerCallbackQuery"}) {
+			t.Errorf("called %v, want only the answer", methods)
+		}
+	})
+}
@@ -0,0 +1,339 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SettingsCallbackPrefix starts the callback data of the /settings buttons, followed by
+// the action and an optional value, e.g. "settings:threshold:20"
+const SettingsCallbackPrefix = "settings:"
+
+var (
+	settingsThresholds = []int{0, 10, 20, 50, 100} // Tickets-left thresholds offered by /settings
+	settingsMuteHours  = []int{1, 2, 4, 8, 24}     // Mute durations offered by /settings
+	settingsIntervals  = []int{1, 5, 15, 30}       // Update intervals in minutes offered by /settings
+)
+
+// Views of the settings menu besides the main one
+const (
+	settingsViewMain      = ""
+	settingsViewLanguage  = "language"
+	settingsViewThreshold = "threshold"
+	settingsViewMute      = "mute"
+	settingsViewInterval  = "interval"
+	settingsViewTicket    = "ticket"
+)
+
+// handleSettingsCommand handles /settings, sending a menu that changes the settings with
+// inline keyboard buttons instead of separate commands
+func (b *TelegramBot) handleSettingsCommand(ctx context.Context, chatID int64, username string, group bool, lang i18n.Language) {
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil || user == nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	text, keyboard := b.settingsMenu(user, settingsViewMain, group, lang)
+	msg := tgbotapi.NewMessage(chatID, b.render(text))
+	msg.ParseMode = b.parseMode
+	msg.ReplyMarkup = keyboard
+	if _, err := b.send(ctx, chatID, msg); err != nil {
+		log.Printf("Failed to send settings menu to %d: %v", chatID, err)
+	}
+}
+
+// handleCallbackQuery handles a press of an inline keyboard button
+func (b *TelegramBot) handleCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil || query.From == nil {
+		return // Buttons of inline-mode messages are not the bot's
+	}
+	if !strings.HasPrefix(query.Data, SettingsCallbackPrefix) {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	group := isGroupChat(query.Message.Chat)
+	if !b.allowCommand(ctx, chatID, query.From.LanguageCode) {
+		b.answerCallback(ctx, query, "")
+		return
+	}
+
+	if banned, err := b.db.IsUserBanned(ctx, chatID); err != nil {
+		log.Printf("Failed to check ban for user %d: %v", chatID, err)
+	} else if banned {
+		log.Printf("Ignoring button press from banned user %d", chatID)
+		b.answerCallback(ctx, query, "")
+		return
+	}
+
+	lang, _ := b.userLanguage(ctx, chatID, query.From.LanguageCode)
+	if group && !b.isChatAdmin(chatID, query.From.ID) {
+		b.answerCallback(ctx, query, i18n.T(lang, "button.admins_only"))
+		return
+	}
+
+	username := query.From.UserName
+	if group {
+		username = query.Message.Chat.Title
+	}
+	action, value, _ := strings.Cut(strings.TrimPrefix(query.Data, SettingsCallbackPrefix), ":")
+	log.Printf("Settings button from %s (ID: %d): %s %s", username, chatID, action, value)
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.answerCallback(ctx, query, "")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+	if err := b.db.RecordActivity(ctx, chatID, time.Now()); err != nil {
+		log.Printf("Failed to record activity of user %d: %v", chatID, err)
+	}
+
+	view, err := b.applySettingsButton(ctx, chatID, action, value, &lang)
+	b.answerCallback(ctx, query, "")
+	if err != nil {
+		log.Printf("Failed to apply setting %s for user %d: %v", action, chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	switch {
+	case action == "close":
+		b.editSettingsMenu(ctx, chatID, query.Message.MessageID, i18n.T(lang, "settings.closed"), nil)
+		return
+	case action == settingsViewTicket && value == "new":
+		b.sendTicketPrompt(ctx, chatID, lang)
+		return
+	}
+
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil || user == nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+		return
+	}
+
+	text, keyboard := b.settingsMenu(user, view, group, lang)
+	b.editSettingsMenu(ctx, chatID, query.Message.MessageID, text, &keyboard)
+}
+
+// applySettingsButton stores the setting chosen with a button and returns the view of the
+// menu to show next; a language change also updates lang
+func (b *TelegramBot) applySettingsButton(ctx context.Context, chatID int64, action, value string, lang *i18n.Language) (string, error) {
+	switch action {
+	case settingsViewLanguage:
+		newLang, ok := i18n.Parse(value)
+		if !ok {
+			return settingsViewLanguage, nil
+		}
+		*lang = newLang
+		return settingsViewMain, b.db.SetUserLanguage(ctx, chatID, string(newLang))
+	case "alerts":
+		return settingsViewMain, b.db.SetUserStatusAlerts(ctx, chatID, value == "on")
+	case "weekly":
+		return settingsViewMain, b.db.SetUserWeeklyReport(ctx, chatID, value == "on")
+	case settingsViewThreshold:
+		if value == "off" {
+			return settingsViewMain, b.db.SetUserTicketsAlert(ctx, chatID, -1)
+		}
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 0 {
+			return settingsViewThreshold, nil
+		}
+		return settingsViewMain, b.db.SetUserTicketsAlert(ctx, chatID, threshold)
+	case settingsViewMute:
+		if value == "off" {
+			return settingsViewMain, b.db.SetUserMutedUntil(ctx, chatID, time.Time{})
+		}
+		hours, err := strconv.Atoi(value)
+		if err != nil || hours <= 0 || time.Duration(hours)*time.Hour > MaxMute {
+			return settingsViewMute, nil
+		}
+		return settingsViewMain, b.db.SetUserMutedUntil(ctx, chatID, time.Now().Add(time.Duration(hours)*time.Hour))
+	case settingsViewInterval:
+		if value == "off" {
+			return settingsViewMain, b.db.SetUserUpdateInterval(ctx, chatID, 0)
+		}
+		minutes, err := strconv.Atoi(value)
+		interval := time.Duration(minutes) * time.Minute
+		if err != nil || interval < MinUpdateInterval || interval > MaxUpdateInterval {
+			return settingsViewInterval, nil
+		}
+		return settingsViewMain, b.db.SetUserUpdateInterval(ctx, chatID, interval)
+	case settingsViewTicket:
+		if value == "forget" {
+			return settingsViewMain, b.db.SetUserTicketNumber(ctx, chatID, "")
+		}
+		return settingsViewTicket, nil
+	}
+	return settingsViewMain, nil
+}
+
+// settingsMenu returns the text and buttons of a settings menu view. Groups share one
+// subscription, so their menu has no ticket.
+func (b *TelegramBot) settingsMenu(user *database.User, view string, group bool, lang i18n.Language) (string, tgbotapi.InlineKeyboardMarkup) {
+	back := settingsButton(i18n.T(lang, "button.back"), "main", "")
+
+	switch view {
+	case settingsViewLanguage:
+		var rows [][]tgbotapi.InlineKeyboardButton
+		var row []tgbotapi.InlineKeyboardButton
+		for _, l := range i18n.Supported() {
+			row = append(row, settingsButton(i18n.T(l, "language.name"), settingsViewLanguage, string(l)))
+			if len(row) == 2 {
+				rows, row = append(rows, row), nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(back))
+		return i18n.T(lang, "settings.menu_language"), tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	case settingsViewThreshold:
+		var row []tgbotapi.InlineKeyboardButton
+		for _, threshold := range settingsThresholds {
+			row = append(row, settingsButton(i18n.T(lang, "button.threshold_value", threshold), settingsViewThreshold, strconv.Itoa(threshold)))
+		}
+		return i18n.T(lang, "settings.menu_threshold"), tgbotapi.NewInlineKeyboardMarkup(
+			row[:3], row[3:],
+			tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.off"), settingsViewThreshold, "off"), back),
+		)
+
+	case settingsViewMute:
+		var row []tgbotapi.InlineKeyboardButton
+		for _, hours := range settingsMuteHours {
+			row = append(row, settingsButton(i18n.T(lang, "button.hours", hours), settingsViewMute, strconv.Itoa(hours)))
+		}
+		last := tgbotapi.NewInlineKeyboardRow(back)
+		if user.Muted(time.Now()) {
+			last = tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.resume"), settingsViewMute, "off"), back)
+		}
+		return i18n.T(lang, "settings.menu_mute"), tgbotapi.NewInlineKeyboardMarkup(row, last)
+
+	case settingsViewInterval:
+		var row []tgbotapi.InlineKeyboardButton
+		for _, minutes := range settingsIntervals {
+			row = append(row, settingsButton(i18n.T(lang, "button.minutes", minutes), settingsViewInterval, strconv.Itoa(minutes)))
+		}
+		return i18n.T(lang, "settings.menu_interval"), tgbotapi.NewInlineKeyboardMarkup(
+			row,
+			tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.every_change"), settingsViewInterval, "off"), back),
+		)
+
+	case settingsViewTicket:
+		return i18n.T(lang, "settings.menu_ticket", user.TicketNumber), tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.change_ticket"), settingsViewTicket, "new")),
+			tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.forget_ticket"), settingsViewTicket, "forget")),
+			tgbotapi.NewInlineKeyboardRow(back),
+		)
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.language", i18n.T(lang, "language.name")), settingsViewLanguage, "")),
+	}
+
+	if user.StatusAlerts {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.alerts_on"), "alerts", "off")))
+	} else {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.alerts_off"), "alerts", "on")))
+	}
+
+	// The weekly report only covers the primary office, like /weekly
+	if b.userOffice(user.Office).ID == b.primaryOffice().ID {
+		if user.WeeklyReport {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.weekly_on"), "weekly", "off")))
+		} else {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.weekly_off"), "weekly", "on")))
+		}
+	}
+
+	threshold := i18n.T(lang, "button.threshold_off")
+	if user.TicketsAlert >= 0 {
+		threshold = i18n.T(lang, "button.threshold", user.TicketsAlert)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(threshold, settingsViewThreshold, "")))
+
+	mute := i18n.T(lang, "button.unmuted")
+	if user.Muted(time.Now()) {
+		mute = i18n.T(lang, "button.muted", formatMuteEnd(user.MutedUntil.In(b.userLocation(user))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(mute, settingsViewMute, "")))
+
+	interval := i18n.T(lang, "button.interval_off")
+	if user.UpdateInterval > 0 {
+		interval = i18n.T(lang, "button.interval", int(user.UpdateInterval/time.Minute))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(interval, settingsViewInterval, "")))
+
+	if !group {
+		if user.TicketNumber != "" {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.ticket", user.TicketNumber), settingsViewTicket, "")))
+		} else {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.no_ticket"), settingsViewTicket, "new")))
+		}
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(settingsButton(i18n.T(lang, "button.close"), "close", "")))
+	return i18n.T(lang, "settings.menu"), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// settingsButton creates a settings menu button with its callback data
+func settingsButton(label, action, value string) tgbotapi.InlineKeyboardButton {
+	data := SettingsCallbackPrefix + action
+	if value != "" {
+		data += ":" + value
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(label, data)
+}
+
+// editSettingsMenu replaces the text and buttons of a settings menu; without a keyboard
+// the buttons are removed
+func (b *TelegramBot) editSettingsMenu(ctx context.Context, chatID int64, messageID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	msg := tgbotapi.NewEditMessageText(chatID, messageID, b.render(text))
+	msg.ParseMode = b.parseMode
+	msg.ReplyMarkup = keyboard
+
+	// Pressing the current language again leaves the menu as it is
+	if _, err := b.send(ctx, chatID, msg); err != nil && !isNotModifiedError(err) {
+		log.Printf("Failed to update settings menu for %d: %v", chatID, err)
+	}
+}
+
+// sendTicketPrompt asks for a ticket number with a forced reply; the answer is handled like
+// any ticket number sent to the bot
+func (b *TelegramBot) sendTicketPrompt(ctx context.Context, chatID int64, lang i18n.Language) {
+	msg := tgbotapi.NewMessage(chatID, b.render(i18n.T(lang, "settings.ticket_prompt")))
+	msg.ParseMode = b.parseMode
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, InputFieldPlaceholder: "K222"}
+	if _, err := b.send(ctx, chatID, msg); err != nil {
+		log.Printf("Failed to send ticket prompt to %d: %v", chatID, err)
+	}
+}
+
+// answerCallback stops the loading indicator of a pressed button, showing text if not empty
+func (b *TelegramBot) answerCallback(ctx context.Context, query *tgbotapi.CallbackQuery, text string) {
+	var chatID int64
+	if query.Message != nil {
+		chatID = query.Message.Chat.ID
+	}
+	if err := b.request(ctx, chatID, tgbotapi.NewCallback(query.ID, text)); err != nil {
+		log.Printf("Failed to answer callback query of %d: %v", chatID, err)
+	}
+}
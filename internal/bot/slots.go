@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/reservation"
+)
+
+// MaxListedSlotDays is the number of days with free slots listed per service
+const MaxListedSlotDays = 5
+
+// SetReservations enables /slots and appointment slot alerts for the monitored services
+func (b *TelegramBot) SetReservations(monitor *reservation.Monitor) {
+	b.reservations = monitor
+}
+
+// handleSlotsCommand handles /slots: no arguments list the services with their free days,
+// a service name turns its slot alerts on or off and "off" turns all of them off
+func (b *TelegramBot) handleSlotsCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	if b.reservations == nil {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "slots.disabled"))
+		return
+	}
+
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+	}
+	var subscribed []string
+	if user != nil {
+		subscribed = user.SlotServices
+	}
+
+	args = strings.TrimSpace(args)
+	if args == "" {
+		b.sendMessage(ctx, chatID, b.formatSlots(subscribed, lang))
+		return
+	}
+
+	var services []string
+	var reply string
+	if strings.EqualFold(args, "off") {
+		reply = i18n.T(lang, "slots.all_off")
+	} else {
+		service, ok := b.reservations.FindService(args)
+		if !ok {
+			b.sendMessage(ctx, chatID, i18n.T(lang, "slots.unknown", models.EscapeMarkdown(b.serviceNames())))
+			return
+		}
+
+		if slices.Contains(subscribed, service.Name) {
+			services = slices.DeleteFunc(slices.Clone(subscribed), func(name string) bool { return name == service.Name })
+			reply = i18n.T(lang, "slots.unsubscribed", models.EscapeMarkdown(service.Name))
+		} else {
+			services = append(slices.Clone(subscribed), service.Name)
+			reply = i18n.T(lang, "slots.subscribed", models.EscapeMarkdown(service.Name))
+		}
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserSlotServices(ctx, chatID, services); err != nil {
+		log.Printf("Failed to set slot services for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, reply)
+}
+
+// serviceNames lists the names of the monitored services
+func (b *TelegramBot) serviceNames() string {
+	var names []string
+	for _, service := range b.reservations.Services() {
+		names = append(names, service.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatSlots formats the free days of all services, marking the ones the user gets alerts for
+func (b *TelegramBot) formatSlots(subscribed []string, lang i18n.Language) string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "slots.title") + "\n\n")
+
+	for _, service := range b.reservations.Services() {
+		marker := "🔕"
+		if slices.Contains(subscribed, service.Name) {
+			marker = "🔔"
+		}
+
+		name := models.EscapeMarkdown(service.Name)
+		latest := b.reservations.Latest(service.Name)
+		switch {
+		case latest.CheckedAt.IsZero():
+			builder.WriteString(i18n.T(lang, "slots.service_unknown", marker, name))
+		case len(latest.Days) == 0:
+			builder.WriteString(i18n.T(lang, "slots.service_none", marker, name))
+		default:
+			builder.WriteString(i18n.T(lang, "slots.service_free", marker, name, formatSlotDays(latest.Days, lang)))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("\n" + i18n.T(lang, "slots.usage"))
+	return builder.String()
+}
+
+// formatSlotDays lists the first days with free slots and how many more there are
+func formatSlotDays(days []reservation.Day, lang i18n.Language) string {
+	var listed []string
+	for i, day := range days {
+		if i == MaxListedSlotDays {
+			break
+		}
+		text := day.Date.Format("02.01")
+		if day.Free > 0 {
+			text += fmt.Sprintf(" (%d)", day.Free)
+		}
+		listed = append(listed, models.EscapeMarkdown(text))
+	}
+
+	text := strings.Join(listed, ", ")
+	if len(days) > MaxListedSlotDays {
+		text += " " + i18n.T(lang, "slots.more", len(days)-MaxListedSlotDays)
+	}
+	return text
+}
+
+// BroadcastNewSlots alerts the users following a service about days that got free slots.
+// Slots are gone quickly, so muted users get the alert too, like the alerts about their ticket.
+func (b *TelegramBot) BroadcastNewSlots(ctx context.Context, service reservation.Service, days []reservation.Day) {
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users for slot alerts: %v", err)
+		return
+	}
+
+	var recipients []database.User
+	for _, user := range users {
+		if slices.Contains(user.SlotServices, service.Name) {
+			recipients = append(recipients, user)
+		}
+	}
+
+	sentCount, _ := b.broadcast(ctx, recipients, func(ctx context.Context, user database.User) error {
+		lang := i18n.OrDefault(user.Language)
+		message := i18n.T(lang, "slots.alert", models.EscapeMarkdown(service.Name), formatSlotDays(days, lang))
+		return b.deliverMessage(ctx, user.ChatID, models.MessageSlots, message)
+	})
+
+	log.Printf("Slot alert for %s sent to %d users", service.Name, sentCount)
+}
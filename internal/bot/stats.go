@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/stats"
+)
+
+const StatsServiceWindow = 2 * time.Hour // Window of the average service time
+
+// handleStatsCommand handles the /stats command with today's queue statistics
+func (b *TelegramBot) handleStatsCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	today, err := b.db.GetQueueDataSince(ctx, startOfDay)
+	if err != nil {
+		log.Printf("Failed to get today's history: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	if len(today) == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.today_empty"))
+		return
+	}
+
+	// The same weekday last week up to the current time of day
+	lastWeek, err := b.db.GetQueueDataBetween(ctx, startOfDay.AddDate(0, 0, -7), now.AddDate(0, 0, -7))
+	if err != nil {
+		log.Printf("Failed to get last week's history: %v", err)
+	}
+
+	b.sendMessage(ctx, chatID, b.formatStatsMessage(ctx, today, lastWeek, now, lang))
+}
+
+// formatStatsMessage formats throughput, service time, the time to clear the waiting
+// queue and the comparison with last week, skipping values without enough data
+func (b *TelegramBot) formatStatsMessage(ctx context.Context, today, lastWeek []*models.QueueData, now time.Time, lang i18n.Language) string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "stats.title") + "\n\n")
+
+	latest := today[len(today)-1]
+	served := latest.ServedClients
+	builder.WriteString(i18n.T(lang, "history.served", served) + "\n")
+
+	throughput, hasThroughput := stats.Throughput(today)
+	if hasThroughput {
+		builder.WriteString(i18n.T(lang, "stats.throughput", models.EscapeMarkdown(fmt.Sprintf("%.1f", throughput))) + "\n")
+	}
+
+	if serviceTime, ok := stats.MeanServiceTime(today, now.Add(-StatsServiceWindow)); ok {
+		builder.WriteString(i18n.T(lang, "stats.service_time", formatServiceMinutes(serviceTime, lang)) + "\n")
+	}
+	if percentiles, ok := stats.ServiceTimePercentilesOf(today, 50, 90); ok {
+		builder.WriteString(i18n.T(lang, "stats.service_percentiles",
+			formatServiceMinutes(percentiles[0], lang), formatServiceMinutes(percentiles[1], lang)) + "\n")
+	}
+
+	if clearTime, ok := b.clearTime(ctx, latest, throughput, hasThroughput); ok {
+		builder.WriteString(i18n.T(lang, "stats.clear_time", models.FormatMinutes(lang, int(math.Ceil(clearTime.Minutes())))) + "\n")
+	}
+
+	if len(lastWeek) > 0 {
+		lastWeekServed := lastWeek[len(lastWeek)-1].ServedClients
+		builder.WriteString(i18n.T(lang, "stats.vs_last_week", lastWeekServed, models.EscapeMarkdown(fmt.Sprintf("%+d", served-lastWeekServed))) + "\n")
+	}
+
+	return builder.String()
+}
+
+// clearTime estimates how long serving the currently waiting clients takes, using the
+// predictor's service rate when available and today's throughput otherwise
+func (b *TelegramBot) clearTime(ctx context.Context, latest *models.QueueData, throughput float64, hasThroughput bool) (time.Duration, bool) {
+	waiting := latest.WaitingClients
+	if waiting <= 0 || latest.Status != models.StatusOpen {
+		return 0, false
+	}
+
+	perMinute := throughput / 60
+	if b.predictor != nil {
+		if rate, err := b.predictor.Rate(ctx); err == nil {
+			if latest.Workplaces > 0 {
+				perMinute = rate.Mean * float64(latest.Workplaces)
+				hasThroughput = true
+			}
+		}
+	}
+
+	if !hasThroughput || perMinute <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(waiting) / perMinute * float64(time.Minute)), true
+}
+
+// formatServiceMinutes formats a service time in whole minutes, at least one
+func formatServiceMinutes(serviceTime time.Duration, lang i18n.Language) string {
+	return models.FormatMinutes(lang, int(math.Max(1, math.Round(serviceTime.Minutes()))))
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// handleStopCommand handles the /stop command: the user stops receiving updates and alerts,
+// but keeps their settings for a later /start
+func (b *TelegramBot) handleStopCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if user == nil || !user.Active {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "stop.not_subscribed"))
+		return
+	}
+
+	if err := b.db.DeactivateUser(ctx, chatID); err != nil {
+		log.Printf("Failed to deactivate user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	// The live message stays as the last snapshot but is no longer edited
+	b.forgetMessageID(ctx, chatID)
+	b.sendMessage(ctx, chatID, i18n.T(lang, "stop.done"))
+}
+
+// formatRestoredSettings lists the settings kept from an earlier subscription
+func formatRestoredSettings(user *database.User, lang i18n.Language) string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "start.welcome_back"))
+
+	if user.TicketNumber != "" {
+		builder.WriteString("\n" + i18n.T(lang, "settings.ticket", models.EscapeMarkdown(user.TicketNumber)))
+	}
+	if user.StatusAlerts {
+		builder.WriteString("\n" + i18n.T(lang, "settings.status_alerts"))
+	}
+	if user.TicketsAlert >= 0 {
+		builder.WriteString("\n" + i18n.T(lang, "settings.threshold", user.TicketsAlert))
+	}
+	builder.WriteString("\n" + i18n.T(lang, "settings.language", i18n.T(lang, "language.name")))
+
+	return builder.String()
+}
@@ -2,47 +2,158 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"regexp"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"karta/internal/casestatus"
 	"karta/internal/database"
+	"karta/internal/i18n"
 	"karta/internal/models"
+	"karta/internal/parser"
+	"karta/internal/prediction"
+	"karta/internal/reservation"
+	"karta/internal/schedule"
+	"karta/internal/state"
+	"karta/internal/stats"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+const (
+	// HandlerTimeout bounds the handling of an incoming message or button press
+	HandlerTimeout = 30 * time.Second
+	// RequestTimeout bounds each Telegram API request but the long polling for updates
+	RequestTimeout = 15 * time.Second
+	// PollTimeout is how long a long poll for updates waits on the Telegram side
+	PollTimeout = 60 * time.Second
+)
+
 // TelegramBot represents the Telegram bot instance
 type TelegramBot struct {
-	api      *tgbotapi.BotAPI
-	db       *database.Database
-	userMsgs sync.Map // map[int64]int - stores chat_id -> message_id for updates
+	api       *tgbotapi.BotAPI
+	db        database.Store
+	predictor *prediction.Predictor
+	forecast  *prediction.ExhaustionForecaster
+	stats     *stats.QueueStats
+	queue     *state.Queue // Latest primary office data, read through to db on cold start
+	schedule  atomic.Pointer[schedule.Schedule]
+	template  atomic.Pointer[models.MessageTemplate] // Custom status message layout, nil for the default
+	parseMode string                                 // models.ParseModeMarkdownV2 or models.ParseModeHTML
+	silent    map[models.MessageKind]bool            // Kinds of messages sent without sound
+	userMsgs  sync.Map                               // map[int64]int - stores chat_id -> message_id for updates
+	pushedAt  sync.Map                               // map[int64]time.Time - last live message update, for update intervals
+	held      sync.Map                               // map[int64]heldUpdate - latest update not yet shown to a throttled user
+
+	offices      []parser.Office      // Monitored offices, the primary one first
+	reservations *reservation.Monitor // Appointment slot monitor, nil when disabled
+	caseStatus   *casestatus.Client   // Case status page client, nil when disabled
+	caseInterval time.Duration
+	webAppURL    string // Public URL of the Mini App opened by /app, empty when disabled
+
+	officeData           sync.Map // map[string]*models.QueueData - latest data of the other offices
+	staleOffices         sync.Map // map[string]time.Time - last successful poll of offices with outdated data
+	yesterdayQueue       yesterdayCache
+	monitoringAlertUsers bool // Monitoring alerts also go to the users of the office
+
+	router            *router
+	limiter           *rateLimiter
+	commandLimiter    *commandLimiter
+	broadcastWorkers  int
+	admins            map[int64]bool
+	setInterval       func(time.Duration)
+	currentInterval   func() time.Duration
+	effectiveInterval func() time.Duration
+	dryRun            bool
+
+	weeklyChats    []int64 // Chats getting every weekly report besides subscribed users
+	weeklyLanguage i18n.Language
+
+	panics          atomic.Int64 // Recovered panics since the start
+	panicMu         sync.Mutex
+	lastPanicReport time.Time
+
+	restarts          func() int // Restarts of supervised goroutines since the start, nil if not set
+	restartMu         sync.Mutex
+	lastRestartReport time.Time
+
+	updatesOnce sync.Once
+	updates     tgbotapi.UpdatesChannel // Shared by restarts of Start, as only one poller may run
 }
 
 // NewTelegramBot creates a new Telegram bot instance
-func NewTelegramBot(token string, db *database.Database) (*TelegramBot, error) {
-	api, err := tgbotapi.NewBotAPI(token)
+func NewTelegramBot(ctx context.Context, token string, db database.Store, predictor *prediction.Predictor, forecast *prediction.ExhaustionForecaster) (*TelegramBot, error) {
+	api, err := tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, &http.Client{Timeout: RequestTimeout})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot API: %w", err)
 	}
 
 	log.Printf("Authorized on account %s", api.Self.UserName)
+	return newTelegramBot(ctx, api, db, predictor, forecast), nil
+}
+
+// newTelegramBot creates a bot sending through an authorized Bot API client
+func newTelegramBot(ctx context.Context, api *tgbotapi.BotAPI, db database.Store, predictor *prediction.Predictor, forecast *prediction.ExhaustionForecaster) *TelegramBot {
+	bot := &TelegramBot{
+		api:       api,
+		db:        db,
+		predictor: predictor,
+		forecast:  forecast,
+		stats:     stats.New(db),
+		queue:     state.NewQueue(db),
+		limiter:   newRateLimiter(GlobalMessagesPerSecond, PerChatInterval),
+		parseMode: models.ParseModeMarkdownV2,
+
+		commandLimiter: newCommandLimiter(DefaultCommandsPerMinute, DefaultCommandMute),
+	}
 
-	return &TelegramBot{
-		api: api,
-		db:  db,
-	}, nil
+	bot.router = bot.newCommandRouter()
+
+	// Restore live message IDs so updates keep editing the same messages after a restart
+	if err := bot.loadMessageIDs(ctx); err != nil {
+		log.Printf("Failed to load stored message IDs: %v", err)
+	}
+
+	return bot
+}
+
+// shutdownCtxKey is the context key of the context of Start, which is only cancelled on shutdown
+type shutdownCtxKey struct{}
+
+// shutdownContext returns the context of Start a handler context was derived from, for work
+// outlasting HandlerTimeout that still has to stop on shutdown; ctx itself outside handlers
+func shutdownContext(ctx context.Context) context.Context {
+	if parent, ok := ctx.Value(shutdownCtxKey{}).(context.Context); ok {
+		return parent
+	}
+	return ctx
 }
 
 // Start starts the bot and handles incoming messages
 func (b *TelegramBot) Start(ctx context.Context) error {
+	ctx = context.WithValue(ctx, shutdownCtxKey{}, ctx)
+
+	if b.dryRun {
+		log.Println("Dry run: not receiving Telegram updates")
+		<-ctx.Done()
+		return nil
+	}
+
 	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	u.Timeout = int(PollTimeout / time.Second)
 
-	updates := b.api.GetUpdatesChan(u)
+	// Long polls stay open for PollTimeout, longer than other requests may take
+	b.updatesOnce.Do(func() {
+		poller := *b.api
+		poller.Client = &http.Client{Timeout: PollTimeout + RequestTimeout}
+		b.updates = poller.GetUpdatesChan(u)
+	})
+	updates := b.updates
 
 	log.Println("Telegram bot started, waiting for messages...")
 
@@ -53,100 +164,264 @@ func (b *TelegramBot) Start(ctx context.Context) error {
 			return nil
 		case update := <-updates:
 			if update.Message != nil {
-				go b.handleMessage(update.Message)
+				go func(message *tgbotapi.Message) {
+					defer b.recoverPanic(ctx, fmt.Sprintf("message handler of chat %d", message.Chat.ID))
+					ctx, cancel := context.WithTimeout(ctx, HandlerTimeout)
+					defer cancel()
+					b.handleMessage(ctx, message)
+				}(update.Message)
+			}
+			if update.CallbackQuery != nil {
+				go func(query *tgbotapi.CallbackQuery) {
+					defer b.recoverPanic(ctx, fmt.Sprintf("button handler of user %d", query.From.ID))
+					ctx, cancel := context.WithTimeout(ctx, HandlerTimeout)
+					defer cancel()
+					b.handleCallbackQuery(ctx, query)
+				}(update.CallbackQuery)
 			}
 		}
 	}
 }
 
 // handleMessage processes incoming messages
-func (b *TelegramBot) handleMessage(message *tgbotapi.Message) {
-	chatID := message.Chat.ID
-	username := message.From.UserName
-
-	log.Printf("Received message from %s (ID: %d): %s", username, chatID, message.Text)
-
-	switch message.Command() {
-	case "start":
-		b.handleStartCommand(chatID, username)
-	default:
-		if message.Text != "" {
-			// Check if message matches ticket pattern (K followed by numbers)
-			if b.isTicketNumber(message.Text) {
-				b.handleTicketNumber(chatID, username, message.Text)
-			} else {
-				b.sendMessage(chatID, "Используйте команду /start для получения информации о очереди\\.\n\nЧтобы отслеживать ваш билет, отправьте номер билета \\(например: K222\\)\\.")
-			}
-		}
-	}
+func (b *TelegramBot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
+	b.router.serve(newCommandRequest(ctx, message))
 }
 
-// handleStartCommand handles the /start command
-func (b *TelegramBot) handleStartCommand(chatID int64, username string) {
+// handleStartCommand handles the /start command, applying the settings of a start link
+func (b *TelegramBot) handleStartCommand(ctx context.Context, chatID int64, username string, link startLink, lang i18n.Language) {
+	// Users coming back after /stop or a blocked bot keep their earlier settings
+	previous, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+	}
+
 	// Add user to database
-	if err := b.db.AddUser(chatID, username); err != nil {
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
 		log.Printf("Failed to add user to database: %v", err)
-		b.sendMessage(chatID, "Произошла ошибка при регистрации. Попробуйте позже.")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
 		return
 	}
 
-	// Get latest queue data
-	queueData, err := b.db.GetLatestQueueData()
+	if previous != nil && !previous.Active {
+		log.Printf("User %d reactivated", chatID)
+		b.sendMessage(ctx, chatID, formatRestoredSettings(previous, lang))
+	}
+
+	if err := b.applyStartLink(ctx, chatID, link); err != nil {
+		log.Printf("Failed to apply start link for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+	}
+
+	// Get latest queue data of the user's office
+	office := b.primaryOffice()
+	if link.office != nil {
+		office = *link.office
+	} else if previous != nil {
+		office = b.userOffice(previous.Office)
+	}
+	queueData, err := b.latestQueueData(ctx, office)
 	if err != nil {
 		log.Printf("Failed to get latest queue data: %v", err)
-		b.sendMessage(chatID, "Добро пожаловать! Данные о очереди будут доступны после первого обновления.")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "start.no_data_yet"))
 		return
 	}
 
 	if queueData == nil {
-		b.sendMessage(chatID, "Добро пожаловать! Данные о очереди пока недоступны. Ожидайте первого обновления.")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "start.no_data"))
 		return
 	}
 
 	// Get user's ticket number if they have one
-	userTicket, err := b.db.GetUserTicketNumber(chatID)
+	userTicket, err := b.db.GetUserTicketNumber(ctx, chatID)
 	if err != nil {
 		log.Printf("Failed to get user ticket number: %v", err)
 		userTicket = "" // Continue without ticket info
 	}
 
 	// Send current queue data with user's ticket info if available
-	var message string
-	if userTicket != "" {
-		message = queueData.FormatTelegramMessageWithTicket(nil, userTicket)
-	} else {
-		message = queueData.FormatTelegramMessage(nil)
-	}
-	msgID := b.sendMessage(chatID, message)
+	message := b.formatQueueMessage(ctx, queueData, nil, userTicket, lang, b.userLocation(previous))
+	msgID := b.sendMessage(ctx, chatID, message)
 
 	// Store message ID for future updates
 	if msgID != 0 {
-		b.userMsgs.Store(chatID, msgID)
+		b.storeMessageID(ctx, chatID, msgID)
 	}
 }
 
-// sendMessage sends a message to a chat and returns message ID
-func (b *TelegramBot) sendMessage(chatID int64, text string) int {
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = tgbotapi.ModeMarkdownV2
-	msg.DisableWebPagePreview = true
+// formatQueueMessage formats the live status message for a user, using history-based
+// wait estimates when the predictor has enough data
+func (b *TelegramBot) formatQueueMessage(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges, userTicket string, lang i18n.Language, location *time.Location) string {
+	opts := models.MessageOptions{UserTicket: userTicket, Language: lang, Location: location, Template: b.template.Load()}
+
+	opts.Estimate = b.ticketEstimate(ctx, queueData, userTicket)
+	opts.TicketsForecast = b.ticketsForecast(ctx, queueData)
+	opts.Velocity = b.velocity(ctx, queueData)
+	if queueData.Status == models.StatusOpen && queueData.WaitingClients > 0 {
+		if clearsAt, ok := b.clearEstimate(ctx, queueData); ok {
+			opts.ClearsAt = clearsAt
+		}
+	}
+	opts.StaleSince = b.staleSince(b.dataOffice(queueData))
+	opts.Yesterday = b.yesterday(ctx, queueData, time.Now())
+	opts.OfficeOpensAt = b.officeOpening(time.Now())
+	opts.Schedule = b.schedule.Load()
+
+	return queueData.FormatTelegramMessageWithOptions(changes, opts)
+}
+
+// ticketEstimate returns the history-based wait for the user's ticket, nil when there is
+// no ticket or not enough history. Estimates come from the history, which only covers the
+// primary office.
+func (b *TelegramBot) ticketEstimate(ctx context.Context, queueData *models.QueueData, userTicket string) *models.WaitEstimate {
+	if userTicket == "" || b.predictor == nil || !b.isPrimary(queueData) {
+		return nil
+	}
+
+	estimate, err := b.predictor.EstimateWait(ctx, queueData, userTicket)
+	if err != nil {
+		if !errors.Is(err, prediction.ErrInsufficientHistory) {
+			log.Printf("Failed to estimate wait time for ticket %s: %v", userTicket, err)
+		}
+		return nil
+	}
+	return estimate
+}
+
+// SetQueueState shares the latest primary office data kept by the monitor, instead of the
+// bot's own copy read from the database
+func (b *TelegramBot) SetQueueState(queue *state.Queue) {
+	b.queue = queue
+}
+
+// SetMessageTemplate sets a custom layout of the status message (nil restores the default);
+// safe to call while the bot runs
+func (b *TelegramBot) SetMessageTemplate(template *models.MessageTemplate) {
+	b.template.Store(template)
+}
+
+// SetSilentMessages sets the kinds of messages sent without sound. Edits of the live status
+// message never notify anyway.
+func (b *TelegramBot) SetSilentMessages(kinds []models.MessageKind) {
+	silent := make(map[models.MessageKind]bool, len(kinds))
+	for _, kind := range kinds {
+		silent[kind] = true
+	}
+	b.silent = silent
+}
+
+// SetParseMode selects how messages are sent: models.ParseModeMarkdownV2 as written, or
+// models.ParseModeHTML after converting them with models.MarkdownToHTML
+func (b *TelegramBot) SetParseMode(mode string) {
+	b.parseMode = mode
+}
+
+// render converts a MarkdownV2 message to the parse mode of the bot
+func (b *TelegramBot) render(text string) string {
+	if b.parseMode == models.ParseModeHTML {
+		return models.MarkdownToHTML(text)
+	}
+	return text
+}
+
+// SetSchedule sets the office hours used to tell users when the queue opens; safe to call
+// while the bot runs
+func (b *TelegramBot) SetSchedule(s *schedule.Schedule) {
+	b.schedule.Store(s)
+}
+
+// officeOpening returns the next office opening while the office is closed, or zero time otherwise
+func (b *TelegramBot) officeOpening(now time.Time) time.Time {
+	s := b.schedule.Load()
+	if s.IsOpen(now) {
+		return time.Time{}
+	}
+
+	opening, _ := s.NextOpening(now)
+	return opening
+}
+
+// ticketsForecast returns the usual ticket exhaustion time of the primary office while it
+// is still ahead and tickets are available, or zero time otherwise
+func (b *TelegramBot) ticketsForecast(ctx context.Context, queueData *models.QueueData) time.Time {
+	if b.forecast == nil || queueData.Status != models.StatusOpen || !b.isPrimary(queueData) {
+		return time.Time{}
+	}
+
+	if queueData.TicketsLeft <= 0 {
+		return time.Time{}
+	}
+
+	now := time.Now()
+	forecast, ok, err := b.forecast.Forecast(ctx, now)
+	if err != nil {
+		log.Printf("Failed to forecast tickets exhaustion: %v", err)
+		return time.Time{}
+	}
+
+	if !ok || !now.Before(forecast) {
+		return time.Time{}
+	}
+
+	return forecast
+}
 
-	sentMsg, err := b.api.Send(msg)
+// velocity returns the recent pace of the open primary office queue, nil without enough
+// history
+func (b *TelegramBot) velocity(ctx context.Context, queueData *models.QueueData) *models.QueueVelocity {
+	if b.predictor == nil || queueData.Status != models.StatusOpen || !b.isPrimary(queueData) {
+		return nil
+	}
+
+	velocity, ok, err := b.predictor.Velocity(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to measure queue velocity: %v", err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	return &velocity
+}
+
+// sendMessage sends a message to a chat and returns message ID
+func (b *TelegramBot) sendMessage(ctx context.Context, chatID int64, text string) int {
+	msgID, err := b.trySendMessage(ctx, chatID, text)
 	if err != nil {
 		log.Printf("Failed to send message to %d: %v", chatID, err)
 		return 0
 	}
 
-	return sentMsg.MessageID
+	return msgID
+}
+
+// trySendMessage sends a message to a chat and returns its ID or the Bot API error
+func (b *TelegramBot) trySendMessage(ctx context.Context, chatID int64, text string) (int, error) {
+	return b.sendText(ctx, chatID, text, false)
+}
+
+// sendText sends a message, without sound when silent, and returns its ID or the Bot API error
+func (b *TelegramBot) sendText(ctx context.Context, chatID int64, text string, silent bool) (int, error) {
+	msg := tgbotapi.NewMessage(chatID, b.render(text))
+	msg.ParseMode = b.parseMode
+	msg.DisableWebPagePreview = true
+	msg.DisableNotification = silent
+
+	sentMsg, err := b.send(ctx, chatID, msg)
+	if err != nil {
+		return 0, err
+	}
+
+	return sentMsg.MessageID, nil
 }
 
 // updateMessage updates an existing message
-func (b *TelegramBot) updateMessage(chatID int64, messageID int, text string) error {
-	msg := tgbotapi.NewEditMessageText(chatID, messageID, text)
-	msg.ParseMode = tgbotapi.ModeMarkdownV2
+func (b *TelegramBot) updateMessage(ctx context.Context, chatID int64, messageID int, text string) error {
+	msg := tgbotapi.NewEditMessageText(chatID, messageID, b.render(text))
+	msg.ParseMode = b.parseMode
 	msg.DisableWebPagePreview = true
 
-	_, err := b.api.Send(msg)
+	_, err := b.send(ctx, chatID, msg)
 	if err != nil {
 		log.Printf("Failed to update message for %d: %v", chatID, err)
 		return err
@@ -156,10 +431,11 @@ func (b *TelegramBot) updateMessage(chatID int64, messageID int, text string) er
 }
 
 // deleteMessage deletes a message
-func (b *TelegramBot) deleteMessage(chatID int64, messageID int) error {
+func (b *TelegramBot) deleteMessage(ctx context.Context, chatID int64, messageID int) error {
 	msg := tgbotapi.NewDeleteMessage(chatID, messageID)
 
-	_, err := b.api.Send(msg)
+	// The Bot API answers deletions with true instead of a message
+	err := b.request(ctx, chatID, msg)
 	if err != nil {
 		log.Printf("Failed to delete message %d for chat %d: %v", messageID, chatID, err)
 		return err
@@ -168,80 +444,156 @@ func (b *TelegramBot) deleteMessage(chatID int64, messageID int) error {
 	return nil
 }
 
-// BroadcastQueueUpdate sends queue updates to all active users
-func (b *TelegramBot) BroadcastQueueUpdate(queueData *models.QueueData, changes *models.QueueChanges) error {
-	users, err := b.db.GetActiveUsers()
+// BroadcastQueueUpdate sends queue updates to all active users following the office of the data
+func (b *TelegramBot) BroadcastQueueUpdate(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	b.rememberOfficeData(queueData)
+
+	users, err := b.db.GetActiveUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get active users: %w", err)
 	}
+	users = unmutedUsers(b.officeUsers(users, queueData), time.Now())
 
+	// The same update interrupted by a shutdown only goes to the users it did not reach
+	users, resumed := b.resumeBroadcast(ctx, "", queueData, users)
 	if len(users) == 0 {
 		log.Println("No active users to broadcast to")
+		b.finishBroadcast(ctx, "", queueData, resumed)
+		return nil
+	}
+
+	// Users with an update interval get the latest state once their interval has passed
+	users = b.holdThrottled(users, queueData, changes, time.Now())
+	if len(users) == 0 {
+		log.Println("All users to broadcast to are throttled")
+		b.finishBroadcast(ctx, "", queueData, resumed)
 		return nil
 	}
 
 	log.Printf("Broadcasting queue update to %d users", len(users))
 
-	var successCount, errorCount int
+	successCount, errorCount, pending := b.broadcastUntil(ctx, users, func(ctx context.Context, user database.User) error {
+		return b.pushLiveMessage(ctx, user, queueData, changes)
+	})
+	if len(pending) > 0 {
+		log.Printf("Broadcast interrupted: %d successful, %d errors, %d users left", successCount, errorCount, len(pending))
+		b.saveBroadcast(ctx, "", queueData, pending)
+		return fmt.Errorf("broadcast interrupted with %d users left: %w", len(pending), ctx.Err())
+	}
 
-	for _, user := range users {
-		// Create personalized message with user's ticket if they have one
-		var message string
-		if user.TicketNumber != "" {
-			message = queueData.FormatTelegramMessageWithTicket(changes, user.TicketNumber)
-		} else {
-			message = queueData.FormatTelegramMessage(changes)
-		}
+	log.Printf("Broadcast completed: %d successful, %d errors", successCount, errorCount)
+	b.finishBroadcast(ctx, "", queueData, resumed)
+	return nil
+}
 
-		// Try to update existing message first
-		if msgIDInterface, exists := b.userMsgs.Load(user.ChatID); exists {
-			if msgID, ok := msgIDInterface.(int); ok {
-				if err := b.updateMessage(user.ChatID, msgID, message); err == nil {
-					successCount++
-					continue
-				}
-				// If update fails, remove stored message ID and send new message
-				b.userMsgs.Delete(user.ChatID)
-			}
-		}
+// pushLiveMessage edits the live status message of a user, or sends a new one when there
+// is none or it cannot be edited
+func (b *TelegramBot) pushLiveMessage(ctx context.Context, user database.User, queueData *models.QueueData, changes *models.QueueChanges) error {
+	// Create personalized message with user's ticket if they have one
+	message := b.formatQueueMessage(ctx, queueData, changes, user.TicketNumber, i18n.OrDefault(user.Language), b.userLocation(&user))
 
-		// Send new message
-		msgID := b.sendMessage(user.ChatID, message)
-		if msgID != 0 {
-			b.userMsgs.Store(user.ChatID, msgID)
-			successCount++
-		} else {
-			errorCount++
-			// Deactivate user if message sending fails (user might have blocked the bot)
-			if err := b.db.DeactivateUser(user.ChatID); err != nil {
-				log.Printf("Failed to deactivate user %d: %v", user.ChatID, err)
+	// Try to update existing message first
+	var replaced int
+	if msgIDInterface, exists := b.userMsgs.Load(user.ChatID); exists {
+		if msgID, ok := msgIDInterface.(int); ok {
+			// An unchanged message was already edited, e.g. by a resumed broadcast
+			if err := b.updateMessage(ctx, user.ChatID, msgID, message); err == nil || isNotModifiedError(err) {
+				b.markPushed(user.ChatID, time.Now())
+				return nil
+			} else if isBlockedError(err) {
+				return err
 			}
+			// If update fails, remove stored message ID and send new message
+			b.forgetMessageID(ctx, user.ChatID)
+			replaced = msgID
 		}
+	}
 
-		// Small delay to avoid hitting rate limits
-		time.Sleep(50 * time.Millisecond)
+	// Send new message
+	msgID, err := b.sendText(ctx, user.ChatID, message, b.silent[models.MessageLive])
+	if replaced != 0 {
+		// The old message is deleted only now, so the chat is never without one for long
+		b.retireLiveMessage(ctx, user.ChatID, replaced)
+	}
+	if err != nil {
+		log.Printf("Failed to send message to %d: %v", user.ChatID, err)
+		return err
 	}
 
-	log.Printf("Broadcast completed: %d successful, %d errors", successCount, errorCount)
+	b.storeMessageID(ctx, user.ChatID, msgID)
+	b.markPushed(user.ChatID, time.Now())
 	return nil
 }
 
 // GetStats returns bot statistics
-func (b *TelegramBot) GetStats() (map[string]interface{}, error) {
-	userCount, err := b.db.GetUserCount()
+func (b *TelegramBot) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	userCount, err := b.db.GetUserCount(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user count: %w", err)
 	}
 
 	stats := map[string]interface{}{
-		"active_users":    userCount,
-		"stored_messages": b.getStoredMessageCount(),
-		"bot_username":    b.api.Self.UserName,
+		"active_users":     userCount,
+		"stored_messages":  b.getStoredMessageCount(),
+		"bot_username":     b.api.Self.UserName,
+		"recovered_panics": b.panics.Load(),
+	}
+	if b.restarts != nil {
+		stats["goroutine_restarts"] = b.restarts()
 	}
 
 	return stats, nil
 }
 
+// Ping checks that the Telegram Bot API is reachable with the configured token
+func (b *TelegramBot) Ping() error {
+	if _, err := b.api.GetMe(); err != nil {
+		return fmt.Errorf("failed to reach Telegram API: %w", err)
+	}
+	return nil
+}
+
+// loadMessageIDs loads persisted live message IDs into memory
+func (b *TelegramBot) loadMessageIDs(ctx context.Context) error {
+	messageIDs, err := b.db.GetUserMessageIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for chatID, msgID := range messageIDs {
+		b.userMsgs.Store(chatID, msgID)
+	}
+
+	log.Printf("Loaded %d stored message IDs", len(messageIDs))
+	return nil
+}
+
+// storeMessageID remembers the live status message of a chat in memory and in the database
+// and pins it when the user enabled pinning
+func (b *TelegramBot) storeMessageID(ctx context.Context, chatID int64, msgID int) {
+	b.userMsgs.Store(chatID, msgID)
+	if err := b.db.SetUserMessageID(ctx, chatID, msgID); err != nil {
+		log.Printf("Failed to persist message ID for %d: %v", chatID, err)
+	}
+
+	if b.pinEnabled(ctx, chatID) {
+		b.pinMessage(ctx, chatID, msgID)
+	}
+}
+
+// forgetMessageID drops the live status message of a chat from memory and the database
+// and unpins it when the user enabled pinning
+func (b *TelegramBot) forgetMessageID(ctx context.Context, chatID int64) {
+	msgIDInterface, exists := b.userMsgs.LoadAndDelete(chatID)
+	if err := b.db.SetUserMessageID(ctx, chatID, 0); err != nil {
+		log.Printf("Failed to clear message ID for %d: %v", chatID, err)
+	}
+
+	if msgID, ok := msgIDInterface.(int); exists && ok && b.pinEnabled(ctx, chatID) {
+		b.unpinMessage(ctx, chatID, msgID)
+	}
+}
+
 // getStoredMessageCount returns the number of stored message IDs
 func (b *TelegramBot) getStoredMessageCount() int {
 	count := 0
@@ -254,61 +606,67 @@ func (b *TelegramBot) getStoredMessageCount() int {
 
 // isTicketNumber checks if the message matches ticket pattern (K followed by numbers)
 func (b *TelegramBot) isTicketNumber(text string) bool {
-	// Pattern: K followed by one or more digits
-	pattern := `^[Kk]\d+$`
-	matched, _ := regexp.MatchString(pattern, strings.TrimSpace(text))
-	return matched
+	return models.IsTicketNumber(text)
 }
 
 // handleTicketNumber processes ticket number input from user
-func (b *TelegramBot) handleTicketNumber(chatID int64, username, ticketNumber string) {
+func (b *TelegramBot) handleTicketNumber(ctx context.Context, chatID int64, username, ticketNumber string, lang i18n.Language) {
 	// Normalize ticket number (uppercase K)
 	normalizedTicket := strings.ToUpper(strings.TrimSpace(ticketNumber))
 
 	// Add user to database if not exists
-	if err := b.db.AddUser(chatID, username); err != nil {
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
 		log.Printf("Failed to add user to database: %v", err)
-		b.sendMessage(chatID, "Произошла ошибка при регистрации\\. Попробуйте позже\\.")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
 		return
 	}
 
 	// Save ticket number for user
-	if err := b.db.SetUserTicketNumber(chatID, normalizedTicket); err != nil {
+	if err := b.db.SetUserTicketNumber(ctx, chatID, normalizedTicket); err != nil {
 		log.Printf("Failed to set ticket number for user %d: %v", chatID, err)
-		b.sendMessage(chatID, "Произошла ошибка при сохранении номера билета\\. Попробуйте позже\\.")
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.ticket_save"))
 		return
 	}
 
 	log.Printf("User %s (ID: %d) registered ticket: %s", username, chatID, normalizedTicket)
 
-	// Get latest queue data and show with user's wait time
-	queueData, err := b.db.GetLatestQueueData()
+	// Get latest queue data of the user's office and show with user's wait time
+	office := b.primaryOffice()
+	user, err := b.db.GetUser(ctx, chatID)
 	if err != nil {
-		log.Printf("Failed to get latest queue data: %v", err)
-		b.sendMessage(chatID, fmt.Sprintf("Билет %s сохранен\\! Данные о очереди будут доступны после первого обновления\\.", normalizedTicket))
+		log.Printf("Failed to get user %d: %v", chatID, err)
+	} else if user != nil {
+		office = b.userOffice(user.Office)
+	}
+
+	queueData, err := b.latestQueueData(ctx, office)
+	if err != nil || queueData == nil {
+		if err != nil {
+			log.Printf("Failed to get latest queue data: %v", err)
+		}
+		b.sendMessage(ctx, chatID, i18n.T(lang, "ticket.saved_no_data", normalizedTicket))
 		return
 	}
 
+	// Format message with user's ticket info
+	b.replaceLiveMessage(ctx, chatID, b.formatQueueMessage(ctx, queueData, nil, normalizedTicket, lang, b.userLocation(user)))
+}
+
+// replaceLiveMessage deletes the live status message of a chat and sends a new one in its place
+func (b *TelegramBot) replaceLiveMessage(ctx context.Context, chatID int64, message string) {
 	// Delete old message if exists
 	if msgIDInterface, exists := b.userMsgs.Load(chatID); exists {
 		if msgID, ok := msgIDInterface.(int); ok {
 			log.Printf("Deleting old message %d for user %d", msgID, chatID)
-			if err := b.deleteMessage(chatID, msgID); err != nil {
-				log.Printf("Failed to delete old message: %v", err)
-			} else {
-				log.Printf("Successfully deleted old message %d", msgID)
-			}
-			b.userMsgs.Delete(chatID)
+			b.forgetMessageID(ctx, chatID)
+			b.retireLiveMessage(ctx, chatID, msgID)
 		}
 	}
 
-	// Format message with user's ticket info
-	message := queueData.FormatTelegramMessageWithTicket(nil, normalizedTicket)
-
 	// Send new message and store its ID for future updates
-	msgID := b.sendMessage(chatID, message)
+	msgID := b.sendMessage(ctx, chatID, message)
 	if msgID != 0 {
-		b.userMsgs.Store(chatID, msgID)
+		b.storeMessageID(ctx, chatID, msgID)
 	}
 }
 
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const (
+	MinUpdateInterval     = time.Minute      // Shortest accepted /interval
+	MaxUpdateInterval     = time.Hour        // Longest accepted /interval
+	ThrottleCheckInterval = 15 * time.Second // How often held updates are checked for being due
+)
+
+// heldUpdate is the latest queue state not yet shown to a throttled user
+type heldUpdate struct {
+	queueData *models.QueueData
+	changes   *models.QueueChanges
+}
+
+// handleIntervalCommand handles /interval 5m: the live status message is updated at most
+// that often, "off" goes back to every update and no arguments show the usage
+func (b *TelegramBot) handleIntervalCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	args = strings.ToLower(strings.TrimSpace(args))
+
+	var interval time.Duration
+	if args != "off" {
+		value, err := time.ParseDuration(args)
+		if err != nil || value < MinUpdateInterval || value > MaxUpdateInterval {
+			b.sendMessage(ctx, chatID, i18n.T(lang, "interval.usage"))
+			return
+		}
+		interval = value.Round(time.Minute)
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserUpdateInterval(ctx, chatID, interval); err != nil {
+		log.Printf("Failed to set update interval for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if interval == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "interval.disabled"))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "interval.enabled", int(interval/time.Minute)))
+	}
+}
+
+// holdThrottled keeps the update for the users whose interval has not passed since their
+// last update and returns the users to update now
+func (b *TelegramBot) holdThrottled(users []database.User, queueData *models.QueueData, changes *models.QueueChanges, now time.Time) []database.User {
+	var due []database.User
+	for _, user := range users {
+		if b.updateDue(user, now) {
+			due = append(due, user)
+			continue
+		}
+		b.held.Store(user.ChatID, heldUpdate{queueData: queueData, changes: changes})
+	}
+	return due
+}
+
+// updateDue reports whether the user's live message may be updated at the given time
+func (b *TelegramBot) updateDue(user database.User, now time.Time) bool {
+	if user.UpdateInterval <= 0 {
+		return true
+	}
+	pushedAt, ok := b.pushedAt.Load(user.ChatID)
+	return !ok || now.Sub(pushedAt.(time.Time)) >= user.UpdateInterval
+}
+
+// markPushed records an update of the user's live message, which also shows any held one
+func (b *TelegramBot) markPushed(chatID int64, now time.Time) {
+	b.pushedAt.Store(chatID, now)
+	b.held.Delete(chatID)
+}
+
+// StartThrottledUpdates sends the held updates once the users' intervals have passed, until
+// the context is cancelled
+func (b *TelegramBot) StartThrottledUpdates(ctx context.Context) {
+	ticker := time.NewTicker(ThrottleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.sendHeldUpdates(ctx, time.Now())
+		}
+	}
+}
+
+// sendHeldUpdates updates the live messages of the users whose held update is due
+func (b *TelegramBot) sendHeldUpdates(ctx context.Context, now time.Time) {
+	pending := false
+	b.held.Range(func(key, value interface{}) bool {
+		pending = true
+		return false
+	})
+	if !pending {
+		return
+	}
+
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		log.Printf("Failed to get active users for held updates: %v", err)
+		return
+	}
+
+	// Users who unsubscribed in the meantime are not updated anymore
+	active := make(map[int64]bool, len(users))
+	for _, user := range users {
+		active[user.ChatID] = true
+	}
+	b.held.Range(func(key, value interface{}) bool {
+		if !active[key.(int64)] {
+			b.held.Delete(key)
+		}
+		return true
+	})
+
+	var due []database.User
+	updates := make(map[int64]heldUpdate)
+	for _, user := range unmutedUsers(users, now) {
+		value, ok := b.held.Load(user.ChatID)
+		if !ok || !b.updateDue(user, now) {
+			continue
+		}
+		held := value.(heldUpdate)
+		// A held update of an office the user no longer follows is dropped
+		if b.dataOffice(held.queueData).ID != b.userOffice(user.Office).ID {
+			b.held.Delete(user.ChatID)
+			continue
+		}
+		due = append(due, user)
+		updates[user.ChatID] = held
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	successCount, errorCount := b.broadcast(ctx, due, func(ctx context.Context, user database.User) error {
+		held := updates[user.ChatID]
+		return b.pushLiveMessage(ctx, user, held.queueData, held.changes)
+	})
+	log.Printf("Held updates sent: %d successful, %d errors", successCount, errorCount)
+}
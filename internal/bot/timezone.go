@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+// handleTimezoneCommand handles /timezone: an IANA name like "Europe/Kyiv" sets the time
+// zone of the times shown to the user, "reset" restores the office time zone and no
+// arguments show the current one
+func (b *TelegramBot) handleTimezoneCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	name := strings.TrimSpace(args)
+	if name == "" {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "timezone.usage", models.EscapeMarkdown(b.chatLocation(ctx, chatID).String())))
+		return
+	}
+
+	if strings.EqualFold(name, "reset") {
+		name = ""
+	} else if _, err := time.LoadLocation(name); err != nil || strings.EqualFold(name, "local") {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "timezone.unknown", models.EscapeMarkdown(name)))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserTimezone(ctx, chatID, name); err != nil {
+		log.Printf("Failed to set timezone for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if name == "" {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "timezone.reset", models.EscapeMarkdown(time.Local.String())))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "timezone.changed", models.EscapeMarkdown(name)))
+	}
+}
+
+// userLocation returns the time zone of the times shown to a user, the office time zone
+// when the user has not chosen one
+func (b *TelegramBot) userLocation(user *database.User) *time.Location {
+	if user == nil || user.Timezone == "" {
+		return time.Local
+	}
+
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		log.Printf("Ignoring invalid timezone %q of user %d: %v", user.Timezone, user.ChatID, err)
+		return time.Local
+	}
+	return location
+}
+
+// chatLocation looks up the user of a chat and returns their time zone
+func (b *TelegramBot) chatLocation(ctx context.Context, chatID int64) *time.Location {
+	user, err := b.db.GetUser(ctx, chatID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", chatID, err)
+	}
+	return b.userLocation(user)
+}
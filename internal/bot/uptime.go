@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const (
+	UptimeShortPeriod = 24 * time.Hour     // First availability figure of /uptime
+	UptimeLongPeriod  = 7 * 24 * time.Hour // Second availability figure, also the range of the listed outages
+	UptimeMaxOutages  = 5                  // Most recent outages listed by /uptime
+)
+
+// handleUptimeCommand handles /uptime: the availability of the DUW API over the last 24
+// hours and 7 days, and its most recent outages
+func (b *TelegramBot) handleUptimeCommand(ctx context.Context, chatID int64, lang i18n.Language) {
+	now := time.Now()
+	outages, err := b.db.GetOutages(ctx, now.Add(-UptimeLongPeriod), now)
+	if err != nil {
+		log.Printf("Failed to get outages: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "uptime.error"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, formatUptimeMessage(outages, now, b.chatLocation(ctx, chatID), lang))
+}
+
+// formatUptimeMessage formats the availability over both periods and the latest outages
+func formatUptimeMessage(outages []database.Outage, now time.Time, location *time.Location, lang i18n.Language) string {
+	var builder strings.Builder
+	builder.WriteString(i18n.T(lang, "uptime.title") + "\n\n")
+
+	for _, period := range []struct {
+		key    string
+		length time.Duration
+	}{{"uptime.day", UptimeShortPeriod}, {"uptime.week", UptimeLongPeriod}} {
+		from := now.Add(-period.length)
+		count := 0
+		for _, outage := range outages {
+			if outage.Ongoing() || outage.End.After(from) {
+				count++
+			}
+		}
+		downtime := database.Downtime(outages, from, now)
+		availability := 100 * (1 - float64(downtime)/float64(period.length))
+		builder.WriteString(i18n.T(lang, "uptime.period", i18n.T(lang, period.key),
+			models.EscapeMarkdown(fmt.Sprintf("%.2f", availability)), count,
+			models.FormatMinutes(lang, int(downtime.Minutes()))) + "\n")
+	}
+
+	if len(outages) == 0 {
+		builder.WriteString("\n" + i18n.T(lang, "uptime.none"))
+		return builder.String()
+	}
+
+	builder.WriteString("\n" + i18n.T(lang, "uptime.recent") + "\n")
+	recent := outages[max(len(outages)-UptimeMaxOutages, 0):]
+	for i := len(recent) - 1; i >= 0; i-- {
+		outage := recent[i]
+		end := i18n.T(lang, "uptime.ongoing")
+		if !outage.Ongoing() {
+			end = outage.End.In(location).Format("15:04")
+		}
+		builder.WriteString(i18n.T(lang, "uptime.outage",
+			models.EscapeMarkdown(outage.Start.In(location).Format("02.01 15:04")), models.EscapeMarkdown(end),
+			models.FormatMinutes(lang, int(outage.Duration(now).Minutes()))) + "\n")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
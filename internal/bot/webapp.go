@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"karta/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webAppKeyboard is an inline keyboard with Mini App buttons, which tgbotapi does not support
+type webAppKeyboard struct {
+	InlineKeyboard [][]webAppButton `json:"inline_keyboard"`
+}
+
+// webAppButton is an inline keyboard button opening a Mini App
+type webAppButton struct {
+	Text   string     `json:"text"`
+	WebApp webAppInfo `json:"web_app"`
+}
+
+// webAppInfo is the HTTPS URL of a Mini App
+type webAppInfo struct {
+	URL string `json:"url"`
+}
+
+// SetWebAppURL sets the public HTTPS URL of the Mini App served by the HTTP server at /app/
+func (b *TelegramBot) SetWebAppURL(url string) {
+	b.webAppURL = url
+}
+
+// handleAppCommand handles /app, sending a button that opens the Mini App. The app
+// authenticates users against the user table, so the user is registered first.
+func (b *TelegramBot) handleAppCommand(ctx context.Context, chatID int64, username string, lang i18n.Language) {
+	if b.webAppURL == "" {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "app.unavailable"))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, b.render(i18n.T(lang, "app.open")))
+	msg.ParseMode = b.parseMode
+	msg.ReplyMarkup = webAppKeyboard{InlineKeyboard: [][]webAppButton{{
+		{Text: i18n.T(lang, "app.button"), WebApp: webAppInfo{URL: b.webAppURL}},
+	}}}
+	if _, err := b.send(ctx, chatID, msg); err != nil {
+		log.Printf("Failed to send Mini App button to %d: %v", chatID, err)
+	}
+}
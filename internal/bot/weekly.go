@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/report"
+)
+
+// WeeklyReportHour is the local hour on Monday the report of the past week is sent
+const WeeklyReportHour = 9
+
+// SetWeeklyReportChats sets chats, e.g. a Telegram channel, that get every weekly report
+// in the given language besides the subscribed users
+func (b *TelegramBot) SetWeeklyReportChats(chatIDs []int64, lang i18n.Language) {
+	b.weeklyChats = chatIDs
+	b.weeklyLanguage = lang
+}
+
+// handleWeeklyCommand handles /weekly: "on" and "off" subscribe to the report sent every
+// Monday, no arguments send the report of the past week right away
+func (b *TelegramBot) handleWeeklyCommand(ctx context.Context, chatID int64, username, args string, lang i18n.Language) {
+	var enabled bool
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		b.sendWeeklyReport(ctx, chatID, lang)
+		return
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		b.sendMessage(ctx, chatID, i18n.T(lang, "weekly.usage"))
+		return
+	}
+
+	if err := b.db.AddUser(ctx, chatID, username); err != nil {
+		log.Printf("Failed to add user to database: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.registration"))
+		return
+	}
+
+	if err := b.db.SetUserWeeklyReport(ctx, chatID, enabled); err != nil {
+		log.Printf("Failed to set weekly report for user %d: %v", chatID, err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if enabled {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "weekly.enabled"))
+	} else {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "weekly.disabled"))
+	}
+}
+
+// sendWeeklyReport sends the report of the past week to one chat
+func (b *TelegramBot) sendWeeklyReport(ctx context.Context, chatID int64, lang i18n.Language) {
+	weekly, err := report.BuildWeekly(ctx, b.db, time.Now())
+	if err != nil {
+		log.Printf("Failed to build weekly report: %v", err)
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.error"))
+		return
+	}
+
+	if weekly.Week.Days == 0 {
+		b.sendMessage(ctx, chatID, i18n.T(lang, "history.period_empty"))
+		return
+	}
+
+	b.sendMessage(ctx, chatID, formatWeeklyReport(weekly, lang))
+}
+
+// StartWeeklyReports sends the report of the past week every Monday at WeeklyReportHour
+// until the context is cancelled
+func (b *TelegramBot) StartWeeklyReports(ctx context.Context) {
+	for {
+		next := nextWeeklyReport(time.Now())
+		log.Printf("Next weekly report at %s", next.Format(time.RFC3339))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+			if err := b.BroadcastWeeklyReport(ctx, time.Now()); err != nil {
+				log.Printf("Failed to send weekly report: %v", err)
+			}
+		}
+	}
+}
+
+// nextWeeklyReport returns the first report time after now
+func nextWeeklyReport(now time.Time) time.Time {
+	next := report.WeekStart(now).Add(WeeklyReportHour * time.Hour)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// BroadcastWeeklyReport sends the report of the last full week before now to the users of
+// the primary office who subscribed and to the weekly report chats
+func (b *TelegramBot) BroadcastWeeklyReport(ctx context.Context, now time.Time) error {
+	weekly, err := report.BuildWeekly(ctx, b.db, now)
+	if err != nil {
+		return err
+	}
+	if weekly.Week.Days == 0 {
+		log.Println("No history for the weekly report")
+		return nil
+	}
+
+	users, err := b.db.GetActiveUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	primary := b.primaryOffice()
+	var recipients []database.User
+	for _, user := range users {
+		if user.WeeklyReport && b.userOffice(user.Office).ID == primary.ID {
+			recipients = append(recipients, user)
+		}
+	}
+
+	sentCount, _ := b.broadcast(ctx, recipients, func(ctx context.Context, user database.User) error {
+		return b.deliverMessage(ctx, user.ChatID, models.MessageWeeklyReport, formatWeeklyReport(weekly, i18n.OrDefault(user.Language)))
+	})
+
+	for _, chatID := range b.weeklyChats {
+		if err := b.deliverMessage(ctx, chatID, models.MessageWeeklyReport, formatWeeklyReport(weekly, b.weeklyLanguage)); err == nil {
+			sentCount++
+		}
+	}
+
+	log.Printf("Weekly report sent to %d chats", sentCount)
+	return nil
+}
+
+// formatWeeklyReport formats a weekly report with the comparison to the week before,
+// leaving out comparisons without data for the previous week
+func formatWeeklyReport(weekly *report.Weekly, lang i18n.Language) string {
+	week, previous := weekly.Week, weekly.Previous
+
+	var builder strings.Builder
+	period := fmt.Sprintf("%s – %s", week.Start.Format("02.01"), week.End().AddDate(0, 0, -1).Format("02.01"))
+	builder.WriteString(i18n.T(lang, "weekly.title", models.EscapeMarkdown(period)) + "\n\n")
+
+	builder.WriteString(i18n.T(lang, "weekly.served", week.Served, week.Days))
+	if previous.Served > 0 {
+		builder.WriteString(" " + i18n.T(lang, "weekly.vs_previous", percentChange(float64(week.Served), float64(previous.Served))))
+	}
+	builder.WriteString("\n")
+
+	if week.Busiest.MaxServed > 0 {
+		builder.WriteString(i18n.T(lang, "weekly.busiest", models.EscapeMarkdown(week.Busiest.Period.Format("02.01")), week.Busiest.MaxServed) + "\n")
+	}
+
+	if week.ExhaustedDays > 0 {
+		builder.WriteString(i18n.T(lang, "weekly.exhaustion", week.ExhaustedDays, week.Days, models.EscapeMarkdown(formatTimeOfDay(week.Exhaustion))))
+		if previous.ExhaustedDays > 0 {
+			builder.WriteString(" " + i18n.T(lang, "weekly.previous_exhaustion", models.EscapeMarkdown(formatTimeOfDay(previous.Exhaustion))))
+		}
+		builder.WriteString("\n")
+	} else {
+		builder.WriteString(i18n.T(lang, "weekly.not_exhausted") + "\n")
+	}
+
+	if week.ServiceTime > 0 {
+		minutes := int(math.Max(1, math.Round(week.ServiceTime.Minutes())))
+		builder.WriteString(i18n.T(lang, "weekly.service_time", models.FormatMinutes(lang, minutes)))
+		if previous.ServiceTime > 0 {
+			builder.WriteString(" " + i18n.T(lang, "weekly.vs_previous", percentChange(float64(week.ServiceTime), float64(previous.ServiceTime))))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// percentChange formats the change from previous to current as an escaped signed percentage
+func percentChange(current, previous float64) string {
+	change := int(math.Round((current - previous) / previous * 100))
+	return models.EscapeMarkdown(fmt.Sprintf("%+d%%", change))
+}
+
+// formatTimeOfDay formats an offset from midnight as HH:MM
+func formatTimeOfDay(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"karta/internal/models"
+)
+
+const (
+	ComparisonOffset = 24 * time.Hour   // The live message compares with the queue that long ago
+	ComparisonWindow = 15 * time.Minute // Longest gap before the compared time still showing a history entry
+)
+
+// yesterdayCache keeps the compared history entry for a minute, as the live messages of all
+// users are formatted with it on every poll
+type yesterdayCache struct {
+	mu        sync.Mutex
+	minute    time.Time // Compared minute of the cached entry
+	queueData *models.QueueData
+}
+
+// yesterday returns the open primary office queue at the same time yesterday, or nil when
+// there is no history for it or the office was closed
+func (b *TelegramBot) yesterday(ctx context.Context, queueData *models.QueueData, now time.Time) *models.QueueData {
+	if !b.isPrimary(queueData) {
+		return nil
+	}
+
+	b.yesterdayQueue.mu.Lock()
+	defer b.yesterdayQueue.mu.Unlock()
+
+	minute := now.Add(-ComparisonOffset).Truncate(time.Minute)
+	if b.yesterdayQueue.minute.Equal(minute) {
+		return b.yesterdayQueue.queueData
+	}
+
+	previous, err := b.db.GetQueueDataAt(ctx, minute, ComparisonWindow)
+	if err != nil {
+		log.Printf("Failed to get yesterday's queue data: %v", err)
+		return nil
+	}
+	if previous != nil && previous.Status != models.StatusOpen {
+		previous = nil
+	}
+	b.yesterdayQueue.minute, b.yesterdayQueue.queueData = minute, previous
+	return previous
+}
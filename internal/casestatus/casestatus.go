@@ -0,0 +1,147 @@
+package casestatus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"karta/internal/parser"
+
+	"golang.org/x/net/html"
+)
+
+// caseNumberPattern matches DUW case numbers such as SO-II.6151.12345.2024
+var caseNumberPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9./-]{2,39}$`)
+
+// readyPhrases mark statuses saying the card can be picked up ("karta gotowa do odbioru"), lowercased
+var readyPhrases = []string{"do odbioru", "ready for pickup", "ready for collection"}
+
+// ValidCaseNumber reports whether the text looks like a case number
+func ValidCaseNumber(text string) bool {
+	return caseNumberPattern.MatchString(text)
+}
+
+// Ready reports whether the status says the residence card is ready for pickup
+func Ready(status string) bool {
+	status = strings.ToLower(status)
+	for _, phrase := range readyPhrases {
+		if strings.Contains(status, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// Client reads case statuses from the public DUW case status page
+type Client struct {
+	client  *http.Client
+	pageURL string
+}
+
+// NewClient creates a client for the status page URL, in which {case} is replaced by the
+// case number. Requests go through the same proxies and TLS settings as the queue requests.
+func NewClient(pageURL string) *Client {
+	return &Client{client: parser.NewHTTPClient(), pageURL: pageURL}
+}
+
+// Status requests the status page of a case once and returns the status text
+func (c *Client) Status(ctx context.Context, caseNumber string) (string, error) {
+	pageURL := strings.ReplaceAll(c.pageURL, "{case}", url.QueryEscape(caseNumber))
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", parser.UserAgent)
+	req.Header.Set("Accept", "text/html,application/json;q=0.9,*/*;q=0.8")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch case status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, parser.MaxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read case status: %w", err)
+	}
+
+	status, err := extractStatus(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract case status: %w", err)
+	}
+	return status, nil
+}
+
+// extractStatus reads the status from a JSON object with a "status" field or from the
+// first HTML element whose id or class mentions "status"
+func extractStatus(body []byte) (string, error) {
+	if trimmed := bytes.TrimSpace(body); bytes.HasPrefix(trimmed, []byte("{")) {
+		var response struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(trimmed, &response); err != nil {
+			return "", fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		if status := strings.Join(strings.Fields(response.Status), " "); status != "" {
+			return status, nil
+		}
+		return "", fmt.Errorf("no status in JSON response")
+	}
+
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	if node := findStatusNode(doc); node != nil {
+		if status := nodeText(node); status != "" {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("no status element on the page")
+}
+
+// findStatusNode returns the first element with "status" in its id or class
+func findStatusNode(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode {
+		for _, attr := range n.Attr {
+			if (attr.Key == "id" || attr.Key == "class") && strings.Contains(strings.ToLower(attr.Val), "status") {
+				return n
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findStatusNode(c); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText returns the whitespace-normalized text content of a node
+func nodeText(n *html.Node) string {
+	var builder strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			builder.WriteString(n.Data)
+			builder.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
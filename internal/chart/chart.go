@@ -0,0 +1,158 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/stats"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+const (
+	Width  = 20 * vg.Centimeter
+	Height = 10 * vg.Centimeter
+)
+
+// lineColor is used for all chart series
+var lineColor = color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff}
+
+// outageColor shades the DUW API outages
+var outageColor = color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 0x40}
+
+// WaitingClients renders waiting clients over the given history as a PNG line chart, with
+// the DUW API outages shaded
+func WaitingClients(history []*models.QueueData, outages []database.Outage, lang i18n.Language) ([]byte, error) {
+	points := make(plotter.XYs, 0, len(history))
+	for _, queueData := range history {
+		if queueData.LastUpdated.IsZero() {
+			continue
+		}
+		points = append(points, plotter.XY{X: float64(queueData.LastUpdated.Unix()), Y: float64(queueData.WaitingClients)})
+	}
+
+	if len(points) < 2 {
+		return nil, fmt.Errorf("not enough data for a chart: %d points", len(points))
+	}
+
+	p := newPlot(lang, "chart.waiting_title", "chart.waiting_axis")
+	p.X.Tick.Marker = plot.TimeTicks{
+		Format: "15:04",
+		Time: func(t float64) time.Time {
+			return time.Unix(int64(t), 0).Local()
+		},
+	}
+
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create line: %w", err)
+	}
+	line.Color = lineColor
+	line.Width = vg.Points(2)
+	p.Add(plotter.NewGrid())
+	if err := addOutages(p, lang, outages, points); err != nil {
+		return nil, err
+	}
+	p.Add(line)
+
+	return render(p)
+}
+
+// addOutages shades the outages since the first of the points up to their highest value;
+// an ongoing outage extends the chart until now
+func addOutages(p *plot.Plot, lang i18n.Language, outages []database.Outage, points plotter.XYs) error {
+	first := points[0].X
+	top := 0.0
+	for _, point := range points {
+		top = max(top, point.Y)
+	}
+	if top == 0 {
+		top = 1
+	}
+
+	labeled := false
+	for _, outage := range outages {
+		start, end := float64(outage.Start.Unix()), float64(outage.End.Unix())
+		if outage.Ongoing() {
+			end = float64(time.Now().Unix())
+		}
+		start = max(start, first)
+		if end <= start {
+			continue
+		}
+
+		area, err := plotter.NewPolygon(plotter.XYs{{X: start, Y: 0}, {X: end, Y: 0}, {X: end, Y: top}, {X: start, Y: top}})
+		if err != nil {
+			return fmt.Errorf("failed to create outage area: %w", err)
+		}
+		area.Color = outageColor
+		area.LineStyle.Width = 0
+		p.Add(area)
+
+		if !labeled {
+			p.Legend.Add(models.PlainText(i18n.T(lang, "chart.outage")), area)
+			p.Legend.Top = true
+			labeled = true
+		}
+	}
+	return nil
+}
+
+// ServedPerHour renders the tickets served in each hour as a PNG bar chart
+func ServedPerHour(throughput []stats.HourThroughput, lang i18n.Language) ([]byte, error) {
+	if len(throughput) == 0 {
+		return nil, fmt.Errorf("not enough data for a chart: no hourly buckets")
+	}
+
+	values := make(plotter.Values, len(throughput))
+	labels := make([]string, len(throughput))
+	for i, hour := range throughput {
+		values[i] = float64(hour.Served)
+		labels[i] = hour.Period.Format("15")
+	}
+
+	p := newPlot(lang, "chart.served_title", "chart.served_axis")
+	p.NominalX(labels...)
+
+	bars, err := plotter.NewBarChart(values, vg.Points(24))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bars: %w", err)
+	}
+	bars.Color = lineColor
+	bars.LineStyle.Width = 0
+	p.Add(plotter.NewGrid(), bars)
+
+	return render(p)
+}
+
+// newPlot creates a plot with a localized title and Y axis label
+func newPlot(lang i18n.Language, titleKey, axisKey string) *plot.Plot {
+	p := plot.New()
+	p.Title.Text = models.PlainText(i18n.T(lang, titleKey, time.Now().Format("02.01.2006")))
+	p.Y.Label.Text = models.PlainText(i18n.T(lang, axisKey))
+	p.Y.Min = 0
+	return p
+}
+
+// render encodes the plot as PNG, leaving some room above the highest value
+func render(p *plot.Plot) ([]byte, error) {
+	p.Y.Max *= 1.1
+
+	writer, err := p.WriterTo(Width, Height, "png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	if _, err := writer.WriteTo(&buffer); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,592 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // TIMEZONE works on hosts without a zoneinfo database
+
+	"karta/internal/logging"
+	"karta/internal/models"
+	"karta/internal/parser"
+	"karta/internal/reservation"
+	"karta/internal/schedule"
+)
+
+const (
+	DefaultDatabasePath      = "karta.db"
+	DefaultBroadcastWorkers  = 10
+	DefaultCommandsPerMinute = 10
+	DefaultCommandMute       = 5 * time.Minute
+	DefaultHTTPAddr          = ":8080"
+	DefaultLeaderLease       = 30 * time.Second
+	EncryptionKeySize        = 32 // AES-256
+
+	DefaultHistorySnapshotInterval = 5 * time.Minute
+	DefaultHistoryRetention        = 15 * 24 * time.Hour // Two weeks plus a day, for the week-over-week comparison of the weekly report
+	DefaultOutageRetention         = 90 * 24 * time.Hour
+	DefaultCommandUsageRetention   = 90 * 24 * time.Hour
+	DefaultCaseStatusInterval      = time.Hour
+
+	DefaultBackupInterval = 24 * time.Hour
+	DefaultBackupKeep     = 7
+
+	DefaultPollInterval       = 11 * time.Second
+	DefaultPollQuietInterval  = 30 * time.Second
+	DefaultPollQuietAfter     = 10 * time.Minute
+	DefaultPollClosedInterval = 5 * time.Minute
+
+	DefaultHTMLFallbackAfter = 3
+	DefaultStaleDataAfter    = 10 * time.Minute
+	DefaultTimezone          = "Europe/Warsaw"
+	DefaultOffices           = "wroclaw"
+
+	DefaultOfficeHours = "mon 08:00-17:00, tue-fri 08:00-15:00"
+	// Fixed-date Polish public holidays; Easter-based ones change yearly and go into OFFICE_HOLIDAYS
+	DefaultOfficeHolidays = "01-01, 01-06, 05-01, 05-03, 08-15, 11-01, 11-11, 12-24, 12-25, 12-26"
+)
+
+// Config represents application configuration loaded from environment variables
+type Config struct {
+	TelegramBotToken string
+	DatabasePath     string
+	DatabaseURL      string // PostgreSQL DSN; when set it is used instead of the SQLite file
+	MemoryStorage    bool   // Keep everything in memory instead of a database, lost on restart
+	AdminChatIDs     []int64
+	BroadcastWorkers int
+
+	// Incoming commands allowed per chat and minute; chats sending more are ignored for CommandMute
+	CommandsPerMinute int
+	CommandMute       time.Duration
+	HTTPAddr          string // Listen address of the HTTP server (RSS feed)
+	ImportToken       string // Static admin key of the HTTP API, e.g. for history imports
+	APIAuth           bool   // The read endpoints of the HTTP API require an API key too
+	WebAppURL         string // Public HTTPS URL of the Telegram Mini App at /app/, disabled when empty
+	WebhookURL        string // Optional endpoint receiving queue events as JSON
+	DiscordBotToken   string
+	DiscordChannelID  string
+	DiscordLanguage   string
+	NtfyURL           string // Full ntfy topic URL, e.g. https://ntfy.sh/karta
+	NtfyToken         string
+	GotifyURL         string
+	GotifyToken       string
+	PushLanguage      string // Language of ntfy/Gotify notifications
+	MessageTemplate   string // Path of a text/template file with a custom status message layout
+	ParseMode         string // Telegram parse mode, models.ParseModeMarkdownV2 or models.ParseModeHTML
+
+	// Kinds of messages sent without sound; edits of the live status message are always silent
+	SilentMessages []models.MessageKind
+
+	// Chats (e.g. a Telegram channel) receiving the weekly report besides subscribed users
+	WeeklyReportChatIDs  []int64
+	WeeklyReportLanguage string
+
+	// Unchanged polls are stored at most once per interval; changes are always stored
+	HistorySnapshotInterval time.Duration
+
+	// How long rows are kept, and caps deleting the oldest history beyond them (0 = no cap)
+	HistoryRetention      time.Duration
+	AggregateRetention    time.Duration // Hourly aggregates of deleted history, 0 keeps them forever
+	HistoryMaxRows        int
+	DatabaseMaxSize       int64 // Bytes
+	OutageRetention       time.Duration
+	CommandUsageRetention time.Duration
+
+	// Database backups to a local directory or an S3-compatible bucket; disabled when neither is set
+	BackupDir         string
+	BackupS3Endpoint  string
+	BackupS3Bucket    string
+	BackupS3Prefix    string
+	BackupS3Region    string
+	BackupS3AccessKey string
+	BackupS3SecretKey string
+	BackupInterval    time.Duration
+	BackupKeep        int
+
+	// Base polling interval, and adaptive polling; Closed/Quiet intervals never go below it
+	PollInterval       time.Duration
+	PollAdaptive       bool
+	PollQuietInterval  time.Duration
+	PollQuietAfter     time.Duration
+	PollClosedInterval time.Duration
+
+	// Scrape the public status page after this many consecutive JSON API failures; 0 disables it
+	HTMLFallbackAfter int
+
+	// Data counts as outdated when polls kept failing this long since the last successful
+	// one; 0 disables the watchdog
+	StaleDataAfter time.Duration
+
+	// IDs of the monitored offices; the first one is the primary office with history,
+	// statistics and the notification channels besides Telegram
+	Offices []string
+
+	// Other names of the monitored queues, by normalized queue name, for when DUW renames them
+	QueueAliases map[string][]string
+
+	// Alerts about degraded monitoring also go to the users of the office, not only the admins
+	MonitoringAlertUsers bool
+
+	// Fields and tolerances deciding whether a poll changed the queue
+	Comparison models.Comparison
+
+	// Office opening hours, holidays and breaks; nil when OFFICE_HOURS is empty (always open)
+	Schedule *schedule.Schedule
+
+	// Time zone of the office: office hours, history days and displayed times follow it
+	Timezone *time.Location
+
+	// Only the instance holding the leader lease in the shared database polls and notifies;
+	// the others stand by until the lease expires
+	LeaderElection bool
+	LeaderLease    time.Duration
+	InstanceID     string // Lease holder name, the host name and process ID by default
+
+	// Services of rezerwacje.duw.pl whose calendars are polled for free appointment slots;
+	// slot monitoring is disabled when empty
+	ReservationServices     []reservation.Service
+	ReservationCalendarURL  string
+	ReservationPollInterval time.Duration
+
+	// Public DUW case status page, {case} being replaced by the case number; /case is
+	// disabled when empty
+	CaseStatusURL      string
+	CaseStatusInterval time.Duration
+
+	// AES-256 keys encrypting usernames, ticket and case numbers at rest; nil stores them as plaintext.
+	// Old keys only decrypt, until `karta rotate-key` rewrites the users with the current key.
+	EncryptionKey     []byte
+	EncryptionOldKeys [][]byte
+
+	// Env-format file read on top of the environment and reloaded at runtime; empty for none
+	ConfigFile string
+	LogLevel   logging.Level
+}
+
+// Load reads configuration from environment variables, and from CONFIG_FILE when set
+func Load() (*Config, error) {
+	configFile := os.Getenv("CONFIG_FILE")
+	if configFile != "" {
+		if err := applyConfigFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{
+		ConfigFile:       configFile,
+		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
+		DatabasePath:     getEnv("DATABASE_PATH", DefaultDatabasePath),
+		DatabaseURL:      os.Getenv("DATABASE_URL"),
+		HTTPAddr:         getEnv("HTTP_ADDR", DefaultHTTPAddr),
+		ImportToken:      os.Getenv("IMPORT_TOKEN"),
+		APIAuth:          getEnv("API_AUTH", "false") == "true",
+		WebAppURL:        os.Getenv("WEBAPP_URL"),
+		WebhookURL:       os.Getenv("WEBHOOK_URL"),
+		DiscordBotToken:  os.Getenv("DISCORD_BOT_TOKEN"),
+		DiscordChannelID: os.Getenv("DISCORD_CHANNEL_ID"),
+		DiscordLanguage:  os.Getenv("DISCORD_LANGUAGE"),
+		NtfyURL:          os.Getenv("NTFY_URL"),
+		NtfyToken:        os.Getenv("NTFY_TOKEN"),
+		GotifyURL:        os.Getenv("GOTIFY_URL"),
+		GotifyToken:      os.Getenv("GOTIFY_TOKEN"),
+		PushLanguage:     os.Getenv("PUSH_LANGUAGE"),
+		MessageTemplate:  os.Getenv("MESSAGE_TEMPLATE"),
+
+		WeeklyReportLanguage: os.Getenv("WEEKLY_REPORT_LANGUAGE"),
+	}
+
+	if cfg.TelegramBotToken == "" {
+		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN environment variable is required")
+	}
+
+	adminChatIDs, err := parseChatIDs(os.Getenv("ADMIN_CHAT_IDS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ADMIN_CHAT_IDS: %w", err)
+	}
+	cfg.AdminChatIDs = adminChatIDs
+
+	if cfg.WeeklyReportChatIDs, err = parseChatIDs(os.Getenv("WEEKLY_REPORT_CHAT_IDS")); err != nil {
+		return nil, fmt.Errorf("invalid WEEKLY_REPORT_CHAT_IDS: %w", err)
+	}
+
+	broadcastWorkers, err := getEnvInt("BROADCAST_WORKERS", DefaultBroadcastWorkers)
+	if err != nil {
+		return nil, err
+	}
+	cfg.BroadcastWorkers = broadcastWorkers
+
+	if cfg.CommandsPerMinute, err = getEnvInt("COMMANDS_PER_MINUTE", DefaultCommandsPerMinute); err != nil {
+		return nil, err
+	}
+	if cfg.CommandMute, err = getEnvDuration("COMMAND_MUTE", DefaultCommandMute); err != nil {
+		return nil, err
+	}
+
+	if cfg.HistorySnapshotInterval, err = getEnvDuration("HISTORY_SNAPSHOT_INTERVAL", DefaultHistorySnapshotInterval); err != nil {
+		return nil, err
+	}
+
+	for key, value := range map[string]string{"WEBHOOK_URL": cfg.WebhookURL, "NTFY_URL": cfg.NtfyURL, "GOTIFY_URL": cfg.GotifyURL} {
+		if err := validateURL(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.WebAppURL != "" && !strings.HasPrefix(cfg.WebAppURL, "https://") {
+		return nil, fmt.Errorf("invalid WEBAPP_URL: Telegram Mini Apps must be served over HTTPS, got %q", cfg.WebAppURL)
+	}
+
+	if (cfg.DiscordBotToken == "") != (cfg.DiscordChannelID == "") {
+		return nil, fmt.Errorf("DISCORD_BOT_TOKEN and DISCORD_CHANNEL_ID must be set together")
+	}
+
+	if (cfg.GotifyURL == "") != (cfg.GotifyToken == "") {
+		return nil, fmt.Errorf("GOTIFY_URL and GOTIFY_TOKEN must be set together")
+	}
+
+	if err := loadBackupConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := loadRetentionConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := loadPollingConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := loadSchedule(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Timezone, err = time.LoadLocation(getEnv("TIMEZONE", DefaultTimezone)); err != nil {
+		return nil, fmt.Errorf("invalid TIMEZONE: %w", err)
+	}
+
+	switch storage := strings.ToLower(getEnv("STORAGE", "database")); storage {
+	case "database":
+	case "memory":
+		cfg.MemoryStorage = true
+	default:
+		return nil, fmt.Errorf("invalid STORAGE: must be database or memory, got %q", storage)
+	}
+
+	if err := loadLeaderConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := loadEncryptionConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.ReservationServices, err = reservation.ParseServices(os.Getenv("RESERVATION_SERVICES")); err != nil {
+		return nil, fmt.Errorf("invalid RESERVATION_SERVICES: %w", err)
+	}
+	cfg.ReservationCalendarURL = os.Getenv("RESERVATION_CALENDAR_URL")
+	if err := validateURL("RESERVATION_CALENDAR_URL", cfg.ReservationCalendarURL); err != nil {
+		return nil, err
+	}
+	if cfg.ReservationPollInterval, err = getEnvDuration("RESERVATION_POLL_INTERVAL", reservation.DefaultPollInterval); err != nil {
+		return nil, err
+	}
+
+	cfg.CaseStatusURL = os.Getenv("CASE_STATUS_URL")
+	if err := validateURL("CASE_STATUS_URL", cfg.CaseStatusURL); err != nil {
+		return nil, err
+	}
+	if cfg.CaseStatusInterval, err = getEnvDuration("CASE_STATUS_INTERVAL", DefaultCaseStatusInterval); err != nil {
+		return nil, err
+	}
+
+	switch mode := strings.ToLower(getEnv("TELEGRAM_PARSE_MODE", "markdownv2")); mode {
+	case "markdownv2":
+		cfg.ParseMode = models.ParseModeMarkdownV2
+	case "html":
+		cfg.ParseMode = models.ParseModeHTML
+	default:
+		return nil, fmt.Errorf("invalid TELEGRAM_PARSE_MODE: must be markdownv2 or html, got %q", mode)
+	}
+
+	if cfg.Comparison, err = models.ParseComparison(os.Getenv("COMPARE_FIELDS"), os.Getenv("COMPARE_TOLERANCES")); err != nil {
+		return nil, fmt.Errorf("invalid COMPARE_FIELDS or COMPARE_TOLERANCES: %w", err)
+	}
+
+	if cfg.SilentMessages, err = models.ParseMessageKinds(getEnv("SILENT_MESSAGES", models.DefaultSilentMessages)); err != nil {
+		return nil, fmt.Errorf("invalid SILENT_MESSAGES: %w", err)
+	}
+
+	if cfg.LogLevel, err = logging.ParseLevel(os.Getenv("LOG_LEVEL")); err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// getEnv returns environment variable value or default
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt returns environment variable parsed as a positive integer or default
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be a positive integer, got %q", key, value)
+	}
+	return parsed, nil
+}
+
+// loadBackupConfig reads the database backup settings
+func loadBackupConfig(cfg *Config) error {
+	cfg.BackupDir = os.Getenv("BACKUP_DIR")
+	cfg.BackupS3Endpoint = os.Getenv("BACKUP_S3_ENDPOINT")
+	cfg.BackupS3Bucket = os.Getenv("BACKUP_S3_BUCKET")
+	cfg.BackupS3Prefix = os.Getenv("BACKUP_S3_PREFIX")
+	cfg.BackupS3Region = os.Getenv("BACKUP_S3_REGION")
+	cfg.BackupS3AccessKey = os.Getenv("BACKUP_S3_ACCESS_KEY")
+	cfg.BackupS3SecretKey = os.Getenv("BACKUP_S3_SECRET_KEY")
+
+	if cfg.BackupDir != "" && cfg.BackupS3Bucket != "" {
+		return fmt.Errorf("BACKUP_DIR and BACKUP_S3_BUCKET cannot be used together")
+	}
+	if cfg.BackupS3Bucket != "" && (cfg.BackupS3Endpoint == "" || cfg.BackupS3AccessKey == "" || cfg.BackupS3SecretKey == "") {
+		return fmt.Errorf("BACKUP_S3_BUCKET requires BACKUP_S3_ENDPOINT, BACKUP_S3_ACCESS_KEY and BACKUP_S3_SECRET_KEY")
+	}
+	if err := validateURL("BACKUP_S3_ENDPOINT", cfg.BackupS3Endpoint); err != nil {
+		return err
+	}
+
+	var err error
+	if cfg.BackupInterval, err = getEnvDuration("BACKUP_INTERVAL", DefaultBackupInterval); err != nil {
+		return err
+	}
+	if cfg.BackupKeep, err = getEnvInt("BACKUP_KEEP", DefaultBackupKeep); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// loadRetentionConfig reads how long database rows are kept
+func loadRetentionConfig(cfg *Config) error {
+	var err error
+	if cfg.HistoryRetention, err = getEnvDuration("HISTORY_RETENTION", DefaultHistoryRetention); err != nil {
+		return err
+	}
+	if cfg.AggregateRetention, err = getEnvDuration("HOURLY_HISTORY_RETENTION", 0); err != nil {
+		return err
+	}
+	if cfg.HistoryMaxRows, err = getEnvInt("HISTORY_MAX_ROWS", 0); err != nil {
+		return err
+	}
+	maxSizeMB, err := getEnvInt("DATABASE_MAX_SIZE_MB", 0)
+	if err != nil {
+		return err
+	}
+	cfg.DatabaseMaxSize = int64(maxSizeMB) << 20
+	if cfg.OutageRetention, err = getEnvDuration("OUTAGE_RETENTION", DefaultOutageRetention); err != nil {
+		return err
+	}
+	if cfg.CommandUsageRetention, err = getEnvDuration("COMMAND_USAGE_RETENTION", DefaultCommandUsageRetention); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// loadPollingConfig reads the adaptive polling settings
+func loadPollingConfig(cfg *Config) error {
+	cfg.PollAdaptive = getEnv("POLL_ADAPTIVE", "true") != "false"
+
+	var err error
+	if cfg.PollInterval, err = getEnvDuration("POLL_INTERVAL", DefaultPollInterval); err != nil {
+		return err
+	}
+	if cfg.PollQuietInterval, err = getEnvDuration("POLL_QUIET_INTERVAL", DefaultPollQuietInterval); err != nil {
+		return err
+	}
+	if cfg.PollQuietAfter, err = getEnvDuration("POLL_QUIET_AFTER", DefaultPollQuietAfter); err != nil {
+		return err
+	}
+	if cfg.PollClosedInterval, err = getEnvDuration("POLL_CLOSED_INTERVAL", DefaultPollClosedInterval); err != nil {
+		return err
+	}
+
+	for _, office := range strings.Split(getEnv("OFFICES", DefaultOffices), ",") {
+		if office = strings.TrimSpace(office); office != "" {
+			cfg.Offices = append(cfg.Offices, strings.ToLower(office))
+		}
+	}
+	if len(cfg.Offices) == 0 {
+		return fmt.Errorf("OFFICES must list at least one office")
+	}
+
+	if cfg.QueueAliases, err = parser.ParseQueueAliases(os.Getenv("QUEUE_ALIASES")); err != nil {
+		return fmt.Errorf("invalid QUEUE_ALIASES: %w", err)
+	}
+	cfg.MonitoringAlertUsers = getEnv("MONITORING_ALERT_USERS", "false") == "true"
+
+	if cfg.StaleDataAfter, err = getEnvDuration("STALE_DATA_AFTER", DefaultStaleDataAfter); err != nil {
+		return err
+	}
+
+	if getEnv("HTML_FALLBACK", "true") != "false" {
+		if cfg.HTMLFallbackAfter, err = getEnvInt("HTML_FALLBACK_AFTER", DefaultHTMLFallbackAfter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadEncryptionConfig reads the field encryption keys, given as base64 in the environment
+// or in a file such as a secret mounted by a KMS or secret manager
+func loadEncryptionConfig(cfg *Config) error {
+	key := os.Getenv("FIELD_ENCRYPTION_KEY")
+	if path := os.Getenv("FIELD_ENCRYPTION_KEY_FILE"); path != "" {
+		if key != "" {
+			return fmt.Errorf("FIELD_ENCRYPTION_KEY and FIELD_ENCRYPTION_KEY_FILE cannot be used together")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read FIELD_ENCRYPTION_KEY_FILE: %w", err)
+		}
+		key = string(data)
+	}
+
+	var err error
+	if key = strings.TrimSpace(key); key != "" {
+		if cfg.EncryptionKey, err = parseEncryptionKey(key); err != nil {
+			return fmt.Errorf("invalid FIELD_ENCRYPTION_KEY: %w", err)
+		}
+	}
+
+	for _, oldKey := range strings.Split(os.Getenv("FIELD_ENCRYPTION_OLD_KEYS"), ",") {
+		if oldKey = strings.TrimSpace(oldKey); oldKey == "" {
+			continue
+		}
+		decoded, err := parseEncryptionKey(oldKey)
+		if err != nil {
+			return fmt.Errorf("invalid FIELD_ENCRYPTION_OLD_KEYS: %w", err)
+		}
+		cfg.EncryptionOldKeys = append(cfg.EncryptionOldKeys, decoded)
+	}
+
+	if len(cfg.EncryptionOldKeys) > 0 && cfg.EncryptionKey == nil {
+		return fmt.Errorf("FIELD_ENCRYPTION_OLD_KEYS requires FIELD_ENCRYPTION_KEY")
+	}
+	return nil
+}
+
+// parseEncryptionKey decodes a base64 AES-256 key, e.g. from `openssl rand -base64 32`
+func parseEncryptionKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected base64: %w", err)
+	}
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("expected %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+	return key, nil
+}
+
+// loadLeaderConfig reads the leader election settings
+func loadLeaderConfig(cfg *Config) error {
+	cfg.LeaderElection = getEnv("LEADER_ELECTION", "false") == "true"
+	if cfg.LeaderElection && cfg.MemoryStorage {
+		return fmt.Errorf("LEADER_ELECTION needs a database shared by the instances, not STORAGE=memory")
+	}
+
+	var err error
+	if cfg.LeaderLease, err = getEnvDuration("LEADER_LEASE", DefaultLeaderLease); err != nil {
+		return err
+	}
+
+	cfg.InstanceID = os.Getenv("INSTANCE_ID")
+	if cfg.InstanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "karta"
+		}
+		cfg.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return nil
+}
+
+// loadSchedule reads the office opening hours, holidays and breaks; an explicitly empty
+// OFFICE_HOURS disables the schedule
+func loadSchedule(cfg *Config) error {
+	officeHours := DefaultOfficeHours
+	if value, ok := os.LookupEnv("OFFICE_HOURS"); ok {
+		officeHours = value
+	}
+	holidays := DefaultOfficeHolidays
+	if value, ok := os.LookupEnv("OFFICE_HOLIDAYS"); ok {
+		holidays = value
+	}
+
+	var err error
+	if cfg.Schedule, err = schedule.Parse(officeHours, holidays, os.Getenv("OFFICE_BREAKS")); err != nil {
+		return fmt.Errorf("invalid OFFICE_HOURS, OFFICE_HOLIDAYS or OFFICE_BREAKS: %w", err)
+	}
+	return nil
+}
+
+// getEnvDuration returns environment variable parsed as a positive duration or default
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be a positive duration like 30s or 5m, got %q", key, value)
+	}
+	return parsed, nil
+}
+
+// validateURL checks that an optional URL setting is an http(s) URL
+func validateURL(key, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("invalid %s: must be an http(s) URL, got %q", key, value)
+	}
+	return nil
+}
+
+// parseChatIDs parses a comma-separated list of chat IDs
+func parseChatIDs(value string) ([]int64, error) {
+	var chatIDs []int64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		chatID, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat ID %q: %w", part, err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs, nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	DailyActivePeriod  = 24 * time.Hour
+	WeeklyActivePeriod = 7 * 24 * time.Hour
+	ChurnPeriod        = 30 * 24 * time.Hour // Active subscribers not seen for that long count as churned
+	NotificationWindow = time.Hour           // Activity that soon after a notification counts as a response to it
+	usageDayLayout     = "2006-01-02"
+)
+
+// ActivityStats summarizes how users engage with the bot
+type ActivityStats struct {
+	DailyActive           int // Users seen in the last DailyActivePeriod
+	WeeklyActive          int // Users seen in the last WeeklyActivePeriod
+	Churned               int // Active subscribers not seen for ChurnPeriod
+	Notifications         int // Notifications delivered
+	NotificationResponses int // Notifications followed by activity within NotificationWindow
+}
+
+// CommandUsage is the number of times a command was used
+type CommandUsage struct {
+	Command string
+	Count   int
+}
+
+// RecordActivity records that a user used the bot at the given time, as the response to
+// the last notification when it was delivered within NotificationWindow
+func (d *Database) RecordActivity(ctx context.Context, chatID int64, at time.Time) error {
+	// A responded notification is cleared so later activity does not count for it again
+	query := `UPDATE users SET last_seen = ?,
+			  notification_responses = notification_responses + CASE WHEN notified_at >= ? THEN 1 ELSE 0 END,
+			  notified_at = ''
+			  WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, formatTimestamp(at), formatTimestamp(at.Add(-NotificationWindow)), chatID)
+	if err != nil {
+		return fmt.Errorf("failed to record user activity: %w", err)
+	}
+
+	return nil
+}
+
+// RecordNotification records a notification delivered to a user at the given time
+func (d *Database) RecordNotification(ctx context.Context, chatID int64, at time.Time) error {
+	query := `UPDATE users SET notifications = notifications + 1, notified_at = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, formatTimestamp(at), chatID)
+	if err != nil {
+		return fmt.Errorf("failed to record notification: %w", err)
+	}
+
+	return nil
+}
+
+// RecordCommand counts a use of the command on the day of the given time
+func (d *Database) RecordCommand(ctx context.Context, command string, at time.Time) error {
+	query := `INSERT INTO command_usage (day, command, count) VALUES (?, ?, 1)
+			  ON CONFLICT (day, command) DO UPDATE SET count = command_usage.count + 1`
+
+	_, err := d.exec(ctx, query, at.UTC().Format(usageDayLayout), command)
+	if err != nil {
+		return fmt.Errorf("failed to record command usage: %w", err)
+	}
+
+	return nil
+}
+
+// CleanOldCommandUsage removes the command counts of days before olderThan ago and returns
+// the number of removed counts
+func (d *Database) CleanOldCommandUsage(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-olderThan).UTC().Format(usageDayLayout)
+	query := `DELETE FROM command_usage WHERE day < ?`
+
+	result, err := d.exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean old command usage: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
+
+// GetActivityStats returns the engagement of the users as of the given time
+func (d *Database) GetActivityStats(ctx context.Context, now time.Time) (ActivityStats, error) {
+	// Users not seen since activity tracking started are neither active nor churned
+	query := `SELECT
+			  COUNT(CASE WHEN last_seen >= ? THEN 1 END),
+			  COUNT(CASE WHEN last_seen >= ? THEN 1 END),
+			  COUNT(CASE WHEN active = TRUE AND last_seen <> '' AND last_seen < ? THEN 1 END),
+			  COALESCE(SUM(notifications), 0),
+			  COALESCE(SUM(notification_responses), 0)
+			  FROM users`
+
+	var stats ActivityStats
+	err := d.queryRow(ctx, query,
+		formatTimestamp(now.Add(-DailyActivePeriod)),
+		formatTimestamp(now.Add(-WeeklyActivePeriod)),
+		formatTimestamp(now.Add(-ChurnPeriod)),
+	).Scan(&stats.DailyActive, &stats.WeeklyActive, &stats.Churned, &stats.Notifications, &stats.NotificationResponses)
+	if err != nil {
+		return ActivityStats{}, fmt.Errorf("failed to get activity stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetCommandUsage returns how often each command was used since the day of the given
+// time, the most used first
+func (d *Database) GetCommandUsage(ctx context.Context, since time.Time) ([]CommandUsage, error) {
+	query := `SELECT command, SUM(count) AS total FROM command_usage WHERE day >= ?
+			  GROUP BY command ORDER BY total DESC, command ASC`
+
+	rows, err := d.query(ctx, query, since.UTC().Format(usageDayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query command usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []CommandUsage
+	for rows.Next() {
+		var command CommandUsage
+		if err := rows.Scan(&command.Command, &command.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan command usage: %w", err)
+		}
+		usage = append(usage, command)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate command usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// APIKey is a key of the HTTP API, stored by the hash of the key
+type APIKey struct {
+	ID        int64
+	Name      string
+	Hash      string
+	Scope     string // apikey.ScopeRead or apikey.ScopeAdmin
+	RateLimit int    // Requests per minute
+	CreatedAt time.Time
+}
+
+// CreateAPIKey stores a new API key and returns its ID
+func (d *Database) CreateAPIKey(ctx context.Context, key APIKey) (int64, error) {
+	query := `INSERT INTO api_keys (name, key_hash, scope, rate_limit, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`
+
+	var id int64
+	if err := d.queryRow(ctx, query, key.Name, key.Hash, key.Scope, key.RateLimit, d.dialect.timestamp(time.Now())).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return id, nil
+}
+
+// GetAPIKey returns the API key with the given hash, or nil if there is none
+func (d *Database) GetAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	query := `SELECT id, name, key_hash, scope, rate_limit, created_at FROM api_keys WHERE key_hash = ?`
+
+	key, err := scanAPIKey(d.queryRow(ctx, query, hash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns all API keys, oldest first
+func (d *Database) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	query := `SELECT id, name, key_hash, scope, rate_limit, created_at FROM api_keys ORDER BY id ASC`
+
+	rows, err := d.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// DeleteAPIKey revokes an API key and reports whether it existed
+func (d *Database) DeleteAPIKey(ctx context.Context, id int64) (bool, error) {
+	result, err := d.exec(ctx, `DELETE FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API key: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// scanAPIKey scans an api_keys row
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var key APIKey
+	var createdAt string
+	if err := row.Scan(&key.ID, &key.Name, &key.Hash, &key.Scope, &key.RateLimit, &createdAt); err != nil {
+		return nil, err
+	}
+
+	created, err := parseTimestamp(createdAt)
+	if err != nil {
+		return nil, err
+	}
+	key.CreatedAt = created.Local()
+	return &key, nil
+}
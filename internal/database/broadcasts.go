@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BroadcastProgress is a queue update, alert or admin announcement broadcast interrupted by a
+// shutdown, with the users it has not reached yet
+type BroadcastProgress struct {
+	Office   string    // Office of the data, followed by "/<alert kind>" for alerts
+	PolledAt time.Time // LastUpdated of the broadcast queue data, to the nanosecond
+	Pending  []int64   // Chat IDs of the users not reached yet
+	Message  string    // Text of an announcement, empty for queue data
+	SavedAt  time.Time
+}
+
+// SaveBroadcastProgress stores an interrupted broadcast, replacing the one of the same office
+func (d *Database) SaveBroadcastProgress(ctx context.Context, progress BroadcastProgress) error {
+	pending, err := json.Marshal(progress.Pending)
+	if err != nil {
+		return fmt.Errorf("failed to encode pending users: %w", err)
+	}
+
+	query := `INSERT INTO broadcast_progress (office, polled_at, pending, message, saved_at) VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT (office) DO UPDATE SET polled_at = excluded.polled_at, pending = excluded.pending,
+			  message = excluded.message, saved_at = excluded.saved_at`
+
+	if _, err := d.exec(ctx, query, progress.Office, progress.PolledAt.UnixNano(), string(pending), progress.Message, d.dialect.timestamp(progress.SavedAt)); err != nil {
+		return fmt.Errorf("failed to save broadcast progress: %w", err)
+	}
+	return nil
+}
+
+// GetBroadcastProgress returns the interrupted broadcast of the office, or nil when there is none
+func (d *Database) GetBroadcastProgress(ctx context.Context, office string) (*BroadcastProgress, error) {
+	query := `SELECT polled_at, pending, message, saved_at FROM broadcast_progress WHERE office = ?`
+
+	var polledAt int64
+	var pending, message, savedAt string
+	err := d.queryRow(ctx, query, office).Scan(&polledAt, &pending, &message, &savedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get broadcast progress: %w", err)
+	}
+
+	progress := &BroadcastProgress{Office: office, PolledAt: time.Unix(0, polledAt), Message: message}
+	if err := json.Unmarshal([]byte(pending), &progress.Pending); err != nil {
+		return nil, fmt.Errorf("failed to decode pending users: %w", err)
+	}
+	if progress.SavedAt, err = parseTimestamp(savedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse broadcast save time: %w", err)
+	}
+	return progress, nil
+}
+
+// DeleteBroadcastProgress forgets the interrupted broadcast of the office
+func (d *Database) DeleteBroadcastProgress(ctx context.Context, office string) error {
+	query := `DELETE FROM broadcast_progress WHERE office = ?`
+
+	if _, err := d.exec(ctx, query, office); err != nil {
+		return fmt.Errorf("failed to delete broadcast progress: %w", err)
+	}
+	return nil
+}
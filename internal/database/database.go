@@ -0,0 +1,888 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"karta/internal/models"
+)
+
+const (
+	// QueryTimeout bounds each database operation, so a locked or slow database cannot hang
+	// a handler or the shutdown
+	QueryTimeout = 10 * time.Second
+	// MaintenanceTimeout bounds the long operations: maintenance, snapshots and imports
+	MaintenanceTimeout = 10 * time.Minute
+)
+
+// Database represents the SQL database connection and operations. The SQL dialect
+// (SQLite or PostgreSQL) is chosen by the constructor.
+type Database struct {
+	db      *sql.DB
+	dialect dialect
+	cipher  *FieldCipher // Encrypts usernames, ticket and case numbers, nil for plaintext
+}
+
+// User represents a Telegram user in the database
+type User struct {
+	ID           int64     `json:"id"`
+	ChatID       int64     `json:"chat_id"`
+	Username     string    `json:"username"`
+	JoinedAt     time.Time `json:"joined_at"`
+	Active       bool      `json:"active"`
+	TicketNumber string    `json:"ticket_number"` // User's queue ticket number (e.g., "K222")
+	StatusAlerts bool      `json:"status_alerts"` // Whether user receives queue open/close alerts
+	TicketsAlert int       `json:"tickets_alert"` // Tickets-left threshold for exhaustion alerts (-1 = off)
+	Language     string    `json:"language"`      // Preferred message language code (empty = not chosen yet)
+	Office       string    `json:"office"`        // ID of the office the user follows (empty = primary office)
+	ShareTicket  bool      `json:"share_ticket"`  // Whether the ticket is counted, anonymized, in other users' /queuepos
+	Timezone     string    `json:"timezone"`      // IANA time zone of displayed times (empty = office time zone)
+	WeeklyReport bool      `json:"weekly_report"` // Whether user receives the weekly report
+
+	SendFailures     int       `json:"send_failures"`     // Consecutive failed broadcast deliveries
+	QuarantinedUntil time.Time `json:"quarantined_until"` // Broadcasts skip the user until then (zero = not quarantined)
+	MutedUntil       time.Time `json:"muted_until"`       // Rolling updates are paused until then (zero = not muted)
+
+	UpdateInterval time.Duration `json:"update_interval"` // Minimum time between live message updates (0 = every update)
+	LastSeen       time.Time     `json:"last_seen"`       // Last command or button press (zero = not seen since activity tracking started)
+	SlotServices   []string      `json:"slot_services"`   // Reservation services the user gets appointment slot alerts for
+	CaseNumber     string        `json:"case_number"`     // DUW case whose status is checked (empty = none)
+	CaseStatus     string        `json:"case_status"`     // Last checked status of the case (empty = not checked yet)
+}
+
+// Quarantined reports whether broadcasts should skip the user at the given time
+func (u User) Quarantined(now time.Time) bool {
+	return now.Before(u.QuarantinedUntil)
+}
+
+// Muted reports whether the user paused rolling updates at the given time
+func (u User) Muted(now time.Time) bool {
+	return now.Before(u.MutedUntil)
+}
+
+// QueueHistory represents historical queue data
+type QueueHistory struct {
+	ID        int64             `json:"id"`
+	QueueData *models.QueueData `json:"queue_data"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// newDatabase wraps an open connection and initializes tables
+func newDatabase(db *sql.DB, dialect dialect) (*Database, error) {
+	database := &Database{db: db, dialect: dialect}
+
+	if err := database.initTables(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	}
+
+	return database, nil
+}
+
+// Ping checks that the database is reachable
+func (d *Database) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := d.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// Maintain compacts the database and refreshes query planner statistics
+// (VACUUM and ANALYZE)
+func (d *Database) Maintain(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	start := time.Now()
+	for _, query := range d.dialect.maintenanceQueries() {
+		if _, err := d.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to run %s: %w", query, err)
+		}
+	}
+
+	log.Printf("Database maintenance completed in %v", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Size returns the bytes of data stored in the database, not counting space freed by
+// deleted rows that the database file keeps until it is vacuumed
+func (d *Database) Size(ctx context.Context) (int64, error) {
+	var size int64
+	if err := d.queryRow(ctx, d.dialect.sizeQuery()).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to get database size: %w", err)
+	}
+	return size, nil
+}
+
+// Snapshot writes a consistent copy of the database to a new file at path while it stays
+// in use. Only supported for SQLite.
+func (d *Database) Snapshot(ctx context.Context, path string) error {
+	query := d.dialect.snapshotQuery()
+	if query == "" {
+		return fmt.Errorf("database snapshots are not supported by this backend")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	if _, err := d.db.ExecContext(ctx, d.dialect.rebind(query), path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database connection
+func (d *Database) Close() error {
+	return d.db.Close()
+}
+
+// initTables creates the necessary database tables
+func (d *Database) initTables(ctx context.Context) error {
+	queries := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS users (
+			id %s,
+			chat_id BIGINT UNIQUE NOT NULL,
+			username TEXT,
+			joined_at %s,
+			active BOOLEAN DEFAULT TRUE,
+			ticket_number TEXT DEFAULT ''
+		)`, d.dialect.primaryKey(), d.dialect.createdAt()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS queue_history (
+			id %s,
+			queue_data TEXT NOT NULL,
+			created_at %s
+		)`, d.dialect.primaryKey(), d.dialect.createdAt()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS notification_outbox (
+			id %s,
+			channel TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			office TEXT DEFAULT '',
+			dedup_key TEXT UNIQUE NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT DEFAULT 'pending',
+			attempts INTEGER DEFAULT 0,
+			next_attempt %s,
+			last_error TEXT DEFAULT '',
+			created_at %s
+		)`, d.dialect.primaryKey(), d.dialect.createdAt(), d.dialect.createdAt()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS leases (
+			name TEXT PRIMARY KEY,
+			holder TEXT NOT NULL,
+			expires_at %s
+		)`, d.dialect.timestampType()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS stale_messages (
+			chat_id BIGINT NOT NULL,
+			message_id INTEGER NOT NULL,
+			created_at %s,
+			PRIMARY KEY (chat_id, message_id)
+		)`, d.dialect.createdAt()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS outages (
+			id %s,
+			started_at %s NOT NULL,
+			ended_at %s,
+			reason TEXT DEFAULT ''
+		)`, d.dialect.primaryKey(), d.dialect.timestampType(), d.dialect.timestampType()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS broadcast_progress (
+			office TEXT PRIMARY KEY,
+			polled_at BIGINT NOT NULL,
+			pending TEXT NOT NULL,
+			saved_at %s NOT NULL
+		)`, d.dialect.timestampType()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS history_hourly (
+			hour %s PRIMARY KEY,
+			samples INTEGER NOT NULL,
+			min_waiting INTEGER NOT NULL,
+			max_waiting INTEGER NOT NULL,
+			sum_waiting BIGINT NOT NULL,
+			max_served INTEGER NOT NULL,
+			min_tickets_left INTEGER NOT NULL,
+			service_seconds BIGINT NOT NULL,
+			service_samples INTEGER NOT NULL
+		)`, d.dialect.timestampType()),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS api_keys (
+			id %s,
+			name TEXT NOT NULL,
+			key_hash TEXT UNIQUE NOT NULL,
+			scope TEXT NOT NULL,
+			rate_limit INTEGER NOT NULL,
+			created_at %s
+		)`, d.dialect.primaryKey(), d.dialect.createdAt()),
+		`CREATE TABLE IF NOT EXISTS command_usage (
+			day TEXT NOT NULL,
+			command TEXT NOT NULL,
+			count INTEGER DEFAULT 0,
+			PRIMARY KEY (day, command)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_users_chat_id ON users(chat_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_users_active ON users(active)`,
+		`CREATE INDEX IF NOT EXISTS idx_queue_history_created_at ON queue_history(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_outbox_pending ON notification_outbox(status, next_attempt)`,
+	}
+
+	for _, query := range queries {
+		if _, err := d.exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to execute query %s: %w", query, err)
+		}
+	}
+
+	return d.migrateTables(ctx)
+}
+
+// columnMigration is a column added to an existing table
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// migrateTables adds columns introduced after the initial schema to existing databases
+// and moves JSON queue history and text average times into typed columns
+func (d *Database) migrateTables(ctx context.Context) error {
+	migrations := []columnMigration{
+		{"users", "status_alerts", "BOOLEAN DEFAULT FALSE"},
+		{"users", "tickets_alert", "INTEGER DEFAULT -1"},
+		{"users", "banned", "BOOLEAN DEFAULT FALSE"},
+		{"users", "message_id", "INTEGER DEFAULT 0"},
+		{"users", "pin_message", "BOOLEAN DEFAULT FALSE"},
+		{"users", "language", "TEXT DEFAULT ''"},
+		{"users", "send_failures", "INTEGER DEFAULT 0"},
+		{"users", "quarantined_until", "TEXT DEFAULT ''"},
+		{"users", "office", "TEXT DEFAULT ''"},
+		{"users", "share_ticket", "BOOLEAN DEFAULT FALSE"},
+		{"users", "timezone", "TEXT DEFAULT ''"},
+		{"users", "muted_until", "TEXT DEFAULT ''"},
+		{"users", "weekly_report", "BOOLEAN DEFAULT FALSE"},
+		{"users", "update_interval", "INTEGER DEFAULT 0"},
+		{"users", "last_seen", "TEXT DEFAULT ''"},
+		{"users", "notified_at", "TEXT DEFAULT ''"},
+		{"users", "notifications", "INTEGER DEFAULT 0"},
+		{"users", "notification_responses", "INTEGER DEFAULT 0"},
+		{"users", "slot_services", "TEXT DEFAULT ''"},
+		{"users", "case_number", "TEXT DEFAULT ''"},
+		{"users", "case_status", "TEXT DEFAULT ''"},
+		{"broadcast_progress", "message", "TEXT DEFAULT ''"},
+	}
+	migrations = append(migrations, d.historyMigrations()...)
+
+	for _, migration := range migrations {
+		if err := d.addColumnIfMissing(ctx, migration.table, migration.column, migration.definition); err != nil {
+			return err
+		}
+	}
+
+	if err := d.backfillHistory(ctx); err != nil {
+		return err
+	}
+	return d.backfillDurations(ctx)
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func (d *Database) addColumnIfMissing(ctx context.Context, table, column, definition string) error {
+	var count int
+	if err := d.queryRow(ctx, d.dialect.columnExistsQuery(), table, column).Scan(&count); err != nil {
+		return fmt.Errorf("failed to read table info for %s: %w", table, err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := d.exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+
+	log.Printf("Added column %s.%s", table, column)
+	return nil
+}
+
+// exec runs a statement written with ? placeholders within QueryTimeout
+func (d *Database) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+	return d.db.ExecContext(ctx, d.dialect.rebind(query), args...)
+}
+
+// rows are the results of a query, which has until they are closed to finish
+type rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+// Close closes the results and releases the timeout of the query
+func (r *rows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return err
+}
+
+// query runs a query written with ? placeholders within QueryTimeout
+func (d *Database) query(ctx context.Context, query string, args ...interface{}) (*rows, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	result, err := d.db.QueryContext(ctx, d.dialect.rebind(query), args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &rows{Rows: result, cancel: cancel}, nil
+}
+
+// row is a single-row query, run within QueryTimeout when scanned
+type row struct {
+	ctx   context.Context
+	db    *sql.DB
+	query string
+	args  []interface{}
+}
+
+// Scan runs the query and copies the columns of its first row into dest
+func (r row) Scan(dest ...interface{}) error {
+	ctx, cancel := context.WithTimeout(r.ctx, QueryTimeout)
+	defer cancel()
+	return r.db.QueryRowContext(ctx, r.query, r.args...).Scan(dest...)
+}
+
+// queryRow prepares a single-row query written with ? placeholders
+func (d *Database) queryRow(ctx context.Context, query string, args ...interface{}) row {
+	return row{ctx: ctx, db: d.db, query: d.dialect.rebind(query), args: args}
+}
+
+// AddUser adds a new user to the database or updates existing user
+func (d *Database) AddUser(ctx context.Context, chatID int64, username string) error {
+	// Upsert keeps per-user settings (ticket, alerts) intact for existing users,
+	// lifts a delivery quarantine and never reactivates banned ones
+	query := `INSERT INTO users (chat_id, username, active)
+			  VALUES (?, ?, TRUE)
+			  ON CONFLICT(chat_id) DO UPDATE SET username = excluded.username,
+			  active = NOT users.banned,
+			  send_failures = 0, quarantined_until = ''`
+
+	stored, err := d.sealField(chatID, "username", username)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.exec(ctx, query, chatID, stored); err != nil {
+		return fmt.Errorf("failed to add user: %w", err)
+	}
+
+	log.Printf("User added/updated: chat_id=%d, username=%s", chatID, username)
+	return nil
+}
+
+// userColumns lists the users columns read by scanUser
+const userColumns = `id, chat_id, username, joined_at, active, ticket_number, status_alerts, tickets_alert, language,
+			  send_failures, quarantined_until, office, share_ticket, timezone, muted_until, weekly_report, update_interval, last_seen, slot_services, case_number, case_status`
+
+// rowScanner is implemented by row and rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a row selected with userColumns
+func (d *Database) scanUser(row rowScanner) (User, error) {
+	var user User
+	var username sql.NullString
+	var ticketNumber sql.NullString
+	var language sql.NullString
+	var quarantinedUntil sql.NullString
+	var office sql.NullString
+	var shareTicket sql.NullBool
+	var timezone sql.NullString
+	var mutedUntil sql.NullString
+	var weeklyReport sql.NullBool
+	var updateInterval sql.NullInt64
+	var lastSeen sql.NullString
+	var slotServices sql.NullString
+	var caseNumber sql.NullString
+	var caseStatus sql.NullString
+
+	err := row.Scan(&user.ID, &user.ChatID, &username, &user.JoinedAt, &user.Active, &ticketNumber, &user.StatusAlerts, &user.TicketsAlert, &language,
+		&user.SendFailures, &quarantinedUntil, &office, &shareTicket, &timezone, &mutedUntil, &weeklyReport, &updateInterval, &lastSeen, &slotServices, &caseNumber, &caseStatus)
+	if err != nil {
+		return User{}, err
+	}
+
+	if user.Username, err = d.openField(user.ChatID, "username", username.String); err != nil {
+		return User{}, err
+	}
+
+	if user.TicketNumber, err = d.openField(user.ChatID, "ticket_number", ticketNumber.String); err != nil {
+		return User{}, err
+	}
+
+	if language.Valid {
+		user.Language = language.String
+	}
+
+	if office.Valid {
+		user.Office = office.String
+	}
+
+	user.ShareTicket = shareTicket.Valid && shareTicket.Bool
+	user.WeeklyReport = weeklyReport.Valid && weeklyReport.Bool
+	user.UpdateInterval = time.Duration(updateInterval.Int64) * time.Second
+	if slotServices.Valid && slotServices.String != "" {
+		user.SlotServices = strings.Split(slotServices.String, ",")
+	}
+	user.CaseStatus = caseStatus.String
+	if user.CaseNumber, err = d.openField(user.ChatID, "case_number", caseNumber.String); err != nil {
+		return User{}, err
+	}
+	if timezone.Valid {
+		user.Timezone = timezone.String
+	}
+
+	if quarantinedUntil.Valid && quarantinedUntil.String != "" {
+		if user.QuarantinedUntil, err = parseTimestamp(quarantinedUntil.String); err != nil {
+			return User{}, fmt.Errorf("failed to parse quarantine time of user %d: %w", user.ChatID, err)
+		}
+	}
+
+	if mutedUntil.Valid && mutedUntil.String != "" {
+		if user.MutedUntil, err = parseTimestamp(mutedUntil.String); err != nil {
+			return User{}, fmt.Errorf("failed to parse mute time of user %d: %w", user.ChatID, err)
+		}
+	}
+
+	if lastSeen.Valid && lastSeen.String != "" {
+		if user.LastSeen, err = parseTimestamp(lastSeen.String); err != nil {
+			return User{}, fmt.Errorf("failed to parse last seen time of user %d: %w", user.ChatID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// GetActiveUsers returns all active users
+func (d *Database) GetActiveUsers(ctx context.Context) ([]User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE active = TRUE`
+
+	rows, err := d.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		user, err := d.scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUser returns a user by chat ID, or nil if the user is not registered
+func (d *Database) GetUser(ctx context.Context, chatID int64) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE chat_id = ?`
+
+	user, err := d.scanUser(d.queryRow(ctx, query, chatID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// DeactivateUser marks a user as inactive
+func (d *Database) DeactivateUser(ctx context.Context, chatID int64) error {
+	query := `UPDATE users SET active = FALSE WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	log.Printf("User deactivated: chat_id=%d", chatID)
+	return nil
+}
+
+// RecordSendFailure increments the consecutive delivery failures of a user and returns the new count
+func (d *Database) RecordSendFailure(ctx context.Context, chatID int64) (int, error) {
+	query := `UPDATE users SET send_failures = send_failures + 1 WHERE chat_id = ? RETURNING send_failures`
+
+	var failures int
+	if err := d.queryRow(ctx, query, chatID).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record send failure: %w", err)
+	}
+
+	return failures, nil
+}
+
+// QuarantineUser excludes a user from broadcasts until the given time
+func (d *Database) QuarantineUser(ctx context.Context, chatID int64, until time.Time) error {
+	query := `UPDATE users SET quarantined_until = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, formatTimestamp(until), chatID)
+	if err != nil {
+		return fmt.Errorf("failed to quarantine user: %w", err)
+	}
+
+	log.Printf("User quarantined: chat_id=%d, until=%s", chatID, until.Format(time.RFC3339))
+	return nil
+}
+
+// ResetSendFailures clears the delivery failure counter and quarantine of a user
+func (d *Database) ResetSendFailures(ctx context.Context, chatID int64) error {
+	query := `UPDATE users SET send_failures = 0, quarantined_until = '' WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to reset send failures: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserCount returns the total number of active users
+func (d *Database) GetUserCount(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM users WHERE active = TRUE`
+
+	var count int
+	err := d.queryRow(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user count: %w", err)
+	}
+
+	return count, nil
+}
+
+// SetUserTicketNumber sets the ticket number for a user
+func (d *Database) SetUserTicketNumber(ctx context.Context, chatID int64, ticketNumber string) error {
+	query := `UPDATE users SET ticket_number = ? WHERE chat_id = ?`
+
+	stored, err := d.sealField(chatID, "ticket_number", ticketNumber)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.exec(ctx, query, stored, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set ticket number: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserTicketNumber gets the ticket number for a user
+func (d *Database) GetUserTicketNumber(ctx context.Context, chatID int64) (string, error) {
+	query := `SELECT ticket_number FROM users WHERE chat_id = ? AND active = TRUE`
+
+	var ticketNumber string
+	err := d.queryRow(ctx, query, chatID).Scan(&ticketNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil // User not found or not active
+		}
+		return "", fmt.Errorf("failed to get ticket number: %w", err)
+	}
+
+	return d.openField(chatID, "ticket_number", ticketNumber)
+}
+
+// SetUserBanned bans or unbans a user; banned users are deactivated and ignored by the bot
+func (d *Database) SetUserBanned(ctx context.Context, chatID int64, banned bool) error {
+	query := `UPDATE users SET banned = ?, active = ? WHERE chat_id = ?`
+
+	result, err := d.exec(ctx, query, banned, !banned, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user banned: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return fmt.Errorf("user %d not found", chatID)
+	}
+
+	log.Printf("User ban updated: chat_id=%d, banned=%t", chatID, banned)
+	return nil
+}
+
+// IsUserBanned checks whether a user is banned
+func (d *Database) IsUserBanned(ctx context.Context, chatID int64) (bool, error) {
+	query := `SELECT banned FROM users WHERE chat_id = ?`
+
+	var banned bool
+	err := d.queryRow(ctx, query, chatID).Scan(&banned)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check user ban: %w", err)
+	}
+
+	return banned, nil
+}
+
+// SetUserMessageID stores the ID of the live status message for a user (0 clears it)
+func (d *Database) SetUserMessageID(ctx context.Context, chatID int64, messageID int) error {
+	query := `UPDATE users SET message_id = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, messageID, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set message ID: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserMessageIDs returns live status message IDs of active users keyed by chat ID
+func (d *Database) GetUserMessageIDs(ctx context.Context) (map[int64]int, error) {
+	query := `SELECT chat_id, message_id FROM users WHERE active = TRUE AND message_id > 0`
+
+	rows, err := d.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query message IDs: %w", err)
+	}
+	defer rows.Close()
+
+	messageIDs := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var messageID int
+		if err := rows.Scan(&chatID, &messageID); err != nil {
+			return nil, fmt.Errorf("failed to scan message ID: %w", err)
+		}
+		messageIDs[chatID] = messageID
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating message IDs: %w", err)
+	}
+
+	return messageIDs, nil
+}
+
+// SetUserPinMessage enables or disables pinning of the live status message for a user
+func (d *Database) SetUserPinMessage(ctx context.Context, chatID int64, enabled bool) error {
+	query := `UPDATE users SET pin_message = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, enabled, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set pin message: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserPinMessage checks whether a user wants the live status message pinned
+func (d *Database) GetUserPinMessage(ctx context.Context, chatID int64) (bool, error) {
+	query := `SELECT pin_message FROM users WHERE chat_id = ? AND active = TRUE`
+
+	var enabled bool
+	err := d.queryRow(ctx, query, chatID).Scan(&enabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get pin message: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// SetUserLanguage sets the preferred message language for a user
+func (d *Database) SetUserLanguage(ctx context.Context, chatID int64, language string) error {
+	query := `UPDATE users SET language = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, language, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user language: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserLanguage returns the preferred message language for a user (empty if not chosen)
+func (d *Database) GetUserLanguage(ctx context.Context, chatID int64) (string, error) {
+	query := `SELECT language FROM users WHERE chat_id = ?`
+
+	var language sql.NullString
+	err := d.queryRow(ctx, query, chatID).Scan(&language)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get user language: %w", err)
+	}
+
+	return language.String, nil
+}
+
+// SetUserStatusAlerts enables or disables queue open/close alerts for a user
+func (d *Database) SetUserStatusAlerts(ctx context.Context, chatID int64, enabled bool) error {
+	query := `UPDATE users SET status_alerts = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, enabled, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set status alerts: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserTicketsAlert sets the tickets-left threshold for exhaustion alerts (-1 disables them)
+func (d *Database) SetUserTicketsAlert(ctx context.Context, chatID int64, threshold int) error {
+	query := `UPDATE users SET tickets_alert = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, threshold, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set tickets alert: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserOffice sets the office a user follows (empty for the primary office)
+func (d *Database) SetUserOffice(ctx context.Context, chatID int64, office string) error {
+	query := `UPDATE users SET office = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, office, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user office: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserShareTicket sets whether a user's ticket is counted in other users' queue positions
+func (d *Database) SetUserShareTicket(ctx context.Context, chatID int64, enabled bool) error {
+	query := `UPDATE users SET share_ticket = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, enabled, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set ticket sharing: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserTimezone sets the time zone of the times shown to a user (empty for the office time zone)
+func (d *Database) SetUserTimezone(ctx context.Context, chatID int64, timezone string) error {
+	query := `UPDATE users SET timezone = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, timezone, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user timezone: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserWeeklyReport sets whether a user receives the weekly report
+func (d *Database) SetUserWeeklyReport(ctx context.Context, chatID int64, enabled bool) error {
+	query := `UPDATE users SET weekly_report = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, enabled, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user weekly report: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserUpdateInterval sets the minimum time between live message updates of a user; zero
+// updates it on every queue change
+func (d *Database) SetUserUpdateInterval(ctx context.Context, chatID int64, interval time.Duration) error {
+	query := `UPDATE users SET update_interval = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, int64(interval/time.Second), chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user update interval: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserSlotServices sets the reservation services a user gets appointment slot alerts for
+func (d *Database) SetUserSlotServices(ctx context.Context, chatID int64, services []string) error {
+	query := `UPDATE users SET slot_services = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, strings.Join(services, ","), chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user slot services: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserCaseNumber sets the DUW case whose status is checked for a user, or clears it with
+// an empty number; the last checked status is reset
+func (d *Database) SetUserCaseNumber(ctx context.Context, chatID int64, caseNumber string) error {
+	query := `UPDATE users SET case_number = ?, case_status = '' WHERE chat_id = ?`
+
+	stored, err := d.sealField(chatID, "case_number", caseNumber)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.exec(ctx, query, stored, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user case number: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserCaseStatus records the last checked status of a user's case
+func (d *Database) SetUserCaseStatus(ctx context.Context, chatID int64, status string) error {
+	query := `UPDATE users SET case_status = ? WHERE chat_id = ?`
+
+	_, err := d.exec(ctx, query, status, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user case status: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserMutedUntil pauses rolling updates for a user until the given time; the zero time resumes them
+func (d *Database) SetUserMutedUntil(ctx context.Context, chatID int64, until time.Time) error {
+	query := `UPDATE users SET muted_until = ? WHERE chat_id = ?`
+
+	value := ""
+	if !until.IsZero() {
+		value = formatTimestamp(until)
+	}
+
+	_, err := d.exec(ctx, query, value, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to set user mute: %w", err)
+	}
+
+	return nil
+}
+
+// formatTimestamp formats time in the UTC layout used by SQLite CURRENT_TIMESTAMP
+// (and by text timestamp columns on all backends)
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// parseTimestamp parses a UTC timestamp produced by SQLite CURRENT_TIMESTAMP
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse timestamp %q", value)
+}
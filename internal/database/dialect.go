@@ -0,0 +1,38 @@
+package database
+
+import "time"
+
+// period is a local-time bucket size for history aggregation
+type period int
+
+const (
+	periodHour      period = iota
+	periodDay              // Formatted as the start of the day
+	periodHourOfDay        // Formatted as "15" for grouping across days
+)
+
+// dialect hides SQL differences between the supported database backends.
+// Queries are written with ? placeholders and rebound by the dialect.
+type dialect interface {
+	// rebind converts ? placeholders to the backend syntax
+	rebind(query string) string
+	// primaryKey returns the auto-increment primary key column definition
+	primaryKey() string
+	// createdAt returns a timestamp column definition defaulting to the current UTC time
+	createdAt() string
+	// timestampType returns the column type for UTC timestamps
+	timestampType() string
+	// columnExistsQuery returns a query counting columns by table and column name
+	columnExistsQuery() string
+	// timestamp converts a time to a value comparable with createdAt columns
+	timestamp(t time.Time) interface{}
+	// maintenanceQueries returns the statements run by Maintain
+	maintenanceQueries() []string
+	// snapshotQuery returns a statement writing a consistent copy of the database to the
+	// path given as its argument, or "" when the backend has no such statement
+	snapshotQuery() string
+	// sizeQuery returns a query for the bytes of live data in the database
+	sizeQuery() string
+	// localPeriod formats a UTC timestamp column as a local "2006-01-02 15:04:05" bucket start
+	localPeriod(column string, unit period) string
+}
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HourlyAggregate is queue history of an hour downsampled when its entries are deleted, so
+// long-range analytics outlive the raw history. Sums are kept instead of averages, so the
+// aggregates of an hour deleted in parts merge exactly.
+type HourlyAggregate struct {
+	Hour           time.Time // Start of the hour, UTC
+	Samples        int
+	MinWaiting     int
+	MaxWaiting     int
+	SumWaiting     int64
+	MaxServed      int
+	MinTicketsLeft int
+	ServiceSeconds int64 // Sum of the reported average service times
+	ServiceSamples int   // Entries with a reported average service time
+}
+
+// add counts a history entry into the aggregate
+func (a *HourlyAggregate) add(waiting, served, ticketsLeft int, serviceSeconds int64) {
+	if a.Samples == 0 {
+		a.MinWaiting, a.MaxWaiting, a.MaxServed, a.MinTicketsLeft = waiting, waiting, served, ticketsLeft
+	}
+	a.Samples++
+	a.SumWaiting += int64(waiting)
+	a.MinWaiting = min(a.MinWaiting, waiting)
+	a.MaxWaiting = max(a.MaxWaiting, waiting)
+	a.MaxServed = max(a.MaxServed, served)
+	a.MinTicketsLeft = min(a.MinTicketsLeft, ticketsLeft)
+	if serviceSeconds > 0 {
+		a.ServiceSeconds += serviceSeconds
+		a.ServiceSamples++
+	}
+}
+
+// merge adds another aggregate of the same hour
+func (a *HourlyAggregate) merge(other HourlyAggregate) {
+	if other.Samples == 0 {
+		return
+	}
+	if a.Samples == 0 {
+		*a = other
+		return
+	}
+	a.Samples += other.Samples
+	a.SumWaiting += other.SumWaiting
+	a.MinWaiting = min(a.MinWaiting, other.MinWaiting)
+	a.MaxWaiting = max(a.MaxWaiting, other.MaxWaiting)
+	a.MaxServed = max(a.MaxServed, other.MaxServed)
+	a.MinTicketsLeft = min(a.MinTicketsLeft, other.MinTicketsLeft)
+	a.ServiceSeconds += other.ServiceSeconds
+	a.ServiceSamples += other.ServiceSamples
+}
+
+// aggregateOf turns a history bucket back into sums, taking every sample of a bucket with a
+// service time as reporting it
+func aggregateOf(bucket HistoryBucket) HourlyAggregate {
+	aggregate := HourlyAggregate{
+		Hour:           bucket.Period,
+		Samples:        bucket.Samples,
+		MinWaiting:     bucket.MinWaiting,
+		MaxWaiting:     bucket.MaxWaiting,
+		SumWaiting:     int64(bucket.AvgWaiting*float64(bucket.Samples) + 0.5),
+		MaxServed:      bucket.MaxServed,
+		MinTicketsLeft: bucket.MinTicketsLeft,
+	}
+	if bucket.AvgServiceTime > 0 {
+		aggregate.ServiceSamples = bucket.Samples
+		aggregate.ServiceSeconds = int64(bucket.AvgServiceTime.Seconds()*float64(bucket.Samples) + 0.5)
+	}
+	return aggregate
+}
+
+// bucket returns the aggregate as a history bucket of the given period
+func (a HourlyAggregate) bucket(period time.Time) HistoryBucket {
+	bucket := HistoryBucket{
+		Period:         period,
+		Samples:        a.Samples,
+		AvgWaiting:     float64(a.SumWaiting) / float64(a.Samples),
+		MinWaiting:     a.MinWaiting,
+		MaxWaiting:     a.MaxWaiting,
+		MaxServed:      a.MaxServed,
+		MinTicketsLeft: a.MinTicketsLeft,
+	}
+	if a.ServiceSamples > 0 {
+		bucket.AvgServiceTime = time.Duration(float64(a.ServiceSeconds) / float64(a.ServiceSamples) * float64(time.Second))
+	}
+	return bucket
+}
+
+// mergeAggregates adds hourly aggregates to history buckets of local hours or days, oldest
+// first; buckets of the same period are merged
+func mergeAggregates(buckets []HistoryBucket, aggregates []HourlyAggregate, unit period) []HistoryBucket {
+	if len(aggregates) == 0 {
+		return buckets
+	}
+
+	// Keyed by Unix time, as equal times may differ in their location
+	byPeriod := make(map[int64]*HourlyAggregate)
+	periods := make(map[int64]time.Time)
+	for _, bucket := range buckets {
+		aggregate := aggregateOf(bucket)
+		byPeriod[bucket.Period.Unix()] = &aggregate
+		periods[bucket.Period.Unix()] = bucket.Period
+	}
+	for _, aggregate := range aggregates {
+		period := localPeriodOf(aggregate.Hour, unit)
+		if existing := byPeriod[period.Unix()]; existing != nil {
+			existing.merge(aggregate)
+			continue
+		}
+		copied := aggregate
+		byPeriod[period.Unix()] = &copied
+		periods[period.Unix()] = period
+	}
+
+	merged := make([]HistoryBucket, 0, len(byPeriod))
+	for key, aggregate := range byPeriod {
+		merged = append(merged, aggregate.bucket(periods[key]))
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Period.Before(merged[j].Period) })
+	return merged
+}
+
+// downsampleHistory adds the queue history entries matching the condition to the hourly
+// aggregates and deletes them in one transaction, returning the number of deleted entries
+func (d *Database) downsampleHistory(ctx context.Context, condition string, args ...interface{}) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin history downsampling: %w", err)
+	}
+	defer tx.Rollback()
+
+	hours, err := d.aggregateEntries(ctx, tx, condition, args...)
+	if err != nil {
+		return 0, err
+	}
+	for _, aggregate := range hours {
+		if err := d.mergeAggregate(ctx, tx, *aggregate); err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, d.dialect.rebind(`DELETE FROM queue_history WHERE `+condition), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete downsampled history: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit history downsampling: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
+
+// aggregateEntries reads the queue history entries matching the condition into aggregates
+// by UTC hour
+func (d *Database) aggregateEntries(ctx context.Context, tx *sql.Tx, condition string, args ...interface{}) (map[time.Time]*HourlyAggregate, error) {
+	query := `SELECT created_at, COALESCE(waiting, 0), COALESCE(served, 0), COALESCE(tickets_left, 0),
+			  COALESCE(avg_service_seconds, 0) FROM queue_history WHERE ` + condition
+
+	rows, err := tx.QueryContext(ctx, d.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history to downsample: %w", err)
+	}
+	defer rows.Close()
+
+	hours := make(map[time.Time]*HourlyAggregate)
+	for rows.Next() {
+		var createdAt string
+		var waiting, served, ticketsLeft int
+		var serviceSeconds int64
+		if err := rows.Scan(&createdAt, &waiting, &served, &ticketsLeft, &serviceSeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan history to downsample: %w", err)
+		}
+
+		recordedAt, err := parseTimestamp(createdAt)
+		if err != nil {
+			return nil, err
+		}
+		hour := recordedAt.Truncate(time.Hour)
+		if hours[hour] == nil {
+			hours[hour] = &HourlyAggregate{Hour: hour}
+		}
+		hours[hour].add(waiting, served, ticketsLeft, serviceSeconds)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate history to downsample: %w", err)
+	}
+	return hours, nil
+}
+
+// mergeAggregate adds an aggregate to the stored one of its hour
+func (d *Database) mergeAggregate(ctx context.Context, tx *sql.Tx, aggregate HourlyAggregate) error {
+	hour := d.dialect.timestamp(aggregate.Hour)
+
+	stored := HourlyAggregate{Hour: aggregate.Hour}
+	err := tx.QueryRowContext(ctx, d.dialect.rebind(`SELECT samples, min_waiting, max_waiting, sum_waiting, max_served,
+			  min_tickets_left, service_seconds, service_samples FROM history_hourly WHERE hour = ?`), hour).
+		Scan(&stored.Samples, &stored.MinWaiting, &stored.MaxWaiting, &stored.SumWaiting, &stored.MaxServed,
+			&stored.MinTicketsLeft, &stored.ServiceSeconds, &stored.ServiceSamples)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get hourly history: %w", err)
+	}
+	stored.merge(aggregate)
+
+	query := `INSERT INTO history_hourly (hour, samples, min_waiting, max_waiting, sum_waiting, max_served,
+			  min_tickets_left, service_seconds, service_samples) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			  ON CONFLICT (hour) DO UPDATE SET samples = excluded.samples, min_waiting = excluded.min_waiting,
+			  max_waiting = excluded.max_waiting, sum_waiting = excluded.sum_waiting, max_served = excluded.max_served,
+			  min_tickets_left = excluded.min_tickets_left, service_seconds = excluded.service_seconds,
+			  service_samples = excluded.service_samples`
+
+	_, err = tx.ExecContext(ctx, d.dialect.rebind(query), hour, stored.Samples, stored.MinWaiting, stored.MaxWaiting,
+		stored.SumWaiting, stored.MaxServed, stored.MinTicketsLeft, stored.ServiceSeconds, stored.ServiceSamples)
+	if err != nil {
+		return fmt.Errorf("failed to save hourly history: %w", err)
+	}
+	return nil
+}
+
+// getAggregates returns the hourly aggregates since the given time, oldest first
+func (d *Database) getAggregates(ctx context.Context, since time.Time) ([]HourlyAggregate, error) {
+	query := `SELECT hour, samples, min_waiting, max_waiting, sum_waiting, max_served, min_tickets_left,
+			  service_seconds, service_samples FROM history_hourly WHERE hour >= ? ORDER BY hour ASC`
+
+	rows, err := d.query(ctx, query, d.dialect.timestamp(since.Truncate(time.Hour)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hourly history: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []HourlyAggregate
+	for rows.Next() {
+		var hour string
+		var aggregate HourlyAggregate
+		err := rows.Scan(&hour, &aggregate.Samples, &aggregate.MinWaiting, &aggregate.MaxWaiting, &aggregate.SumWaiting,
+			&aggregate.MaxServed, &aggregate.MinTicketsLeft, &aggregate.ServiceSeconds, &aggregate.ServiceSamples)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hourly history: %w", err)
+		}
+		if aggregate.Hour, err = parseTimestamp(hour); err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate hourly history: %w", err)
+	}
+	return aggregates, nil
+}
+
+// CleanOldAggregates removes hourly aggregates older than olderThan and returns the number
+// of removed hours
+func (d *Database) CleanOldAggregates(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-olderThan)
+	query := `DELETE FROM history_hourly WHERE hour < ?`
+
+	result, err := d.exec(ctx, query, d.dialect.timestamp(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean old hourly history: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
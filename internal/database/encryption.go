@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	EncryptionKeySize = 32 // AES-256
+
+	// encryptedPrefix marks encrypted values, stored as enc:<key ID>:<base64 nonce and ciphertext>;
+	// values without it are plaintext written before encryption was enabled
+	encryptedPrefix = "enc:"
+)
+
+// FieldCipher encrypts the personal fields of the users table (usernames, ticket and case
+// numbers) with AES-GCM. Values are written with the current key and read with any
+// configured key, so old keys can be kept until the rows are rotated.
+type FieldCipher struct {
+	currentID string
+	keys      map[string]cipher.AEAD
+}
+
+// NewFieldCipher creates a cipher writing with the key and reading with it and the old keys
+func NewFieldCipher(key []byte, oldKeys [][]byte) (*FieldCipher, error) {
+	c := &FieldCipher{keys: make(map[string]cipher.AEAD)}
+	for i, k := range append([][]byte{key}, oldKeys...) {
+		if len(k) != EncryptionKeySize {
+			return nil, fmt.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(k))
+		}
+
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCM: %w", err)
+		}
+
+		id := keyID(k)
+		if i == 0 {
+			c.currentID = id
+		}
+		c.keys[id] = aead
+	}
+	return c, nil
+}
+
+// keyID identifies a key in stored values without revealing it
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// fieldData binds a ciphertext to its row and column, so values cannot be swapped between them
+func fieldData(chatID int64, field string) []byte {
+	return []byte(field + ":" + strconv.FormatInt(chatID, 10))
+}
+
+// encrypt encrypts a field value with the current key; empty values stay empty
+func (c *FieldCipher) encrypt(chatID int64, field, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	aead := c.keys[c.currentID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), fieldData(chatID, field))
+	return encryptedPrefix + c.currentID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt decrypts a stored field value; plaintext values are returned as they are
+func (c *FieldCipher) decrypt(chatID int64, field, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+
+	id, data, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", fmt.Errorf("malformed encrypted %s of user %d", field, chatID)
+	}
+	aead, ok := c.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%s of user %d is encrypted with unknown key %s", field, chatID, id)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted %s of user %d", field, chatID)
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], fieldData(chatID, field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s of user %d: %w", field, chatID, err)
+	}
+	return string(plain), nil
+}
+
+// SetFieldCipher enables encryption of the personal fields in encryptedColumns; without a
+// cipher they are written as plaintext
+func (d *Database) SetFieldCipher(c *FieldCipher) {
+	d.cipher = c
+}
+
+// sealField prepares a personal field value for storage
+func (d *Database) sealField(chatID int64, field, value string) (string, error) {
+	if d.cipher == nil {
+		return value, nil
+	}
+	return d.cipher.encrypt(chatID, field, value)
+}
+
+// openField reads a stored personal field value
+func (d *Database) openField(chatID int64, field, value string) (string, error) {
+	if d.cipher == nil {
+		if strings.HasPrefix(value, encryptedPrefix) {
+			return "", fmt.Errorf("%s of user %d is encrypted but FIELD_ENCRYPTION_KEY is not set", field, chatID)
+		}
+		return value, nil
+	}
+	return d.cipher.decrypt(chatID, field, value)
+}
+
+// encryptedColumns are the users columns stored encrypted when a cipher is set
+var encryptedColumns = []string{"username", "ticket_number", "case_number"}
+
+// RotateEncryption rewrites the encrypted columns of all users with the current key, which
+// also encrypts values stored before encryption was enabled; it returns the number of
+// rewritten users and of users with personal fields at all
+func (d *Database) RotateEncryption(ctx context.Context) (rotated, withFields int, err error) {
+	if d.cipher == nil {
+		return 0, 0, fmt.Errorf("no encryption key configured")
+	}
+
+	type personalFields struct {
+		chatID int64
+		values []string // In the order of encryptedColumns
+	}
+
+	columns := make([]string, len(encryptedColumns))
+	assignments := make([]string, len(encryptedColumns))
+	for i, column := range encryptedColumns {
+		columns[i] = "COALESCE(" + column + ", '')"
+		assignments[i] = column + " = ?"
+	}
+
+	rows, err := d.query(ctx, `SELECT chat_id, `+strings.Join(columns, ", ")+` FROM users`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query users: %w", err)
+	}
+
+	// All rows are read before updating, so the updates do not wait for the open query
+	var users []personalFields
+	for rows.Next() {
+		user := personalFields{values: make([]string, len(encryptedColumns))}
+		dest := []interface{}{&user.chatID}
+		for i := range user.values {
+			dest = append(dest, &user.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	query := `UPDATE users SET ` + strings.Join(assignments, ", ") + ` WHERE chat_id = ?`
+	for _, user := range users {
+		current, empty := true, true
+		for _, value := range user.values {
+			current = current && d.encryptedWithCurrentKey(value)
+			empty = empty && value == ""
+		}
+		if !empty {
+			withFields++
+		}
+		if current {
+			continue
+		}
+
+		args := make([]interface{}, 0, len(encryptedColumns)+1)
+		for i, column := range encryptedColumns {
+			value, err := d.reseal(user.chatID, column, user.values[i])
+			if err != nil {
+				return rotated, withFields, err
+			}
+			args = append(args, value)
+		}
+
+		if _, err := d.exec(ctx, query, append(args, user.chatID)...); err != nil {
+			return rotated, withFields, fmt.Errorf("failed to rewrite user %d: %w", user.chatID, err)
+		}
+		rotated++
+	}
+
+	log.Printf("Rewrote the personal fields of %d of %d users with key %s", rotated, len(users), d.cipher.currentID)
+	return rotated, withFields, nil
+}
+
+// encryptedWithCurrentKey reports whether a stored value needs no rotation
+func (d *Database) encryptedWithCurrentKey(value string) bool {
+	return value == "" || strings.HasPrefix(value, encryptedPrefix+d.cipher.currentID+":")
+}
+
+// reseal decrypts a stored value and encrypts it with the current key
+func (d *Database) reseal(chatID int64, field, value string) (string, error) {
+	plain, err := d.openField(chatID, field, value)
+	if err != nil {
+		return "", err
+	}
+	return d.sealField(chatID, field, plain)
+}
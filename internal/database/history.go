@@ -0,0 +1,603 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"karta/internal/models"
+)
+
+// historyColumns lists the typed queue_history columns read by scanQueueData
+const historyColumns = `name, served, waiting, workplaces, avg_service_seconds, avg_wait_seconds,
+			  last_ticket, tickets_left, status, last_updated, last_changed, office`
+
+// historyMigrations returns the typed queue_history columns replacing the queue_data JSON blob
+func (d *Database) historyMigrations() []columnMigration {
+	timestamp := d.dialect.timestampType()
+	return []columnMigration{
+		{"queue_history", "name", "TEXT DEFAULT ''"},
+		{"queue_history", "served", "INTEGER"},
+		{"queue_history", "waiting", "INTEGER"},
+		{"queue_history", "workplaces", "INTEGER"},
+		{"queue_history", "avg_service_time", "TEXT DEFAULT ''"},
+		{"queue_history", "avg_wait_time", "TEXT DEFAULT ''"},
+		{"queue_history", "last_ticket", "TEXT DEFAULT ''"},
+		{"queue_history", "tickets_left", "INTEGER"},
+		{"queue_history", "status", "TEXT DEFAULT ''"},
+		{"queue_history", "last_updated", timestamp},
+		{"queue_history", "last_changed", timestamp},
+		{"queue_history", "office", "TEXT DEFAULT ''"},
+		{"queue_history", "avg_service_seconds", "INTEGER"},
+		{"queue_history", "avg_wait_seconds", "INTEGER"},
+	}
+}
+
+// backfillHistory moves queue history stored as JSON into the typed columns and empties
+// the JSON, so analytics queries can use plain SQL
+func (d *Database) backfillHistory(ctx context.Context) error {
+	rows, err := d.query(ctx, `SELECT id, queue_data FROM queue_history WHERE queue_data <> ''`)
+	if err != nil {
+		return fmt.Errorf("failed to query JSON history: %w", err)
+	}
+
+	type legacyRow struct {
+		id   int64
+		data models.QueueData
+	}
+
+	var legacy []legacyRow
+	for rows.Next() {
+		var row legacyRow
+		var jsonData string
+		if err := rows.Scan(&row.id, &jsonData); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan JSON history: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(jsonData), &row.data); err != nil {
+			log.Printf("Skipping unreadable history record %d: %v", row.id, err)
+			continue
+		}
+		legacy = append(legacy, row)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating JSON history: %w", err)
+	}
+
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin history backfill: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := d.dialect.rebind(`UPDATE queue_history SET name = ?, served = ?, waiting = ?, workplaces = ?,
+			  avg_service_seconds = ?, avg_wait_seconds = ?, last_ticket = ?, tickets_left = ?, status = ?,
+			  last_updated = ?, last_changed = ?, office = ?, queue_data = '' WHERE id = ?`)
+
+	for _, row := range legacy {
+		args := append(d.historyValues(&row.data), row.id)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to backfill history record %d: %w", row.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit history backfill: %w", err)
+	}
+
+	log.Printf("Migrated %d history records to typed columns", len(legacy))
+	return nil
+}
+
+// backfillDurations converts average times stored as text like "6 min." into the
+// seconds columns and empties the text
+func (d *Database) backfillDurations(ctx context.Context) error {
+	rows, err := d.query(ctx, `SELECT id, avg_service_time, avg_wait_time FROM queue_history
+			  WHERE avg_service_time <> '' OR avg_wait_time <> ''`)
+	if err != nil {
+		return fmt.Errorf("failed to query text durations: %w", err)
+	}
+
+	type textRow struct {
+		id                    int64
+		serviceTime, waitTime string
+	}
+
+	var legacy []textRow
+	for rows.Next() {
+		var row textRow
+		if err := rows.Scan(&row.id, &row.serviceTime, &row.waitTime); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan text durations: %w", err)
+		}
+		legacy = append(legacy, row)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating text durations: %w", err)
+	}
+
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin duration backfill: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := d.dialect.rebind(`UPDATE queue_history SET avg_service_seconds = COALESCE(avg_service_seconds, ?),
+			  avg_wait_seconds = COALESCE(avg_wait_seconds, ?),
+			  avg_service_time = '', avg_wait_time = '' WHERE id = ?`)
+
+	for _, row := range legacy {
+		serviceTime := nullSeconds(models.ParseServiceTime(row.serviceTime))
+		waitTime := nullSeconds(models.ParseServiceTime(row.waitTime))
+		if _, err := tx.ExecContext(ctx, query, serviceTime, waitTime, row.id); err != nil {
+			return fmt.Errorf("failed to backfill durations of history record %d: %w", row.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit duration backfill: %w", err)
+	}
+
+	log.Printf("Migrated average times of %d history records to seconds", len(legacy))
+	return nil
+}
+
+// historyValues returns the typed column values of queue data in historyColumns order
+func (d *Database) historyValues(q *models.QueueData) []interface{} {
+	return []interface{}{
+		q.Name,
+		q.ServedClients,
+		q.WaitingClients,
+		q.Workplaces,
+		nullSeconds(q.AvgServiceTime),
+		nullSeconds(q.AvgWaitTime),
+		q.LastTicket,
+		q.TicketsLeft,
+		q.Status,
+		d.nullTimestamp(q.LastUpdated),
+		d.nullTimestamp(q.LastChanged),
+		q.Office,
+	}
+}
+
+// scanQueueData scans a row selected with historyColumns
+func scanQueueData(row rowScanner) (*models.QueueData, error) {
+	var q models.QueueData
+	var served, waiting, workplaces, ticketsLeft, serviceTime, waitTime sql.NullInt64
+	var lastUpdated, lastChanged sql.NullTime
+	var office sql.NullString
+
+	err := row.Scan(&q.Name, &served, &waiting, &workplaces, &serviceTime, &waitTime,
+		&q.LastTicket, &ticketsLeft, &q.Status, &lastUpdated, &lastChanged, &office)
+	if err != nil {
+		return nil, err
+	}
+
+	// NULL counts come from history recorded when DUW sent no number
+	q.ServedClients = int(served.Int64)
+	q.WaitingClients = int(waiting.Int64)
+	q.Workplaces = int(workplaces.Int64)
+	q.TicketsLeft = int(ticketsLeft.Int64)
+	q.AvgServiceTime = time.Duration(serviceTime.Int64) * time.Second
+	q.AvgWaitTime = time.Duration(waitTime.Int64) * time.Second
+	q.Office = office.String
+
+	// Timestamps are stored in UTC; messages show them in local time
+	if lastUpdated.Valid {
+		q.LastUpdated = lastUpdated.Time.Local()
+	}
+	if lastChanged.Valid {
+		q.LastChanged = lastChanged.Time.Local()
+	}
+
+	return &q, nil
+}
+
+// SaveQueueHistory saves queue data to history
+func (d *Database) SaveQueueHistory(ctx context.Context, queueData *models.QueueData) error {
+	query := `INSERT INTO queue_history (queue_data, ` + historyColumns + `)
+			  VALUES ('', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := d.exec(ctx, query, d.historyValues(queueData)...)
+	if err != nil {
+		return fmt.Errorf("failed to save queue history: %w", err)
+	}
+
+	return nil
+}
+
+// HistoryRecord is a queue history entry with the time it was recorded
+type HistoryRecord struct {
+	RecordedAt time.Time
+	Queue      *models.QueueData
+}
+
+// ImportQueueHistory stores history entries with their original recording times in one
+// transaction, so a failed import leaves the history unchanged. Entries recorded in the
+// same second as a stored or earlier imported one are skipped as duplicates; the number of
+// imported entries is returned.
+func (d *Database) ImportQueueHistory(ctx context.Context, records []HistoryRecord) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+	defer cancel()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin history import: %w", err)
+	}
+	defer tx.Rollback()
+
+	exists := d.dialect.rebind(`SELECT COUNT(*) FROM queue_history WHERE created_at >= ? AND created_at < ?`)
+	query := d.dialect.rebind(`INSERT INTO queue_history (queue_data, created_at, ` + historyColumns + `)
+			  VALUES ('', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+
+	imported := 0
+	for _, record := range records {
+		second := record.RecordedAt.Truncate(time.Second)
+
+		var count int
+		err := tx.QueryRowContext(ctx, exists, d.dialect.timestamp(second), d.dialect.timestamp(second.Add(time.Second))).Scan(&count)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check history record of %s: %w", record.RecordedAt.Format(time.RFC3339), err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		args := append([]interface{}{d.dialect.timestamp(record.RecordedAt)}, d.historyValues(record.Queue)...)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return 0, fmt.Errorf("failed to import history record of %s: %w", record.RecordedAt.Format(time.RFC3339), err)
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit history import: %w", err)
+	}
+	return imported, nil
+}
+
+// GetLatestQueueData returns the most recent queue data from history
+func (d *Database) GetLatestQueueData(ctx context.Context) (*models.QueueData, error) {
+	query := `SELECT ` + historyColumns + ` FROM queue_history ORDER BY created_at DESC LIMIT 1`
+
+	queueData, err := scanQueueData(d.queryRow(ctx, query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No data found
+		}
+		return nil, fmt.Errorf("failed to query latest queue data: %w", err)
+	}
+
+	return queueData, nil
+}
+
+// GetQueueDataAt returns the last queue history entry recorded in (at-within, at], or nil
+// when there is none, e.g. as the office was closed
+func (d *Database) GetQueueDataAt(ctx context.Context, at time.Time, within time.Duration) (*models.QueueData, error) {
+	query := `SELECT ` + historyColumns + ` FROM queue_history
+			  WHERE created_at <= ? AND created_at > ? ORDER BY created_at DESC LIMIT 1`
+
+	queueData, err := scanQueueData(d.queryRow(ctx, query, d.dialect.timestamp(at), d.dialect.timestamp(at.Add(-within))))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query queue data at %s: %w", at.Format(time.RFC3339), err)
+	}
+
+	return queueData, nil
+}
+
+// GetQueueDataSince returns queue history entries recorded since the given time, oldest first
+func (d *Database) GetQueueDataSince(ctx context.Context, since time.Time) ([]*models.QueueData, error) {
+	query := `SELECT ` + historyColumns + ` FROM queue_history WHERE created_at >= ? ORDER BY created_at ASC`
+	return d.queryQueueData(ctx, query, d.dialect.timestamp(since))
+}
+
+// GetQueueDataBetween returns queue history entries recorded in [from, to), oldest first
+func (d *Database) GetQueueDataBetween(ctx context.Context, from, to time.Time) ([]*models.QueueData, error) {
+	query := `SELECT ` + historyColumns + ` FROM queue_history
+			  WHERE created_at >= ? AND created_at < ? ORDER BY created_at ASC`
+	return d.queryQueueData(ctx, query, d.dialect.timestamp(from), d.dialect.timestamp(to))
+}
+
+// queryQueueData runs a query selecting historyColumns
+func (d *Database) queryQueueData(ctx context.Context, query string, args ...interface{}) ([]*models.QueueData, error) {
+	rows, err := d.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queue history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*models.QueueData
+	for rows.Next() {
+		queueData, err := scanQueueData(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan queue history: %w", err)
+		}
+
+		history = append(history, queueData)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queue history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetTicketsExhaustedTimes returns, for each day since the given time, the first moment
+// tickets left reached zero after the office started serving clients
+func (d *Database) GetTicketsExhaustedTimes(ctx context.Context, since time.Time) ([]time.Time, error) {
+	query := fmt.Sprintf(`SELECT MIN(created_at) FROM queue_history
+			  WHERE created_at >= ? AND tickets_left = 0 AND served > 0
+			  GROUP BY %s
+			  ORDER BY 1`, d.dialect.localPeriod("created_at", periodDay))
+
+	rows, err := d.query(ctx, query, d.dialect.timestamp(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tickets exhausted times: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var createdAt string
+		if err := rows.Scan(&createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tickets exhausted time: %w", err)
+		}
+
+		exhaustedAt, err := parseTimestamp(createdAt)
+		if err != nil {
+			return nil, err
+		}
+
+		times = append(times, exhaustedAt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tickets exhausted times: %w", err)
+	}
+
+	return times, nil
+}
+
+// ExportQueueHistory calls emit for each history entry recorded in [from, to), oldest
+// first, without loading the whole range into memory
+func (d *Database) ExportQueueHistory(ctx context.Context, from, to time.Time, emit func(recordedAt time.Time, queueData *models.QueueData) error) error {
+	query := `SELECT created_at, ` + historyColumns + ` FROM queue_history
+			  WHERE created_at >= ? AND created_at < ? ORDER BY created_at ASC`
+
+	// Exports take as long as the receiver needs, so only the caller's context bounds them
+	rows, err := d.db.QueryContext(ctx, d.dialect.rebind(query), d.dialect.timestamp(from), d.dialect.timestamp(to))
+	if err != nil {
+		return fmt.Errorf("failed to query queue history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var createdAt string
+		queueData, err := scanQueueData(prefixScanner{rows, &createdAt})
+		if err != nil {
+			return fmt.Errorf("failed to scan queue history: %w", err)
+		}
+
+		recordedAt, err := parseTimestamp(createdAt)
+		if err != nil {
+			return err
+		}
+
+		if err := emit(recordedAt.Local(), queueData); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating queue history: %w", err)
+	}
+
+	return nil
+}
+
+// prefixScanner scans a leading column into extra before the columns of the wrapped scan
+type prefixScanner struct {
+	row   rowScanner
+	extra interface{}
+}
+
+func (s prefixScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append([]interface{}{s.extra}, dest...)...)
+}
+
+// CleanOldHistory downsamples queue history older than specified duration into hourly
+// aggregates, removes it and returns the number of removed entries
+func (d *Database) CleanOldHistory(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-olderThan)
+
+	removed, err := d.downsampleHistory(ctx, `created_at < ?`, d.dialect.timestamp(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean old history: %w", err)
+	}
+	return removed, nil
+}
+
+// GetHistoryCount returns the number of queue history entries
+func (d *Database) GetHistoryCount(ctx context.Context) (int, error) {
+	var count int
+	if err := d.queryRow(ctx, `SELECT COUNT(*) FROM queue_history`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count history: %w", err)
+	}
+	return count, nil
+}
+
+// TrimHistory downsamples and removes the oldest queue history entries beyond the newest
+// keep ones and returns the number of removed entries
+func (d *Database) TrimHistory(ctx context.Context, keep int) (int64, error) {
+	count, err := d.GetHistoryCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if count <= keep {
+		return 0, nil
+	}
+
+	condition := `id IN (SELECT id FROM queue_history ORDER BY created_at ASC, id ASC LIMIT ?)`
+
+	removed, err := d.downsampleHistory(ctx, condition, count-keep)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trim history: %w", err)
+	}
+	return removed, nil
+}
+
+// HistoryBucket represents aggregated queue history for a time period (hour or day)
+type HistoryBucket struct {
+	Period         time.Time `json:"period"`
+	Samples        int       `json:"samples"`
+	AvgWaiting     float64   `json:"avg_waiting"`
+	MinWaiting     int       `json:"min_waiting"`
+	MaxWaiting     int       `json:"max_waiting"`
+	MaxServed      int       `json:"max_served"`
+	MinTicketsLeft int       `json:"min_tickets_left"`
+
+	AvgServiceTime time.Duration `json:"avg_service_time"` // Mean reported service time, 0 without reports
+}
+
+// GetHourlyHistory returns queue history aggregated by local hour since the given time
+func (d *Database) GetHourlyHistory(ctx context.Context, since time.Time) ([]HistoryBucket, error) {
+	return d.aggregateHistory(ctx, periodHour, since)
+}
+
+// GetDailyHistory returns queue history aggregated by local day since the given time
+func (d *Database) GetDailyHistory(ctx context.Context, since time.Time) ([]HistoryBucket, error) {
+	return d.aggregateHistory(ctx, periodDay, since)
+}
+
+// aggregateHistory groups queue history into local hour or day buckets, including the
+// hourly aggregates of deleted history
+func (d *Database) aggregateHistory(ctx context.Context, unit period, since time.Time) ([]HistoryBucket, error) {
+	query := fmt.Sprintf(`SELECT %s AS bucket, COUNT(*),
+			  COALESCE(AVG(waiting), 0), COALESCE(MIN(waiting), 0), COALESCE(MAX(waiting), 0),
+			  COALESCE(MAX(served), 0), COALESCE(MIN(tickets_left), 0),
+			  COALESCE(AVG(CASE WHEN avg_service_seconds > 0 THEN avg_service_seconds END), 0)
+			  FROM queue_history
+			  WHERE created_at >= ?
+			  GROUP BY bucket
+			  ORDER BY bucket`, d.dialect.localPeriod("created_at", unit))
+
+	rows, err := d.query(ctx, query, d.dialect.timestamp(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history buckets: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []HistoryBucket
+	for rows.Next() {
+		var bucket HistoryBucket
+		var period string
+		var serviceSeconds float64
+
+		err := rows.Scan(&period, &bucket.Samples, &bucket.AvgWaiting, &bucket.MinWaiting, &bucket.MaxWaiting, &bucket.MaxServed, &bucket.MinTicketsLeft, &serviceSeconds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan history bucket: %w", err)
+		}
+
+		bucket.Period, err = time.ParseInLocation("2006-01-02 15:04:05", period, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse history bucket period %q: %w", period, err)
+		}
+
+		bucket.AvgServiceTime = time.Duration(serviceSeconds * float64(time.Second))
+		buckets = append(buckets, bucket)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating history buckets: %w", err)
+	}
+
+	aggregates, err := d.getAggregates(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return mergeAggregates(buckets, aggregates, unit), nil
+}
+
+// HourOfDayStats represents queue history aggregated by local hour of day across days
+type HourOfDayStats struct {
+	Hour       int     `json:"hour"` // 0-23
+	Days       int     `json:"days"` // Days with samples in this hour
+	AvgWaiting float64 `json:"avg_waiting"`
+	MaxWaiting int     `json:"max_waiting"`
+}
+
+// GetHourOfDayStats returns waiting clients by local hour of day since the given time,
+// e.g. to find the usually quietest hour
+func (d *Database) GetHourOfDayStats(ctx context.Context, since time.Time) ([]HourOfDayStats, error) {
+	query := fmt.Sprintf(`SELECT %s AS hour, COUNT(DISTINCT %s),
+			  COALESCE(AVG(waiting), 0), COALESCE(MAX(waiting), 0)
+			  FROM queue_history
+			  WHERE created_at >= ? AND waiting IS NOT NULL
+			  GROUP BY hour
+			  ORDER BY hour`,
+		d.dialect.localPeriod("created_at", periodHourOfDay), d.dialect.localPeriod("created_at", periodDay))
+
+	rows, err := d.query(ctx, query, d.dialect.timestamp(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hour of day stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []HourOfDayStats
+	for rows.Next() {
+		var hour HourOfDayStats
+		var hourText string
+		if err := rows.Scan(&hourText, &hour.Days, &hour.AvgWaiting, &hour.MaxWaiting); err != nil {
+			return nil, fmt.Errorf("failed to scan hour of day stats: %w", err)
+		}
+
+		if hour.Hour, err = strconv.Atoi(hourText); err != nil {
+			return nil, fmt.Errorf("failed to parse hour %q: %w", hourText, err)
+		}
+
+		stats = append(stats, hour)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hour of day stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// nullTimestamp converts a time to a column value, NULL for the zero time
+func (d *Database) nullTimestamp(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return d.dialect.timestamp(t)
+}
+
+// nullSeconds converts an average time to whole seconds, NULL when it is unknown
+func nullSeconds(d time.Duration) sql.NullInt64 {
+	if d <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(d.Seconds()), Valid: true}
+}
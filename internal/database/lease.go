@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AcquireLease takes or renews the named lease for holder until now plus ttl. It reports
+// false while another holder's lease has not expired yet.
+func (d *Database) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	query := `INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)
+			  ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+			  WHERE leases.holder = excluded.holder OR leases.expires_at < ?`
+
+	result, err := d.exec(ctx, query, name, holder, d.dialect.timestamp(now.Add(ttl)), d.dialect.timestamp(now))
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+
+	acquired, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	return acquired > 0, nil
+}
+
+// ReleaseLease gives up the named lease if holder still holds it
+func (d *Database) ReleaseLease(ctx context.Context, name, holder string) error {
+	query := `DELETE FROM leases WHERE name = ? AND holder = ?`
+
+	if _, err := d.exec(ctx, query, name, holder); err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
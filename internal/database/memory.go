@@ -0,0 +1,843 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory is a Store keeping everything in process memory, for tests and ephemeral demo
+// deployments: nothing survives a restart. It follows the semantics of Database. Safe for
+// concurrent use.
+type Memory struct {
+	mu sync.Mutex
+
+	users      map[int64]*memoryUser
+	lastUserID int64
+
+	history []HistoryRecord                // Oldest first
+	hourly  map[time.Time]*HourlyAggregate // Downsampled deleted history by UTC hour
+
+	outages       []Outage
+	staleMessages []memoryStaleMessage
+	commandUsage  map[string]map[string]int // Day -> command -> count
+	broadcasts    map[string]BroadcastProgress
+
+	notifications      []*memoryNotification
+	lastNotificationID int64
+	leases             map[string]memoryLease
+
+	apiKeys      []APIKey // Oldest first
+	lastAPIKeyID int64
+}
+
+// memoryUser is a user with the columns not exposed by User
+type memoryUser struct {
+	User
+	banned                bool
+	messageID             int
+	pinMessage            bool
+	notifiedAt            time.Time
+	notifications         int
+	notificationResponses int
+}
+
+// memoryStaleMessage is a replaced live status message with the time it was recorded
+type memoryStaleMessage struct {
+	StaleMessage
+	createdAt time.Time
+}
+
+// memoryNotification is an outbox entry with its delivery state
+type memoryNotification struct {
+	OutboxEntry
+	status      string
+	nextAttempt time.Time
+	lastError   string
+	createdAt   time.Time
+}
+
+// memoryLease is a lease held until it expires
+type memoryLease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+var _ Store = (*Memory)(nil)
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		users:        make(map[int64]*memoryUser),
+		hourly:       make(map[time.Time]*HourlyAggregate),
+		commandUsage: make(map[string]map[string]int),
+		broadcasts:   make(map[string]BroadcastProgress),
+		leases:       make(map[string]memoryLease),
+	}
+}
+
+// Ping implements Store; memory is always reachable
+func (m *Memory) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Maintain implements Store; there is nothing to compact
+func (m *Memory) Maintain(ctx context.Context) error {
+	return nil
+}
+
+// Snapshot implements Store; memory cannot be snapshotted to a file
+func (m *Memory) Snapshot(ctx context.Context, path string) error {
+	return fmt.Errorf("database snapshots are not supported by this backend")
+}
+
+// Close implements Store
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Size implements Store; nothing is stored in a database, so a size cap never applies
+func (m *Memory) Size(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// user returns the stored user, or nil if the user is not registered. The caller holds mu.
+func (m *Memory) user(chatID int64) *memoryUser {
+	return m.users[chatID]
+}
+
+// update applies a change to a registered user; unknown users are ignored like an UPDATE
+// matching no rows
+func (m *Memory) update(chatID int64, change func(user *memoryUser)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user := m.user(chatID); user != nil {
+		change(user)
+	}
+}
+
+// copyUser returns a copy of the user safe to hand out
+func copyUser(user *memoryUser) User {
+	copied := user.User
+	copied.SlotServices = append([]string(nil), user.SlotServices...)
+	if len(copied.SlotServices) == 0 {
+		copied.SlotServices = nil
+	}
+	return copied
+}
+
+// AddUser adds a new user or updates an existing one, keeping its settings
+func (m *Memory) AddUser(ctx context.Context, chatID int64, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user := m.user(chatID); user != nil {
+		user.Username = username
+		user.Active = !user.banned
+		user.SendFailures = 0
+		user.QuarantinedUntil = time.Time{}
+	} else {
+		m.lastUserID++
+		m.users[chatID] = &memoryUser{User: User{
+			ID:           m.lastUserID,
+			ChatID:       chatID,
+			Username:     username,
+			JoinedAt:     time.Now().UTC(),
+			Active:       true,
+			TicketsAlert: -1,
+		}}
+	}
+
+	log.Printf("User added/updated: chat_id=%d, username=%s", chatID, username)
+	return nil
+}
+
+// GetActiveUsers returns all active users
+func (m *Memory) GetActiveUsers(ctx context.Context) ([]User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var users []User
+	for _, user := range m.users {
+		if user.Active {
+			users = append(users, copyUser(user))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// GetUser returns a user by chat ID, or nil if the user is not registered
+func (m *Memory) GetUser(ctx context.Context, chatID int64) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := m.user(chatID)
+	if user == nil {
+		return nil, nil
+	}
+	copied := copyUser(user)
+	return &copied, nil
+}
+
+// GetUserCount returns the total number of active users
+func (m *Memory) GetUserCount(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int
+	for _, user := range m.users {
+		if user.Active {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeactivateUser marks a user as inactive
+func (m *Memory) DeactivateUser(ctx context.Context, chatID int64) error {
+	m.update(chatID, func(user *memoryUser) { user.Active = false })
+	log.Printf("User deactivated: chat_id=%d", chatID)
+	return nil
+}
+
+// RecordSendFailure increments the consecutive delivery failures of a user and returns the new count
+func (m *Memory) RecordSendFailure(ctx context.Context, chatID int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := m.user(chatID)
+	if user == nil {
+		return 0, fmt.Errorf("failed to record send failure: user %d not found", chatID)
+	}
+	user.SendFailures++
+	return user.SendFailures, nil
+}
+
+// QuarantineUser excludes a user from broadcasts until the given time
+func (m *Memory) QuarantineUser(ctx context.Context, chatID int64, until time.Time) error {
+	m.update(chatID, func(user *memoryUser) { user.QuarantinedUntil = until })
+	log.Printf("User quarantined: chat_id=%d, until=%s", chatID, until.Format(time.RFC3339))
+	return nil
+}
+
+// ResetSendFailures clears the delivery failure counter and quarantine of a user
+func (m *Memory) ResetSendFailures(ctx context.Context, chatID int64) error {
+	m.update(chatID, func(user *memoryUser) {
+		user.SendFailures = 0
+		user.QuarantinedUntil = time.Time{}
+	})
+	return nil
+}
+
+// SetUserTicketNumber sets the ticket number for a user
+func (m *Memory) SetUserTicketNumber(ctx context.Context, chatID int64, ticketNumber string) error {
+	m.update(chatID, func(user *memoryUser) { user.TicketNumber = ticketNumber })
+	return nil
+}
+
+// GetUserTicketNumber gets the ticket number for an active user
+func (m *Memory) GetUserTicketNumber(ctx context.Context, chatID int64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := m.user(chatID)
+	if user == nil || !user.Active {
+		return "", nil
+	}
+	return user.TicketNumber, nil
+}
+
+// SetUserBanned bans or unbans a user; banned users are deactivated and ignored by the bot
+func (m *Memory) SetUserBanned(ctx context.Context, chatID int64, banned bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := m.user(chatID)
+	if user == nil {
+		return fmt.Errorf("user %d not found", chatID)
+	}
+	user.banned = banned
+	user.Active = !banned
+
+	log.Printf("User ban updated: chat_id=%d, banned=%t", chatID, banned)
+	return nil
+}
+
+// IsUserBanned checks whether a user is banned
+func (m *Memory) IsUserBanned(ctx context.Context, chatID int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := m.user(chatID)
+	return user != nil && user.banned, nil
+}
+
+// SetUserMessageID stores the ID of the live status message for a user (0 clears it)
+func (m *Memory) SetUserMessageID(ctx context.Context, chatID int64, messageID int) error {
+	m.update(chatID, func(user *memoryUser) { user.messageID = messageID })
+	return nil
+}
+
+// GetUserMessageIDs returns live status message IDs of active users keyed by chat ID
+func (m *Memory) GetUserMessageIDs(ctx context.Context) (map[int64]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messageIDs := make(map[int64]int)
+	for chatID, user := range m.users {
+		if user.Active && user.messageID > 0 {
+			messageIDs[chatID] = user.messageID
+		}
+	}
+	return messageIDs, nil
+}
+
+// SetUserPinMessage enables or disables pinning of the live status message for a user
+func (m *Memory) SetUserPinMessage(ctx context.Context, chatID int64, enabled bool) error {
+	m.update(chatID, func(user *memoryUser) { user.pinMessage = enabled })
+	return nil
+}
+
+// GetUserPinMessage checks whether an active user wants the live status message pinned
+func (m *Memory) GetUserPinMessage(ctx context.Context, chatID int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user := m.user(chatID)
+	return user != nil && user.Active && user.pinMessage, nil
+}
+
+// SetUserLanguage sets the preferred message language for a user
+func (m *Memory) SetUserLanguage(ctx context.Context, chatID int64, language string) error {
+	m.update(chatID, func(user *memoryUser) { user.Language = language })
+	return nil
+}
+
+// GetUserLanguage returns the preferred message language for a user (empty if not chosen)
+func (m *Memory) GetUserLanguage(ctx context.Context, chatID int64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user := m.user(chatID); user != nil {
+		return user.Language, nil
+	}
+	return "", nil
+}
+
+// SetUserStatusAlerts enables or disables queue open/close alerts for a user
+func (m *Memory) SetUserStatusAlerts(ctx context.Context, chatID int64, enabled bool) error {
+	m.update(chatID, func(user *memoryUser) { user.StatusAlerts = enabled })
+	return nil
+}
+
+// SetUserTicketsAlert sets the tickets-left threshold for exhaustion alerts (-1 disables them)
+func (m *Memory) SetUserTicketsAlert(ctx context.Context, chatID int64, threshold int) error {
+	m.update(chatID, func(user *memoryUser) { user.TicketsAlert = threshold })
+	return nil
+}
+
+// SetUserOffice sets the office a user follows (empty for the primary office)
+func (m *Memory) SetUserOffice(ctx context.Context, chatID int64, office string) error {
+	m.update(chatID, func(user *memoryUser) { user.Office = office })
+	return nil
+}
+
+// SetUserShareTicket sets whether a user's ticket is counted in other users' queue positions
+func (m *Memory) SetUserShareTicket(ctx context.Context, chatID int64, enabled bool) error {
+	m.update(chatID, func(user *memoryUser) { user.ShareTicket = enabled })
+	return nil
+}
+
+// SetUserTimezone sets the time zone of the times shown to a user (empty for the office time zone)
+func (m *Memory) SetUserTimezone(ctx context.Context, chatID int64, timezone string) error {
+	m.update(chatID, func(user *memoryUser) { user.Timezone = timezone })
+	return nil
+}
+
+// SetUserMutedUntil pauses rolling updates for a user until the given time; the zero time resumes them
+func (m *Memory) SetUserMutedUntil(ctx context.Context, chatID int64, until time.Time) error {
+	m.update(chatID, func(user *memoryUser) { user.MutedUntil = until })
+	return nil
+}
+
+// SetUserWeeklyReport sets whether a user receives the weekly report
+func (m *Memory) SetUserWeeklyReport(ctx context.Context, chatID int64, enabled bool) error {
+	m.update(chatID, func(user *memoryUser) { user.WeeklyReport = enabled })
+	return nil
+}
+
+// SetUserUpdateInterval sets the minimum time between live message updates of a user; zero
+// updates it on every queue change
+func (m *Memory) SetUserUpdateInterval(ctx context.Context, chatID int64, interval time.Duration) error {
+	m.update(chatID, func(user *memoryUser) { user.UpdateInterval = interval.Truncate(time.Second) })
+	return nil
+}
+
+// SetUserSlotServices sets the reservation services a user gets appointment slot alerts for
+func (m *Memory) SetUserSlotServices(ctx context.Context, chatID int64, services []string) error {
+	m.update(chatID, func(user *memoryUser) { user.SlotServices = append([]string(nil), services...) })
+	return nil
+}
+
+// SetUserCaseNumber sets the DUW case whose status is checked for a user, or clears it with
+// an empty number; the last checked status is reset
+func (m *Memory) SetUserCaseNumber(ctx context.Context, chatID int64, caseNumber string) error {
+	m.update(chatID, func(user *memoryUser) {
+		user.CaseNumber = caseNumber
+		user.CaseStatus = ""
+	})
+	return nil
+}
+
+// SetUserCaseStatus records the last checked status of a user's case
+func (m *Memory) SetUserCaseStatus(ctx context.Context, chatID int64, status string) error {
+	m.update(chatID, func(user *memoryUser) { user.CaseStatus = status })
+	return nil
+}
+
+// RecordActivity records that a user used the bot at the given time, as the response to
+// the last notification when it was delivered within NotificationWindow
+func (m *Memory) RecordActivity(ctx context.Context, chatID int64, at time.Time) error {
+	m.update(chatID, func(user *memoryUser) {
+		user.LastSeen = at
+		if !user.notifiedAt.IsZero() && !user.notifiedAt.Before(at.Add(-NotificationWindow)) {
+			user.notificationResponses++
+		}
+		user.notifiedAt = time.Time{}
+	})
+	return nil
+}
+
+// RecordNotification records a notification delivered to a user at the given time
+func (m *Memory) RecordNotification(ctx context.Context, chatID int64, at time.Time) error {
+	m.update(chatID, func(user *memoryUser) {
+		user.notifications++
+		user.notifiedAt = at
+	})
+	return nil
+}
+
+// RecordCommand counts a use of the command on the day of the given time
+func (m *Memory) RecordCommand(ctx context.Context, command string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	day := at.UTC().Format(usageDayLayout)
+	if m.commandUsage[day] == nil {
+		m.commandUsage[day] = make(map[string]int)
+	}
+	m.commandUsage[day][command]++
+	return nil
+}
+
+// GetActivityStats returns the engagement of the users as of the given time
+func (m *Memory) GetActivityStats(ctx context.Context, now time.Time) (ActivityStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stats ActivityStats
+	for _, user := range m.users {
+		stats.Notifications += user.notifications
+		stats.NotificationResponses += user.notificationResponses
+
+		// Users not seen since activity tracking started are neither active nor churned
+		if user.LastSeen.IsZero() {
+			continue
+		}
+		if !user.LastSeen.Before(now.Add(-DailyActivePeriod)) {
+			stats.DailyActive++
+		}
+		if !user.LastSeen.Before(now.Add(-WeeklyActivePeriod)) {
+			stats.WeeklyActive++
+		}
+		if user.Active && user.LastSeen.Before(now.Add(-ChurnPeriod)) {
+			stats.Churned++
+		}
+	}
+	return stats, nil
+}
+
+// CleanOldCommandUsage removes the command counts of days before olderThan ago
+func (m *Memory) CleanOldCommandUsage(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan).UTC().Format(usageDayLayout)
+	var removed int64
+	for day, commands := range m.commandUsage {
+		if day < cutoff {
+			removed += int64(len(commands))
+			delete(m.commandUsage, day)
+		}
+	}
+	return removed, nil
+}
+
+// GetCommandUsage returns how often each command was used since the day of the given
+// time, the most used first
+func (m *Memory) GetCommandUsage(ctx context.Context, since time.Time) ([]CommandUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	totals := make(map[string]int)
+	first := since.UTC().Format(usageDayLayout)
+	for day, commands := range m.commandUsage {
+		if day < first {
+			continue
+		}
+		for command, count := range commands {
+			totals[command] += count
+		}
+	}
+
+	var usage []CommandUsage
+	for command, count := range totals {
+		usage = append(usage, CommandUsage{Command: command, Count: count})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		return usage[i].Command < usage[j].Command
+	})
+	return usage, nil
+}
+
+// StartOutage records an outage that started at the given time, unless one is still open
+func (m *Memory) StartOutage(ctx context.Context, start time.Time, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, outage := range m.outages {
+		if outage.Ongoing() {
+			return nil
+		}
+	}
+	m.outages = append(m.outages, Outage{Start: start, Reason: reason})
+	return nil
+}
+
+// EndOutage ends the open outage at the given time
+func (m *Memory) EndOutage(ctx context.Context, end time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.outages {
+		if m.outages[i].Ongoing() {
+			m.outages[i].End = end
+		}
+	}
+	return nil
+}
+
+// GetOutages returns the outages overlapping [from, to), oldest first
+func (m *Memory) GetOutages(ctx context.Context, from, to time.Time) ([]Outage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var outages []Outage
+	for _, outage := range m.outages {
+		if outage.Start.Before(to) && (outage.Ongoing() || outage.End.After(from)) {
+			outages = append(outages, outage)
+		}
+	}
+	sort.SliceStable(outages, func(i, j int) bool { return outages[i].Start.Before(outages[j].Start) })
+	return outages, nil
+}
+
+// CleanOldOutages removes outages that ended longer ago than olderThan; an ongoing one is kept
+func (m *Memory) CleanOldOutages(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	kept := m.outages[:0]
+	for _, outage := range m.outages {
+		if outage.Ongoing() || !outage.End.Before(cutoff) {
+			kept = append(kept, outage)
+		}
+	}
+
+	removed := int64(len(m.outages) - len(kept))
+	m.outages = kept
+	return removed, nil
+}
+
+// AddStaleMessage records a replaced live status message for deletion
+func (m *Memory) AddStaleMessage(ctx context.Context, chatID int64, messageID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message := StaleMessage{ChatID: chatID, MessageID: messageID}
+	for _, stale := range m.staleMessages {
+		if stale.StaleMessage == message {
+			return nil
+		}
+	}
+	m.staleMessages = append(m.staleMessages, memoryStaleMessage{StaleMessage: message, createdAt: time.Now()})
+	return nil
+}
+
+// GetStaleMessages returns the replaced live status messages not deleted yet, oldest first
+func (m *Memory) GetStaleMessages(ctx context.Context) ([]StaleMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var messages []StaleMessage
+	for _, stale := range m.staleMessages {
+		messages = append(messages, stale.StaleMessage)
+	}
+	return messages, nil
+}
+
+// RemoveStaleMessage forgets a replaced live status message once it is deleted
+func (m *Memory) RemoveStaleMessage(ctx context.Context, chatID int64, messageID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message := StaleMessage{ChatID: chatID, MessageID: messageID}
+	kept := m.staleMessages[:0]
+	for _, stale := range m.staleMessages {
+		if stale.StaleMessage != message {
+			kept = append(kept, stale)
+		}
+	}
+	m.staleMessages = kept
+	return nil
+}
+
+// CleanStaleMessages forgets replaced messages recorded longer ago than olderThan, which
+// can no longer be deleted
+func (m *Memory) CleanStaleMessages(ctx context.Context, olderThan time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	kept := m.staleMessages[:0]
+	for _, stale := range m.staleMessages {
+		if !stale.createdAt.Before(cutoff) {
+			kept = append(kept, stale)
+		}
+	}
+
+	if removed := len(m.staleMessages) - len(kept); removed > 0 {
+		log.Printf("Gave up deleting %d stale messages", removed)
+	}
+	m.staleMessages = kept
+	return nil
+}
+
+// SaveBroadcastProgress stores an interrupted broadcast, replacing the one of the same office
+func (m *Memory) SaveBroadcastProgress(ctx context.Context, progress BroadcastProgress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	progress.Pending = append([]int64(nil), progress.Pending...)
+	m.broadcasts[progress.Office] = progress
+	return nil
+}
+
+// GetBroadcastProgress returns the interrupted broadcast of the office, or nil when there is none
+func (m *Memory) GetBroadcastProgress(ctx context.Context, office string) (*BroadcastProgress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	progress, exists := m.broadcasts[office]
+	if !exists {
+		return nil, nil
+	}
+	progress.Pending = append([]int64(nil), progress.Pending...)
+	return &progress, nil
+}
+
+// DeleteBroadcastProgress forgets the interrupted broadcast of the office
+func (m *Memory) DeleteBroadcastProgress(ctx context.Context, office string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.broadcasts, office)
+	return nil
+}
+
+// EnqueueNotification adds an intent to the outbox, due right away. It reports false when
+// an intent with the same dedup key is already there.
+func (m *Memory) EnqueueNotification(ctx context.Context, entry OutboxEntry) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, notification := range m.notifications {
+		if notification.DedupKey == entry.DedupKey {
+			return false, nil
+		}
+	}
+
+	m.lastNotificationID++
+	entry.ID = m.lastNotificationID
+	entry.Attempts = 0
+	now := time.Now()
+	m.notifications = append(m.notifications, &memoryNotification{OutboxEntry: entry, status: OutboxPending, nextAttempt: now, createdAt: now})
+	return true, nil
+}
+
+// PendingNotifications returns up to limit pending intents due at now, oldest first
+func (m *Memory) PendingNotifications(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var entries []OutboxEntry
+	for _, notification := range m.notifications {
+		if len(entries) == limit {
+			break
+		}
+		if notification.status == OutboxPending && !notification.nextAttempt.After(now) {
+			entries = append(entries, notification.OutboxEntry)
+		}
+	}
+	return entries, nil
+}
+
+// CompleteNotification sets the final status of an intent
+func (m *Memory) CompleteNotification(ctx context.Context, id int64, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, notification := range m.notifications {
+		if notification.ID == id {
+			notification.status = status
+		}
+	}
+	return nil
+}
+
+// SupersedeNotifications marks the pending intents of the same channel, kind and office
+// created before the given entry as superseded by it, including those waiting for a retry
+func (m *Memory) SupersedeNotifications(ctx context.Context, entry OutboxEntry) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var superseded int64
+	for _, notification := range m.notifications {
+		if notification.status == OutboxPending && notification.Channel == entry.Channel && notification.Kind == entry.Kind &&
+			notification.Office == entry.Office && notification.ID < entry.ID {
+			notification.status = OutboxSuperseded
+			superseded++
+		}
+	}
+	return superseded, nil
+}
+
+// RetryNotification records a failed delivery attempt and schedules the next one
+func (m *Memory) RetryNotification(ctx context.Context, id int64, attempts int, next time.Time, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, notification := range m.notifications {
+		if notification.ID == id {
+			notification.Attempts = attempts
+			notification.nextAttempt = next
+			notification.lastError = lastError
+		}
+	}
+	return nil
+}
+
+// DeleteFinishedNotifications removes intents that are no longer pending and were created
+// before the given time
+func (m *Memory) DeleteFinishedNotifications(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.notifications[:0]
+	for _, notification := range m.notifications {
+		if notification.status == OutboxPending || !notification.createdAt.Before(before) {
+			kept = append(kept, notification)
+		}
+	}
+
+	deleted := int64(len(m.notifications) - len(kept))
+	m.notifications = kept
+	return deleted, nil
+}
+
+// CreateAPIKey stores a new API key and returns its ID
+func (m *Memory) CreateAPIKey(ctx context.Context, key APIKey) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, stored := range m.apiKeys {
+		if stored.Hash == key.Hash {
+			return 0, fmt.Errorf("failed to create API key: duplicate key")
+		}
+	}
+
+	m.lastAPIKeyID++
+	key.ID = m.lastAPIKeyID
+	key.CreatedAt = time.Now()
+	m.apiKeys = append(m.apiKeys, key)
+	return key.ID, nil
+}
+
+// GetAPIKey returns the API key with the given hash, or nil if there is none
+func (m *Memory) GetAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range m.apiKeys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListAPIKeys returns all API keys, oldest first
+func (m *Memory) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]APIKey(nil), m.apiKeys...), nil
+}
+
+// DeleteAPIKey revokes an API key and reports whether it existed
+func (m *Memory) DeleteAPIKey(ctx context.Context, id int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, key := range m.apiKeys {
+		if key.ID == id {
+			m.apiKeys = append(m.apiKeys[:i], m.apiKeys[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AcquireLease takes or renews the named lease for holder until now plus ttl. It reports
+// false while another holder's lease has not expired yet.
+func (m *Memory) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if lease, exists := m.leases[name]; exists && lease.holder != holder && !lease.expiresAt.Before(now) {
+		return false, nil
+	}
+	m.leases[name] = memoryLease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// ReleaseLease gives up the named lease if holder still holds it
+func (m *Memory) ReleaseLease(ctx context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lease, exists := m.leases[name]; exists && lease.holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
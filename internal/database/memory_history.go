@@ -0,0 +1,333 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"karta/internal/models"
+)
+
+// SaveQueueHistory saves queue data to history
+func (m *Memory) SaveQueueHistory(ctx context.Context, queueData *models.QueueData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.history = append(m.history, HistoryRecord{RecordedAt: time.Now(), Queue: queueData.Clone()})
+	return nil
+}
+
+// ImportQueueHistory stores history entries with their original recording times, skipping
+// those recorded in the same second as a stored or earlier imported one
+func (m *Memory) ImportQueueHistory(ctx context.Context, records []HistoryRecord) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seconds := make(map[int64]bool, len(m.history))
+	for _, record := range m.history {
+		seconds[record.RecordedAt.Unix()] = true
+	}
+
+	imported := 0
+	for _, record := range records {
+		if seconds[record.RecordedAt.Unix()] {
+			continue
+		}
+		seconds[record.RecordedAt.Unix()] = true
+		m.history = append(m.history, HistoryRecord{RecordedAt: record.RecordedAt, Queue: record.Queue.Clone()})
+		imported++
+	}
+	sort.SliceStable(m.history, func(i, j int) bool { return m.history[i].RecordedAt.Before(m.history[j].RecordedAt) })
+	return imported, nil
+}
+
+// historyBetween returns copies of the entries recorded in [from, to), oldest first; the
+// zero to is open-ended. The caller holds mu.
+func (m *Memory) historyBetween(from, to time.Time) []HistoryRecord {
+	var records []HistoryRecord
+	for _, record := range m.history {
+		if record.RecordedAt.Before(from) || (!to.IsZero() && !record.RecordedAt.Before(to)) {
+			continue
+		}
+		records = append(records, HistoryRecord{RecordedAt: record.RecordedAt, Queue: record.Queue.Clone()})
+	}
+	return records
+}
+
+// queues returns the queue data of the records
+func queues(records []HistoryRecord) []*models.QueueData {
+	var history []*models.QueueData
+	for _, record := range records {
+		history = append(history, record.Queue)
+	}
+	return history
+}
+
+// GetLatestQueueData returns the most recent queue data from history
+func (m *Memory) GetLatestQueueData(ctx context.Context) (*models.QueueData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.history) == 0 {
+		return nil, nil
+	}
+	return m.history[len(m.history)-1].Queue.Clone(), nil
+}
+
+// GetQueueDataAt returns the last queue history entry recorded in (at-within, at], or nil
+// when there is none, e.g. as the office was closed
+func (m *Memory) GetQueueDataAt(ctx context.Context, at time.Time, within time.Duration) (*models.QueueData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.history) - 1; i >= 0; i-- {
+		record := m.history[i]
+		if record.RecordedAt.After(at) {
+			continue
+		}
+		if !record.RecordedAt.After(at.Add(-within)) {
+			break
+		}
+		return record.Queue.Clone(), nil
+	}
+	return nil, nil
+}
+
+// GetQueueDataSince returns queue history entries recorded since the given time, oldest first
+func (m *Memory) GetQueueDataSince(ctx context.Context, since time.Time) ([]*models.QueueData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return queues(m.historyBetween(since, time.Time{})), nil
+}
+
+// GetQueueDataBetween returns queue history entries recorded in [from, to), oldest first
+func (m *Memory) GetQueueDataBetween(ctx context.Context, from, to time.Time) ([]*models.QueueData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return queues(m.historyBetween(from, to)), nil
+}
+
+// GetTicketsExhaustedTimes returns, for each day since the given time, the first moment
+// tickets left reached zero after the office started serving clients
+func (m *Memory) GetTicketsExhaustedTimes(ctx context.Context, since time.Time) ([]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var times []time.Time
+	var lastDay time.Time
+	for _, record := range m.historyBetween(since, time.Time{}) {
+		if record.Queue.TicketsLeft != 0 || record.Queue.ServedClients <= 0 {
+			continue
+		}
+		if day := localPeriodOf(record.RecordedAt, periodDay); !day.Equal(lastDay) {
+			times = append(times, record.RecordedAt.UTC())
+			lastDay = day
+		}
+	}
+	return times, nil
+}
+
+// ExportQueueHistory calls emit for each history entry recorded in [from, to), oldest first
+func (m *Memory) ExportQueueHistory(ctx context.Context, from, to time.Time, emit func(recordedAt time.Time, queueData *models.QueueData) error) error {
+	m.mu.Lock()
+	records := m.historyBetween(from, to)
+	m.mu.Unlock()
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := emit(record.RecordedAt.Local(), record.Queue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CleanOldHistory removes queue history older than specified duration
+func (m *Memory) CleanOldHistory(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	kept := m.history[:0]
+	var removed int64
+	for _, record := range m.history {
+		if !record.RecordedAt.Before(cutoff) {
+			kept = append(kept, record)
+			continue
+		}
+		m.downsample(record)
+		removed++
+	}
+
+	m.history = kept
+	return removed, nil
+}
+
+// GetHistoryCount returns the number of queue history entries
+func (m *Memory) GetHistoryCount(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.history), nil
+}
+
+// TrimHistory removes the oldest queue history entries beyond the newest keep ones
+func (m *Memory) TrimHistory(ctx context.Context, keep int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.history) <= keep {
+		return 0, nil
+	}
+
+	removed := len(m.history) - keep
+	for _, record := range m.history[:removed] {
+		m.downsample(record)
+	}
+	m.history = append([]HistoryRecord(nil), m.history[removed:]...)
+	return int64(removed), nil
+}
+
+// downsample adds a deleted history entry to the aggregate of its hour. The caller holds mu.
+func (m *Memory) downsample(record HistoryRecord) {
+	hour := record.RecordedAt.UTC().Truncate(time.Hour)
+	if m.hourly[hour] == nil {
+		m.hourly[hour] = &HourlyAggregate{Hour: hour}
+	}
+
+	queue := record.Queue
+	m.hourly[hour].add(queue.WaitingClients, queue.ServedClients, queue.TicketsLeft, int64(queue.AvgServiceTime/time.Second))
+}
+
+// CleanOldAggregates removes hourly aggregates older than olderThan
+func (m *Memory) CleanOldAggregates(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	var removed int64
+	for hour := range m.hourly {
+		if hour.Before(cutoff) {
+			delete(m.hourly, hour)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// GetHourlyHistory returns queue history aggregated by local hour since the given time
+func (m *Memory) GetHourlyHistory(ctx context.Context, since time.Time) ([]HistoryBucket, error) {
+	return m.aggregateHistory(periodHour, since), nil
+}
+
+// GetDailyHistory returns queue history aggregated by local day since the given time
+func (m *Memory) GetDailyHistory(ctx context.Context, since time.Time) ([]HistoryBucket, error) {
+	return m.aggregateHistory(periodDay, since), nil
+}
+
+// aggregateHistory groups queue history into local hour or day buckets, including the
+// hourly aggregates of deleted history
+func (m *Memory) aggregateHistory(unit period, since time.Time) []HistoryBucket {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var buckets []HistoryBucket
+	var totalWaiting float64
+	var serviceTime time.Duration
+	var serviceSamples int
+
+	finish := func() {
+		bucket := &buckets[len(buckets)-1]
+		bucket.AvgWaiting = totalWaiting / float64(bucket.Samples)
+		if serviceSamples > 0 {
+			bucket.AvgServiceTime = serviceTime / time.Duration(serviceSamples)
+		}
+	}
+
+	for _, record := range m.historyBetween(since, time.Time{}) {
+		queue := record.Queue
+		period := localPeriodOf(record.RecordedAt, unit)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Period.Equal(period) {
+			if len(buckets) > 0 {
+				finish()
+			}
+			buckets = append(buckets, HistoryBucket{Period: period, MinWaiting: queue.WaitingClients, MaxWaiting: queue.WaitingClients, MaxServed: queue.ServedClients, MinTicketsLeft: queue.TicketsLeft})
+			totalWaiting, serviceTime, serviceSamples = 0, 0, 0
+		}
+
+		bucket := &buckets[len(buckets)-1]
+		bucket.Samples++
+		totalWaiting += float64(queue.WaitingClients)
+		bucket.MinWaiting = min(bucket.MinWaiting, queue.WaitingClients)
+		bucket.MaxWaiting = max(bucket.MaxWaiting, queue.WaitingClients)
+		bucket.MaxServed = max(bucket.MaxServed, queue.ServedClients)
+		bucket.MinTicketsLeft = min(bucket.MinTicketsLeft, queue.TicketsLeft)
+		if queue.AvgServiceTime > 0 {
+			serviceTime += queue.AvgServiceTime
+			serviceSamples++
+		}
+	}
+	if len(buckets) > 0 {
+		finish()
+	}
+
+	first := since.Truncate(time.Hour)
+	var aggregates []HourlyAggregate
+	for hour, aggregate := range m.hourly {
+		if !hour.Before(first) {
+			aggregates = append(aggregates, *aggregate)
+		}
+	}
+	return mergeAggregates(buckets, aggregates, unit)
+}
+
+// GetHourOfDayStats returns waiting clients by local hour of day since the given time,
+// e.g. to find the usually quietest hour
+func (m *Memory) GetHourOfDayStats(ctx context.Context, since time.Time) ([]HourOfDayStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type hourTotals struct {
+		stats   HourOfDayStats
+		days    map[time.Time]bool
+		waiting float64
+		samples int
+	}
+
+	hours := make(map[int]*hourTotals)
+	for _, record := range m.historyBetween(since, time.Time{}) {
+		hour := record.RecordedAt.Local().Hour()
+		totals := hours[hour]
+		if totals == nil {
+			totals = &hourTotals{stats: HourOfDayStats{Hour: hour, MaxWaiting: record.Queue.WaitingClients}, days: make(map[time.Time]bool)}
+			hours[hour] = totals
+		}
+
+		totals.days[localPeriodOf(record.RecordedAt, periodDay)] = true
+		totals.waiting += float64(record.Queue.WaitingClients)
+		totals.samples++
+		totals.stats.MaxWaiting = max(totals.stats.MaxWaiting, record.Queue.WaitingClients)
+	}
+
+	var stats []HourOfDayStats
+	for _, totals := range hours {
+		totals.stats.Days = len(totals.days)
+		totals.stats.AvgWaiting = totals.waiting / float64(totals.samples)
+		stats = append(stats, totals.stats)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Hour < stats[j].Hour })
+	return stats, nil
+}
+
+// localPeriodOf truncates a time to the start of its local hour or day
+func localPeriodOf(t time.Time, unit period) time.Time {
+	local := t.Local()
+	if unit == periodDay {
+		return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+	}
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, time.Local)
+}
@@ -0,0 +1,280 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"karta/internal/models"
+)
+
+// forEachStore runs the test against the in-memory store and a SQLite database, so the
+// memory store is checked to follow the semantics of Database
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemory())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := NewDatabase(filepath.Join(t.TempDir(), "karta.db"))
+		if err != nil {
+			t.Fatalf("failed to open SQLite database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		test(t, db)
+	})
+}
+
+func TestStoreUsers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		if user, err := store.GetUser(ctx, 1); err != nil || user != nil {
+			t.Fatalf("GetUser of an unknown user = %+v, %v, want nil", user, err)
+		}
+
+		for _, chatID := range []int64{1, 2, 3} {
+			if err := store.AddUser(ctx, chatID, "user"); err != nil {
+				t.Fatalf("AddUser failed: %v", err)
+			}
+		}
+		if err := store.SetUserTicketNumber(ctx, 1, "K123"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetUserLanguage(ctx, 1, "pl"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetUserTicketsAlert(ctx, 1, 20); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.DeactivateUser(ctx, 2); err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := store.GetUser(ctx, 1)
+		if err != nil || user == nil {
+			t.Fatalf("GetUser failed: %v", err)
+		}
+		if user.TicketNumber != "K123" || user.Language != "pl" || user.TicketsAlert != 20 || !user.Active {
+			t.Errorf("user = %+v, want the stored settings", user)
+		}
+
+		// Adding a known user again keeps their settings
+		if err := store.AddUser(ctx, 1, "renamed"); err != nil {
+			t.Fatal(err)
+		}
+		if ticket, err := store.GetUserTicketNumber(ctx, 1); err != nil || ticket != "K123" {
+			t.Errorf("ticket after AddUser = %q, %v, want K123", ticket, err)
+		}
+
+		active, err := store.GetActiveUsers(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, user := range active {
+			ids = append(ids, user.ChatID)
+		}
+		if !reflect.DeepEqual(ids, []int64{1, 3}) {
+			t.Errorf("active users = %v, want [1 3]", ids)
+		}
+		if count, err := store.GetUserCount(ctx); err != nil || count != 2 {
+			t.Errorf("GetUserCount = %d, %v, want 2", count, err)
+		}
+	})
+}
+
+func TestStoreBans(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		if banned, err := store.IsUserBanned(ctx, 7); err != nil || banned {
+			t.Fatalf("IsUserBanned of an unknown user = %v, %v", banned, err)
+		}
+		if err := store.AddUser(ctx, 7, "user"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetUserBanned(ctx, 7, true); err != nil {
+			t.Fatal(err)
+		}
+		if banned, err := store.IsUserBanned(ctx, 7); err != nil || !banned {
+			t.Errorf("IsUserBanned after a ban = %v, %v", banned, err)
+		}
+		if err := store.SetUserBanned(ctx, 7, false); err != nil {
+			t.Fatal(err)
+		}
+		if banned, err := store.IsUserBanned(ctx, 7); err != nil || banned {
+			t.Errorf("IsUserBanned after an unban = %v, %v", banned, err)
+		}
+	})
+}
+
+func TestStoreHistory(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		start := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.Local)
+
+		record := func(minutes, served int) HistoryRecord {
+			at := start.Add(time.Duration(minutes) * time.Minute)
+			return HistoryRecord{RecordedAt: at, Queue: &models.QueueData{
+				Name: models.DefaultQueueName, ServedClients: served, Status: models.StatusOpen, LastUpdated: at,
+			}}
+		}
+
+		// Imports skip records of a second already stored
+		imported, err := store.ImportQueueHistory(ctx, []HistoryRecord{record(20, 8), record(0, 1), record(10, 4), record(10, 5)})
+		if err != nil {
+			t.Fatalf("ImportQueueHistory failed: %v", err)
+		}
+		if imported != 3 {
+			t.Errorf("imported %d records, want 3", imported)
+		}
+
+		history, err := store.GetQueueDataBetween(ctx, start, start.Add(15*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var served []int
+		for _, queueData := range history {
+			served = append(served, queueData.ServedClients)
+		}
+		if !reflect.DeepEqual(served, []int{1, 4}) {
+			t.Errorf("served in [9:00, 9:15) = %v, want [1 4] oldest first", served)
+		}
+
+		latest, err := store.GetLatestQueueData(ctx)
+		if err != nil || latest == nil || latest.ServedClients != 8 {
+			t.Errorf("GetLatestQueueData = %+v, %v, want the 9:20 record", latest, err)
+		}
+	})
+}
+
+func TestStoreBroadcastProgress(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		polledAt := time.Date(2026, time.March, 10, 9, 0, 0, 123456789, time.Local)
+
+		if progress, err := store.GetBroadcastProgress(ctx, "Wrocław"); err != nil || progress != nil {
+			t.Fatalf("GetBroadcastProgress without progress = %+v, %v, want nil", progress, err)
+		}
+
+		pending := []int64{3, 1, 2}
+		saved := BroadcastProgress{Office: "Wrocław", PolledAt: polledAt, Pending: pending, SavedAt: time.Now()}
+		if err := store.SaveBroadcastProgress(ctx, saved); err != nil {
+			t.Fatal(err)
+		}
+		pending[0] = 99 // The store keeps its own copy
+
+		// The progress of an alert is stored next to the one of the queue update
+		alert := BroadcastProgress{Office: "Wrocław/status", PolledAt: polledAt, Pending: []int64{5}, SavedAt: time.Now()}
+		if err := store.SaveBroadcastProgress(ctx, alert); err != nil {
+			t.Fatal(err)
+		}
+
+		progress, err := store.GetBroadcastProgress(ctx, "Wrocław")
+		if err != nil || progress == nil {
+			t.Fatalf("GetBroadcastProgress failed: %v", err)
+		}
+		if !progress.PolledAt.Equal(polledAt) || !reflect.DeepEqual(progress.Pending, []int64{3, 1, 2}) {
+			t.Errorf("progress = %+v, want the saved one to the nanosecond", progress)
+		}
+
+		if err := store.DeleteBroadcastProgress(ctx, "Wrocław"); err != nil {
+			t.Fatal(err)
+		}
+		if progress, err := store.GetBroadcastProgress(ctx, "Wrocław"); err != nil || progress != nil {
+			t.Errorf("progress after delete = %+v, %v, want nil", progress, err)
+		}
+		if progress, err := store.GetBroadcastProgress(ctx, "Wrocław/status"); err != nil || progress == nil || !reflect.DeepEqual(progress.Pending, []int64{5}) {
+			t.Errorf("alert progress = %+v, %v, want it kept", progress, err)
+		}
+
+		// Announcements keep their text
+		announcement := BroadcastProgress{Office: "announcement", PolledAt: polledAt, Pending: []int64{7}, Message: "📢 Closed *tomorrow*", SavedAt: time.Now()}
+		if err := store.SaveBroadcastProgress(ctx, announcement); err != nil {
+			t.Fatal(err)
+		}
+		if progress, err := store.GetBroadcastProgress(ctx, "announcement"); err != nil || progress == nil || progress.Message != announcement.Message {
+			t.Errorf("announcement progress = %+v, %v, want its message", progress, err)
+		}
+	})
+}
+
+func TestStoreOutbox(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		entry := func(key string) OutboxEntry {
+			return OutboxEntry{Channel: "telegram", Kind: "broadcast", Office: "Wrocław", DedupKey: key, Payload: "{}"}
+		}
+		for _, key := range []string{"a", "b", "a"} {
+			if _, err := store.EnqueueNotification(ctx, entry(key)); err != nil {
+				t.Fatalf("EnqueueNotification failed: %v", err)
+			}
+		}
+		if added, err := store.EnqueueNotification(ctx, entry("b")); err != nil || added {
+			t.Errorf("EnqueueNotification of a duplicate = %v, %v, want false", added, err)
+		}
+
+		pending, err := store.PendingNotifications(ctx, time.Now().Add(time.Second), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 2 || pending[0].DedupKey != "a" || pending[1].DedupKey != "b" {
+			t.Fatalf("pending = %+v, want a and b, oldest first", pending)
+		}
+
+		// The newer update supersedes the older one
+		if superseded, err := store.SupersedeNotifications(ctx, pending[1]); err != nil || superseded != 1 {
+			t.Errorf("SupersedeNotifications = %d, %v, want 1", superseded, err)
+		}
+
+		// A retry is not due before its time
+		next := time.Now().Add(time.Minute)
+		if err := store.RetryNotification(ctx, pending[1].ID, 1, next, "timeout"); err != nil {
+			t.Fatal(err)
+		}
+		if due, err := store.PendingNotifications(ctx, time.Now().Add(time.Second), 10); err != nil || len(due) != 0 {
+			t.Errorf("due before the retry = %+v, %v, want none", due, err)
+		}
+		due, err := store.PendingNotifications(ctx, next.Add(time.Second), 10)
+		if err != nil || len(due) != 1 || due[0].Attempts != 1 {
+			t.Fatalf("due after the retry time = %+v, %v, want b with 1 attempt", due, err)
+		}
+
+		if err := store.CompleteNotification(ctx, due[0].ID, OutboxDelivered); err != nil {
+			t.Fatal(err)
+		}
+		if deleted, err := store.DeleteFinishedNotifications(ctx, time.Now().Add(time.Hour)); err != nil || deleted != 2 {
+			t.Errorf("DeleteFinishedNotifications = %d, %v, want 2", deleted, err)
+		}
+	})
+}
+
+func TestStoreLeases(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		acquire := func(holder string, want bool) {
+			t.Helper()
+			if acquired, err := store.AcquireLease(ctx, "leader", holder, time.Minute); err != nil || acquired != want {
+				t.Errorf("AcquireLease by %s = %v, %v, want %v", holder, acquired, err, want)
+			}
+		}
+
+		acquire("a", true)
+		acquire("b", false)
+		acquire("a", true) // Renewed by its holder
+
+		if err := store.ReleaseLease(ctx, "leader", "b"); err != nil {
+			t.Fatal(err)
+		}
+		acquire("b", false) // Only the holder releases a lease
+
+		if err := store.ReleaseLease(ctx, "leader", "a"); err != nil {
+			t.Fatal(err)
+		}
+		acquire("b", true)
+	})
+}
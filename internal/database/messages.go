@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// StaleMessage is a replaced live status message still waiting to be deleted from its chat
+type StaleMessage struct {
+	ChatID    int64
+	MessageID int
+}
+
+// AddStaleMessage records a replaced live status message for deletion
+func (d *Database) AddStaleMessage(ctx context.Context, chatID int64, messageID int) error {
+	query := `INSERT INTO stale_messages (chat_id, message_id) VALUES (?, ?)
+			  ON CONFLICT (chat_id, message_id) DO NOTHING`
+
+	if _, err := d.exec(ctx, query, chatID, messageID); err != nil {
+		return fmt.Errorf("failed to add stale message: %w", err)
+	}
+	return nil
+}
+
+// GetStaleMessages returns the replaced live status messages not deleted yet, oldest first
+func (d *Database) GetStaleMessages(ctx context.Context) ([]StaleMessage, error) {
+	query := `SELECT chat_id, message_id FROM stale_messages ORDER BY created_at ASC`
+
+	rows, err := d.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []StaleMessage
+	for rows.Next() {
+		var message StaleMessage
+		if err := rows.Scan(&message.ChatID, &message.MessageID); err != nil {
+			return nil, fmt.Errorf("failed to scan stale message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stale messages: %w", err)
+	}
+	return messages, nil
+}
+
+// RemoveStaleMessage forgets a replaced live status message once it is deleted
+func (d *Database) RemoveStaleMessage(ctx context.Context, chatID int64, messageID int) error {
+	query := `DELETE FROM stale_messages WHERE chat_id = ? AND message_id = ?`
+
+	if _, err := d.exec(ctx, query, chatID, messageID); err != nil {
+		return fmt.Errorf("failed to remove stale message: %w", err)
+	}
+	return nil
+}
+
+// CleanStaleMessages forgets replaced messages recorded longer ago than olderThan, which
+// can no longer be deleted
+func (d *Database) CleanStaleMessages(ctx context.Context, olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan)
+	query := `DELETE FROM stale_messages WHERE created_at < ?`
+
+	result, err := d.exec(ctx, query, d.dialect.timestamp(cutoff))
+	if err != nil {
+		return fmt.Errorf("failed to clean stale messages: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		log.Printf("Gave up deleting %d stale messages", rowsAffected)
+	}
+	return nil
+}
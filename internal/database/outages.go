@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Outage is a period in which the polls of the DUW API kept failing
+type Outage struct {
+	Start  time.Time
+	End    time.Time // Zero while the outage goes on
+	Reason string    // Error of the first failed poll
+}
+
+// Ongoing reports whether the outage has not ended yet
+func (o Outage) Ongoing() bool {
+	return o.End.IsZero()
+}
+
+// Duration returns how long the outage lasted, up to now while it goes on
+func (o Outage) Duration(now time.Time) time.Duration {
+	if o.Ongoing() {
+		return now.Sub(o.Start)
+	}
+	return o.End.Sub(o.Start)
+}
+
+// StartOutage records an outage that started at the given time, unless one is still open
+func (d *Database) StartOutage(ctx context.Context, start time.Time, reason string) error {
+	var open int
+	if err := d.queryRow(ctx, `SELECT COUNT(*) FROM outages WHERE ended_at IS NULL`).Scan(&open); err != nil {
+		return fmt.Errorf("failed to check open outages: %w", err)
+	}
+	if open > 0 {
+		return nil
+	}
+
+	query := `INSERT INTO outages (started_at, reason) VALUES (?, ?)`
+
+	if _, err := d.exec(ctx, query, d.dialect.timestamp(start), reason); err != nil {
+		return fmt.Errorf("failed to start outage: %w", err)
+	}
+	return nil
+}
+
+// EndOutage ends the open outage at the given time
+func (d *Database) EndOutage(ctx context.Context, end time.Time) error {
+	query := `UPDATE outages SET ended_at = ? WHERE ended_at IS NULL`
+
+	if _, err := d.exec(ctx, query, d.dialect.timestamp(end)); err != nil {
+		return fmt.Errorf("failed to end outage: %w", err)
+	}
+	return nil
+}
+
+// GetOutages returns the outages overlapping [from, to), oldest first
+func (d *Database) GetOutages(ctx context.Context, from, to time.Time) ([]Outage, error) {
+	query := `SELECT started_at, ended_at, reason FROM outages
+			  WHERE started_at < ? AND (ended_at IS NULL OR ended_at > ?) ORDER BY started_at ASC`
+
+	rows, err := d.query(ctx, query, d.dialect.timestamp(to), d.dialect.timestamp(from))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outages: %w", err)
+	}
+	defer rows.Close()
+
+	var outages []Outage
+	for rows.Next() {
+		var startedAt string
+		var endedAt, reason sql.NullString
+		if err := rows.Scan(&startedAt, &endedAt, &reason); err != nil {
+			return nil, fmt.Errorf("failed to scan outage: %w", err)
+		}
+
+		outage := Outage{Reason: reason.String}
+		start, err := parseTimestamp(startedAt)
+		if err != nil {
+			return nil, err
+		}
+		outage.Start = start.Local()
+		if endedAt.Valid {
+			end, err := parseTimestamp(endedAt.String)
+			if err != nil {
+				return nil, err
+			}
+			outage.End = end.Local()
+		}
+		outages = append(outages, outage)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outages: %w", err)
+	}
+	return outages, nil
+}
+
+// CleanOldOutages removes outages that ended longer ago than olderThan and returns the
+// number of removed outages; an ongoing one is kept
+func (d *Database) CleanOldOutages(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-olderThan)
+	query := `DELETE FROM outages WHERE ended_at IS NOT NULL AND ended_at < ?`
+
+	result, err := d.exec(ctx, query, d.dialect.timestamp(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean old outages: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
+
+// Downtime returns how much of [from, to) the outages cover, ongoing ones lasting until to
+func Downtime(outages []Outage, from, to time.Time) time.Duration {
+	var downtime time.Duration
+	for _, outage := range outages {
+		start, end := outage.Start, outage.End
+		if outage.Ongoing() || end.After(to) {
+			end = to
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			downtime += end.Sub(start)
+		}
+	}
+	return downtime
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Statuses of notification outbox entries
+const (
+	OutboxPending    = "pending"
+	OutboxDelivered  = "delivered"
+	OutboxSuperseded = "superseded" // A newer queue update of the same channel was delivered instead
+	OutboxFailed     = "failed"     // Given up after too many attempts
+)
+
+// OutboxEntry is a notification intent waiting in the outbox for delivery to one channel
+type OutboxEntry struct {
+	ID       int64
+	Channel  string // Notifier name, e.g. "telegram"
+	Kind     string // "broadcast" or the alert kind
+	Office   string // Office of the queue data
+	DedupKey string // Unique per intent; enqueueing the same key again is a no-op
+	Payload  string // JSON encoded intent
+	Attempts int    // Failed delivery attempts so far
+}
+
+// EnqueueNotification adds an intent to the outbox, due right away. It reports false when
+// an intent with the same dedup key is already there.
+func (d *Database) EnqueueNotification(ctx context.Context, entry OutboxEntry) (bool, error) {
+	query := `INSERT INTO notification_outbox (channel, kind, office, dedup_key, payload, next_attempt)
+			  VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (dedup_key) DO NOTHING`
+
+	result, err := d.exec(ctx, query, entry.Channel, entry.Kind, entry.Office, entry.DedupKey, entry.Payload, d.dialect.timestamp(time.Now()))
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+	return added > 0, nil
+}
+
+// PendingNotifications returns up to limit pending intents due at now, oldest first
+func (d *Database) PendingNotifications(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error) {
+	query := `SELECT id, channel, kind, office, dedup_key, payload, attempts FROM notification_outbox
+			  WHERE status = ? AND next_attempt <= ? ORDER BY id ASC LIMIT ?`
+
+	rows, err := d.query(ctx, query, OutboxPending, d.dialect.timestamp(now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []OutboxEntry
+	for rows.Next() {
+		var entry OutboxEntry
+		if err := rows.Scan(&entry.ID, &entry.Channel, &entry.Kind, &entry.Office, &entry.DedupKey, &entry.Payload, &entry.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// CompleteNotification sets the final status of an intent
+func (d *Database) CompleteNotification(ctx context.Context, id int64, status string) error {
+	query := `UPDATE notification_outbox SET status = ? WHERE id = ?`
+
+	if _, err := d.exec(ctx, query, status, id); err != nil {
+		return fmt.Errorf("failed to complete notification %d: %w", id, err)
+	}
+	return nil
+}
+
+// SupersedeNotifications marks the pending intents of the same channel, kind and office
+// created before the given entry as superseded by it, including those waiting for a retry
+func (d *Database) SupersedeNotifications(ctx context.Context, entry OutboxEntry) (int64, error) {
+	query := `UPDATE notification_outbox SET status = ?
+			  WHERE status = ? AND channel = ? AND kind = ? AND office = ? AND id < ?`
+
+	result, err := d.exec(ctx, query, OutboxSuperseded, OutboxPending, entry.Channel, entry.Kind, entry.Office, entry.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to supersede notifications: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RetryNotification records a failed delivery attempt and schedules the next one
+func (d *Database) RetryNotification(ctx context.Context, id int64, attempts int, next time.Time, lastError string) error {
+	query := `UPDATE notification_outbox SET attempts = ?, next_attempt = ?, last_error = ? WHERE id = ?`
+
+	if _, err := d.exec(ctx, query, attempts, d.dialect.timestamp(next), lastError, id); err != nil {
+		return fmt.Errorf("failed to reschedule notification %d: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteFinishedNotifications removes intents that are no longer pending and were created
+// before the given time
+func (d *Database) DeleteFinishedNotifications(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM notification_outbox WHERE status <> ? AND created_at < ?`
+
+	result, err := d.exec(ctx, query, OutboxPending, d.dialect.timestamp(before))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished notifications: %w", err)
+	}
+	return result.RowsAffected()
+}
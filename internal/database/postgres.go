@@ -0,0 +1,109 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// NewPostgres creates a PostgreSQL database connection from a DSN
+// (e.g. postgres://karta:secret@db:5432/karta) and initializes tables
+func NewPostgres(dsn string) (*Database, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+
+	// Local-time history buckets follow the session time zone; default it to the
+	// application's zone (e.g. TZ=Europe/Warsaw) unless the DSN sets one
+	if _, ok := connConfig.RuntimeParams["timezone"]; !ok {
+		if zone := time.Local.String(); zone != "Local" && zone != "" {
+			connConfig.RuntimeParams["timezone"] = zone
+		}
+	}
+
+	db := stdlib.OpenDB(*connConfig)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	database, err := newDatabase(db, postgresDialect{})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Database initialized successfully at %s:%d/%s", connConfig.Host, connConfig.Port, connConfig.Database)
+	return database, nil
+}
+
+// postgresDialect implements dialect for PostgreSQL, storing timestamps as UTC TIMESTAMP values
+type postgresDialect struct{}
+
+func (postgresDialect) rebind(query string) string {
+	var builder strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+func (postgresDialect) primaryKey() string {
+	return "BIGSERIAL PRIMARY KEY"
+}
+
+func (postgresDialect) createdAt() string {
+	return "TIMESTAMP DEFAULT (now() AT TIME ZONE 'UTC')"
+}
+
+func (postgresDialect) timestampType() string {
+	return "TIMESTAMP"
+}
+
+func (postgresDialect) columnExistsQuery() string {
+	return `SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`
+}
+
+func (postgresDialect) timestamp(t time.Time) interface{} {
+	return t.UTC()
+}
+
+func (postgresDialect) maintenanceQueries() []string {
+	// Autovacuum normally keeps up; this only reclaims space after the history cleanup sooner
+	return []string{"VACUUM (ANALYZE) queue_history", "VACUUM (ANALYZE) users"}
+}
+
+func (postgresDialect) snapshotQuery() string {
+	return "" // Dumped with pg_dump instead
+}
+
+func (postgresDialect) sizeQuery() string {
+	// The history table is counted by its rows, as its dead tuples stay part of the files
+	return `SELECT (pg_database_size(current_database()) - pg_relation_size('queue_history')
+			+ COALESCE((SELECT SUM(pg_column_size(h.*)) FROM queue_history h), 0))::BIGINT`
+}
+
+func (postgresDialect) localPeriod(column string, unit period) string {
+	// AT TIME ZONE 'UTC' turns the stored UTC time into timestamptz, which to_char
+	// renders in the session time zone
+	switch unit {
+	case periodDay:
+		return fmt.Sprintf("to_char(%s AT TIME ZONE 'UTC', 'YYYY-MM-DD 00:00:00')", column)
+	case periodHourOfDay:
+		return fmt.Sprintf("to_char(%s AT TIME ZONE 'UTC', 'HH24')", column)
+	default:
+		return fmt.Sprintf("to_char(%s AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:00:00')", column)
+	}
+}
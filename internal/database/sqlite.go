@@ -2,248 +2,95 @@ package database
 
 import (
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
-
-	"karta/internal/models"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
-// Database represents the SQLite database connection and operations
-type Database struct {
-	db *sql.DB
-}
-
-// User represents a Telegram user in the database
-type User struct {
-	ID           int64     `json:"id"`
-	ChatID       int64     `json:"chat_id"`
-	Username     string    `json:"username"`
-	JoinedAt     time.Time `json:"joined_at"`
-	Active       bool      `json:"active"`
-	TicketNumber string    `json:"ticket_number"` // User's queue ticket number (e.g., "K222")
-}
-
-// QueueHistory represents historical queue data
-type QueueHistory struct {
-	ID        int64             `json:"id"`
-	QueueData *models.QueueData `json:"queue_data"`
-	CreatedAt time.Time         `json:"created_at"`
-}
+const (
+	SQLiteBusyTimeout  = 5 * time.Second // Wait for a lock instead of failing with "database is locked"
+	SQLiteMaxOpenConns = 4               // WAL allows concurrent readers next to the single writer
+)
 
-// NewDatabase creates a new database connection and initializes tables
+// NewDatabase creates a new SQLite database connection and initializes tables
 func NewDatabase(dbPath string) (*Database, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(sqliteDriver, sqliteDSN(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	database := &Database{db: db}
+	db.SetMaxOpenConns(SQLiteMaxOpenConns)
+	db.SetMaxIdleConns(SQLiteMaxOpenConns)
 
-	if err := database.initTables(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	database, err := newDatabase(db, sqliteDialect{})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("Database initialized successfully at %s", dbPath)
 	return database, nil
 }
 
-// Close closes the database connection
-func (d *Database) Close() error {
-	return d.db.Close()
-}
-
-// initTables creates the necessary database tables
-func (d *Database) initTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS users (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			chat_id INTEGER UNIQUE NOT NULL,
-			username TEXT,
-			joined_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			active BOOLEAN DEFAULT 1,
-			ticket_number TEXT DEFAULT ''
-		)`,
-		`CREATE TABLE IF NOT EXISTS queue_history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			queue_data TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_users_chat_id ON users(chat_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_users_active ON users(active)`,
-		`CREATE INDEX IF NOT EXISTS idx_queue_history_created_at ON queue_history(created_at)`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query %s: %w", query, err)
-		}
+// sqliteDSN adds connection settings to the database path: WAL journaling so readers
+// do not block the writer, a busy timeout, foreign keys and immediate write transactions
+// (avoiding lock upgrade deadlocks)
+func sqliteDSN(dbPath string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
 	}
 
-	return nil
+	return dbPath + separator + sqliteParams()
 }
 
-// AddUser adds a new user to the database or updates existing user
-func (d *Database) AddUser(chatID int64, username string) error {
-	query := `INSERT OR REPLACE INTO users (chat_id, username, joined_at, active) 
-			  VALUES (?, ?, COALESCE((SELECT joined_at FROM users WHERE chat_id = ?), CURRENT_TIMESTAMP), 1)`
-
-	_, err := d.db.Exec(query, chatID, username, chatID)
-	if err != nil {
-		return fmt.Errorf("failed to add user: %w", err)
-	}
+// sqliteDialect implements dialect for SQLite, storing timestamps as UTC text
+type sqliteDialect struct{}
 
-	log.Printf("User added/updated: chat_id=%d, username=%s", chatID, username)
-	return nil
+func (sqliteDialect) rebind(query string) string {
+	return query
 }
 
-// GetActiveUsers returns all active users
-func (d *Database) GetActiveUsers() ([]User, error) {
-	query := `SELECT id, chat_id, username, joined_at, active, ticket_number FROM users WHERE active = 1`
-
-	rows, err := d.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query users: %w", err)
-	}
-	defer rows.Close()
-
-	var users []User
-	for rows.Next() {
-		var user User
-		var username sql.NullString
-		var ticketNumber sql.NullString
-
-		err := rows.Scan(&user.ID, &user.ChatID, &username, &user.JoinedAt, &user.Active, &ticketNumber)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan user: %w", err)
-		}
-
-		if username.Valid {
-			user.Username = username.String
-		}
-
-		if ticketNumber.Valid {
-			user.TicketNumber = ticketNumber.String
-		}
-
-		users = append(users, user)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating users: %w", err)
-	}
-
-	return users, nil
+func (sqliteDialect) primaryKey() string {
+	return "INTEGER PRIMARY KEY AUTOINCREMENT"
 }
 
-// DeactivateUser marks a user as inactive
-func (d *Database) DeactivateUser(chatID int64) error {
-	query := `UPDATE users SET active = 0 WHERE chat_id = ?`
-
-	_, err := d.db.Exec(query, chatID)
-	if err != nil {
-		return fmt.Errorf("failed to deactivate user: %w", err)
-	}
-
-	log.Printf("User deactivated: chat_id=%d", chatID)
-	return nil
+func (sqliteDialect) createdAt() string {
+	return "DATETIME DEFAULT CURRENT_TIMESTAMP"
 }
 
-// SaveQueueHistory saves queue data to history
-func (d *Database) SaveQueueHistory(queueData *models.QueueData) error {
-	jsonData, err := json.Marshal(queueData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal queue data: %w", err)
-	}
-
-	query := `INSERT INTO queue_history (queue_data) VALUES (?)`
-
-	_, err = d.db.Exec(query, string(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to save queue history: %w", err)
-	}
-
-	return nil
+func (sqliteDialect) timestampType() string {
+	return "DATETIME"
 }
 
-// GetLatestQueueData returns the most recent queue data from history
-func (d *Database) GetLatestQueueData() (*models.QueueData, error) {
-	query := `SELECT queue_data FROM queue_history ORDER BY created_at DESC LIMIT 1`
-
-	var jsonData string
-	err := d.db.QueryRow(query).Scan(&jsonData)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // No data found
-		}
-		return nil, fmt.Errorf("failed to query latest queue data: %w", err)
-	}
-
-	var queueData models.QueueData
-	if err := json.Unmarshal([]byte(jsonData), &queueData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal queue data: %w", err)
-	}
-
-	return &queueData, nil
+func (sqliteDialect) columnExistsQuery() string {
+	return `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
 }
 
-// CleanOldHistory removes queue history older than specified duration
-func (d *Database) CleanOldHistory(olderThan time.Duration) error {
-	cutoff := time.Now().Add(-olderThan)
-	query := `DELETE FROM queue_history WHERE created_at < ?`
-
-	result, err := d.db.Exec(query, cutoff)
-	if err != nil {
-		return fmt.Errorf("failed to clean old history: %w", err)
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	log.Printf("Cleaned %d old history records", rowsAffected)
-
-	return nil
+func (sqliteDialect) timestamp(t time.Time) interface{} {
+	return formatTimestamp(t)
 }
 
-// GetUserCount returns the total number of active users
-func (d *Database) GetUserCount() (int, error) {
-	query := `SELECT COUNT(*) FROM users WHERE active = 1`
-
-	var count int
-	err := d.db.QueryRow(query).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get user count: %w", err)
-	}
-
-	return count, nil
+func (sqliteDialect) maintenanceQueries() []string {
+	return []string{"VACUUM", "ANALYZE", "PRAGMA wal_checkpoint(TRUNCATE)"}
 }
 
-// SetUserTicketNumber sets the ticket number for a user
-func (d *Database) SetUserTicketNumber(chatID int64, ticketNumber string) error {
-	query := `UPDATE users SET ticket_number = ? WHERE chat_id = ?`
-
-	_, err := d.db.Exec(query, ticketNumber, chatID)
-	if err != nil {
-		return fmt.Errorf("failed to set ticket number: %w", err)
-	}
-
-	return nil
+func (sqliteDialect) snapshotQuery() string {
+	return "VACUUM INTO ?"
 }
 
-// GetUserTicketNumber gets the ticket number for a user
-func (d *Database) GetUserTicketNumber(chatID int64) (string, error) {
-	query := `SELECT ticket_number FROM users WHERE chat_id = ? AND active = 1`
+func (sqliteDialect) sizeQuery() string {
+	return `SELECT (page_count - freelist_count) * page_size
+			FROM pragma_page_count(), pragma_freelist_count(), pragma_page_size()`
+}
 
-	var ticketNumber string
-	err := d.db.QueryRow(query, chatID).Scan(&ticketNumber)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", nil // User not found or not active
-		}
-		return "", fmt.Errorf("failed to get ticket number: %w", err)
+func (sqliteDialect) localPeriod(column string, unit period) string {
+	switch unit {
+	case periodDay:
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d 00:00:00', %s, 'localtime')", column)
+	case periodHourOfDay:
+		return fmt.Sprintf("strftime('%%H', %s, 'localtime')", column)
+	default:
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:00:00', %s, 'localtime')", column)
 	}
-
-	return ticketNumber, nil
 }
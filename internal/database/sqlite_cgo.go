@@ -0,0 +1,19 @@
+//go:build !modernc
+
+package database
+
+import (
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver is the cgo SQLite driver, the default; build with the modernc tag for the
+// pure-Go one
+const sqliteDriver = "sqlite3"
+
+// sqliteParams returns the connection settings in the format of mattn/go-sqlite3
+func sqliteParams() string {
+	return fmt.Sprintf("_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on&_synchronous=NORMAL&_txlock=immediate",
+		SQLiteBusyTimeout.Milliseconds())
+}
@@ -0,0 +1,19 @@
+//go:build modernc
+
+package database
+
+import (
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDriver is the pure-Go SQLite driver, allowing builds with CGO_ENABLED=0
+const sqliteDriver = "sqlite"
+
+// sqliteParams returns the connection settings in the format of modernc.org/sqlite, which
+// applies them as pragmas on every new connection
+func sqliteParams() string {
+	return fmt.Sprintf("_pragma=journal_mode(WAL)&_pragma=busy_timeout(%d)&_pragma=foreign_keys(1)&_pragma=synchronous(NORMAL)&_txlock=immediate",
+		SQLiteBusyTimeout.Milliseconds())
+}
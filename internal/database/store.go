@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"karta/internal/models"
+)
+
+// Store is the persistence interface used by the application, implemented by
+// Database for both SQLite and PostgreSQL and by Memory
+type Store interface {
+	Ping(ctx context.Context) error
+	Maintain(ctx context.Context) error
+	Snapshot(ctx context.Context, path string) error
+	Close() error
+	Size(ctx context.Context) (int64, error)
+
+	// Users
+	AddUser(ctx context.Context, chatID int64, username string) error
+	GetActiveUsers(ctx context.Context) ([]User, error)
+	GetUser(ctx context.Context, chatID int64) (*User, error)
+	GetUserCount(ctx context.Context) (int, error)
+	DeactivateUser(ctx context.Context, chatID int64) error
+	RecordSendFailure(ctx context.Context, chatID int64) (int, error)
+	QuarantineUser(ctx context.Context, chatID int64, until time.Time) error
+	ResetSendFailures(ctx context.Context, chatID int64) error
+
+	// User settings
+	SetUserTicketNumber(ctx context.Context, chatID int64, ticketNumber string) error
+	GetUserTicketNumber(ctx context.Context, chatID int64) (string, error)
+	SetUserBanned(ctx context.Context, chatID int64, banned bool) error
+	IsUserBanned(ctx context.Context, chatID int64) (bool, error)
+	SetUserMessageID(ctx context.Context, chatID int64, messageID int) error
+	GetUserMessageIDs(ctx context.Context) (map[int64]int, error)
+	SetUserPinMessage(ctx context.Context, chatID int64, enabled bool) error
+	GetUserPinMessage(ctx context.Context, chatID int64) (bool, error)
+	SetUserLanguage(ctx context.Context, chatID int64, language string) error
+	GetUserLanguage(ctx context.Context, chatID int64) (string, error)
+	SetUserStatusAlerts(ctx context.Context, chatID int64, enabled bool) error
+	SetUserTicketsAlert(ctx context.Context, chatID int64, threshold int) error
+	SetUserOffice(ctx context.Context, chatID int64, office string) error
+	SetUserShareTicket(ctx context.Context, chatID int64, enabled bool) error
+	SetUserTimezone(ctx context.Context, chatID int64, timezone string) error
+	SetUserMutedUntil(ctx context.Context, chatID int64, until time.Time) error
+	SetUserWeeklyReport(ctx context.Context, chatID int64, enabled bool) error
+	SetUserUpdateInterval(ctx context.Context, chatID int64, interval time.Duration) error
+	SetUserSlotServices(ctx context.Context, chatID int64, services []string) error
+	SetUserCaseNumber(ctx context.Context, chatID int64, caseNumber string) error
+	SetUserCaseStatus(ctx context.Context, chatID int64, status string) error
+
+	// User activity
+	RecordActivity(ctx context.Context, chatID int64, at time.Time) error
+	RecordNotification(ctx context.Context, chatID int64, at time.Time) error
+	RecordCommand(ctx context.Context, command string, at time.Time) error
+	GetActivityStats(ctx context.Context, now time.Time) (ActivityStats, error)
+	GetCommandUsage(ctx context.Context, since time.Time) ([]CommandUsage, error)
+	CleanOldCommandUsage(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	// Queue history
+	SaveQueueHistory(ctx context.Context, queueData *models.QueueData) error
+	ImportQueueHistory(ctx context.Context, records []HistoryRecord) (int, error)
+	GetLatestQueueData(ctx context.Context) (*models.QueueData, error)
+	GetQueueDataAt(ctx context.Context, at time.Time, within time.Duration) (*models.QueueData, error)
+	GetQueueDataSince(ctx context.Context, since time.Time) ([]*models.QueueData, error)
+	GetQueueDataBetween(ctx context.Context, from, to time.Time) ([]*models.QueueData, error)
+	GetTicketsExhaustedTimes(ctx context.Context, since time.Time) ([]time.Time, error)
+	ExportQueueHistory(ctx context.Context, from, to time.Time, emit func(recordedAt time.Time, queueData *models.QueueData) error) error
+	GetHourlyHistory(ctx context.Context, since time.Time) ([]HistoryBucket, error)
+	GetDailyHistory(ctx context.Context, since time.Time) ([]HistoryBucket, error)
+	GetHourOfDayStats(ctx context.Context, since time.Time) ([]HourOfDayStats, error)
+	GetHistoryCount(ctx context.Context) (int, error)
+	CleanOldHistory(ctx context.Context, olderThan time.Duration) (int64, error)
+	TrimHistory(ctx context.Context, keep int) (int64, error)
+	CleanOldAggregates(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	// DUW API outages
+	StartOutage(ctx context.Context, start time.Time, reason string) error
+	EndOutage(ctx context.Context, end time.Time) error
+	GetOutages(ctx context.Context, from, to time.Time) ([]Outage, error)
+	CleanOldOutages(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	// Replaced live status messages waiting to be deleted
+	AddStaleMessage(ctx context.Context, chatID int64, messageID int) error
+	GetStaleMessages(ctx context.Context) ([]StaleMessage, error)
+	RemoveStaleMessage(ctx context.Context, chatID int64, messageID int) error
+	CleanStaleMessages(ctx context.Context, olderThan time.Duration) error
+
+	// Queue update broadcasts interrupted by a shutdown
+	SaveBroadcastProgress(ctx context.Context, progress BroadcastProgress) error
+	GetBroadcastProgress(ctx context.Context, office string) (*BroadcastProgress, error)
+	DeleteBroadcastProgress(ctx context.Context, office string) error
+
+	// Notification outbox
+	EnqueueNotification(ctx context.Context, entry OutboxEntry) (bool, error)
+	PendingNotifications(ctx context.Context, now time.Time, limit int) ([]OutboxEntry, error)
+	CompleteNotification(ctx context.Context, id int64, status string) error
+	SupersedeNotifications(ctx context.Context, entry OutboxEntry) (int64, error)
+	RetryNotification(ctx context.Context, id int64, attempts int, next time.Time, lastError string) error
+	DeleteFinishedNotifications(ctx context.Context, before time.Time) (int64, error)
+
+	// HTTP API keys
+	CreateAPIKey(ctx context.Context, key APIKey) (int64, error)
+	GetAPIKey(ctx context.Context, hash string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	DeleteAPIKey(ctx context.Context, id int64) (bool, error)
+
+	// Leases of the leader election
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+var _ Store = (*Database)(nil)
+
+// Open connects to PostgreSQL when databaseURL is set and to the SQLite file at sqlitePath otherwise
+func Open(databaseURL, sqlitePath string) (*Database, error) {
+	if databaseURL != "" {
+		return NewPostgres(databaseURL)
+	}
+	return NewDatabase(sqlitePath)
+}
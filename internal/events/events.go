@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+
+	"karta/internal/models"
+)
+
+// Kind identifies the type of an event
+type Kind string
+
+const (
+	QueueUpdated     Kind = "queue_updated"     // A poll was accepted, published for every poll
+	QueueOpened      Kind = "queue_opened"      // The queue became available
+	QueueClosed      Kind = "queue_closed"      // The queue became unavailable
+	TicketsDecreased Kind = "tickets_decreased" // Fewer tickets are left than at the previous poll
+	TicketsExhausted Kind = "tickets_exhausted" // The last ticket of the day was issued
+	TicketCalled     Kind = "ticket_called"     // The current ticket moved on
+	UserTicketNear   Kind = "user_ticket_near"  // A user's registered ticket is only a few tickets away
+	QueueMissing     Kind = "queue_missing"     // The monitored queue or its section disappeared from the response
+	QueueFound       Kind = "queue_found"       // The missing queue is back in the response
+)
+
+// Event describes something that happened to the queue. Queue is always set, except for
+// QueueMissing, where it is the last data received; the other fields depend on the kind.
+type Event struct {
+	Kind       Kind
+	Queue      *models.QueueData    // Current queue data
+	Previous   *models.QueueData    // Data of the previous poll, nil on the first one
+	Changes    *models.QueueChanges // Changes since the previous poll
+	Shown      *models.QueueChanges // Changes highlighted in messages, kept until the next change (QueueUpdated)
+	OfficeOpen bool                 // Whether the office is open by its schedule
+
+	ChatID       int64  // User whose ticket is near (UserTicketNear)
+	Ticket       string // The user's ticket (UserTicketNear)
+	TicketsAhead int    // Tickets before the user's ticket (UserTicketNear)
+
+	Err error // Why the queue was not found (QueueMissing)
+}
+
+// Handler reacts to an event within the context of its publisher
+type Handler func(ctx context.Context, event Event)
+
+// Bus delivers published events to the handlers subscribed to their kind
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Kind][]Handler
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[Kind][]Handler)}
+}
+
+// Subscribe registers a handler for the given event kinds. Handlers of a kind run in
+// subscription order.
+func (b *Bus) Subscribe(handler Handler, kinds ...Kind) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, kind := range kinds {
+		b.handlers[kind] = append(b.handlers[kind], handler)
+	}
+}
+
+// HasSubscribers reports whether any handler is subscribed to the kind, so publishers can
+// skip preparing events nobody listens to
+func (b *Bus) HasSubscribers(kind Kind) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.handlers[kind]) > 0
+}
+
+// Publish runs the handlers of the event kind synchronously. A panicking handler is
+// logged and does not keep the others from running.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.Kind]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		run(ctx, handler, event)
+	}
+}
+
+// run calls the handler, recovering from panics
+func run(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event handler for %s panicked: %v\n%s", event.Kind, r, debug.Stack())
+		}
+	}()
+	handler(ctx, event)
+}
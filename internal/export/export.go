@@ -0,0 +1,307 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/models"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+
+	dateLayout = "2006-01-02"
+
+	entryHistory = "history" // Entry kind of queue history records
+	entryOutage  = "outage"  // Entry kind of DUW API outage annotations
+)
+
+// Source streams queue history and lists the DUW API outages in a time range, implemented
+// by database.Store
+type Source interface {
+	ExportQueueHistory(ctx context.Context, from, to time.Time, emit func(recordedAt time.Time, queueData *models.QueueData) error) error
+	GetOutages(ctx context.Context, from, to time.Time) ([]database.Outage, error)
+}
+
+// csvHeader lists the CSV columns
+var csvHeader = []string{
+	"recorded_at", "name", "served_clients", "waiting_clients", "workplaces", "avg_service_time",
+	"avg_wait_time", "last_ticket", "tickets_left", "status", "last_updated", "last_changed",
+}
+
+// csvOutageColumns follow csvHeader: outage rows have the start of the outage as
+// recorded_at and no queue data. Imports do not require them, so older exports still load.
+var csvOutageColumns = []string{"entry", "outage_end", "outage_reason"}
+
+// outageEntry is a DUW API outage in JSON exports, told apart from records by its entry field
+type outageEntry struct {
+	Entry           string     `json:"entry"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"` // Nil while the outage goes on
+	DurationSeconds int        `json:"duration_seconds"`
+	Reason          string     `json:"reason"`
+}
+
+// newOutageEntry returns the JSON form of an outage, lasting until now while it goes on
+func newOutageEntry(outage database.Outage, now time.Time) outageEntry {
+	entry := outageEntry{
+		Entry:           entryOutage,
+		StartedAt:       outage.Start,
+		DurationSeconds: int(outage.Duration(now).Seconds()),
+		Reason:          outage.Reason,
+	}
+	if !outage.Ongoing() {
+		entry.EndedAt = &outage.End
+	}
+	return entry
+}
+
+// outageQueue interleaves the outages with the history entries in time order
+type outageQueue []database.Outage
+
+// due calls emit for the outages starting before the given time and drops them
+func (q *outageQueue) due(before time.Time, emit func(database.Outage) error) error {
+	for len(*q) > 0 && (*q)[0].Start.Before(before) {
+		if err := emit((*q)[0]); err != nil {
+			return err
+		}
+		*q = (*q)[1:]
+	}
+	return nil
+}
+
+// record is a history entry in JSON exports: the queue data with its recording time
+type record struct {
+	RecordedAt time.Time
+	QueueData  *models.QueueData
+}
+
+// recordTime is the field record adds to the JSON form of the queue data
+type recordTime struct {
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// MarshalJSON encodes the record as the queue data object with a leading recorded_at field
+func (r record) MarshalJSON() ([]byte, error) {
+	recordedAt, err := json.Marshal(recordTime{r.RecordedAt})
+	if err != nil {
+		return nil, err
+	}
+	queueData, err := json.Marshal(r.QueueData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Join {"recorded_at":...} and {"name":...} into one object
+	return append(append(recordedAt[:len(recordedAt)-1], ','), queueData[1:]...), nil
+}
+
+// UnmarshalJSON decodes a record written by MarshalJSON
+func (r *record) UnmarshalJSON(data []byte) error {
+	var recordedAt recordTime
+	if err := json.Unmarshal(data, &recordedAt); err != nil {
+		return err
+	}
+
+	var queueData models.QueueData
+	if err := json.Unmarshal(data, &queueData); err != nil {
+		return err
+	}
+
+	r.RecordedAt, r.QueueData = recordedAt.RecordedAt, &queueData
+	return nil
+}
+
+// ParseFormat parses a format name, defaulting to CSV when empty
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q, expected csv or json", value)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName returns the export file name for a range, e.g. karta-2024-05-01-2024-05-07.csv
+func FileName(format Format, from, to time.Time) string {
+	last := to.Add(-time.Nanosecond) // to is exclusive
+	return fmt.Sprintf("karta-%s-%s.%s", from.Format(dateLayout), last.Format(dateLayout), format)
+}
+
+// ParseRange parses from/to bounds given as local dates (2024-05-01, the to date included)
+// or RFC 3339 times. An empty from is the start of today, an empty to is the end of today.
+func ParseRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+
+	if from != "" {
+		t, _, err := parseBound(from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %w", err)
+		}
+		start = t
+	}
+
+	if to != "" {
+		t, isDate, err := parseBound(to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %w", err)
+		}
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+		}
+		end = t
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must be after start")
+	}
+	return start, end, nil
+}
+
+// parseBound parses a local date or an RFC 3339 time and reports whether it was a date
+func parseBound(value string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation(dateLayout, value, time.Local); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("expected YYYY-MM-DD or an RFC 3339 time, got %q", value)
+}
+
+// Write streams the queue history in [from, to) to w in the given format, annotated with
+// the DUW API outages of the range
+func Write(ctx context.Context, w io.Writer, format Format, source Source, from, to time.Time) error {
+	outages, err := source.GetOutages(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to export outages: %w", err)
+	}
+
+	if format == FormatJSON {
+		return writeJSON(ctx, w, source, outageQueue(outages), from, to)
+	}
+	return writeCSV(ctx, w, source, outageQueue(outages), from, to)
+}
+
+// writeCSV writes one row per history entry and outage with times in RFC 3339
+func writeCSV(ctx context.Context, w io.Writer, source Source, outages outageQueue, from, to time.Time) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string{}, csvHeader...), csvOutageColumns...)); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	writeOutage := func(outage database.Outage) error {
+		row := make([]string, len(csvHeader))
+		row[0] = outage.Start.Format(time.RFC3339)
+		return writer.Write(append(row, entryOutage, formatTime(outage.End), outage.Reason))
+	}
+
+	err := source.ExportQueueHistory(ctx, from, to, func(recordedAt time.Time, q *models.QueueData) error {
+		if err := outages.due(recordedAt, writeOutage); err != nil {
+			return err
+		}
+		return writer.Write([]string{
+			recordedAt.Format(time.RFC3339), q.Name, strconv.Itoa(q.ServedClients), strconv.Itoa(q.WaitingClients),
+			strconv.Itoa(q.Workplaces), seconds(q.AvgServiceTime), seconds(q.AvgWaitTime), q.LastTicket,
+			strconv.Itoa(q.TicketsLeft), q.Status,
+			formatTime(q.LastUpdated), formatTime(q.LastChanged),
+			entryHistory, "", "",
+		})
+	})
+	if err == nil {
+		err = outages.due(to, writeOutage)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export history: %w", err)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes a JSON array of history entries and outages, one per line
+func writeJSON(ctx context.Context, w io.Writer, source Source, outages outageQueue, from, to time.Time) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+
+	first := true
+	writeEntry := func(entry interface{}) error {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+
+		separator := ",\n"
+		if first {
+			separator = "\n"
+			first = false
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	now := time.Now()
+	writeOutage := func(outage database.Outage) error {
+		return writeEntry(newOutageEntry(outage, now))
+	}
+
+	err := source.ExportQueueHistory(ctx, from, to, func(recordedAt time.Time, q *models.QueueData) error {
+		if err := outages.due(recordedAt, writeOutage); err != nil {
+			return err
+		}
+		return writeEntry(record{RecordedAt: recordedAt, QueueData: q})
+	})
+	if err == nil {
+		err = outages.due(to, writeOutage)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export history: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "\n]\n"); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// formatTime formats a time as RFC 3339, leaving zero times empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// seconds formats an average time in whole seconds, like JSON exports
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(d.Seconds()))
+}
@@ -0,0 +1,159 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/models"
+)
+
+// Read parses a history export written by Write and calls emit for each history entry in
+// file order, skipping the outage annotations
+func Read(r io.Reader, format Format, emit func(recordedAt time.Time, queueData *models.QueueData) error) error {
+	if format == FormatJSON {
+		return readJSON(r, emit)
+	}
+	return readCSV(r, emit)
+}
+
+// ReadRecords parses a history export into history records in file order
+func ReadRecords(r io.Reader, format Format) ([]database.HistoryRecord, error) {
+	var records []database.HistoryRecord
+	err := Read(r, format, func(recordedAt time.Time, queueData *models.QueueData) error {
+		records = append(records, database.HistoryRecord{RecordedAt: recordedAt, Queue: queueData})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// readCSV reads rows by the column names of the header, so column order does not matter
+func readCSV(r io.Reader, emit func(recordedAt time.Time, queueData *models.QueueData) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range csvHeader {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("CSV header lacks the %s column", name)
+		}
+	}
+
+	entry, hasEntry := columns["entry"]
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if hasEntry && row[entry] == entryOutage {
+			continue
+		}
+
+		recordedAt, queueData, err := parseRow(row, columns)
+		if err != nil {
+			return fmt.Errorf("invalid CSV line %d: %w", line, err)
+		}
+		if err := emit(recordedAt, queueData); err != nil {
+			return err
+		}
+	}
+}
+
+// parseRow parses a CSV row with the given column positions
+func parseRow(row []string, columns map[string]int) (time.Time, *models.QueueData, error) {
+	field := func(name string) string {
+		return row[columns[name]]
+	}
+
+	var parseErr error
+	number := func(name string) int {
+		value, err := strconv.Atoi(field(name))
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return value
+	}
+	timestamp := func(name string) time.Time {
+		if field(name) == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(time.RFC3339, field(name))
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return t.Local()
+	}
+
+	recordedAt := timestamp("recorded_at")
+	queueData := &models.QueueData{
+		Name:           field("name"),
+		ServedClients:  number("served_clients"),
+		WaitingClients: number("waiting_clients"),
+		Workplaces:     number("workplaces"),
+		AvgServiceTime: time.Duration(number("avg_service_time")) * time.Second,
+		AvgWaitTime:    time.Duration(number("avg_wait_time")) * time.Second,
+		LastTicket:     field("last_ticket"),
+		TicketsLeft:    number("tickets_left"),
+		Status:         field("status"),
+		LastUpdated:    timestamp("last_updated"),
+		LastChanged:    timestamp("last_changed"),
+	}
+	if parseErr == nil && recordedAt.IsZero() {
+		parseErr = fmt.Errorf("recorded_at is empty")
+	}
+	return recordedAt, queueData, parseErr
+}
+
+// readJSON reads the array of entries element by element, without loading the whole file
+func readJSON(r io.Reader, emit func(recordedAt time.Time, queueData *models.QueueData) error) error {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return fmt.Errorf("expected a JSON array of history entries")
+	}
+
+	for i := 0; decoder.More(); i++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return fmt.Errorf("invalid history entry %d: %w", i, err)
+		}
+
+		var kind struct {
+			Entry string `json:"entry"`
+		}
+		if err := json.Unmarshal(raw, &kind); err == nil && kind.Entry == entryOutage {
+			continue
+		}
+
+		var entry record
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return fmt.Errorf("invalid history entry %d: %w", i, err)
+		}
+		if entry.RecordedAt.IsZero() {
+			return fmt.Errorf("invalid history entry %d: recorded_at is missing", i)
+		}
+		if err := emit(entry.RecordedAt.Local(), entry.QueueData); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to read JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"karta/internal/apikey"
+)
+
+// SetAPIAuth sets whether the read endpoints require an API key; admin endpoints always do
+func (s *Server) SetAPIAuth(required bool) {
+	s.apiAuth = required
+}
+
+// authorize wraps an endpoint requiring an API key of the scope and applies the rate limit
+// of the key. Read endpoints stay open to requests without a key unless API auth is on.
+func (s *Server) authorize(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := requestKey(r)
+		if key == "" && scope == apikey.ScopeRead && !s.apiAuth {
+			next(w, r)
+			return
+		}
+		// IMPORT_TOKEN is a static admin key from the configuration, without a rate limit
+		if scope == apikey.ScopeAdmin && s.importToken != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.importToken)) == 1 {
+			next(w, r)
+			return
+		}
+		if key == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "API key required", http.StatusUnauthorized)
+			return
+		}
+
+		stored, err := s.db.GetAPIKey(r.Context(), apikey.Hash(key))
+		if err != nil {
+			log.Printf("Failed to check API key: %v", err)
+			http.Error(w, "failed to check API key", http.StatusInternalServerError)
+			return
+		}
+		if stored == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		if !apikey.Allows(stored.Scope, scope) {
+			http.Error(w, "API key lacks the "+scope+" scope", http.StatusForbidden)
+			return
+		}
+
+		if allowed, retryAfter := s.limiter.Allow(stored.ID, stored.RateLimit, time.Now()); !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// requestKey returns the API key of a request, sent as a bearer token, in X-API-Key or, for
+// browser WebSockets that cannot set headers, as the api_key parameter
+func requestKey(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	return strings.TrimSpace(r.URL.Query().Get("api_key"))
+}
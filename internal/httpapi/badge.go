@@ -0,0 +1,140 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"unicode/utf8"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const (
+	BadgeBusyWaiting    = 20 // Waiting clients from which the badge turns yellow
+	BadgeCrowdedWaiting = 50 // Waiting clients from which the badge turns orange
+	BadgeMaxAge         = 60 // Seconds badges may be cached by browsers and image proxies
+)
+
+// badgeColors maps the shields.io color names used by badges to their hex values
+var badgeColors = map[string]string{
+	"green":     "#4c1",
+	"yellow":    "#dfb317",
+	"orange":    "#fe7d37",
+	"lightgrey": "#9f9f9f",
+}
+
+// badgeResponse is the shields.io endpoint schema served by /badge.json
+type badgeResponse struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+	CacheSeconds  int    `json:"cacheSeconds"`
+}
+
+// handleBadgeJSON serves the badge for https://img.shields.io/endpoint?url=..., e.g.
+// /badge.json?lang=pl
+func (s *Server) handleBadgeJSON(w http.ResponseWriter, r *http.Request) {
+	badge, err := s.badge(r.Context(), i18n.OrDefault(r.URL.Query().Get("lang")))
+	if err != nil {
+		log.Printf("Failed to build badge: %v", err)
+		http.Error(w, "failed to build badge", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", BadgeMaxAge))
+	writeJSON(w, badge)
+}
+
+// handleBadgeSVG renders the badge itself in the flat shields.io style, e.g.
+// <img src="/badge.svg?lang=uk">
+func (s *Server) handleBadgeSVG(w http.ResponseWriter, r *http.Request) {
+	badge, err := s.badge(r.Context(), i18n.OrDefault(r.URL.Query().Get("lang")))
+	if err != nil {
+		log.Printf("Failed to build badge: %v", err)
+		http.Error(w, "failed to build badge", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", BadgeMaxAge))
+	fmt.Fprint(w, renderBadge(badge))
+}
+
+// badge reports the waiting clients while the queue is open and the status otherwise
+func (s *Server) badge(ctx context.Context, lang i18n.Language) (badgeResponse, error) {
+	badge := badgeResponse{
+		SchemaVersion: 1,
+		Label:         i18n.T(lang, "badge.label"),
+		Message:       i18n.T(lang, "badge.no_data"),
+		Color:         "lightgrey",
+		CacheSeconds:  BadgeMaxAge,
+	}
+
+	var queueData *models.QueueData
+	var err error
+	if s.queue != nil {
+		queueData, err = s.queue.Current(ctx)
+	} else {
+		queueData, err = s.db.GetLatestQueueData(ctx)
+	}
+	if err != nil {
+		return badge, fmt.Errorf("failed to load queue data: %w", err)
+	}
+	if queueData == nil {
+		return badge, nil
+	}
+
+	if queueData.Status != models.StatusOpen {
+		badge.Message = queueData.LocalizedStatus(lang)
+		return badge, nil
+	}
+
+	badge.Message = i18n.T(lang, "badge.waiting", queueData.WaitingClients)
+	switch {
+	case queueData.WaitingClients >= BadgeCrowdedWaiting:
+		badge.Color = "orange"
+	case queueData.WaitingClients >= BadgeBusyWaiting:
+		badge.Color = "yellow"
+	default:
+		badge.Color = "green"
+	}
+	return badge, nil
+}
+
+// renderBadge draws a two-part badge, sizing the parts by an average Verdana 11px glyph
+// width as shields.io does
+func renderBadge(badge badgeResponse) string {
+	labelWidth := textWidth(badge.Label)
+	messageWidth := textWidth(badge.Message)
+	width := labelWidth + messageWidth
+	label := html.EscapeString(badge.Label)
+	message := html.EscapeString(badge.Message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+<title>%s: %s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+</g>
+</svg>
+`,
+		width, label, message,
+		label, message,
+		width,
+		labelWidth, labelWidth, messageWidth, badgeColors[badge.Color], width,
+		labelWidth/2, label, labelWidth/2, label,
+		labelWidth+messageWidth/2, message, labelWidth+messageWidth/2, message,
+	)
+}
+
+// textWidth estimates the width of a badge part with its padding
+func textWidth(text string) int {
+	return utf8.RuneCountInString(text)*7 + 10
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/prediction"
+)
+
+const (
+	EmbedRefresh      = 30 // Seconds between reloads of the widget
+	MaxEmbedTicketLen = 16
+)
+
+// embedPage is the widget served by /embed, sized for an iframe
+var embedPage = template.Must(template.New("embed").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; padding: 12px; font: 14px/1.5 system-ui, -apple-system, "Segoe UI", sans-serif; color: #222; background: #fff; }
+@media (prefers-color-scheme: dark) { body { color: #eee; background: #1e1e1e; } }
+code { font-size: 13px; }
+</style>
+</head>
+<body>
+{{.Message}}
+</body>
+</html>
+`))
+
+// embedView is the template data of the widget
+type embedView struct {
+	Lang    string
+	Title   string
+	Refresh int
+	Message template.HTML // The status message converted from MarkdownV2, escaped by models.MarkdownToHTML
+}
+
+// SetPredictor sets the predictor giving history-based estimates for tickets in /embed;
+// without one the widget falls back to the current pace
+func (s *Server) SetPredictor(predictor *prediction.Predictor) {
+	s.predictor = predictor
+}
+
+// handleEmbed renders the live status message as an iframe-able page, with the estimate
+// for a ticket when given, e.g. <iframe src="/embed?lang=pl&ticket=K123"></iframe>
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	if s.queue == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	lang := i18n.OrDefault(r.URL.Query().Get("lang"))
+	ticket := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("ticket")))
+	if len(ticket) > MaxEmbedTicketLen {
+		http.Error(w, "invalid ticket", http.StatusBadRequest)
+		return
+	}
+
+	queueData, err := s.queue.Current(r.Context())
+	if err != nil {
+		log.Printf("Failed to get current queue data: %v", err)
+		http.Error(w, "failed to load queue state", http.StatusInternalServerError)
+		return
+	}
+
+	message := i18n.T(lang, "badge.no_data")
+	if queueData != nil {
+		opts := models.MessageOptions{UserTicket: ticket, Language: lang, Estimate: s.estimate(r.Context(), queueData, ticket)}
+		message = queueData.FormatTelegramMessageWithOptions(s.queue.Changes(), opts)
+	}
+
+	view := embedView{
+		Lang:    string(lang),
+		Title:   i18n.T(lang, "badge.label"),
+		Refresh: EmbedRefresh,
+		Message: template.HTML(strings.ReplaceAll(models.MarkdownToHTML(message), "\n", "<br>\n")),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := embedPage.Execute(w, view); err != nil {
+		log.Printf("Failed to render embed widget: %v", err)
+	}
+}
+
+// estimate returns the history-based wait for the ticket, nil when there is none
+func (s *Server) estimate(ctx context.Context, queueData *models.QueueData, ticket string) *models.WaitEstimate {
+	if ticket == "" || s.predictor == nil {
+		return nil
+	}
+	if _, err := queueData.TicketsAhead(ticket); err != nil {
+		return nil // Not a ticket number, the message leaves it out
+	}
+
+	estimate, err := s.predictor.EstimateWait(ctx, queueData, ticket)
+	if err != nil {
+		if !errors.Is(err, prediction.ErrInsufficientHistory) {
+			log.Printf("Failed to estimate wait time for ticket %s: %v", ticket, err)
+		}
+		return nil
+	}
+	return estimate
+}
@@ -0,0 +1,36 @@
+package httpapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"karta/internal/export"
+)
+
+// handleExport streams queue history as CSV or JSON, e.g.
+// /api/export?format=csv&from=2024-05-01&to=2024-05-07 (defaults: csv, today)
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format, err := export.ParseFormat(query.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := export.ParseRange(query.Get("from"), query.Get("to"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName(format, from, to)))
+
+	// Rows are streamed, so a failure after the first write can only be logged
+	if err := export.Write(r.Context(), w, format, s.db, from, to); err != nil {
+		log.Printf("Failed to export history: %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const (
+	FeedWindow   = 24 * time.Hour // History scanned for change events
+	FeedMaxItems = 50
+	FeedCacheTTL = time.Minute
+	FeedLink     = "https://rezerwacje.duw.pl/"
+)
+
+// feedField describes a tracked field shown in feed item titles
+type feedField struct {
+	key   string
+	label string // i18n key
+	value func(q *models.QueueData, lang i18n.Language) string
+}
+
+// feedFields lists the fields that can be compared in message order
+var feedFields = []feedField{
+	{"served_clients", "queue.served", func(q *models.QueueData, _ i18n.Language) string { return strconv.Itoa(q.ServedClients) }},
+	{"waiting_clients", "queue.waiting", func(q *models.QueueData, _ i18n.Language) string { return strconv.Itoa(q.WaitingClients) }},
+	{"workplaces", "queue.workplaces", func(q *models.QueueData, _ i18n.Language) string { return strconv.Itoa(q.Workplaces) }},
+	{"avg_service_time", "queue.avg_time", func(q *models.QueueData, _ i18n.Language) string { return models.FormatServiceTime(q.AvgServiceTime) }},
+	{"avg_wait_time", "queue.avg_wait", func(q *models.QueueData, _ i18n.Language) string { return models.FormatServiceTime(q.AvgWaitTime) }},
+	{"last_ticket", "queue.last_ticket", func(q *models.QueueData, _ i18n.Language) string { return q.LastTicket }},
+	{"tickets_left", "queue.tickets_left", func(q *models.QueueData, _ i18n.Language) string { return strconv.Itoa(q.TicketsLeft) }},
+	{"status", "queue.status", func(q *models.QueueData, lang i18n.Language) string { return q.LocalizedStatus(lang) }},
+}
+
+// rss is the RSS 2.0 document root
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel is the RSS channel with its items, newest first
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem is a single queue change event
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+// rssGUID is an item identifier that is not a link
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// feedCache keeps rendered feeds per language for FeedCacheTTL
+type feedCache struct {
+	mu      sync.Mutex
+	entries map[i18n.Language]cachedFeed
+}
+
+// cachedFeed is a rendered feed with its build time
+type cachedFeed struct {
+	body    []byte
+	builtAt time.Time
+}
+
+// newFeedCache creates an empty feed cache
+func newFeedCache() *feedCache {
+	return &feedCache{entries: make(map[i18n.Language]cachedFeed)}
+}
+
+// handleFeed serves the RSS feed of queue changes, e.g. /feed.rss?lang=pl
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	lang := i18n.OrDefault(r.URL.Query().Get("lang"))
+
+	body, err := s.renderFeed(r.Context(), lang)
+	if err != nil {
+		log.Printf("Failed to render feed: %v", err)
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Write(body)
+}
+
+// renderFeed returns the cached feed for a language, rebuilding it when it is stale
+func (s *Server) renderFeed(ctx context.Context, lang i18n.Language) ([]byte, error) {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	if cached, ok := s.feed.entries[lang]; ok && time.Since(cached.builtAt) < FeedCacheTTL {
+		return cached.body, nil
+	}
+
+	history, err := s.db.GetQueueDataSince(ctx, time.Now().Add(-FeedWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := xml.MarshalIndent(buildFeed(history, s.comparison, lang, time.Now()), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed: %w", err)
+	}
+	body = append([]byte(xml.Header), body...)
+
+	s.feed.entries[lang] = cachedFeed{body: body, builtAt: time.Now()}
+	return body, nil
+}
+
+// SetComparison sets the fields and tolerances deciding which polls make a feed item
+func (s *Server) SetComparison(comparison models.Comparison) {
+	s.comparison = comparison
+}
+
+// buildFeed creates a feed with one item per actual state change in the history (oldest first),
+// skipping polls that returned the same data
+func buildFeed(history []*models.QueueData, comparison models.Comparison, lang i18n.Language, now time.Time) rss {
+	var items []rssItem
+	var previous *models.QueueData
+
+	for _, current := range history {
+		changes := comparison.Compare(previous, current)
+		if previous != nil && changes.HasChanges {
+			items = append(items, feedItem(previous, current, changes, lang))
+		}
+		previous = current
+	}
+
+	// Newest first, limited to FeedMaxItems
+	reversed := make([]rssItem, 0, FeedMaxItems)
+	for i := len(items) - 1; i >= 0 && len(reversed) < FeedMaxItems; i-- {
+		reversed = append(reversed, items[i])
+	}
+
+	// The history belongs to a single office; the latest record names it
+	latest := &models.QueueData{}
+	if len(history) > 0 {
+		latest = history[len(history)-1]
+	}
+
+	title := models.PlainText(latest.Title(lang))
+	return rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         title,
+			Link:          FeedLink,
+			Description:   title,
+			Language:      string(lang),
+			LastBuildDate: now.Format(time.RFC1123Z),
+			Items:         reversed,
+		},
+	}
+}
+
+// feedItem formats a change event with the changed fields in the title and the full status in the description
+func feedItem(previous, current *models.QueueData, changes *models.QueueChanges, lang i18n.Language) rssItem {
+	var parts []string
+	for _, field := range feedFields {
+		if changes.ChangedFields[field.key] {
+			parts = append(parts, fmt.Sprintf("%s: %s → %s", i18n.T(lang, field.label), field.value(previous, lang), field.value(current, lang)))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, current.Name)
+	}
+
+	status := models.PlainText(current.FormatTelegramMessageWithOptions(changes, models.MessageOptions{Language: lang}))
+
+	return rssItem{
+		Title:       strings.Join(parts, ", "),
+		Description: strings.ReplaceAll(html.EscapeString(status), "\n", "<br>"),
+		PubDate:     current.LastUpdated.Format(time.RFC1123Z),
+		GUID: rssGUID{
+			Value: fmt.Sprintf("karta-%d", current.LastUpdated.Unix()),
+		},
+	}
+}
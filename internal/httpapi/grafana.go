@@ -0,0 +1,147 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"karta/internal/models"
+)
+
+// DefaultMaxDataPoints limits the points per series when Grafana does not send maxDataPoints
+const DefaultMaxDataPoints = 1000
+
+// grafanaMetrics are the queue history series served to Grafana, by target name
+var grafanaMetrics = map[string]func(q *models.QueueData) float64{
+	"waiting_clients":  func(q *models.QueueData) float64 { return float64(q.WaitingClients) },
+	"served_clients":   func(q *models.QueueData) float64 { return float64(q.ServedClients) },
+	"workplaces":       func(q *models.QueueData) float64 { return float64(q.Workplaces) },
+	"tickets_left":     func(q *models.QueueData) float64 { return float64(q.TicketsLeft) },
+	"avg_service_time": func(q *models.QueueData) float64 { return q.AvgServiceTime.Seconds() },
+	"avg_wait_time":    func(q *models.QueueData) float64 { return q.AvgWaitTime.Seconds() },
+	"open": func(q *models.QueueData) float64 {
+		if q.Status == models.StatusOpen {
+			return 1
+		}
+		return 0
+	},
+}
+
+// grafanaQuery is the body of a /query request of the Grafana JSON data source
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMs    int64 `json:"intervalMs"`
+	MaxDataPoints int   `json:"maxDataPoints"`
+	Targets       []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+// grafanaSeries is a time series in a /query response: [value, unix milliseconds] pairs
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// handleGrafanaTest answers the connection test of the Grafana JSON data source
+func (s *Server) handleGrafanaTest(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleGrafanaSearch lists the available series for the Grafana query editor
+func (s *Server) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(grafanaMetrics))
+	for name := range grafanaMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writeJSON(w, names)
+}
+
+// handleGrafanaQuery serves the requested series over the stored queue history. Records
+// are grouped into buckets of the query interval, widened to stay within maxDataPoints,
+// and each bucket reports its last value.
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var query grafanaQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, to := query.Range.From, query.Range.To
+	if !to.After(from) {
+		http.Error(w, "invalid query: range end must be after its start", http.StatusBadRequest)
+		return
+	}
+
+	var series []*grafanaSeries
+	var values []func(q *models.QueueData) float64
+	for _, target := range query.Targets {
+		value, ok := grafanaMetrics[target.Target]
+		if !ok {
+			http.Error(w, "unknown target: "+target.Target, http.StatusBadRequest)
+			return
+		}
+		series = append(series, &grafanaSeries{Target: target.Target, Datapoints: [][2]float64{}})
+		values = append(values, value)
+	}
+
+	step := grafanaStep(from, to, query.IntervalMs, query.MaxDataPoints)
+	var bucket time.Time
+	err := s.db.ExportQueueHistory(r.Context(), from, to, func(recordedAt time.Time, q *models.QueueData) error {
+		current := recordedAt.Truncate(step)
+		for i, value := range values {
+			point := [2]float64{value(q), float64(current.UnixMilli())}
+			if current.Equal(bucket) && len(series[i].Datapoints) > 0 {
+				series[i].Datapoints[len(series[i].Datapoints)-1] = point
+			} else {
+				series[i].Datapoints = append(series[i].Datapoints, point)
+			}
+		}
+		bucket = current
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to query history for Grafana: %v", err)
+		http.Error(w, "failed to query history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, series)
+}
+
+// grafanaStep returns the bucket width of a query: the requested interval, at least a
+// second, widened so the range fits in maxDataPoints buckets
+func grafanaStep(from, to time.Time, intervalMs int64, maxDataPoints int) time.Duration {
+	if maxDataPoints <= 0 {
+		maxDataPoints = DefaultMaxDataPoints
+	}
+
+	step := time.Duration(intervalMs) * time.Millisecond
+	if step < time.Second {
+		step = time.Second
+	}
+	if minStep := to.Sub(from) / time.Duration(maxDataPoints); step < minStep {
+		step = minStep
+	}
+	return step
+}
+
+// writeJSON encodes a response body as JSON
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// StaleParseThreshold is the minimum time without a poll (liveness) or a successful
+// parse (readiness) after which the service is reported unhealthy. Slower polling
+// intervals raise it to StaleIntervals intervals.
+const (
+	StaleParseThreshold = 5 * time.Minute
+	StaleIntervals      = 3
+)
+
+// HealthChecks provides component state for /healthz and /readyz. Nil functions are skipped.
+type HealthChecks struct {
+	Telegram    func() error            // Telegram API reachability
+	LastAttempt func() time.Time        // Start of the last poll
+	LastSuccess func() time.Time        // Last successful parse
+	Interval    func() time.Duration    // Current polling interval
+	Restarts    func() map[string]int   // Restarts of the supervised goroutines
+	Cleaned     func() map[string]int64 // Rows deleted by the retention cleanup, by table
+}
+
+// healthResponse is the JSON body of the health endpoints
+type healthResponse struct {
+	Status      string            `json:"status"`
+	Checks      map[string]string `json:"checks"`
+	LastAttempt *time.Time        `json:"last_parse_attempt,omitempty"`
+	LastSuccess *time.Time        `json:"last_successful_parse,omitempty"`
+	Restarts    map[string]int    `json:"restarts,omitempty"`
+	Cleaned     map[string]int64  `json:"cleaned_rows,omitempty"`
+}
+
+// SetHealthChecks sets the component checks used by the health endpoints
+func (s *Server) SetHealthChecks(checks HealthChecks) {
+	s.health = checks
+}
+
+// handleHealthz is the liveness probe: it fails only when the polling loop stalled,
+// so the container gets restarted
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	response := s.newHealthResponse()
+
+	lastAttempt := s.started
+	if response.LastAttempt != nil {
+		lastAttempt = *response.LastAttempt
+	}
+	response.check("parser", s.checkFresh(lastAttempt, "no poll"))
+
+	writeHealth(w, response)
+}
+
+// handleReadyz is the readiness probe: database, Telegram API and a recent successful parse
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	response := s.newHealthResponse()
+
+	response.check("database", errorText(s.db.Ping(r.Context())))
+	if s.health.Telegram != nil {
+		response.check("telegram", errorText(s.health.Telegram()))
+	}
+
+	if response.LastSuccess == nil {
+		response.check("queue_data", "no successful parse yet")
+	} else {
+		response.check("queue_data", s.checkFresh(*response.LastSuccess, "no successful parse"))
+	}
+
+	writeHealth(w, response)
+}
+
+// newHealthResponse creates a passing response with the parse timestamps filled in
+func (s *Server) newHealthResponse() *healthResponse {
+	response := &healthResponse{Status: "ok", Checks: make(map[string]string)}
+
+	if s.health.LastAttempt != nil {
+		if t := s.health.LastAttempt(); !t.IsZero() {
+			response.LastAttempt = &t
+		}
+	}
+	if s.health.LastSuccess != nil {
+		if t := s.health.LastSuccess(); !t.IsZero() {
+			response.LastSuccess = &t
+		}
+	}
+
+	if s.health.Restarts != nil {
+		response.Restarts = s.health.Restarts()
+	}
+	if s.health.Cleaned != nil {
+		response.Cleaned = s.health.Cleaned()
+	}
+
+	return response
+}
+
+// checkFresh reports an error text when the timestamp is older than the stale threshold
+func (s *Server) checkFresh(t time.Time, what string) string {
+	threshold := StaleParseThreshold
+	if s.health.Interval != nil {
+		if byInterval := StaleIntervals * s.health.Interval(); byInterval > threshold {
+			threshold = byInterval
+		}
+	}
+
+	if age := time.Since(t); age > threshold {
+		return what + " for " + age.Round(time.Second).String()
+	}
+	return ""
+}
+
+// check records a check result, failing the response on a non-empty error text
+func (r *healthResponse) check(name, errText string) {
+	if errText == "" {
+		r.Checks[name] = "ok"
+		return
+	}
+	r.Checks[name] = errText
+	r.Status = "fail"
+}
+
+// errorText returns the error message or an empty string for nil
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// writeHealth writes the response as JSON with 200 or 503 status
+func writeHealth(w http.ResponseWriter, response *healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if response.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"log"
+	"net/http"
+
+	"karta/internal/export"
+)
+
+// MaxImportSize limits the body of /api/import
+const MaxImportSize = 64 << 20
+
+// importResponse is the JSON body of a successful import
+type importResponse struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"` // Entries recorded in the same second as stored ones
+}
+
+// SetImportToken sets a static key from the configuration accepted by the admin endpoints
+// besides the admin API keys
+func (s *Server) SetImportToken(token string) {
+	s.importToken = token
+}
+
+// handleImport loads a CSV or JSON history export posted as the body into the history,
+// e.g. curl -H "Authorization: Bearer $KEY" --data-binary @may.csv /api/import?format=csv
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := export.ReadRecords(http.MaxBytesReader(w, r.Body, MaxImportSize), format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	imported, err := s.db.ImportQueueHistory(r.Context(), records)
+	if err != nil {
+		log.Printf("Failed to import history: %v", err)
+		http.Error(w, "failed to import history", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Imported %d history records over HTTP, skipped %d already stored", imported, len(records)-imported)
+	writeJSON(w, importResponse{Imported: imported, Skipped: len(records) - imported})
+}
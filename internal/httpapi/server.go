@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"karta/internal/apikey"
+	"karta/internal/database"
+	"karta/internal/models"
+	"karta/internal/parser"
+	"karta/internal/prediction"
+	"karta/internal/state"
+)
+
+const (
+	ReadHeaderTimeout = 10 * time.Second
+	ShutdownTimeout   = 5 * time.Second
+)
+
+// Server exposes public HTTP endpoints (feeds, exports, health checks) backed by the queue history
+type Server struct {
+	addr    string
+	db      database.Store
+	mux     *http.ServeMux
+	feed    *feedCache
+	health  HealthChecks
+	started time.Time
+
+	upstream    func() (parser.CachedResponse, bool) // Last good DUW API response, nil if not set
+	queue       *state.Queue                         // Current queue served by /api/state, nil if not set
+	stream      *streamHub                           // Clients of /ws receiving the queue updates
+	predictor   *prediction.Predictor                // Ticket estimates of /embed, nil if not set
+	comparison  models.Comparison                    // Fields that make a feed item
+	importToken string                               // Static admin key of the configuration, none when empty
+	apiAuth     bool                                 // Read endpoints require an API key too
+	limiter     *apikey.Limiter                      // Requests of each API key per minute
+
+	botToken      string // Validates the Mini App init data, the app is off when empty
+	primaryOffice string // Office the ticket estimates of the Mini App cover
+}
+
+// NewServer creates an HTTP server listening on addr
+func NewServer(addr string, db database.Store) *Server {
+	s := &Server{
+		addr:    addr,
+		db:      db,
+		mux:     http.NewServeMux(),
+		feed:    newFeedCache(),
+		started: time.Now(),
+		limiter: apikey.NewLimiter(),
+		stream:  newStreamHub(),
+
+		comparison: models.DefaultComparison,
+	}
+
+	s.mux.HandleFunc("/feed.rss", s.handleFeed)
+	s.mux.HandleFunc("/badge.json", s.handleBadgeJSON)
+	s.mux.HandleFunc("/badge.svg", s.handleBadgeSVG)
+	s.mux.HandleFunc("/embed", s.handleEmbed)
+
+	// Telegram Mini App
+	s.mux.HandleFunc("/app/", s.handleWebApp)
+	s.mux.HandleFunc("/app/api/me", s.handleWebAppMe)
+	s.mux.HandleFunc("/app/api/settings", s.handleWebAppSettings)
+	s.mux.HandleFunc("/app/api/chart.png", s.handleWebAppChart)
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/readyz", s.handleReadyz)
+	s.mux.HandleFunc("/api/export", s.authorize(apikey.ScopeRead, s.handleExport))
+	s.mux.HandleFunc("/api/import", s.authorize(apikey.ScopeAdmin, s.handleImport))
+	s.mux.HandleFunc("/api/upstream", s.authorize(apikey.ScopeRead, s.handleUpstream))
+	s.mux.HandleFunc("/api/state", s.authorize(apikey.ScopeRead, s.handleState))
+	s.mux.HandleFunc("/ws", s.authorize(apikey.ScopeRead, s.handleStream))
+
+	// Grafana JSON data source (SimpleJSON protocol)
+	s.mux.HandleFunc("/api/grafana/", s.authorize(apikey.ScopeRead, s.handleGrafanaTest))
+	s.mux.HandleFunc("/api/grafana/search", s.authorize(apikey.ScopeRead, s.handleGrafanaSearch))
+	s.mux.HandleFunc("/api/grafana/query", s.authorize(apikey.ScopeRead, s.handleGrafanaQuery))
+
+	return s
+}
+
+// Start serves HTTP requests until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("HTTP server listening on %s", s.addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err, ok := <-errCh:
+		if ok {
+			return fmt.Errorf("failed to serve HTTP: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.stream.close()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down HTTP server: %w", err)
+	}
+
+	log.Println("HTTP server stopped")
+	return nil
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"karta/internal/models"
+	"karta/internal/state"
+)
+
+// stateResponse is the current queue state served by /api/state
+type stateResponse struct {
+	Queue         *models.QueueData `json:"queue"`
+	LastChanged   *time.Time        `json:"last_changed,omitempty"` // Nil before the first poll
+	ChangedFields []string          `json:"changed_fields"`         // Highlighted until the next change
+}
+
+// SetQueueState sets the current primary office queue served by /api/state and streamed
+// to /ws
+func (s *Server) SetQueueState(queue *state.Queue) {
+	s.queue = queue
+	queue.Subscribe(s.publishUpdate)
+}
+
+// handleState serves the current queue data with the fields changed by the last change
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	if s.queue == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	queueData, err := s.queue.Current(r.Context())
+	if err != nil {
+		log.Printf("Failed to get current queue data: %v", err)
+		http.Error(w, "failed to load queue state", http.StatusInternalServerError)
+		return
+	}
+	if queueData == nil {
+		http.Error(w, "no queue data yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	response := stateResponse{Queue: queueData, ChangedFields: changedFields(s.queue.Changes())}
+	if lastChanged := s.queue.LastChanged(); !lastChanged.IsZero() {
+		response.LastChanged = &lastChanged
+	}
+	writeJSON(w, response)
+}
+
+// changedFields returns the sorted names of the changed fields, empty for no changes
+func changedFields(changes *models.QueueChanges) []string {
+	fields := []string{}
+	if changes == nil {
+		return fields
+	}
+	for field, changed := range changes.ChangedFields {
+		if changed {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
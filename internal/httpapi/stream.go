@@ -0,0 +1,201 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"karta/internal/models"
+	"karta/internal/state"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	MaxStreamClients   = 1000
+	StreamBuffer       = 16 // Messages queued per client before it is dropped as too slow
+	StreamPingInterval = 30 * time.Second
+	StreamWriteTimeout = 10 * time.Second
+)
+
+// streamMessage is a queue state pushed to the clients of /ws: "state" right after
+// connecting, then "update" for every accepted poll
+type streamMessage struct {
+	Type          string            `json:"type"`
+	Queue         *models.QueueData `json:"queue"`
+	LastChanged   *time.Time        `json:"last_changed,omitempty"`
+	ChangedFields []string          `json:"changed_fields"`    // Highlighted until the next change
+	Changed       []string          `json:"changed,omitempty"` // Changed by this poll
+	Deltas        map[string]int    `json:"deltas,omitempty"`  // New minus old value of changed numeric fields
+}
+
+// streamHub fans the queue updates out to the connected clients. A client that cannot
+// keep up is disconnected rather than slowing down the monitor.
+type streamHub struct {
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+	closed  bool
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{clients: make(map[chan []byte]struct{})}
+}
+
+// add registers a client, or returns nil when the hub is closed or full
+func (h *streamHub) add() chan []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed || len(h.clients) >= MaxStreamClients {
+		return nil
+	}
+	client := make(chan []byte, StreamBuffer)
+	h.clients[client] = struct{}{}
+	return client
+}
+
+// remove unregisters a client unless it was already dropped
+func (h *streamHub) remove(client chan []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client)
+	}
+}
+
+// publish queues a message for every client without blocking
+func (h *streamHub) publish(message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		select {
+		case client <- message:
+		default:
+			log.Printf("WARNING: Dropping a /ws client that fell %d messages behind", StreamBuffer)
+			delete(h.clients, client)
+			close(client)
+		}
+	}
+}
+
+// close disconnects all clients and refuses new ones, as the HTTP server shutdown does not
+// cover hijacked connections
+func (h *streamHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for client := range h.clients {
+		delete(h.clients, client)
+		close(client)
+	}
+}
+
+// publishUpdate is the queue state subscriber feeding the hub
+func (s *Server) publishUpdate(update state.Update) {
+	message := streamMessage{
+		Type:          "update",
+		Queue:         update.Queue,
+		ChangedFields: changedFields(update.Shown),
+	}
+	if !update.Queue.LastChanged.IsZero() {
+		message.LastChanged = &update.Queue.LastChanged
+	}
+	if update.Changes != nil && update.Changes.HasChanges {
+		message.Changed = changedFields(update.Changes)
+		message.Deltas = update.Changes.Deltas
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Failed to encode queue update: %v", err)
+		return
+	}
+	s.stream.publish(data)
+}
+
+// handleStream upgrades to a WebSocket that receives the current queue state and then
+// every update, e.g. new WebSocket("ws://localhost:8080/ws")
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if s.queue == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	client := s.stream.add()
+	if client == nil {
+		http.Error(w, "too many stream clients", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.stream.remove(client)
+
+	// Dashboards on any origin may read the queue, so the origin is not checked
+	websocket.Server{Handler: func(conn *websocket.Conn) {
+		s.serveStream(conn, client)
+	}}.ServeHTTP(w, r)
+}
+
+// serveStream writes the messages of a client to its connection until either side closes
+func (s *Server) serveStream(conn *websocket.Conn, client chan []byte) {
+	// Clients only send control frames; reading handles them and notices a closed connection
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var discard []byte
+		for websocket.Message.Receive(conn, &discard) == nil {
+		}
+	}()
+
+	if err := s.sendState(conn); err != nil {
+		return
+	}
+
+	conn.PayloadType = websocket.PingFrame // Only used by the pings, messages are text frames
+	ping := time.NewTicker(StreamPingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case message, ok := <-client:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(StreamWriteTimeout))
+			err = websocket.Message.Send(conn, string(message))
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(StreamWriteTimeout))
+			_, err = conn.Write(nil)
+		case <-gone:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// sendState sends the current queue state the way /api/state reports it
+func (s *Server) sendState(conn *websocket.Conn) error {
+	queueData, err := s.queue.Current(conn.Request().Context())
+	if err != nil {
+		log.Printf("Failed to get current queue data: %v", err)
+		return err
+	}
+	if queueData == nil {
+		return nil // No data yet, the first poll comes as an update
+	}
+
+	message := streamMessage{Type: "state", Queue: queueData, ChangedFields: changedFields(s.queue.Changes())}
+	if lastChanged := s.queue.LastChanged(); !lastChanged.IsZero() {
+		message.LastChanged = &lastChanged
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(StreamWriteTimeout))
+	return websocket.JSON.Send(conn, message)
+}
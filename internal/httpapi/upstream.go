@@ -0,0 +1,40 @@
+package httpapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"karta/internal/parser"
+)
+
+// SetUpstreamCache sets the source of the last good DUW API response served by /api/upstream
+func (s *Server) SetUpstreamCache(cached func() (parser.CachedResponse, bool)) {
+	s.upstream = cached
+}
+
+// handleUpstream serves the last good DUW API response, so dashboards keep working while
+// upstream is temporarily down. X-Cache-Age tells how long ago upstream confirmed it.
+func (s *Server) handleUpstream(w http.ResponseWriter, r *http.Request) {
+	if s.upstream == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	cached, ok := s.upstream()
+	if !ok {
+		http.Error(w, "no upstream response yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	contentType := cached.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Cache-Age", fmt.Sprintf("%.0f", time.Since(cached.FetchedAt).Seconds()))
+
+	// ServeContent answers conditional requests from the fetch time
+	http.ServeContent(w, r, "", cached.FetchedAt, bytes.NewReader(cached.Body))
+}
@@ -0,0 +1,293 @@
+package httpapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"karta/internal/chart"
+	"karta/internal/database"
+	"karta/internal/i18n"
+	"karta/internal/models"
+	"karta/internal/webapp"
+)
+
+// MaxSettingsSize limits the body of /app/api/settings
+const MaxSettingsSize = 4 << 10
+
+// webAppPage is the Telegram Mini App served at /app/; it calls the /app/api/ endpoints
+// with the init data Telegram passes to it
+//
+//go:embed webapp.html
+var webAppPage []byte
+
+// webAppLabels are the i18n keys of the texts of the Mini App, sent with its data
+var webAppLabels = []string{
+	"app.language", "app.alerts", "app.weekly", "app.threshold", "app.off",
+	"app.ticket", "app.save", "app.saved", "app.failed", "app.chart",
+}
+
+// appSettings are the settings of a user the Mini App shows and changes
+type appSettings struct {
+	Language     string `json:"language"`
+	StatusAlerts bool   `json:"status_alerts"`
+	WeeklyReport bool   `json:"weekly_report"`
+	TicketsAlert int    `json:"tickets_alert"` // -1 = off
+	Ticket       string `json:"ticket"`
+}
+
+// settingsUpdate is the body of /app/api/settings; fields left out stay as they are
+type settingsUpdate struct {
+	Language     *string `json:"language"`
+	StatusAlerts *bool   `json:"status_alerts"`
+	WeeklyReport *bool   `json:"weekly_report"`
+	TicketsAlert *int    `json:"tickets_alert"`
+	Ticket       *string `json:"ticket"` // Empty forgets the ticket
+}
+
+// appResponse is the data of the Mini App for a user
+type appResponse struct {
+	Settings  appSettings       `json:"settings"`
+	Message   string            `json:"message"` // Status message as HTML, with the ticket estimate
+	Queue     *models.QueueData `json:"queue"`
+	Languages map[string]string `json:"languages"` // Names of the supported languages by code
+	Labels    map[string]string `json:"labels"`
+}
+
+// SetWebApp enables the Telegram Mini App at /app/, validating its users with the bot token.
+// Ticket estimates cover the primary office only, so users following another office get none.
+func (s *Server) SetWebApp(botToken, primaryOffice string) {
+	s.botToken = botToken
+	s.primaryOffice = primaryOffice
+}
+
+// handleWebApp serves the Mini App page
+func (s *Server) handleWebApp(w http.ResponseWriter, r *http.Request) {
+	if s.botToken == "" || r.URL.Path != "/app/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(webAppPage)
+}
+
+// handleWebAppMe serves the settings and status of the user who opened the Mini App
+func (s *Server) handleWebAppMe(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.webAppUser(w, r)
+	if !ok {
+		return
+	}
+	s.writeWebApp(w, r, user)
+}
+
+// handleWebAppSettings changes the settings of the user who opened the Mini App, the same
+// ones the /settings menu and ticket messages change
+func (s *Server) handleWebAppSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := s.webAppUser(w, r)
+	if !ok {
+		return
+	}
+
+	var update settingsUpdate
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxSettingsSize)).Decode(&update); err != nil {
+		http.Error(w, "invalid settings: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.applySettings(r, user.ChatID, update); err != nil {
+		var invalid invalidSetting
+		if errors.As(err, &invalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to update settings of user %d from the Mini App: %v", user.ChatID, err)
+		http.Error(w, "failed to update settings", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Settings of user %d changed in the Mini App", user.ChatID)
+
+	user, err := s.db.GetUser(r.Context(), user.ChatID)
+	if err != nil || user == nil {
+		log.Printf("Failed to get user after a Mini App update: %v", err)
+		http.Error(w, "failed to load user", http.StatusInternalServerError)
+		return
+	}
+	s.writeWebApp(w, r, user)
+}
+
+// invalidSetting is a settings update the user has to correct
+type invalidSetting string
+
+func (e invalidSetting) Error() string {
+	return string(e)
+}
+
+// applySettings stores the fields set in an update
+func (s *Server) applySettings(r *http.Request, chatID int64, update settingsUpdate) error {
+	ctx := r.Context()
+
+	if update.Language != nil {
+		lang, ok := i18n.Parse(*update.Language)
+		if !ok {
+			return invalidSetting("unknown language " + *update.Language)
+		}
+		if err := s.db.SetUserLanguage(ctx, chatID, string(lang)); err != nil {
+			return err
+		}
+	}
+	if update.StatusAlerts != nil {
+		if err := s.db.SetUserStatusAlerts(ctx, chatID, *update.StatusAlerts); err != nil {
+			return err
+		}
+	}
+	if update.WeeklyReport != nil {
+		if err := s.db.SetUserWeeklyReport(ctx, chatID, *update.WeeklyReport); err != nil {
+			return err
+		}
+	}
+	if update.TicketsAlert != nil {
+		if *update.TicketsAlert < -1 {
+			return invalidSetting("tickets_alert must be -1 (off) or more")
+		}
+		if err := s.db.SetUserTicketsAlert(ctx, chatID, *update.TicketsAlert); err != nil {
+			return err
+		}
+	}
+	if update.Ticket != nil {
+		ticket := strings.ToUpper(strings.TrimSpace(*update.Ticket))
+		if ticket != "" && !models.IsTicketNumber(ticket) {
+			return invalidSetting("invalid ticket " + *update.Ticket + ", expected e.g. K222")
+		}
+		if err := s.db.SetUserTicketNumber(ctx, chatID, ticket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleWebAppChart serves today's waiting clients chart of the /chart command; 204 means
+// there is not enough data yet
+func (s *Server) handleWebAppChart(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.webAppUser(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	history, err := s.db.GetQueueDataSince(r.Context(), startOfDay)
+	if err != nil {
+		log.Printf("Failed to load history for the Mini App chart: %v", err)
+		http.Error(w, "failed to load history", http.StatusInternalServerError)
+		return
+	}
+	outages, err := s.db.GetOutages(r.Context(), startOfDay, now)
+	if err != nil {
+		log.Printf("Failed to load outages for the Mini App chart: %v", err)
+		http.Error(w, "failed to load outages", http.StatusInternalServerError)
+		return
+	}
+
+	image, err := chart.WaitingClients(history, outages, i18n.OrDefault(user.Language))
+	if err != nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	w.Write(image)
+}
+
+// webAppUser authenticates a Mini App request by its "Authorization: tma <init data>"
+// header and returns the stored user, replying with an error otherwise. Only users who
+// started the bot and are not banned may use the app.
+func (s *Server) webAppUser(w http.ResponseWriter, r *http.Request) (*database.User, bool) {
+	if s.botToken == "" {
+		http.NotFound(w, r)
+		return nil, false
+	}
+
+	initData, ok := strings.CutPrefix(r.Header.Get("Authorization"), "tma ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "tma")
+		http.Error(w, "init data required", http.StatusUnauthorized)
+		return nil, false
+	}
+	account, err := webapp.Validate(initData, s.botToken, time.Now())
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", "tma")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+
+	// The private chat with a user has the ID of the user
+	user, err := s.db.GetUser(r.Context(), account.ID)
+	if err != nil {
+		log.Printf("Failed to get Mini App user %d: %v", account.ID, err)
+		http.Error(w, "failed to load user", http.StatusInternalServerError)
+		return nil, false
+	}
+	banned, err := s.db.IsUserBanned(r.Context(), account.ID)
+	if err != nil {
+		log.Printf("Failed to check ban for Mini App user %d: %v", account.ID, err)
+		http.Error(w, "failed to load user", http.StatusInternalServerError)
+		return nil, false
+	}
+	if user == nil || banned {
+		http.Error(w, "start the bot first", http.StatusForbidden)
+		return nil, false
+	}
+	return user, true
+}
+
+// writeWebApp writes the Mini App data of a user
+func (s *Server) writeWebApp(w http.ResponseWriter, r *http.Request, user *database.User) {
+	lang := i18n.OrDefault(user.Language)
+	response := appResponse{
+		Settings: appSettings{
+			Language:     string(lang),
+			StatusAlerts: user.StatusAlerts,
+			WeeklyReport: user.WeeklyReport,
+			TicketsAlert: user.TicketsAlert,
+			Ticket:       user.TicketNumber,
+		},
+		Message:   i18n.T(lang, "badge.no_data"),
+		Languages: make(map[string]string),
+		Labels:    make(map[string]string, len(webAppLabels)),
+	}
+	for _, l := range i18n.Supported() {
+		response.Languages[string(l)] = i18n.T(l, "language.name")
+	}
+	for _, key := range webAppLabels {
+		response.Labels[strings.TrimPrefix(key, "app.")] = i18n.T(lang, key)
+	}
+
+	if s.queue != nil {
+		queueData, err := s.queue.Current(r.Context())
+		if err != nil {
+			log.Printf("Failed to get current queue data: %v", err)
+		}
+		if queueData != nil {
+			ticket := ""
+			if user.Office == "" || user.Office == s.primaryOffice {
+				ticket = user.TicketNumber
+			}
+			opts := models.MessageOptions{UserTicket: ticket, Language: lang, Estimate: s.estimate(r.Context(), queueData, ticket)}
+			response.Message = models.MarkdownToHTML(queueData.FormatTelegramMessageWithOptions(s.queue.Changes(), opts))
+			response.Queue = queueData
+		}
+	}
+
+	writeJSON(w, response)
+}
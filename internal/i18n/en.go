@@ -0,0 +1,304 @@
+package i18n
+
+var english = map[string]string{
+	"language.name": "🇬🇧 English",
+
+	"help":               "Use the /start command to get queue information\\.\n\nTo track your ticket, send its number \\(for example: K222\\)\\.\n\nTo follow another office, send /office\\.\n\nTo see the bot users in line around your ticket, send /queuepos\\.\n\nTo see times in your own time zone, send /timezone\\.\n\nTo pause queue updates for a while, send /mute\\.\n\nTo get a queue report every Monday, send /weekly on\\.\n\nTo find the hours with the shortest wait, send /besttime\\.\n\nTo change your settings with buttons, send /settings\\.\n\nTo get the live status message updated less often, send /interval\\.\n\nTo get an alert when appointment slots appear on rezerwacje\\.duw\\.pl, send /slots\\.\n\nTo get a message when the status of your case changes, send /case with your case number\\.\n\nTo see when the waiting queue should clear, send /eta\\.\n\nTo see how reliably the DUW API answered lately, send /uptime\\.\n\nTo unsubscribe, send /stop\\.",
+	"error.registration": "Registration failed\\. Please try again later\\.",
+	"error.settings":     "Failed to save settings\\. Please try again later\\.",
+	"error.ticket_save":  "Failed to save the ticket number\\. Please try again later\\.",
+
+	"start.no_data_yet":    "Welcome\\! Queue data will be available after the first update\\.",
+	"start.no_data":        "Welcome\\! Queue data is not available yet\\. Please wait for the first update\\.",
+	"start.welcome_back":   "👋 *Welcome back\\!* Your settings were restored:",
+	"stop.done":            "👋 You have unsubscribed from queue updates\\. Your settings are kept, send /start to resubscribe\\.",
+	"stop.not_subscribed":  "You are not subscribed\\. Send /start to subscribe\\.",
+	"ticket.saved_no_data": "Ticket %s saved\\! Queue data will be available after the first update\\.",
+
+	"settings.ticket":         "🎫 Ticket: %s",
+	"settings.status_alerts":  "🔔 Queue open/close notifications",
+	"settings.threshold":      "📉 Notification when %d or fewer tickets are left",
+	"settings.language":       "🌐 Language: %s",
+	"settings.menu":           "⚙️ *Settings*\n\nTap a button to change a setting\\.",
+	"settings.menu_language":  "🌐 Choose a language:",
+	"settings.menu_threshold": "📉 Get notified when this many tickets or fewer are left:",
+	"settings.menu_mute":      "🔕 Pause queue updates for:",
+	"settings.menu_interval":  "⏱ Update the live status message at most every:",
+	"settings.menu_ticket":    "🎫 Your ticket: %s",
+	"settings.ticket_prompt":  "🎫 Send your ticket number, for example: K222\\.",
+	"settings.closed":         "✅ Settings saved\\.",
+
+	"button.language":        "🌐 Language: %s",
+	"button.alerts_on":       "🔔 Open/close alerts: on",
+	"button.alerts_off":      "🔕 Open/close alerts: off",
+	"button.weekly_on":       "📅 Weekly report: on",
+	"button.weekly_off":      "📅 Weekly report: off",
+	"button.threshold":       "📉 Tickets alert: %d or fewer",
+	"button.threshold_off":   "📉 Tickets alert: off",
+	"button.muted":           "🔕 Paused until %s",
+	"button.unmuted":         "🔔 Updates: on",
+	"button.interval":        "⏱ Updates: at most every %d min",
+	"button.interval_off":    "⏱ Updates: on every change",
+	"button.ticket":          "🎫 Ticket: %s",
+	"button.no_ticket":       "🎫 Register a ticket",
+	"button.forget_ticket":   "🗑 Forget the ticket",
+	"button.change_ticket":   "✏️ Register another ticket",
+	"button.threshold_value": "%d or fewer",
+	"button.off":             "Off",
+	"button.resume":          "🔔 Resume updates",
+	"button.hours":           "%d h",
+	"button.minutes":         "%d min",
+	"button.every_change":    "On every change",
+	"button.back":            "⬅️ Back",
+	"button.close":           "✅ Done",
+	"button.admins_only":     "Only group admins can change the settings.",
+
+	"command.start":        "Subscribe and show the queue",
+	"command.settings":     "Change the settings with buttons",
+	"command.stats":        "Today's throughput and time to clear the queue",
+	"command.today":        "Waiting clients per hour today",
+	"command.history":      "Daily summary of the last days",
+	"command.chart":        "Charts of today's queue",
+	"command.besttime":     "Hours with the shortest wait",
+	"command.eta":          "When the queue clears",
+	"command.now":          "Short queue summary",
+	"command.uptime":       "DUW API availability",
+	"command.weekly":       "Weekly report (on/off)",
+	"command.office":       "Follow another office",
+	"command.alerts":       "Queue open/close alerts (on/off)",
+	"command.slots":        "Appointment slot alerts",
+	"command.threshold":    "Alert when few tickets are left",
+	"command.interval":     "Update the status message less often",
+	"command.mute":         "Pause updates for a while",
+	"command.snooze_until": "Pause updates until a time",
+	"command.unmute":       "Resume updates",
+	"command.pin":          "Pin the status message (on/off)",
+	"command.case":         "Case status updates",
+	"command.queuepos":     "Bot users in line around your ticket",
+	"command.timezone":     "Show times in your time zone",
+	"command.language":     "Change the language",
+	"command.stop":         "Unsubscribe",
+	"command.botstats":     "Bot statistics",
+	"command.broadcast":    "Send a message to all users",
+	"command.users":        "List active users",
+	"command.ban":          "Ban a user",
+	"command.unban":        "Unban a user",
+	"command.setinterval":  "Change the polling interval",
+	"command.export":       "Export queue history",
+	"command.apikey":       "Manage HTTP API keys",
+	"command.app":          "Open the app",
+
+	"language.usage":   "Choose a language: /language ru, /language uk, /language pl or /language en\\.\n\nCurrent language: %s",
+	"language.changed": "Language changed: %s",
+
+	"alerts.usage":              "Use /alerts on or /alerts off to enable or disable notifications when the queue opens and closes\\.",
+	"alerts.enabled":            "🔔 Queue open/close notifications enabled\\.",
+	"alerts.disabled":           "🔕 Queue open/close notifications disabled\\.",
+	"threshold.usage":           "Use /threshold N to get notified when N or fewer tickets are left \\(/threshold 0 \\- when tickets run out\\), or /threshold off to disable\\.",
+	"threshold.enabled":         "🔔 You will be notified when %d or fewer tickets are left\\.",
+	"threshold.disabled":        "🔕 Ticket exhaustion notifications disabled\\.",
+	"pin.usage":                 "Use /pin on or /pin off to pin or stop pinning the queue status message\\.",
+	"pin.enabled":               "📌 The queue status message will be pinned\\.",
+	"pin.disabled":              "The queue status message is no longer pinned\\.",
+	"office.usage":              "🏢 *Office:* %s\n\nMonitored offices: %s\n\nUse /office \\<name\\> to follow another office, for example: /office legnica\\.",
+	"office.unknown":            "Unknown office\\. Monitored offices: %s",
+	"office.changed":            "🏢 You now follow the queue of the %s office\\.",
+	"office.primary_only":       "History and statistics are only available for the %s office\\.",
+	"queuepos.usage":            "Use /queuepos to see how many bot users are in line ahead of and behind you, and /queuepos on or /queuepos off to share your ticket anonymously or stop sharing it\\.",
+	"queuepos.shared":           "👥 Your ticket is now shared anonymously\\. Send /queuepos to see the bot users in line around you\\.",
+	"queuepos.unshared":         "Your ticket is no longer shared\\.",
+	"queuepos.not_shared":       "👥 /queuepos only shows the tickets of users who share theirs\\. Share yours anonymously with /queuepos on\\.",
+	"queuepos.no_ticket":        "Send your ticket number first \\(for example: K222\\)\\.",
+	"queuepos.called":           "Your ticket %s has already been called\\.",
+	"queuepos.result":           "👥 *Bot users in line around your ticket %s*\n\nTickets until your turn: %d\nAhead of you: %d%s\nBehind you: %d%s\n\nOnly tickets shared with /queuepos on are counted, shown as the distance from yours\\.",
+	"timezone.usage":            "🕒 Times are shown in the *%s* time zone\\. Use /timezone followed by a zone name \\(for example: /timezone Europe/Kyiv\\) to change it, or /timezone reset to use the office time zone\\.",
+	"timezone.unknown":          "❌ Unknown time zone %s\\. Use a name like Europe/Warsaw or Europe/Kyiv\\.",
+	"timezone.changed":          "✅ Times are now shown in the *%s* time zone\\.",
+	"timezone.reset":            "✅ Times are now shown in the office time zone *%s*\\.",
+	"mute.usage":                "🔕 Use /mute followed by a duration \\(for example: /mute 2h or /mute 30m, up to 7 days\\) or /snooze\\_until followed by a time \\(for example: /snooze\\_until 14:00\\) to pause queue updates\\. Alerts about your own ticket still arrive\\.",
+	"mute.enabled":              "🔕 Queue updates are paused until *%s*\\. Alerts about your own ticket still arrive\\. Send /unmute to resume earlier\\.",
+	"mute.state":                "🔕 Queue updates are paused until *%s*\\. Send /unmute to resume them\\.",
+	"mute.not_muted":            "Queue updates are not paused\\.",
+	"mute.resumed":              "🔔 Queue updates are resumed\\.",
+	"mute.expired":              "🔔 Your pause is over, queue updates are resumed\\.",
+	"snooze.usage":              "🔕 Use /snooze\\_until followed by a time \\(for example: /snooze\\_until 14:00\\) to pause queue updates until then\\.",
+	"interval.usage":            "⏱ Use /interval followed by a duration \\(for example: /interval 5m, from 1 minute to 1 hour\\) to get the live status message updated at most that often, or /interval off to get every update\\. Alerts still arrive right away\\.",
+	"interval.enabled":          "⏱ The live status message is now updated at most every *%d min*\\. Alerts still arrive right away\\.",
+	"interval.disabled":         "⏱ The live status message is updated on every queue change again\\.",
+	"slots.disabled":            "🗓 Appointment slot monitoring is not set up on this bot\\.",
+	"slots.title":               "🗓 *Appointment slots on rezerwacje\\.duw\\.pl*",
+	"slots.service_free":        "%s *%s:* free on %s",
+	"slots.service_none":        "%s *%s:* no free slots",
+	"slots.service_unknown":     "%s *%s:* not checked yet",
+	"slots.usage":               "Send /slots followed by a service name to get an alert when new slots appear \\(again to stop\\), or /slots off to stop all slot alerts\\.",
+	"slots.unknown":             "❌ Unknown service\\. Available: %s",
+	"slots.subscribed":          "🔔 You will get an alert when new appointment slots for *%s* appear\\.",
+	"slots.unsubscribed":        "🔕 Slot alerts for *%s* are turned off\\.",
+	"slots.all_off":             "🔕 Slot alerts are turned off\\.",
+	"slots.more":                "and %d more days",
+	"slots.alert":               "🗓 *New appointment slots for %s*\n\n%s\n\nBook quickly on rezerwacje\\.duw\\.pl, slots are gone fast\\.",
+	"case.disabled":             "📄 Case status checks are not set up on this bot\\.",
+	"case.usage":                "Send /case followed by your case number, e\\.g\\. /case SO\\-II\\.6151\\.12345\\.2024, to get a message when its status changes, or /case off to stop\\.",
+	"case.invalid":              "❌ That does not look like a case number\\. Use the number from your decision or letter, e\\.g\\. SO\\-II\\.6151\\.12345\\.2024\\.",
+	"case.registered":           "📄 Case *%s* registered\\. Current status: %s\n\nYou will get a message when it changes\\.",
+	"case.registered_unchecked": "📄 Case *%s* registered\\. The status page could not be checked right now, you will get the status with the next check\\.",
+	"case.removed":              "📄 Case status checks are turned off\\.",
+	"case.current":              "📄 Case *%s*: %s",
+	"case.unchecked":            "not checked yet",
+	"case.changed":              "📄 *The status of case %s changed*\n\n%s",
+	"case.ready":                "🎉 Your card looks ready for pickup\\. Check the pickup hours before you go\\.",
+	"monitoring.missing_admin":  "⚠️ *Monitoring of %s is degraded*\n\nThe queue is missing from the DUW response, so its users get no updates\\. If DUW renamed it, add the new name to QUEUE\\_ALIASES\\.\n\n%s",
+	"monitoring.found_admin":    "✅ *Monitoring of %s is back*\n\nThe queue is in the DUW response again\\.",
+	"monitoring.missing":        "⚠️ *Queue updates for %s are paused*\n\nThe queue is missing from the DUW status page, so the data shown may be outdated\\. You will get a message when updates are back\\.",
+	"monitoring.found":          "✅ *Queue updates for %s are back*",
+	"monitoring.stale_admin":    "⚠️ *Data of %s is outdated*\n\nNo poll succeeded since %s, the status messages of its users are marked as outdated\\. Check the logs for DNS, TLS or API errors\\.",
+	"monitoring.fresh_admin":    "✅ *Data of %s is up to date again*",
+	"group.welcome":             "👋 Hi\\! A group admin can send /start to get one shared queue status message for this group\\. Ticket tracking works in a private chat with the bot\\.",
+	"group.personal":            "🔒 This command works for personal tickets only\\. Send it to the bot in a private chat\\.",
+	"group.admins_only":         "🔒 Only group admins can change the bot settings of this group\\.",
+	"commands.muted":            "⏳ Too many commands\\. The bot will ignore this chat for %s\\.",
+
+	"history.error":          "Failed to load history\\. Please try again later\\.",
+	"history.today_empty":    "No data for today yet\\.",
+	"history.period_empty":   "No data for the selected period\\.",
+	"history.invalid_period": "Invalid period\\. Use, for example: /history 3d \\(from 1 to %d days\\)\\.",
+	"history.today_title":    "📈 *Today: waiting clients by hour*",
+	"history.peak":           "🔺 *Peak:* %s",
+	"history.served":         "✅ *Served:* %d",
+	"history.title":          "📅 *History for %d days*",
+	"history.day_summary":    "served %d, max waiting %d, average %.0f, min tickets %d",
+	"uptime.title":           "🩺 *DUW API availability*",
+	"uptime.day":             "24 hours",
+	"uptime.week":            "7 days",
+	"uptime.period":          "%s: *%s %%*, outages: %d, down %s",
+	"uptime.recent":          "*Recent outages:*",
+	"uptime.outage":          "• %s – %s \\(%s\\)",
+	"uptime.ongoing":         "ongoing",
+	"uptime.none":            "✅ No outages in the last 7 days\\.",
+	"uptime.error":           "Failed to load the outages\\. Please try again later\\.",
+	"eta.estimate":           "⌛ %d clients are waiting\\. At the current pace the queue should clear at about *%s*\\.",
+	"eta.closed":             "The office is closed now, there is no queue to clear\\.",
+	"eta.empty":              "✅ Nobody is waiting right now\\.",
+	"eta.no_data":            "Not enough data from today to estimate when the queue clears yet\\. Please try again later\\.",
+
+	"stats.title":               "📊 *Queue statistics for today*",
+	"stats.throughput":          "🎫 *Throughput:* %s tickets/hour",
+	"stats.service_time":        "⏱ *Average service time \\(last 2 h\\):* %s",
+	"stats.service_percentiles": "⏱ *Service time today:* median %s, 90%% within %s",
+	"stats.clear_time":          "⌛ *Waiting queue clears in:* \\~%s",
+	"stats.vs_last_week":        "📅 *Same day last week:* %d served by this time \\(%s today\\)",
+
+	"weekly.usage":               "📊 Use /weekly on to get a queue report of the past week every Monday, /weekly off to stop it, or /weekly to see the latest report now\\.",
+	"weekly.enabled":             "✅ You will get the weekly queue report every Monday\\.",
+	"weekly.disabled":            "Weekly reports are turned off\\.",
+	"weekly.title":               "📊 *Weekly report %s*",
+	"weekly.served":              "✅ *Served:* %d in %d days",
+	"weekly.vs_previous":         "\\(%s vs the previous week\\)",
+	"weekly.busiest":             "📈 *Busiest day:* %s, %d served",
+	"weekly.exhaustion":          "🎫 *Tickets ran out* on %d of %d days, on average at \\~%s",
+	"weekly.previous_exhaustion": "\\(previous week \\~%s\\)",
+	"weekly.not_exhausted":       "🎫 *Tickets did not run out this week*",
+	"weekly.service_time":        "⏱ *Average service time:* %s",
+
+	"besttime.title":   "🕐 *Best time to come* \\(office time\\)",
+	"besttime.slot":    "*%s %s*: \\~%s wait, %s tickets/hour",
+	"besttime.today":   "📅 *Best time today:* %s",
+	"besttime.note":    "_Based on the queue of the last two weeks: the usual waiting clients divided by the tickets served in that hour\\._",
+	"besttime.no_data": "Not enough history yet to find the best time\\. Please try again in a few days\\.",
+	"weekday.0":        "Sun",
+	"weekday.1":        "Mon",
+	"weekday.2":        "Tue",
+	"weekday.3":        "Wed",
+	"weekday.4":        "Thu",
+	"weekday.5":        "Fri",
+	"weekday.6":        "Sat",
+
+	"chart.waiting_title": "Waiting clients, %s",
+	"chart.waiting_axis":  "Waiting",
+	"chart.served_title":  "Tickets served per hour, %s",
+	"chart.served_axis":   "Tickets",
+	"chart.outage":        "DUW API outage",
+
+	"queue.title":                 "🏢 *Queue: %s \\(%s\\)*",
+	"queue.served":                "Served",
+	"queue.waiting":               "Waiting",
+	"queue.workplaces":            "Workplaces",
+	"queue.avg_time":              "Average time",
+	"queue.avg_wait":              "Average wait",
+	"queue.last_ticket":           "Last ticket",
+	"queue.tickets_left":          "Tickets left",
+	"queue.status":                "Queue status",
+	"queue.ticket_estimate":       "🎫 *Your ticket %s \\- remaining:* \\~%s \\(%s – %s\\), call at \\~%s",
+	"queue.ticket_wait":           "🎫 *Your ticket %s \\- remaining:* %s, call at \\~%s",
+	"queue.ticket_turn":           "🎫 *Your ticket %s \\- it's your turn\\!*",
+	"queue.tickets_forecast":      "📉 *Tickets usually run out by* \\~%s",
+	"queue.office_opens_today":    "🌙 *The office is closed now\\.* The queue opens today at %s",
+	"queue.office_opens_tomorrow": "🌙 *The office is closed now\\.* The queue opens tomorrow at %s",
+	"queue.office_opens_on":       "🌙 *The office is closed now\\.* The queue opens on %s at %s",
+	"queue.synced":                "🔄 *Synced:* %s",
+	"queue.age":                   "%s data %s ago",
+	"queue.changed":               "⏰ *Changed:* %s",
+	"queue.stale":                 "⚠️ *Data is outdated*: no successful update since %s",
+	"queue.velocity":              "🚀 *Queue velocity:* %s tickets / %d min %s",
+	"queue.clear_time":            "⌛ *The queue should clear at about* %s",
+	"queue.yesterday":             "📊 *Yesterday at this time:* %d waiting, %d tickets left",
+	"summary.waiting":             "%d waiting",
+	"summary.tickets_left":        "%d tickets left",
+	"summary.served":              "%d served",
+	"summary.ticket_wait":         "\\~%s for %s",
+	"summary.ticket_turn":         "%s: your turn\\!",
+	"status.open":                 "Open",
+	"status.closed":               "Closed",
+	"badge.label":                 "DUW queue",
+	"badge.waiting":               "%d waiting",
+	"badge.no_data":               "no data",
+	"app.language":                "Language",
+	"app.alerts":                  "Open/close alerts",
+	"app.weekly":                  "Weekly report",
+	"app.threshold":               "Tickets left alert",
+	"app.off":                     "Off",
+	"app.ticket":                  "Ticket",
+	"app.save":                    "Save",
+	"app.saved":                   "Saved",
+	"app.failed":                  "Failed to save",
+	"app.chart":                   "Waiting clients today",
+	"app.open":                    "📱 Charts, your ticket estimate and settings in one place:",
+	"app.button":                  "Open the app",
+	"app.unavailable":             "The app is not available\\.",
+	"duration.hours_minutes":      "%d h %d min",
+	"duration.minutes":            "%d min",
+	"duration.seconds":            "%d sec",
+
+	"alert.opened":            "🟢 *The queue has opened\\!*\n\nTickets left: %d",
+	"alert.closed":            "🔴 *The queue has closed*\n\nServed today: %d",
+	"alert.tickets_exhausted": "⛔ *Tickets have run out\\!*\n\nNo more tickets today, there is no point going to the office\\.",
+	"alert.tickets_low":       "⚠️ *Tickets are running out\\!*\n\nTickets left: %d",
+	"alert.ticket_called":     "🔔 *Your number %s has been called\\!*\n\nPlease go to the service desk\\.",
+	"alert.ticket_near":       "⏰ *Your number %s is coming up soon\\!* Only %d tickets ahead, please head to the office\\.",
+
+	"admin.stats_error":          "Failed to get statistics\\.",
+	"admin.stats_title":          "📊 *Bot statistics*",
+	"admin.broadcast_usage":      "Usage: /broadcast \\<text\\>",
+	"admin.broadcast_done":       "Message sent to %d of %d users\\.",
+	"admin.users_error":          "Failed to get the user list\\.",
+	"admin.users_title":          "👥 *Active users: %d*",
+	"admin.users_more":           "\\.\\.\\. and %d more",
+	"admin.ban_usage":            "Usage: /ban \\<chat\\_id\\> or /unban \\<chat\\_id\\>",
+	"admin.ban_failed":           "Failed to update user %d: %s",
+	"admin.banned":               "🚫 User %d banned\\.",
+	"admin.unbanned":             "✅ User %d unbanned\\.",
+	"admin.interval_unavailable": "Changing the interval is not available\\.",
+	"admin.interval_usage":       "Usage: /setinterval 30s \\(from %s to %s\\)",
+	"admin.interval_changed":     "⏱ Polling interval changed to %s\\.",
+	"admin.export_usage":         "Usage: /export \\[csv\\|json\\] \\[from\\] \\[to\\], dates as YYYY\\-MM\\-DD \\(default: today\\)",
+	"admin.export_failed":        "Failed to send the export: %s",
+	"admin.panic":                "⚠️ *Recovered from a panic* in %s: %s\\. See the log for the stack trace\\.",
+	"admin.restart":              "🔁 *%s stopped*: %s\\. Restart %d in %s\\.",
+	"admin.apikey_usage":         "Usage: /apikey list, /apikey create \\<name\\> \\[read\\|admin\\] \\[requests per minute\\] or /apikey revoke \\<id\\>",
+	"admin.apikey_created":       "🔑 *API key %d created*: %s, %s scope, %d requests per minute\\.\n\n`%s`\n\nThe key is shown only once\\.",
+	"admin.apikey_title":         "🔑 *API keys \\(%d\\)*",
+	"admin.apikey_none":          "No API keys\\.",
+	"admin.apikey_revoked":       "🗑 API key %d revoked\\.",
+	"admin.apikey_not_found":     "API key %d not found\\.",
+	"admin.apikey_error":         "Failed to manage API keys: %s",
+}
@@ -0,0 +1,75 @@
+package i18n
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Language is a supported message language code
+type Language string
+
+const (
+	Russian   Language = "ru"
+	Polish    Language = "pl"
+	English   Language = "en"
+	Ukrainian Language = "uk"
+
+	DefaultLanguage = Russian
+)
+
+// catalogs maps languages to their message templates. Templates are Telegram MarkdownV2
+// with fmt verbs; callers escape dynamic text before passing it as an argument. The button.*
+// and command.* templates are plain text for inline keyboard buttons, callback answers and
+// the command menu.
+var catalogs = map[Language]map[string]string{
+	Russian:   russian,
+	Polish:    polish,
+	English:   english,
+	Ukrainian: ukrainian,
+}
+
+// Supported returns all supported languages in display order
+func Supported() []Language {
+	return []Language{Russian, Ukrainian, Polish, English}
+}
+
+// Parse converts a language code (e.g. "pl", "en-US", "ua") to a supported language
+func Parse(code string) (Language, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	if code == "ua" {
+		code = string(Ukrainian)
+	}
+
+	lang := Language(code)
+	_, ok := catalogs[lang]
+	return lang, ok
+}
+
+// OrDefault returns the language if it is supported, otherwise the default language
+func OrDefault(code string) Language {
+	if lang, ok := Parse(code); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// T returns the formatted message for the key, falling back to the default language
+func T(lang Language, key string, args ...interface{}) string {
+	template, ok := catalogs[lang][key]
+	if !ok {
+		template, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		log.Printf("Missing translation key %q", key)
+		return key
+	}
+
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
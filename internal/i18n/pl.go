@@ -0,0 +1,304 @@
+package i18n
+
+var polish = map[string]string{
+	"language.name": "🇵🇱 Polski",
+
+	"help":               "Użyj komendy /start, aby uzyskać informacje o kolejce\\.\n\nAby śledzić swój bilet, wyślij jego numer \\(na przykład: K222\\)\\.\n\nAby śledzić inny urząd, wyślij /office\\.\n\nAby zobaczyć użytkowników bota w kolejce wokół twojego biletu, wyślij /queuepos\\.\n\nAby widzieć godziny w swojej strefie czasowej, wyślij /timezone\\.\n\nAby na chwilę wstrzymać aktualizacje kolejki, wyślij /mute\\.\n\nAby co poniedziałek dostawać raport kolejki, wyślij /weekly on\\.\n\nAby znaleźć godziny z najkrótszym oczekiwaniem, wyślij /besttime\\.\n\nAby zmienić ustawienia za pomocą przycisków, wyślij /settings\\.\n\nAby wiadomość ze stanem kolejki była aktualizowana rzadziej, wyślij /interval\\.\n\nAby dostać powiadomienie o wolnych terminach na rezerwacje\\.duw\\.pl, wyślij /slots\\.\n\nAby dostać wiadomość, gdy zmieni się status Twojej sprawy, wyślij /case z numerem sprawy\\.\n\nAby sprawdzić, kiedy skończy się kolejka oczekujących, wyślij /eta\\.\n\nAby sprawdzić, jak niezawodnie odpowiadało ostatnio API DUW, wyślij /uptime\\.\n\nAby wypisać się z powiadomień, wyślij /stop\\.",
+	"error.registration": "Wystąpił błąd podczas rejestracji\\. Spróbuj ponownie później\\.",
+	"error.settings":     "Nie udało się zapisać ustawień\\. Spróbuj ponownie później\\.",
+	"error.ticket_save":  "Nie udało się zapisać numeru biletu\\. Spróbuj ponownie później\\.",
+
+	"start.no_data_yet":    "Witaj\\! Dane o kolejce będą dostępne po pierwszej aktualizacji\\.",
+	"start.no_data":        "Witaj\\! Dane o kolejce nie są jeszcze dostępne\\. Poczekaj na pierwszą aktualizację\\.",
+	"start.welcome_back":   "👋 *Witaj ponownie\\!* Twoje ustawienia zostały przywrócone:",
+	"stop.done":            "👋 Wypisano Cię z aktualizacji kolejki\\. Ustawienia zostały zachowane, wyślij /start, aby zapisać się ponownie\\.",
+	"stop.not_subscribed":  "Nie masz subskrypcji\\. Wyślij /start, aby się zapisać\\.",
+	"ticket.saved_no_data": "Bilet %s zapisany\\! Dane o kolejce będą dostępne po pierwszej aktualizacji\\.",
+
+	"settings.ticket":         "🎫 Bilet: %s",
+	"settings.status_alerts":  "🔔 Powiadomienia o otwarciu/zamknięciu kolejki",
+	"settings.threshold":      "📉 Powiadomienie, gdy zostanie %d lub mniej biletów",
+	"settings.language":       "🌐 Język: %s",
+	"settings.menu":           "⚙️ *Ustawienia*\n\nNaciśnij przycisk, aby zmienić ustawienie\\.",
+	"settings.menu_language":  "🌐 Wybierz język:",
+	"settings.menu_threshold": "📉 Powiadom mnie, gdy zostanie tyle biletów lub mniej:",
+	"settings.menu_mute":      "🔕 Wstrzymaj aktualizacje kolejki na:",
+	"settings.menu_interval":  "⏱ Aktualizuj wiadomość ze stanem kolejki najwyżej co:",
+	"settings.menu_ticket":    "🎫 Twój bilet: %s",
+	"settings.ticket_prompt":  "🎫 Wyślij numer swojego biletu, na przykład: K222\\.",
+	"settings.closed":         "✅ Ustawienia zapisane\\.",
+
+	"button.language":        "🌐 Język: %s",
+	"button.alerts_on":       "🔔 Powiadomienia o otwarciu/zamknięciu: wł.",
+	"button.alerts_off":      "🔕 Powiadomienia o otwarciu/zamknięciu: wył.",
+	"button.weekly_on":       "📅 Raport tygodniowy: wł.",
+	"button.weekly_off":      "📅 Raport tygodniowy: wył.",
+	"button.threshold":       "📉 Alert o biletach: %d lub mniej",
+	"button.threshold_off":   "📉 Alert o biletach: wył.",
+	"button.muted":           "🔕 Wstrzymane do %s",
+	"button.unmuted":         "🔔 Aktualizacje: wł.",
+	"button.interval":        "⏱ Aktualizacje: najwyżej co %d min",
+	"button.interval_off":    "⏱ Aktualizacje: przy każdej zmianie",
+	"button.ticket":          "🎫 Bilet: %s",
+	"button.no_ticket":       "🎫 Zarejestruj bilet",
+	"button.forget_ticket":   "🗑 Zapomnij bilet",
+	"button.change_ticket":   "✏️ Zarejestruj inny bilet",
+	"button.threshold_value": "%d lub mniej",
+	"button.off":             "Wyłącz",
+	"button.resume":          "🔔 Wznów aktualizacje",
+	"button.hours":           "%d godz.",
+	"button.minutes":         "%d min",
+	"button.every_change":    "Przy każdej zmianie",
+	"button.back":            "⬅️ Wstecz",
+	"button.close":           "✅ Gotowe",
+	"button.admins_only":     "Tylko administratorzy grupy mogą zmieniać ustawienia.",
+
+	"command.start":        "Zapisz się i pokaż kolejkę",
+	"command.settings":     "Zmień ustawienia przyciskami",
+	"command.stats":        "Dzisiejsza przepustowość i czas do końca kolejki",
+	"command.today":        "Oczekujący w każdej godzinie dzisiaj",
+	"command.history":      "Podsumowanie ostatnich dni",
+	"command.chart":        "Wykresy dzisiejszej kolejki",
+	"command.besttime":     "Godziny z najkrótszym oczekiwaniem",
+	"command.eta":          "Kiedy skończy się kolejka",
+	"command.now":          "Krótkie podsumowanie kolejki",
+	"command.uptime":       "Dostępność API DUW",
+	"command.weekly":       "Raport tygodniowy (on/off)",
+	"command.office":       "Śledź inny urząd",
+	"command.alerts":       "Powiadomienia o otwarciu/zamknięciu (on/off)",
+	"command.slots":        "Powiadomienia o wolnych terminach",
+	"command.threshold":    "Powiadomienie, gdy zostaje mało biletów",
+	"command.interval":     "Aktualizuj wiadomość ze stanem rzadziej",
+	"command.mute":         "Wstrzymaj aktualizacje na chwilę",
+	"command.snooze_until": "Wstrzymaj aktualizacje do godziny",
+	"command.unmute":       "Wznów aktualizacje",
+	"command.pin":          "Przypnij wiadomość ze stanem (on/off)",
+	"command.case":         "Zmiany statusu sprawy",
+	"command.queuepos":     "Użytkownicy bota w kolejce wokół twojego biletu",
+	"command.timezone":     "Pokazuj godziny w twojej strefie czasowej",
+	"command.language":     "Zmień język",
+	"command.stop":         "Wypisz się",
+	"command.botstats":     "Statystyki bota",
+	"command.broadcast":    "Wyślij wiadomość do wszystkich",
+	"command.users":        "Lista aktywnych użytkowników",
+	"command.ban":          "Zablokuj użytkownika",
+	"command.unban":        "Odblokuj użytkownika",
+	"command.setinterval":  "Zmień częstotliwość odpytywania",
+	"command.export":       "Eksportuj historię kolejki",
+	"command.apikey":       "Zarządzaj kluczami HTTP API",
+	"command.app":          "Otwórz aplikację",
+
+	"language.usage":   "Wybierz język: /language ru, /language uk, /language pl lub /language en\\.\n\nObecny język: %s",
+	"language.changed": "Zmieniono język: %s",
+
+	"alerts.usage":              "Użyj /alerts on lub /alerts off, aby włączyć lub wyłączyć powiadomienia o otwarciu i zamknięciu kolejki\\.",
+	"alerts.enabled":            "🔔 Powiadomienia o otwarciu i zamknięciu kolejki włączone\\.",
+	"alerts.disabled":           "🔕 Powiadomienia o otwarciu i zamknięciu kolejki wyłączone\\.",
+	"threshold.usage":           "Użyj /threshold N, aby otrzymać powiadomienie, gdy zostanie N biletów lub mniej \\(/threshold 0 \\- gdy bilety się skończą\\), lub /threshold off, aby wyłączyć\\.",
+	"threshold.enabled":         "🔔 Otrzymasz powiadomienie, gdy zostanie %d biletów lub mniej\\.",
+	"threshold.disabled":        "🔕 Powiadomienia o końcu biletów wyłączone\\.",
+	"pin.usage":                 "Użyj /pin on lub /pin off, aby przypinać lub nie przypinać wiadomości ze stanem kolejki\\.",
+	"pin.enabled":               "📌 Wiadomość ze stanem kolejki będzie przypięta\\.",
+	"pin.disabled":              "Wiadomość ze stanem kolejki nie jest już przypinana\\.",
+	"office.usage":              "🏢 *Urząd:* %s\n\nMonitorowane urzędy: %s\n\nUżyj /office \\<nazwa\\>, aby śledzić inny urząd, na przykład: /office legnica\\.",
+	"office.unknown":            "Nieznany urząd\\. Monitorowane urzędy: %s",
+	"office.changed":            "🏢 Śledzisz teraz kolejkę urzędu: %s\\.",
+	"office.primary_only":       "Historia i statystyki są dostępne tylko dla urzędu: %s\\.",
+	"queuepos.usage":            "Użyj /queuepos, aby zobaczyć, ilu użytkowników bota stoi w kolejce przed tobą i za tobą, oraz /queuepos on lub /queuepos off, aby anonimowo udostępnić swój bilet lub przestać go udostępniać\\.",
+	"queuepos.shared":           "👥 Twój bilet jest teraz anonimowo udostępniany\\. Wyślij /queuepos, aby zobaczyć użytkowników bota w kolejce wokół ciebie\\.",
+	"queuepos.unshared":         "Twój bilet nie jest już udostępniany\\.",
+	"queuepos.not_shared":       "👥 /queuepos pokazuje tylko bilety użytkowników, którzy udostępniają swoje\\. Udostępnij swój anonimowo za pomocą /queuepos on\\.",
+	"queuepos.no_ticket":        "Najpierw wyślij numer swojego biletu \\(na przykład: K222\\)\\.",
+	"queuepos.called":           "Twój bilet %s został już wywołany\\.",
+	"queuepos.result":           "👥 *Użytkownicy bota w kolejce wokół twojego biletu %s*\n\nBiletów do twojej kolejki: %d\nPrzed tobą: %d%s\nZa tobą: %d%s\n\nLiczone są tylko bilety udostępnione przez /queuepos on, pokazane jako odległość od twojego\\.",
+	"timezone.usage":            "🕒 Godziny są podawane w strefie czasowej *%s*\\. Użyj /timezone z nazwą strefy \\(na przykład: /timezone Europe/Kyiv\\), aby ją zmienić, lub /timezone reset, aby wrócić do strefy urzędu\\.",
+	"timezone.unknown":          "❌ Nieznana strefa czasowa %s\\. Użyj nazwy takiej jak Europe/Warsaw lub Europe/Kyiv\\.",
+	"timezone.changed":          "✅ Godziny są teraz podawane w strefie czasowej *%s*\\.",
+	"timezone.reset":            "✅ Godziny są teraz podawane w strefie czasowej urzędu *%s*\\.",
+	"mute.usage":                "🔕 Użyj /mute z czasem trwania \\(na przykład: /mute 2h lub /mute 30m, najwyżej 7 dni\\) albo /snooze\\_until z godziną \\(na przykład: /snooze\\_until 14:00\\), aby wstrzymać aktualizacje kolejki\\. Powiadomienia o twoim bilecie nadal będą przychodzić\\.",
+	"mute.enabled":              "🔕 Aktualizacje kolejki są wstrzymane do *%s*\\. Powiadomienia o twoim bilecie nadal będą przychodzić\\. Wyślij /unmute, aby wznowić je wcześniej\\.",
+	"mute.state":                "🔕 Aktualizacje kolejki są wstrzymane do *%s*\\. Wyślij /unmute, aby je wznowić\\.",
+	"mute.not_muted":            "Aktualizacje kolejki nie są wstrzymane\\.",
+	"mute.resumed":              "🔔 Aktualizacje kolejki zostały wznowione\\.",
+	"mute.expired":              "🔔 Przerwa się skończyła, aktualizacje kolejki zostały wznowione\\.",
+	"snooze.usage":              "🔕 Użyj /snooze\\_until z godziną \\(na przykład: /snooze\\_until 14:00\\), aby wstrzymać aktualizacje kolejki do tego czasu\\.",
+	"interval.usage":            "⏱ Użyj /interval z czasem trwania \\(na przykład: /interval 5m, od 1 minuty do 1 godziny\\), aby wiadomość ze stanem kolejki była aktualizowana najwyżej tak często, albo /interval off, aby dostawać każdą aktualizację\\. Powiadomienia nadal przychodzą od razu\\.",
+	"interval.enabled":          "⏱ Wiadomość ze stanem kolejki będzie teraz aktualizowana najwyżej co *%d min*\\. Powiadomienia nadal przychodzą od razu\\.",
+	"interval.disabled":         "⏱ Wiadomość ze stanem kolejki znów jest aktualizowana przy każdej zmianie\\.",
+	"slots.disabled":            "🗓 Monitorowanie wolnych terminów nie jest skonfigurowane w tym bocie\\.",
+	"slots.title":               "🗓 *Wolne terminy na rezerwacje\\.duw\\.pl*",
+	"slots.service_free":        "%s *%s:* wolne %s",
+	"slots.service_none":        "%s *%s:* brak wolnych terminów",
+	"slots.service_unknown":     "%s *%s:* jeszcze nie sprawdzono",
+	"slots.usage":               "Wyślij /slots z nazwą usługi, aby dostać powiadomienie o nowych terminach \\(ponownie, aby przestać\\), albo /slots off, aby wyłączyć wszystkie powiadomienia o terminach\\.",
+	"slots.unknown":             "❌ Nieznana usługa\\. Dostępne: %s",
+	"slots.subscribed":          "🔔 Dostaniesz powiadomienie, gdy pojawią się nowe terminy dla *%s*\\.",
+	"slots.unsubscribed":        "🔕 Powiadomienia o terminach dla *%s* są wyłączone\\.",
+	"slots.all_off":             "🔕 Powiadomienia o terminach są wyłączone\\.",
+	"slots.more":                "i jeszcze %d dni",
+	"slots.alert":               "🗓 *Nowe wolne terminy dla %s*\n\n%s\n\nZarezerwuj szybko na rezerwacje\\.duw\\.pl, terminy szybko znikają\\.",
+	"case.disabled":             "📄 Sprawdzanie statusu sprawy nie jest skonfigurowane w tym bocie\\.",
+	"case.usage":                "Wyślij /case z numerem sprawy, np\\. /case SO\\-II\\.6151\\.12345\\.2024, aby dostać wiadomość, gdy zmieni się jej status, albo /case off, aby przestać\\.",
+	"case.invalid":              "❌ To nie wygląda na numer sprawy\\. Użyj numeru z decyzji lub pisma, np\\. SO\\-II\\.6151\\.12345\\.2024\\.",
+	"case.registered":           "📄 Sprawa *%s* zarejestrowana\\. Obecny status: %s\n\nDostaniesz wiadomość, gdy się zmieni\\.",
+	"case.registered_unchecked": "📄 Sprawa *%s* zarejestrowana\\. Nie udało się teraz sprawdzić strony statusu, status pojawi się przy następnym sprawdzeniu\\.",
+	"case.removed":              "📄 Sprawdzanie statusu sprawy jest wyłączone\\.",
+	"case.current":              "📄 Sprawa *%s*: %s",
+	"case.unchecked":            "jeszcze nie sprawdzono",
+	"case.changed":              "📄 *Status sprawy %s się zmienił*\n\n%s",
+	"case.ready":                "🎉 Wygląda na to, że karta jest gotowa do odbioru\\. Sprawdź godziny odbioru przed wyjściem\\.",
+	"monitoring.missing_admin":  "⚠️ *Monitorowanie %s nie działa prawidłowo*\n\nKolejki brakuje w odpowiedzi DUW, więc jej użytkownicy nie dostają aktualizacji\\. Jeśli DUW zmienił jej nazwę, dodaj nową nazwę do QUEUE\\_ALIASES\\.\n\n%s",
+	"monitoring.found_admin":    "✅ *Monitorowanie %s znów działa*\n\nKolejka jest znowu w odpowiedzi DUW\\.",
+	"monitoring.missing":        "⚠️ *Aktualizacje kolejki %s są wstrzymane*\n\nKolejki brakuje na stronie statusu DUW, więc pokazane dane mogą być nieaktualne\\. Dostaniesz wiadomość, gdy aktualizacje wrócą\\.",
+	"monitoring.found":          "✅ *Aktualizacje kolejki %s wróciły*",
+	"monitoring.stale_admin":    "⚠️ *Dane %s są nieaktualne*\n\nŻadne odpytanie nie powiodło się od %s, wiadomości ze stanem kolejki jej użytkowników są oznaczone jako nieaktualne\\. Sprawdź logi pod kątem błędów DNS, TLS lub API\\.",
+	"monitoring.fresh_admin":    "✅ *Dane %s są znowu aktualne*",
+	"group.welcome":             "👋 Cześć\\! Administrator grupy może wysłać /start, aby grupa otrzymywała jedną wspólną wiadomość ze stanem kolejki\\. Śledzenie biletu działa w prywatnym czacie z botem\\.",
+	"group.personal":            "🔒 Ta komenda dotyczy tylko osobistych biletów\\. Wyślij ją do bota w prywatnym czacie\\.",
+	"group.admins_only":         "🔒 Tylko administratorzy grupy mogą zmieniać ustawienia bota w tej grupie\\.",
+	"commands.muted":            "⏳ Zbyt wiele komend\\. Bot będzie ignorować ten czat przez %s\\.",
+
+	"history.error":          "Nie udało się pobrać historii\\. Spróbuj ponownie później\\.",
+	"history.today_empty":    "Brak danych z dzisiaj\\.",
+	"history.period_empty":   "Brak danych z wybranego okresu\\.",
+	"history.invalid_period": "Nieprawidłowy okres\\. Użyj na przykład: /history 3d \\(od 1 do %d dni\\)\\.",
+	"history.today_title":    "📈 *Dzisiaj: oczekujący według godzin*",
+	"history.peak":           "🔺 *Szczyt:* %s",
+	"history.served":         "✅ *Obsłużono:* %d",
+	"history.title":          "📅 *Historia z %d dni*",
+	"history.day_summary":    "obsłużono %d, maks\\. oczekujących %d, średnio %.0f, min\\. biletów %d",
+	"uptime.title":           "🩺 *Dostępność API DUW*",
+	"uptime.day":             "24 godziny",
+	"uptime.week":            "7 dni",
+	"uptime.period":          "%s: *%s %%*, awarie: %d, niedostępne przez %s",
+	"uptime.recent":          "*Ostatnie awarie:*",
+	"uptime.outage":          "• %s – %s \\(%s\\)",
+	"uptime.ongoing":         "trwa",
+	"uptime.none":            "✅ Brak awarii w ostatnich 7 dniach\\.",
+	"uptime.error":           "Nie udało się wczytać awarii\\. Spróbuj ponownie później\\.",
+	"eta.estimate":           "⌛ Oczekuje %d klientów\\. W obecnym tempie kolejka powinna skończyć się około *%s*\\.",
+	"eta.closed":             "Urząd jest teraz zamknięty, nie ma kolejki\\.",
+	"eta.empty":              "✅ Teraz nikt nie czeka\\.",
+	"eta.no_data":            "Za mało dzisiejszych danych, aby oszacować koniec kolejki\\. Spróbuj ponownie później\\.",
+
+	"stats.title":               "📊 *Statystyki kolejki na dziś*",
+	"stats.throughput":          "🎫 *Przepustowość:* %s biletów/godz\\.",
+	"stats.service_time":        "⏱ *Średni czas obsługi \\(ostatnie 2 godz\\.\\):* %s",
+	"stats.service_percentiles": "⏱ *Czas obsługi dzisiaj:* mediana %s, 90%% w ciągu %s",
+	"stats.clear_time":          "⌛ *Oczekujący zostaną obsłużeni za:* \\~%s",
+	"stats.vs_last_week":        "📅 *Ten sam dzień tydzień temu:* %d obsłużonych do tej pory \\(dziś %s\\)",
+
+	"weekly.usage":               "📊 Użyj /weekly on, aby co poniedziałek dostawać raport kolejki z minionego tygodnia, /weekly off, aby go wyłączyć, lub /weekly, aby zobaczyć najnowszy raport teraz\\.",
+	"weekly.enabled":             "✅ Będziesz dostawać tygodniowy raport kolejki co poniedziałek\\.",
+	"weekly.disabled":            "Raporty tygodniowe są wyłączone\\.",
+	"weekly.title":               "📊 *Raport tygodniowy %s*",
+	"weekly.served":              "✅ *Obsłużono:* %d w ciągu %d dni",
+	"weekly.vs_previous":         "\\(%s wobec poprzedniego tygodnia\\)",
+	"weekly.busiest":             "📈 *Najbardziej obłożony dzień:* %s, obsłużono %d",
+	"weekly.exhaustion":          "🎫 *Biletów zabrakło* w %d z %d dni, średnio o \\~%s",
+	"weekly.previous_exhaustion": "\\(poprzedni tydzień \\~%s\\)",
+	"weekly.not_exhausted":       "🎫 *W tym tygodniu biletów nie zabrakło*",
+	"weekly.service_time":        "⏱ *Średni czas obsługi:* %s",
+
+	"besttime.title":   "🕐 *Najlepszy czas na wizytę* \\(czas urzędu\\)",
+	"besttime.slot":    "*%s %s*: oczekiwanie \\~%s, %s biletów/godz\\.",
+	"besttime.today":   "📅 *Najlepszy czas dzisiaj:* %s",
+	"besttime.note":    "_Na podstawie kolejki z ostatnich dwóch tygodni: zwykła liczba oczekujących podzielona przez liczbę biletów obsłużonych w danej godzinie\\._",
+	"besttime.no_data": "Za mało historii, aby znaleźć najlepszy czas\\. Spróbuj ponownie za kilka dni\\.",
+	"weekday.0":        "nd",
+	"weekday.1":        "pn",
+	"weekday.2":        "wt",
+	"weekday.3":        "śr",
+	"weekday.4":        "czw",
+	"weekday.5":        "pt",
+	"weekday.6":        "sob",
+
+	"chart.waiting_title": "Oczekujący klienci, %s",
+	"chart.waiting_axis":  "Oczekujący",
+	"chart.served_title":  "Obsłużone bilety na godzinę, %s",
+	"chart.served_axis":   "Bilety",
+	"chart.outage":        "Awaria API DUW",
+
+	"queue.title":                 "🏢 *Kolejka: %s \\(%s\\)*",
+	"queue.served":                "Obsłużono",
+	"queue.waiting":               "Oczekuje",
+	"queue.workplaces":            "Stanowiska",
+	"queue.avg_time":              "Średni czas",
+	"queue.avg_wait":              "Średni czas oczekiwania",
+	"queue.last_ticket":           "Ostatni bilet",
+	"queue.tickets_left":          "Pozostało biletów",
+	"queue.status":                "Stan kolejki",
+	"queue.ticket_estimate":       "🎫 *Twój bilet %s \\- pozostało:* \\~%s \\(%s – %s\\), wezwanie ok\\. %s",
+	"queue.ticket_wait":           "🎫 *Twój bilet %s \\- pozostało:* %s, wezwanie ok\\. %s",
+	"queue.ticket_turn":           "🎫 *Twój bilet %s \\- Twoja kolej\\!*",
+	"queue.tickets_forecast":      "📉 *Bilety zwykle kończą się około* \\~%s",
+	"queue.office_opens_today":    "🌙 *Urząd jest teraz zamknięty\\.* Kolejka otworzy się dziś o %s",
+	"queue.office_opens_tomorrow": "🌙 *Urząd jest teraz zamknięty\\.* Kolejka otworzy się jutro o %s",
+	"queue.office_opens_on":       "🌙 *Urząd jest teraz zamknięty\\.* Kolejka otworzy się %s o %s",
+	"queue.synced":                "🔄 *Synchronizacja:* %s",
+	"queue.age":                   "%s dane sprzed %s",
+	"queue.changed":               "⏰ *Zmiana:* %s",
+	"queue.stale":                 "⚠️ *Dane są nieaktualne*: brak udanej aktualizacji od %s",
+	"queue.velocity":              "🚀 *Tempo kolejki:* %s biletów / %d min\\. %s",
+	"queue.clear_time":            "⌛ *Kolejka powinna skończyć się około* %s",
+	"queue.yesterday":             "📊 *Wczoraj o tej porze:* oczekiwało %d, pozostało biletów %d",
+	"summary.waiting":             "czeka %d",
+	"summary.tickets_left":        "zostało biletów: %d",
+	"summary.served":              "obsłużono %d",
+	"summary.ticket_wait":         "\\~%s dla %s",
+	"summary.ticket_turn":         "%s: Twoja kolej\\!",
+	"status.open":                 "Dostępna",
+	"status.closed":               "Zamknięta",
+	"badge.label":                 "Kolejka DUW",
+	"badge.waiting":               "%d oczekuje",
+	"badge.no_data":               "brak danych",
+	"app.language":                "Język",
+	"app.alerts":                  "Alerty otwarcia/zamknięcia",
+	"app.weekly":                  "Raport tygodniowy",
+	"app.threshold":               "Alert o biletach",
+	"app.off":                     "Wył.",
+	"app.ticket":                  "Bilet",
+	"app.save":                    "Zapisz",
+	"app.saved":                   "Zapisano",
+	"app.failed":                  "Nie udało się zapisać",
+	"app.chart":                   "Oczekujący dzisiaj",
+	"app.open":                    "📱 Wykresy, prognoza dla Twojego biletu i ustawienia w jednym miejscu:",
+	"app.button":                  "Otwórz aplikację",
+	"app.unavailable":             "Aplikacja jest niedostępna\\.",
+	"duration.hours_minutes":      "%d godz\\. %d min\\.",
+	"duration.minutes":            "%d min\\.",
+	"duration.seconds":            "%d sek\\.",
+
+	"alert.opened":            "🟢 *Kolejka została otwarta\\!*\n\nPozostało biletów: %d",
+	"alert.closed":            "🔴 *Kolejka została zamknięta*\n\nObsłużono dzisiaj: %d",
+	"alert.tickets_exhausted": "⛔ *Bilety się skończyły\\!*\n\nNa dziś nie ma już biletów, nie ma sensu jechać do urzędu\\.",
+	"alert.tickets_low":       "⚠️ *Bilety się kończą\\!*\n\nPozostało biletów: %d",
+	"alert.ticket_called":     "🔔 *Twój numer %s został wywołany\\!*\n\nPodejdź do stanowiska\\.",
+	"alert.ticket_near":       "⏰ *Twój numer %s już niedługo\\!* Przed tobą tylko %d bilety, kieruj się do urzędu\\.",
+
+	"admin.stats_error":          "Nie udało się pobrać statystyk\\.",
+	"admin.stats_title":          "📊 *Statystyki bota*",
+	"admin.broadcast_usage":      "Użycie: /broadcast \\<tekst\\>",
+	"admin.broadcast_done":       "Wiadomość wysłana do %d z %d użytkowników\\.",
+	"admin.users_error":          "Nie udało się pobrać listy użytkowników\\.",
+	"admin.users_title":          "👥 *Aktywni użytkownicy: %d*",
+	"admin.users_more":           "\\.\\.\\. i jeszcze %d",
+	"admin.ban_usage":            "Użycie: /ban \\<chat\\_id\\> lub /unban \\<chat\\_id\\>",
+	"admin.ban_failed":           "Nie udało się zaktualizować użytkownika %d: %s",
+	"admin.banned":               "🚫 Użytkownik %d zablokowany\\.",
+	"admin.unbanned":             "✅ Użytkownik %d odblokowany\\.",
+	"admin.interval_unavailable": "Zmiana interwału jest niedostępna\\.",
+	"admin.interval_usage":       "Użycie: /setinterval 30s \\(od %s do %s\\)",
+	"admin.interval_changed":     "⏱ Interwał odpytywania zmieniony na %s\\.",
+	"admin.export_usage":         "Użycie: /export \\[csv\\|json\\] \\[od\\] \\[do\\], daty jako RRRR\\-MM\\-DD \\(domyślnie: dzisiaj\\)",
+	"admin.export_failed":        "Nie udało się wysłać eksportu: %s",
+	"admin.panic":                "⚠️ *Przechwycono panikę* w %s: %s\\. Ślad stosu jest w logu\\.",
+	"admin.restart":              "🔁 *%s zatrzymał się*: %s\\. Ponowne uruchomienie %d za %s\\.",
+	"admin.apikey_usage":         "Użycie: /apikey list, /apikey create \\<nazwa\\> \\[read\\|admin\\] \\[żądań na minutę\\] lub /apikey revoke \\<id\\>",
+	"admin.apikey_created":       "🔑 *Utworzono klucz API %d*: %s, zakres %s, %d żądań na minutę\\.\n\n`%s`\n\nKlucz jest pokazywany tylko raz\\.",
+	"admin.apikey_title":         "🔑 *Klucze API \\(%d\\)*",
+	"admin.apikey_none":          "Brak kluczy API\\.",
+	"admin.apikey_revoked":       "🗑 Klucz API %d unieważniony\\.",
+	"admin.apikey_not_found":     "Nie znaleziono klucza API %d\\.",
+	"admin.apikey_error":         "Nie udało się zarządzać kluczami API: %s",
+}
@@ -0,0 +1,304 @@
+package i18n
+
+var russian = map[string]string{
+	"language.name": "🇷🇺 Русский",
+
+	"help":               "Используйте команду /start для получения информации о очереди\\.\n\nЧтобы отслеживать ваш билет, отправьте номер билета \\(например: K222\\)\\.\n\nЧтобы следить за другим офисом, отправьте /office\\.\n\nЧтобы увидеть пользователей бота в очереди рядом с вашим билетом, отправьте /queuepos\\.\n\nЧтобы видеть время в своём часовом поясе, отправьте /timezone\\.\n\nЧтобы на время приостановить обновления очереди, отправьте /mute\\.\n\nЧтобы каждый понедельник получать отчёт об очереди, отправьте /weekly on\\.\n\nЧтобы узнать часы с самым коротким ожиданием, отправьте /besttime\\.\n\nЧтобы изменить настройки с помощью кнопок, отправьте /settings\\.\n\nЧтобы сообщение о состоянии очереди обновлялось реже, отправьте /interval\\.\n\nЧтобы получать оповещения о свободных записях на rezerwacje\\.duw\\.pl, отправьте /slots\\.\n\nЧтобы получать сообщения об изменении статуса вашего дела, отправьте /case с номером дела\\.\n\nЧтобы узнать, когда закончится очередь ожидающих, отправьте /eta\\.\n\nЧтобы узнать, насколько надёжно отвечал в последнее время API DUW, отправьте /uptime\\.\n\nЧтобы отписаться, отправьте /stop\\.",
+	"error.registration": "Произошла ошибка при регистрации\\. Попробуйте позже\\.",
+	"error.settings":     "Произошла ошибка при сохранении настроек\\. Попробуйте позже\\.",
+	"error.ticket_save":  "Произошла ошибка при сохранении номера билета\\. Попробуйте позже\\.",
+
+	"start.no_data_yet":    "Добро пожаловать\\! Данные о очереди будут доступны после первого обновления\\.",
+	"start.no_data":        "Добро пожаловать\\! Данные о очереди пока недоступны\\. Ожидайте первого обновления\\.",
+	"start.welcome_back":   "👋 *С возвращением\\!* Ваши настройки восстановлены:",
+	"stop.done":            "👋 Вы отписались от обновлений очереди\\. Настройки сохранены, отправьте /start, чтобы подписаться снова\\.",
+	"stop.not_subscribed":  "Вы не подписаны\\. Отправьте /start, чтобы подписаться\\.",
+	"ticket.saved_no_data": "Билет %s сохранен\\! Данные о очереди будут доступны после первого обновления\\.",
+
+	"settings.ticket":         "🎫 Билет: %s",
+	"settings.status_alerts":  "🔔 Уведомления об открытии/закрытии очереди",
+	"settings.threshold":      "📉 Уведомление, когда останется %d билетов или меньше",
+	"settings.language":       "🌐 Язык: %s",
+	"settings.menu":           "⚙️ *Настройки*\n\nНажмите кнопку, чтобы изменить настройку\\.",
+	"settings.menu_language":  "🌐 Выберите язык:",
+	"settings.menu_threshold": "📉 Оповестить, когда останется столько билетов или меньше:",
+	"settings.menu_mute":      "🔕 Приостановить обновления очереди на:",
+	"settings.menu_interval":  "⏱ Обновлять сообщение о состоянии очереди не чаще, чем раз в:",
+	"settings.menu_ticket":    "🎫 Ваш билет: %s",
+	"settings.ticket_prompt":  "🎫 Отправьте номер вашего билета, например: K222\\.",
+	"settings.closed":         "✅ Настройки сохранены\\.",
+
+	"button.language":        "🌐 Язык: %s",
+	"button.alerts_on":       "🔔 Оповещения об открытии/закрытии: вкл.",
+	"button.alerts_off":      "🔕 Оповещения об открытии/закрытии: выкл.",
+	"button.weekly_on":       "📅 Недельный отчёт: вкл.",
+	"button.weekly_off":      "📅 Недельный отчёт: выкл.",
+	"button.threshold":       "📉 Оповещение о билетах: %d или меньше",
+	"button.threshold_off":   "📉 Оповещение о билетах: выкл.",
+	"button.muted":           "🔕 Пауза до %s",
+	"button.unmuted":         "🔔 Обновления: вкл.",
+	"button.interval":        "⏱ Обновления: не чаще раза в %d мин",
+	"button.interval_off":    "⏱ Обновления: при каждом изменении",
+	"button.ticket":          "🎫 Билет: %s",
+	"button.no_ticket":       "🎫 Зарегистрировать билет",
+	"button.forget_ticket":   "🗑 Забыть билет",
+	"button.change_ticket":   "✏️ Зарегистрировать другой билет",
+	"button.threshold_value": "%d или меньше",
+	"button.off":             "Выключить",
+	"button.resume":          "🔔 Возобновить обновления",
+	"button.hours":           "%d ч",
+	"button.minutes":         "%d мин",
+	"button.every_change":    "При каждом изменении",
+	"button.back":            "⬅️ Назад",
+	"button.close":           "✅ Готово",
+	"button.admins_only":     "Менять настройки могут только администраторы группы.",
+
+	"command.start":        "Подписаться и показать очередь",
+	"command.settings":     "Изменить настройки кнопками",
+	"command.stats":        "Пропускная способность и время до конца очереди",
+	"command.today":        "Ожидающие по часам сегодня",
+	"command.history":      "Сводка за последние дни",
+	"command.chart":        "Графики очереди за сегодня",
+	"command.besttime":     "Часы с самым коротким ожиданием",
+	"command.eta":          "Когда закончится очередь",
+	"command.now":          "Краткая сводка очереди",
+	"command.uptime":       "Доступность API DUW",
+	"command.weekly":       "Недельный отчёт (on/off)",
+	"command.office":       "Следить за другим офисом",
+	"command.alerts":       "Оповещения об открытии/закрытии (on/off)",
+	"command.slots":        "Оповещения о свободных записях",
+	"command.threshold":    "Оповещение, когда остаётся мало билетов",
+	"command.interval":     "Обновлять сообщение о состоянии реже",
+	"command.mute":         "Приостановить обновления на время",
+	"command.snooze_until": "Приостановить обновления до времени",
+	"command.unmute":       "Возобновить обновления",
+	"command.pin":          "Закрепить сообщение о состоянии (on/off)",
+	"command.case":         "Изменения статуса дела",
+	"command.queuepos":     "Пользователи бота в очереди рядом с вами",
+	"command.timezone":     "Показывать время в вашем часовом поясе",
+	"command.language":     "Изменить язык",
+	"command.stop":         "Отписаться",
+	"command.botstats":     "Статистика бота",
+	"command.broadcast":    "Отправить сообщение всем",
+	"command.users":        "Список активных пользователей",
+	"command.ban":          "Заблокировать пользователя",
+	"command.unban":        "Разблокировать пользователя",
+	"command.setinterval":  "Изменить интервал опроса",
+	"command.export":       "Экспорт истории очереди",
+	"command.apikey":       "Ключи HTTP API",
+	"command.app":          "Открыть приложение",
+
+	"language.usage":   "Выберите язык: /language ru, /language uk, /language pl или /language en\\.\n\nТекущий язык: %s",
+	"language.changed": "Язык изменён: %s",
+
+	"alerts.usage":              "Используйте /alerts on или /alerts off, чтобы включить или выключить уведомления об открытии и закрытии очереди\\.",
+	"alerts.enabled":            "🔔 Уведомления об открытии и закрытии очереди включены\\.",
+	"alerts.disabled":           "🔕 Уведомления об открытии и закрытии очереди выключены\\.",
+	"threshold.usage":           "Используйте /threshold N, чтобы получить уведомление, когда останется N билетов или меньше \\(/threshold 0 \\- когда билеты закончатся\\), или /threshold off, чтобы выключить\\.",
+	"threshold.enabled":         "🔔 Вы получите уведомление, когда останется %d билетов или меньше\\.",
+	"threshold.disabled":        "🔕 Уведомления об окончании билетов выключены\\.",
+	"pin.usage":                 "Используйте /pin on или /pin off, чтобы закреплять или не закреплять сообщение со статусом очереди\\.",
+	"pin.enabled":               "📌 Сообщение со статусом очереди будет закреплено\\.",
+	"pin.disabled":              "Сообщение со статусом очереди больше не закрепляется\\.",
+	"office.usage":              "🏢 *Офис:* %s\n\nОтслеживаемые офисы: %s\n\nИспользуйте /office \\<название\\>, чтобы следить за другим офисом, например: /office legnica\\.",
+	"office.unknown":            "Неизвестный офис\\. Отслеживаемые офисы: %s",
+	"office.changed":            "🏢 Теперь вы следите за очередью офиса: %s\\.",
+	"office.primary_only":       "История и статистика доступны только для офиса: %s\\.",
+	"queuepos.usage":            "Используйте /queuepos, чтобы узнать, сколько пользователей бота стоит в очереди перед вами и после вас, и /queuepos on или /queuepos off, чтобы анонимно поделиться своим билетом или перестать им делиться\\.",
+	"queuepos.shared":           "👥 Ваш билет теперь анонимно учитывается\\. Отправьте /queuepos, чтобы увидеть пользователей бота в очереди рядом с вами\\.",
+	"queuepos.unshared":         "Ваш билет больше не учитывается\\.",
+	"queuepos.not_shared":       "👥 /queuepos показывает только билеты пользователей, которые делятся своими\\. Поделитесь своим анонимно с помощью /queuepos on\\.",
+	"queuepos.no_ticket":        "Сначала отправьте номер своего билета \\(например: K222\\)\\.",
+	"queuepos.called":           "Ваш билет %s уже вызван\\.",
+	"queuepos.result":           "👥 *Пользователи бота в очереди рядом с билетом %s*\n\nБилетов до вашей очереди: %d\nПеред вами: %d%s\nПосле вас: %d%s\n\nУчитываются только билеты, которыми поделились через /queuepos on, в виде расстояния от вашего\\.",
+	"timezone.usage":            "🕒 Время показывается в часовом поясе *%s*\\. Отправьте /timezone с названием пояса \\(например: /timezone Europe/Kyiv\\), чтобы изменить его, или /timezone reset, чтобы вернуть часовой пояс офиса\\.",
+	"timezone.unknown":          "❌ Неизвестный часовой пояс %s\\. Используйте название вроде Europe/Warsaw или Europe/Kyiv\\.",
+	"timezone.changed":          "✅ Теперь время показывается в часовом поясе *%s*\\.",
+	"timezone.reset":            "✅ Теперь время показывается в часовом поясе офиса *%s*\\.",
+	"mute.usage":                "🔕 Отправьте /mute с длительностью \\(например: /mute 2h или /mute 30m, не больше 7 дней\\) или /snooze\\_until со временем \\(например: /snooze\\_until 14:00\\), чтобы приостановить обновления очереди\\. Оповещения о вашем билете продолжат приходить\\.",
+	"mute.enabled":              "🔕 Обновления очереди приостановлены до *%s*\\. Оповещения о вашем билете продолжат приходить\\. Отправьте /unmute, чтобы возобновить их раньше\\.",
+	"mute.state":                "🔕 Обновления очереди приостановлены до *%s*\\. Отправьте /unmute, чтобы возобновить их\\.",
+	"mute.not_muted":            "Обновления очереди не приостановлены\\.",
+	"mute.resumed":              "🔔 Обновления очереди возобновлены\\.",
+	"mute.expired":              "🔔 Пауза закончилась, обновления очереди возобновлены\\.",
+	"snooze.usage":              "🔕 Отправьте /snooze\\_until со временем \\(например: /snooze\\_until 14:00\\), чтобы приостановить обновления очереди до этого времени\\.",
+	"interval.usage":            "⏱ Отправьте /interval с длительностью \\(например: /interval 5m, от 1 минуты до 1 часа\\), чтобы сообщение о состоянии очереди обновлялось не чаще, или /interval off, чтобы получать каждое обновление\\. Оповещения по\\-прежнему приходят сразу\\.",
+	"interval.enabled":          "⏱ Сообщение о состоянии очереди теперь обновляется не чаще, чем раз в *%d мин*\\. Оповещения по\\-прежнему приходят сразу\\.",
+	"interval.disabled":         "⏱ Сообщение о состоянии очереди снова обновляется при каждом изменении\\.",
+	"slots.disabled":            "🗓 Отслеживание свободных записей не настроено в этом боте\\.",
+	"slots.title":               "🗓 *Свободные записи на rezerwacje\\.duw\\.pl*",
+	"slots.service_free":        "%s *%s:* свободно %s",
+	"slots.service_none":        "%s *%s:* свободных записей нет",
+	"slots.service_unknown":     "%s *%s:* ещё не проверено",
+	"slots.usage":               "Отправьте /slots с названием услуги, чтобы получить оповещение о новых записях \\(повторно, чтобы отключить\\), или /slots off, чтобы отключить все оповещения о записях\\.",
+	"slots.unknown":             "❌ Неизвестная услуга\\. Доступны: %s",
+	"slots.subscribed":          "🔔 Вы получите оповещение, когда появятся новые записи на *%s*\\.",
+	"slots.unsubscribed":        "🔕 Оповещения о записях на *%s* отключены\\.",
+	"slots.all_off":             "🔕 Оповещения о записях отключены\\.",
+	"slots.more":                "и ещё %d дн\\.",
+	"slots.alert":               "🗓 *Новые свободные записи на %s*\n\n%s\n\nЗапишитесь скорее на rezerwacje\\.duw\\.pl, записи быстро заканчиваются\\.",
+	"case.disabled":             "📄 Проверка статуса дела не настроена в этом боте\\.",
+	"case.usage":                "Отправьте /case с номером дела, например /case SO\\-II\\.6151\\.12345\\.2024, чтобы получить сообщение при изменении его статуса, или /case off, чтобы отключить\\.",
+	"case.invalid":              "❌ Это не похоже на номер дела\\. Используйте номер из решения или письма, например SO\\-II\\.6151\\.12345\\.2024\\.",
+	"case.registered":           "📄 Дело *%s* зарегистрировано\\. Текущий статус: %s\n\nВы получите сообщение, когда он изменится\\.",
+	"case.registered_unchecked": "📄 Дело *%s* зарегистрировано\\. Сейчас не удалось проверить страницу статуса, статус придёт при следующей проверке\\.",
+	"case.removed":              "📄 Проверка статуса дела отключена\\.",
+	"case.current":              "📄 Дело *%s*: %s",
+	"case.unchecked":            "ещё не проверено",
+	"case.changed":              "📄 *Статус дела %s изменился*\n\n%s",
+	"case.ready":                "🎉 Похоже, карта готова к получению\\. Проверьте часы выдачи перед визитом\\.",
+	"monitoring.missing_admin":  "⚠️ *Мониторинг %s работает с перебоями*\n\nОчереди нет в ответе DUW, поэтому её пользователи не получают обновлений\\. Если DUW переименовал её, добавьте новое название в QUEUE\\_ALIASES\\.\n\n%s",
+	"monitoring.found_admin":    "✅ *Мониторинг %s восстановлен*\n\nОчередь снова есть в ответе DUW\\.",
+	"monitoring.missing":        "⚠️ *Обновления очереди %s приостановлены*\n\nОчереди нет на странице статуса DUW, поэтому показанные данные могут быть устаревшими\\. Вы получите сообщение, когда обновления возобновятся\\.",
+	"monitoring.found":          "✅ *Обновления очереди %s возобновлены*",
+	"monitoring.stale_admin":    "⚠️ *Данные %s устарели*\n\nНи один опрос не удался с %s, сообщения о статусе её пользователей помечены как устаревшие\\. Проверьте логи на ошибки DNS, TLS или API\\.",
+	"monitoring.fresh_admin":    "✅ *Данные %s снова актуальны*",
+	"group.welcome":             "👋 Привет\\! Администратор группы может отправить /start, чтобы группа получала одно общее сообщение о состоянии очереди\\. Отслеживание билета работает в личном чате с ботом\\.",
+	"group.personal":            "🔒 Эта команда работает только для личных билетов\\. Отправьте её боту в личном чате\\.",
+	"group.admins_only":         "🔒 Только администраторы группы могут менять настройки бота в этой группе\\.",
+	"commands.muted":            "⏳ Слишком много команд\\. Бот будет игнорировать этот чат %s\\.",
+
+	"history.error":          "Произошла ошибка при получении истории\\. Попробуйте позже\\.",
+	"history.today_empty":    "За сегодня данных пока нет\\.",
+	"history.period_empty":   "За выбранный период данных нет\\.",
+	"history.invalid_period": "Неверный период\\. Используйте, например: /history 3d \\(от 1 до %d дней\\)\\.",
+	"history.today_title":    "📈 *Сегодня: ожидающие по часам*",
+	"history.peak":           "🔺 *Пик:* %s",
+	"history.served":         "✅ *Обслужено:* %d",
+	"history.title":          "📅 *История за %d дн\\.*",
+	"history.day_summary":    "обслужено %d, макс\\. ожидали %d, в среднем %.0f, мин\\. билетов %d",
+	"uptime.title":           "🩺 *Доступность API DUW*",
+	"uptime.day":             "24 часа",
+	"uptime.week":            "7 дней",
+	"uptime.period":          "%s: *%s %%*, сбоев: %d, недоступно %s",
+	"uptime.recent":          "*Последние сбои:*",
+	"uptime.outage":          "• %s – %s \\(%s\\)",
+	"uptime.ongoing":         "продолжается",
+	"uptime.none":            "✅ За последние 7 дней сбоев не было\\.",
+	"uptime.error":           "Не удалось загрузить сбои\\. Попробуйте позже\\.",
+	"eta.estimate":           "⌛ Ожидают %d клиентов\\. При текущей скорости очередь закончится примерно в *%s*\\.",
+	"eta.closed":             "Сейчас управление закрыто, очереди нет\\.",
+	"eta.empty":              "✅ Сейчас никто не ждёт\\.",
+	"eta.no_data":            "Пока недостаточно данных за сегодня, чтобы оценить, когда закончится очередь\\. Попробуйте позже\\.",
+
+	"stats.title":               "📊 *Статистика очереди за сегодня*",
+	"stats.throughput":          "🎫 *Пропускная способность:* %s талонов/час",
+	"stats.service_time":        "⏱ *Среднее время обслуживания \\(за 2 ч\\.\\):* %s",
+	"stats.service_percentiles": "⏱ *Время обслуживания сегодня:* медиана %s, 90%% в пределах %s",
+	"stats.clear_time":          "⌛ *Ожидающие будут обслужены через:* \\~%s",
+	"stats.vs_last_week":        "📅 *Тот же день неделю назад:* %d обслужено к этому времени \\(сегодня %s\\)",
+
+	"weekly.usage":               "📊 Отправьте /weekly on, чтобы каждый понедельник получать отчёт об очереди за прошлую неделю, /weekly off, чтобы отключить его, или /weekly, чтобы посмотреть последний отчёт сейчас\\.",
+	"weekly.enabled":             "✅ Вы будете получать недельный отчёт об очереди каждый понедельник\\.",
+	"weekly.disabled":            "Недельные отчёты отключены\\.",
+	"weekly.title":               "📊 *Недельный отчёт %s*",
+	"weekly.served":              "✅ *Обслужено:* %d за %d дн\\.",
+	"weekly.vs_previous":         "\\(%s к прошлой неделе\\)",
+	"weekly.busiest":             "📈 *Самый загруженный день:* %s, обслужено %d",
+	"weekly.exhaustion":          "🎫 *Талоны закончились* в %d из %d дн\\., в среднем в \\~%s",
+	"weekly.previous_exhaustion": "\\(прошлая неделя \\~%s\\)",
+	"weekly.not_exhausted":       "🎫 *На этой неделе талоны не заканчивались*",
+	"weekly.service_time":        "⏱ *Среднее время обслуживания:* %s",
+
+	"besttime.title":   "🕐 *Лучшее время для визита* \\(время офиса\\)",
+	"besttime.slot":    "*%s %s*: ожидание \\~%s, %s талонов/час",
+	"besttime.today":   "📅 *Лучшее время сегодня:* %s",
+	"besttime.note":    "_По данным очереди за последние две недели: обычное число ожидающих, делённое на число талонов, обслуженных за этот час\\._",
+	"besttime.no_data": "Пока недостаточно истории, чтобы найти лучшее время\\. Попробуйте через несколько дней\\.",
+	"weekday.0":        "Вс",
+	"weekday.1":        "Пн",
+	"weekday.2":        "Вт",
+	"weekday.3":        "Ср",
+	"weekday.4":        "Чт",
+	"weekday.5":        "Пт",
+	"weekday.6":        "Сб",
+
+	"chart.waiting_title": "Ожидающие клиенты, %s",
+	"chart.waiting_axis":  "Ожидают",
+	"chart.served_title":  "Обслужено талонов по часам, %s",
+	"chart.served_axis":   "Талоны",
+	"chart.outage":        "Сбой API DUW",
+
+	"queue.title":                 "🏢 *Очередь: %s \\(%s\\)*",
+	"queue.served":                "Обслужено",
+	"queue.waiting":               "Ожидает",
+	"queue.workplaces":            "Стоек",
+	"queue.avg_time":              "Среднее время",
+	"queue.avg_wait":              "Среднее ожидание",
+	"queue.last_ticket":           "Последний билет",
+	"queue.tickets_left":          "Осталось билетов",
+	"queue.status":                "Статус очереди",
+	"queue.ticket_estimate":       "🎫 *Ваш билет %s \\- осталось:* \\~%s \\(%s – %s\\), вызов около %s",
+	"queue.ticket_wait":           "🎫 *Ваш билет %s \\- осталось:* %s, вызов около %s",
+	"queue.ticket_turn":           "🎫 *Ваш билет %s \\- ваша очередь\\!*",
+	"queue.tickets_forecast":      "📉 *Билеты обычно заканчиваются к* \\~%s",
+	"queue.office_opens_today":    "🌙 *Ведомство сейчас закрыто\\.* Очередь откроется сегодня в %s",
+	"queue.office_opens_tomorrow": "🌙 *Ведомство сейчас закрыто\\.* Очередь откроется завтра в %s",
+	"queue.office_opens_on":       "🌙 *Ведомство сейчас закрыто\\.* Очередь откроется %s в %s",
+	"queue.synced":                "🔄 *Синхронизация:* %s",
+	"queue.age":                   "%s данные %s назад",
+	"queue.changed":               "⏰ *Изменение:* %s",
+	"queue.stale":                 "⚠️ *Данные устарели*: нет успешного обновления с %s",
+	"queue.velocity":              "🚀 *Скорость очереди:* %s билетов / %d мин\\. %s",
+	"queue.clear_time":            "⌛ *Очередь закончится примерно в* %s",
+	"queue.yesterday":             "📊 *Вчера в это время:* ожидали %d, осталось билетов %d",
+	"summary.waiting":             "ждут %d",
+	"summary.tickets_left":        "билетов осталось %d",
+	"summary.served":              "обслужено %d",
+	"summary.ticket_wait":         "\\~%s для %s",
+	"summary.ticket_turn":         "%s: ваша очередь\\!",
+	"status.open":                 "Открыта",
+	"status.closed":               "Закрыта",
+	"badge.label":                 "Очередь DUW",
+	"badge.waiting":               "ожидают: %d",
+	"badge.no_data":               "нет данных",
+	"app.language":                "Язык",
+	"app.alerts":                  "Уведомления об открытии/закрытии",
+	"app.weekly":                  "Недельный отчёт",
+	"app.threshold":               "Уведомление о билетах",
+	"app.off":                     "Выкл.",
+	"app.ticket":                  "Билет",
+	"app.save":                    "Сохранить",
+	"app.saved":                   "Сохранено",
+	"app.failed":                  "Не удалось сохранить",
+	"app.chart":                   "Ожидающие сегодня",
+	"app.open":                    "📱 Графики, прогноз по вашему билету и настройки в одном месте:",
+	"app.button":                  "Открыть приложение",
+	"app.unavailable":             "Приложение недоступно\\.",
+	"duration.hours_minutes":      "%d ч\\. %d мин\\.",
+	"duration.minutes":            "%d мин\\.",
+	"duration.seconds":            "%d сек\\.",
+
+	"alert.opened":            "🟢 *Очередь открылась\\!*\n\nОсталось билетов: %d",
+	"alert.closed":            "🔴 *Очередь закрылась*\n\nОбслужено сегодня: %d",
+	"alert.tickets_exhausted": "⛔ *Билеты закончились\\!*\n\nНа сегодня талонов больше нет, ехать в ведомство не имеет смысла\\.",
+	"alert.tickets_low":       "⚠️ *Билеты заканчиваются\\!*\n\nОсталось билетов: %d",
+	"alert.ticket_called":     "🔔 *Ваш номер %s вызван\\!*\n\nПодойдите к окну обслуживания\\.",
+	"alert.ticket_near":       "⏰ *Ваш номер %s скоро вызовут\\!* Перед вами всего %d билетов, подходите к офису\\.",
+
+	"admin.stats_error":          "Не удалось получить статистику\\.",
+	"admin.stats_title":          "📊 *Статистика бота*",
+	"admin.broadcast_usage":      "Использование: /broadcast \\<текст\\>",
+	"admin.broadcast_done":       "Сообщение отправлено %d из %d пользователей\\.",
+	"admin.users_error":          "Не удалось получить список пользователей\\.",
+	"admin.users_title":          "👥 *Активные пользователи: %d*",
+	"admin.users_more":           "\\.\\.\\. и ещё %d",
+	"admin.ban_usage":            "Использование: /ban \\<chat\\_id\\> или /unban \\<chat\\_id\\>",
+	"admin.ban_failed":           "Не удалось обновить пользователя %d: %s",
+	"admin.banned":               "🚫 Пользователь %d заблокирован\\.",
+	"admin.unbanned":             "✅ Пользователь %d разблокирован\\.",
+	"admin.interval_unavailable": "Изменение интервала недоступно\\.",
+	"admin.interval_usage":       "Использование: /setinterval 30s \\(от %s до %s\\)",
+	"admin.interval_changed":     "⏱ Интервал опроса изменён на %s\\.",
+	"admin.export_usage":         "Использование: /export \\[csv\\|json\\] \\[с\\] \\[по\\], даты в формате ГГГГ\\-ММ\\-ДД \\(по умолчанию: сегодня\\)",
+	"admin.export_failed":        "Не удалось отправить экспорт: %s",
+	"admin.panic":                "⚠️ *Перехвачена паника* в %s: %s\\. Трассировка стека в логе\\.",
+	"admin.restart":              "🔁 *%s остановился*: %s\\. Перезапуск %d через %s\\.",
+	"admin.apikey_usage":         "Использование: /apikey list, /apikey create \\<имя\\> \\[read\\|admin\\] \\[запросов в минуту\\] или /apikey revoke \\<id\\>",
+	"admin.apikey_created":       "🔑 *Ключ API %d создан*: %s, область %s, %d запросов в минуту\\.\n\n`%s`\n\nКлюч показывается только один раз\\.",
+	"admin.apikey_title":         "🔑 *Ключи API \\(%d\\)*",
+	"admin.apikey_none":          "Ключей API нет\\.",
+	"admin.apikey_revoked":       "🗑 Ключ API %d отозван\\.",
+	"admin.apikey_not_found":     "Ключ API %d не найден\\.",
+	"admin.apikey_error":         "Не удалось изменить ключи API: %s",
+}
@@ -0,0 +1,304 @@
+package i18n
+
+var ukrainian = map[string]string{
+	"language.name": "🇺🇦 Українська",
+
+	"help":               "Використовуйте команду /start, щоб отримати інформацію про чергу\\.\n\nЩоб відстежувати ваш квиток, надішліть його номер \\(наприклад: K222\\)\\.\n\nЩоб стежити за іншим офісом, надішліть /office\\.\n\nЩоб побачити користувачів бота в черзі поруч із вашим квитком, надішліть /queuepos\\.\n\nЩоб бачити час у своєму часовому поясі, надішліть /timezone\\.\n\nЩоб на деякий час призупинити оновлення черги, надішліть /mute\\.\n\nЩоб щопонеділка отримувати звіт про чергу, надішліть /weekly on\\.\n\nЩоб дізнатися години з найкоротшим очікуванням, надішліть /besttime\\.\n\nЩоб змінити налаштування за допомогою кнопок, надішліть /settings\\.\n\nЩоб повідомлення про стан черги оновлювалося рідше, надішліть /interval\\.\n\nЩоб отримувати сповіщення про вільні записи на rezerwacje\\.duw\\.pl, надішліть /slots\\.\n\nЩоб отримувати повідомлення про зміну статусу вашої справи, надішліть /case з номером справи\\.\n\nЩоб дізнатися, коли закінчиться черга очікуючих, надішліть /eta\\.\n\nЩоб дізнатися, наскільки надійно останнім часом відповідав API DUW, надішліть /uptime\\.\n\nЩоб відписатися, надішліть /stop\\.",
+	"error.registration": "Сталася помилка під час реєстрації\\. Спробуйте пізніше\\.",
+	"error.settings":     "Не вдалося зберегти налаштування\\. Спробуйте пізніше\\.",
+	"error.ticket_save":  "Не вдалося зберегти номер квитка\\. Спробуйте пізніше\\.",
+
+	"start.no_data_yet":    "Ласкаво просимо\\! Дані про чергу будуть доступні після першого оновлення\\.",
+	"start.no_data":        "Ласкаво просимо\\! Дані про чергу поки недоступні\\. Зачекайте на перше оновлення\\.",
+	"start.welcome_back":   "👋 *З поверненням\\!* Ваші налаштування відновлено:",
+	"stop.done":            "👋 Ви відписалися від оновлень черги\\. Налаштування збережено, надішліть /start, щоб підписатися знову\\.",
+	"stop.not_subscribed":  "Ви не підписані\\. Надішліть /start, щоб підписатися\\.",
+	"ticket.saved_no_data": "Квиток %s збережено\\! Дані про чергу будуть доступні після першого оновлення\\.",
+
+	"settings.ticket":         "🎫 Квиток: %s",
+	"settings.status_alerts":  "🔔 Сповіщення про відкриття/закриття черги",
+	"settings.threshold":      "📉 Сповіщення, коли залишиться %d квитків або менше",
+	"settings.language":       "🌐 Мова: %s",
+	"settings.menu":           "⚙️ *Налаштування*\n\nНатисніть кнопку, щоб змінити налаштування\\.",
+	"settings.menu_language":  "🌐 Оберіть мову:",
+	"settings.menu_threshold": "📉 Сповістити, коли залишиться стільки квитків або менше:",
+	"settings.menu_mute":      "🔕 Призупинити оновлення черги на:",
+	"settings.menu_interval":  "⏱ Оновлювати повідомлення про стан черги не частіше, ніж раз на:",
+	"settings.menu_ticket":    "🎫 Ваш квиток: %s",
+	"settings.ticket_prompt":  "🎫 Надішліть номер вашого квитка, наприклад: K222\\.",
+	"settings.closed":         "✅ Налаштування збережено\\.",
+
+	"button.language":        "🌐 Мова: %s",
+	"button.alerts_on":       "🔔 Сповіщення про відкриття/закриття: увімк.",
+	"button.alerts_off":      "🔕 Сповіщення про відкриття/закриття: вимк.",
+	"button.weekly_on":       "📅 Тижневий звіт: увімк.",
+	"button.weekly_off":      "📅 Тижневий звіт: вимк.",
+	"button.threshold":       "📉 Сповіщення про квитки: %d або менше",
+	"button.threshold_off":   "📉 Сповіщення про квитки: вимк.",
+	"button.muted":           "🔕 Пауза до %s",
+	"button.unmuted":         "🔔 Оновлення: увімк.",
+	"button.interval":        "⏱ Оновлення: не частіше разу на %d хв",
+	"button.interval_off":    "⏱ Оновлення: при кожній зміні",
+	"button.ticket":          "🎫 Квиток: %s",
+	"button.no_ticket":       "🎫 Зареєструвати квиток",
+	"button.forget_ticket":   "🗑 Забути квиток",
+	"button.change_ticket":   "✏️ Зареєструвати інший квиток",
+	"button.threshold_value": "%d або менше",
+	"button.off":             "Вимкнути",
+	"button.resume":          "🔔 Відновити оновлення",
+	"button.hours":           "%d год",
+	"button.minutes":         "%d хв",
+	"button.every_change":    "При кожній зміні",
+	"button.back":            "⬅️ Назад",
+	"button.close":           "✅ Готово",
+	"button.admins_only":     "Змінювати налаштування можуть лише адміністратори групи.",
+
+	"command.start":        "Підписатися й показати чергу",
+	"command.settings":     "Змінити налаштування кнопками",
+	"command.stats":        "Пропускна здатність і час до кінця черги",
+	"command.today":        "Очікувальники по годинах сьогодні",
+	"command.history":      "Підсумок за останні дні",
+	"command.chart":        "Графіки черги за сьогодні",
+	"command.besttime":     "Години з найкоротшим очікуванням",
+	"command.eta":          "Коли закінчиться черга",
+	"command.now":          "Короткий підсумок черги",
+	"command.uptime":       "Доступність API DUW",
+	"command.weekly":       "Тижневий звіт (on/off)",
+	"command.office":       "Стежити за іншим офісом",
+	"command.alerts":       "Сповіщення про відкриття/закриття (on/off)",
+	"command.slots":        "Сповіщення про вільні записи",
+	"command.threshold":    "Сповіщення, коли залишається мало квитків",
+	"command.interval":     "Оновлювати повідомлення про стан рідше",
+	"command.mute":         "Призупинити оновлення на деякий час",
+	"command.snooze_until": "Призупинити оновлення до часу",
+	"command.unmute":       "Відновити оновлення",
+	"command.pin":          "Закріпити повідомлення про стан (on/off)",
+	"command.case":         "Зміни статусу справи",
+	"command.queuepos":     "Користувачі бота в черзі поруч із вами",
+	"command.timezone":     "Показувати час у вашому часовому поясі",
+	"command.language":     "Змінити мову",
+	"command.stop":         "Відписатися",
+	"command.botstats":     "Статистика бота",
+	"command.broadcast":    "Надіслати повідомлення всім",
+	"command.users":        "Список активних користувачів",
+	"command.ban":          "Заблокувати користувача",
+	"command.unban":        "Розблокувати користувача",
+	"command.setinterval":  "Змінити інтервал опитування",
+	"command.export":       "Експорт історії черги",
+	"command.apikey":       "Ключі HTTP API",
+	"command.app":          "Відкрити застосунок",
+
+	"language.usage":   "Оберіть мову: /language ru, /language uk, /language pl або /language en\\.\n\nПоточна мова: %s",
+	"language.changed": "Мову змінено: %s",
+
+	"alerts.usage":              "Використовуйте /alerts on або /alerts off, щоб увімкнути чи вимкнути сповіщення про відкриття та закриття черги\\.",
+	"alerts.enabled":            "🔔 Сповіщення про відкриття та закриття черги увімкнено\\.",
+	"alerts.disabled":           "🔕 Сповіщення про відкриття та закриття черги вимкнено\\.",
+	"threshold.usage":           "Використовуйте /threshold N, щоб отримати сповіщення, коли залишиться N квитків або менше \\(/threshold 0 \\- коли квитки закінчаться\\), або /threshold off, щоб вимкнути\\.",
+	"threshold.enabled":         "🔔 Ви отримаєте сповіщення, коли залишиться %d квитків або менше\\.",
+	"threshold.disabled":        "🔕 Сповіщення про закінчення квитків вимкнено\\.",
+	"pin.usage":                 "Використовуйте /pin on або /pin off, щоб закріплювати чи не закріплювати повідомлення зі статусом черги\\.",
+	"pin.enabled":               "📌 Повідомлення зі статусом черги буде закріплено\\.",
+	"pin.disabled":              "Повідомлення зі статусом черги більше не закріплюється\\.",
+	"office.usage":              "🏢 *Офіс:* %s\n\nВідстежувані офіси: %s\n\nВикористовуйте /office \\<назва\\>, щоб стежити за іншим офісом, наприклад: /office legnica\\.",
+	"office.unknown":            "Невідомий офіс\\. Відстежувані офіси: %s",
+	"office.changed":            "🏢 Тепер ви стежите за чергою офісу: %s\\.",
+	"office.primary_only":       "Історія та статистика доступні лише для офісу: %s\\.",
+	"queuepos.usage":            "Використовуйте /queuepos, щоб дізнатися, скільки користувачів бота стоїть у черзі перед вами та після вас, і /queuepos on або /queuepos off, щоб анонімно поділитися своїм квитком або перестати ним ділитися\\.",
+	"queuepos.shared":           "👥 Ваш квиток тепер анонімно враховується\\. Надішліть /queuepos, щоб побачити користувачів бота в черзі поруч із вами\\.",
+	"queuepos.unshared":         "Ваш квиток більше не враховується\\.",
+	"queuepos.not_shared":       "👥 /queuepos показує лише квитки користувачів, які ними діляться\\. Поділіться своїм анонімно за допомогою /queuepos on\\.",
+	"queuepos.no_ticket":        "Спершу надішліть номер свого квитка \\(наприклад: K222\\)\\.",
+	"queuepos.called":           "Ваш квиток %s уже викликано\\.",
+	"queuepos.result":           "👥 *Користувачі бота в черзі поруч із квитком %s*\n\nКвитків до вашої черги: %d\nПеред вами: %d%s\nПісля вас: %d%s\n\nВраховуються лише квитки, якими поділилися через /queuepos on, у вигляді відстані від вашого\\.",
+	"timezone.usage":            "🕒 Час показується в часовому поясі *%s*\\. Надішліть /timezone з назвою поясу \\(наприклад: /timezone Europe/Kyiv\\), щоб змінити його, або /timezone reset, щоб повернути часовий пояс офісу\\.",
+	"timezone.unknown":          "❌ Невідомий часовий пояс %s\\. Використовуйте назву на кшталт Europe/Warsaw або Europe/Kyiv\\.",
+	"timezone.changed":          "✅ Тепер час показується в часовому поясі *%s*\\.",
+	"timezone.reset":            "✅ Тепер час показується в часовому поясі офісу *%s*\\.",
+	"mute.usage":                "🔕 Надішліть /mute з тривалістю \\(наприклад: /mute 2h або /mute 30m, не більше 7 днів\\) або /snooze\\_until з часом \\(наприклад: /snooze\\_until 14:00\\), щоб призупинити оновлення черги\\. Сповіщення про ваш квиток і далі надходитимуть\\.",
+	"mute.enabled":              "🔕 Оновлення черги призупинено до *%s*\\. Сповіщення про ваш квиток і далі надходитимуть\\. Надішліть /unmute, щоб відновити їх раніше\\.",
+	"mute.state":                "🔕 Оновлення черги призупинено до *%s*\\. Надішліть /unmute, щоб відновити їх\\.",
+	"mute.not_muted":            "Оновлення черги не призупинено\\.",
+	"mute.resumed":              "🔔 Оновлення черги відновлено\\.",
+	"mute.expired":              "🔔 Пауза закінчилася, оновлення черги відновлено\\.",
+	"snooze.usage":              "🔕 Надішліть /snooze\\_until з часом \\(наприклад: /snooze\\_until 14:00\\), щоб призупинити оновлення черги до цього часу\\.",
+	"interval.usage":            "⏱ Надішліть /interval з тривалістю \\(наприклад: /interval 5m, від 1 хвилини до 1 години\\), щоб повідомлення про стан черги оновлювалося не частіше, або /interval off, щоб отримувати кожне оновлення\\. Сповіщення й далі надходять одразу\\.",
+	"interval.enabled":          "⏱ Повідомлення про стан черги тепер оновлюється не частіше, ніж раз на *%d хв*\\. Сповіщення й далі надходять одразу\\.",
+	"interval.disabled":         "⏱ Повідомлення про стан черги знову оновлюється при кожній зміні\\.",
+	"slots.disabled":            "🗓 Відстеження вільних записів не налаштовано в цьому боті\\.",
+	"slots.title":               "🗓 *Вільні записи на rezerwacje\\.duw\\.pl*",
+	"slots.service_free":        "%s *%s:* вільно %s",
+	"slots.service_none":        "%s *%s:* вільних записів немає",
+	"slots.service_unknown":     "%s *%s:* ще не перевірено",
+	"slots.usage":               "Надішліть /slots з назвою послуги, щоб отримати сповіщення про нові записи \\(повторно, щоб вимкнути\\), або /slots off, щоб вимкнути всі сповіщення про записи\\.",
+	"slots.unknown":             "❌ Невідома послуга\\. Доступні: %s",
+	"slots.subscribed":          "🔔 Ви отримаєте сповіщення, коли з'являться нові записи на *%s*\\.",
+	"slots.unsubscribed":        "🔕 Сповіщення про записи на *%s* вимкнено\\.",
+	"slots.all_off":             "🔕 Сповіщення про записи вимкнено\\.",
+	"slots.more":                "і ще %d дн\\.",
+	"slots.alert":               "🗓 *Нові вільні записи на %s*\n\n%s\n\nЗапишіться швидше на rezerwacje\\.duw\\.pl, записи швидко закінчуються\\.",
+	"case.disabled":             "📄 Перевірку статусу справи не налаштовано в цьому боті\\.",
+	"case.usage":                "Надішліть /case з номером справи, наприклад /case SO\\-II\\.6151\\.12345\\.2024, щоб отримати повідомлення про зміну її статусу, або /case off, щоб вимкнути\\.",
+	"case.invalid":              "❌ Це не схоже на номер справи\\. Використайте номер із рішення або листа, наприклад SO\\-II\\.6151\\.12345\\.2024\\.",
+	"case.registered":           "📄 Справу *%s* зареєстровано\\. Поточний статус: %s\n\nВи отримаєте повідомлення, коли він зміниться\\.",
+	"case.registered_unchecked": "📄 Справу *%s* зареєстровано\\. Зараз не вдалося перевірити сторінку статусу, статус прийде під час наступної перевірки\\.",
+	"case.removed":              "📄 Перевірку статусу справи вимкнено\\.",
+	"case.current":              "📄 Справа *%s*: %s",
+	"case.unchecked":            "ще не перевірено",
+	"case.changed":              "📄 *Статус справи %s змінився*\n\n%s",
+	"case.ready":                "🎉 Схоже, карта готова до отримання\\. Перевірте години видачі перед візитом\\.",
+	"monitoring.missing_admin":  "⚠️ *Моніторинг %s працює з перебоями*\n\nЧерги немає у відповіді DUW, тому її користувачі не отримують оновлень\\. Якщо DUW перейменував її, додайте нову назву до QUEUE\\_ALIASES\\.\n\n%s",
+	"monitoring.found_admin":    "✅ *Моніторинг %s відновлено*\n\nЧерга знову є у відповіді DUW\\.",
+	"monitoring.missing":        "⚠️ *Оновлення черги %s призупинено*\n\nЧерги немає на сторінці статусу DUW, тому показані дані можуть бути застарілими\\. Ви отримаєте повідомлення, коли оновлення відновляться\\.",
+	"monitoring.found":          "✅ *Оновлення черги %s відновлено*",
+	"monitoring.stale_admin":    "⚠️ *Дані %s застаріли*\n\nЖодне опитування не вдалося з %s, повідомлення про стан її користувачів позначено як застарілі\\. Перевірте логи на помилки DNS, TLS або API\\.",
+	"monitoring.fresh_admin":    "✅ *Дані %s знову актуальні*",
+	"group.welcome":             "👋 Привіт\\! Адміністратор групи може надіслати /start, щоб група отримувала одне спільне повідомлення про стан черги\\. Відстеження квитка працює в особистому чаті з ботом\\.",
+	"group.personal":            "🔒 Ця команда працює лише для особистих квитків\\. Надішліть її боту в особистому чаті\\.",
+	"group.admins_only":         "🔒 Лише адміністратори групи можуть змінювати налаштування бота в цій групі\\.",
+	"commands.muted":            "⏳ Забагато команд\\. Бот ігноруватиме цей чат %s\\.",
+
+	"history.error":          "Не вдалося отримати історію\\. Спробуйте пізніше\\.",
+	"history.today_empty":    "За сьогодні даних поки немає\\.",
+	"history.period_empty":   "За вибраний період даних немає\\.",
+	"history.invalid_period": "Неправильний період\\. Використовуйте, наприклад: /history 3d \\(від 1 до %d днів\\)\\.",
+	"history.today_title":    "📈 *Сьогодні: очікують за годинами*",
+	"history.peak":           "🔺 *Пік:* %s",
+	"history.served":         "✅ *Обслуговано:* %d",
+	"history.title":          "📅 *Історія за %d дн\\.*",
+	"history.day_summary":    "обслуговано %d, макс\\. очікували %d, у середньому %.0f, мін\\. квитків %d",
+	"uptime.title":           "🩺 *Доступність API DUW*",
+	"uptime.day":             "24 години",
+	"uptime.week":            "7 днів",
+	"uptime.period":          "%s: *%s %%*, збоїв: %d, недоступно %s",
+	"uptime.recent":          "*Останні збої:*",
+	"uptime.outage":          "• %s – %s \\(%s\\)",
+	"uptime.ongoing":         "триває",
+	"uptime.none":            "✅ За останні 7 днів збоїв не було\\.",
+	"uptime.error":           "Не вдалося завантажити збої\\. Спробуйте пізніше\\.",
+	"eta.estimate":           "⌛ Очікують %d клієнтів\\. За поточної швидкості черга закінчиться приблизно о *%s*\\.",
+	"eta.closed":             "Зараз управління зачинене, черги немає\\.",
+	"eta.empty":              "✅ Зараз ніхто не чекає\\.",
+	"eta.no_data":            "Поки недостатньо даних за сьогодні, щоб оцінити, коли закінчиться черга\\. Спробуйте пізніше\\.",
+
+	"stats.title":               "📊 *Статистика черги за сьогодні*",
+	"stats.throughput":          "🎫 *Пропускна здатність:* %s талонів/год\\.",
+	"stats.service_time":        "⏱ *Середній час обслуговування \\(за 2 год\\.\\):* %s",
+	"stats.service_percentiles": "⏱ *Час обслуговування сьогодні:* медіана %s, 90%% у межах %s",
+	"stats.clear_time":          "⌛ *Тих, хто очікує, обслужать через:* \\~%s",
+	"stats.vs_last_week":        "📅 *Той самий день тиждень тому:* %d обслуговано до цього часу \\(сьогодні %s\\)",
+
+	"weekly.usage":               "📊 Надішліть /weekly on, щоб щопонеділка отримувати звіт про чергу за минулий тиждень, /weekly off, щоб вимкнути його, або /weekly, щоб побачити останній звіт зараз\\.",
+	"weekly.enabled":             "✅ Ви отримуватимете тижневий звіт про чергу щопонеділка\\.",
+	"weekly.disabled":            "Тижневі звіти вимкнено\\.",
+	"weekly.title":               "📊 *Тижневий звіт %s*",
+	"weekly.served":              "✅ *Обслуговано:* %d за %d дн\\.",
+	"weekly.vs_previous":         "\\(%s до минулого тижня\\)",
+	"weekly.busiest":             "📈 *Найзавантаженіший день:* %s, обслуговано %d",
+	"weekly.exhaustion":          "🎫 *Талони закінчилися* в %d з %d дн\\., в середньому о \\~%s",
+	"weekly.previous_exhaustion": "\\(минулий тиждень \\~%s\\)",
+	"weekly.not_exhausted":       "🎫 *Цього тижня талони не закінчувалися*",
+	"weekly.service_time":        "⏱ *Середній час обслуговування:* %s",
+
+	"besttime.title":   "🕐 *Найкращий час для візиту* \\(час офісу\\)",
+	"besttime.slot":    "*%s %s*: очікування \\~%s, %s талонів/год",
+	"besttime.today":   "📅 *Найкращий час сьогодні:* %s",
+	"besttime.note":    "_За даними черги за останні два тижні: звичайна кількість тих, хто чекає, поділена на кількість талонів, обслугованих за цю годину\\._",
+	"besttime.no_data": "Поки що замало історії, щоб знайти найкращий час\\. Спробуйте за кілька днів\\.",
+	"weekday.0":        "Нд",
+	"weekday.1":        "Пн",
+	"weekday.2":        "Вт",
+	"weekday.3":        "Ср",
+	"weekday.4":        "Чт",
+	"weekday.5":        "Пт",
+	"weekday.6":        "Сб",
+
+	"chart.waiting_title": "Клієнти в очікуванні, %s",
+	"chart.waiting_axis":  "Очікують",
+	"chart.served_title":  "Обслуговано талонів по годинах, %s",
+	"chart.served_axis":   "Талони",
+	"chart.outage":        "Збій API DUW",
+
+	"queue.title":                 "🏢 *Черга: %s \\(%s\\)*",
+	"queue.served":                "Обслуговано",
+	"queue.waiting":               "Очікує",
+	"queue.workplaces":            "Віконець",
+	"queue.avg_time":              "Середній час",
+	"queue.avg_wait":              "Середнє очікування",
+	"queue.last_ticket":           "Останній квиток",
+	"queue.tickets_left":          "Залишилось квитків",
+	"queue.status":                "Статус черги",
+	"queue.ticket_estimate":       "🎫 *Ваш квиток %s \\- залишилось:* \\~%s \\(%s – %s\\), виклик близько %s",
+	"queue.ticket_wait":           "🎫 *Ваш квиток %s \\- залишилось:* %s, виклик близько %s",
+	"queue.ticket_turn":           "🎫 *Ваш квиток %s \\- ваша черга\\!*",
+	"queue.tickets_forecast":      "📉 *Квитки зазвичай закінчуються до* \\~%s",
+	"queue.office_opens_today":    "🌙 *Установа зараз зачинена\\.* Черга відкриється сьогодні о %s",
+	"queue.office_opens_tomorrow": "🌙 *Установа зараз зачинена\\.* Черга відкриється завтра о %s",
+	"queue.office_opens_on":       "🌙 *Установа зараз зачинена\\.* Черга відкриється %s о %s",
+	"queue.synced":                "🔄 *Синхронізація:* %s",
+	"queue.age":                   "%s дані %s тому",
+	"queue.changed":               "⏰ *Зміна:* %s",
+	"queue.stale":                 "⚠️ *Дані застаріли*: немає успішного оновлення з %s",
+	"queue.velocity":              "🚀 *Швидкість черги:* %s квитків / %d хв\\. %s",
+	"queue.clear_time":            "⌛ *Черга закінчиться приблизно о* %s",
+	"queue.yesterday":             "📊 *Учора в цей час:* очікували %d, залишалось квитків %d",
+	"summary.waiting":             "чекають %d",
+	"summary.tickets_left":        "квитків залишилось %d",
+	"summary.served":              "обслуговано %d",
+	"summary.ticket_wait":         "\\~%s для %s",
+	"summary.ticket_turn":         "%s: ваша черга\\!",
+	"status.open":                 "Відкрита",
+	"status.closed":               "Закрита",
+	"badge.label":                 "Черга DUW",
+	"badge.waiting":               "очікують: %d",
+	"badge.no_data":               "немає даних",
+	"app.language":                "Мова",
+	"app.alerts":                  "Сповіщення про відкриття/закриття",
+	"app.weekly":                  "Тижневий звіт",
+	"app.threshold":               "Сповіщення про квитки",
+	"app.off":                     "Вимк.",
+	"app.ticket":                  "Квиток",
+	"app.save":                    "Зберегти",
+	"app.saved":                   "Збережено",
+	"app.failed":                  "Не вдалося зберегти",
+	"app.chart":                   "Очікують сьогодні",
+	"app.open":                    "📱 Графіки, прогноз для вашого квитка та налаштування в одному місці:",
+	"app.button":                  "Відкрити застосунок",
+	"app.unavailable":             "Застосунок недоступний\\.",
+	"duration.hours_minutes":      "%d год\\. %d хв\\.",
+	"duration.minutes":            "%d хв\\.",
+	"duration.seconds":            "%d сек\\.",
+
+	"alert.opened":            "🟢 *Черга відкрилася\\!*\n\nЗалишилось квитків: %d",
+	"alert.closed":            "🔴 *Черга закрилася*\n\nОбслуговано сьогодні: %d",
+	"alert.tickets_exhausted": "⛔ *Квитки закінчилися\\!*\n\nНа сьогодні квитків більше немає, їхати до управління немає сенсу\\.",
+	"alert.tickets_low":       "⚠️ *Квитки закінчуються\\!*\n\nЗалишилось квитків: %d",
+	"alert.ticket_called":     "🔔 *Ваш номер %s викликано\\!*\n\nПідійдіть до вікна обслуговування\\.",
+	"alert.ticket_near":       "⏰ *Ваш номер %s скоро викличуть\\!* Перед вами лише %d квитків, підходьте до офісу\\.",
+
+	"admin.stats_error":          "Не вдалося отримати статистику\\.",
+	"admin.stats_title":          "📊 *Статистика бота*",
+	"admin.broadcast_usage":      "Використання: /broadcast \\<текст\\>",
+	"admin.broadcast_done":       "Повідомлення надіслано %d з %d користувачів\\.",
+	"admin.users_error":          "Не вдалося отримати список користувачів\\.",
+	"admin.users_title":          "👥 *Активні користувачі: %d*",
+	"admin.users_more":           "\\.\\.\\. і ще %d",
+	"admin.ban_usage":            "Використання: /ban \\<chat\\_id\\> або /unban \\<chat\\_id\\>",
+	"admin.ban_failed":           "Не вдалося оновити користувача %d: %s",
+	"admin.banned":               "🚫 Користувача %d заблоковано\\.",
+	"admin.unbanned":             "✅ Користувача %d розблоковано\\.",
+	"admin.interval_unavailable": "Зміна інтервалу недоступна\\.",
+	"admin.interval_usage":       "Використання: /setinterval 30s \\(від %s до %s\\)",
+	"admin.interval_changed":     "⏱ Інтервал опитування змінено на %s\\.",
+	"admin.export_usage":         "Використання: /export \\[csv\\|json\\] \\[з\\] \\[по\\], дати у форматі РРРР\\-ММ\\-ДД \\(за замовчуванням: сьогодні\\)",
+	"admin.export_failed":        "Не вдалося надіслати експорт: %s",
+	"admin.panic":                "⚠️ *Перехоплено паніку* в %s: %s\\. Трасування стеку в лозі\\.",
+	"admin.restart":              "🔁 *%s зупинився*: %s\\. Перезапуск %d через %s\\.",
+	"admin.apikey_usage":         "Використання: /apikey list, /apikey create \\<ім'я\\> \\[read\\|admin\\] \\[запитів на хвилину\\] або /apikey revoke \\<id\\>",
+	"admin.apikey_created":       "🔑 *Ключ API %d створено*: %s, область %s, %d запитів на хвилину\\.\n\n`%s`\n\nКлюч показується лише один раз\\.",
+	"admin.apikey_title":         "🔑 *Ключі API \\(%d\\)*",
+	"admin.apikey_none":          "Ключів API немає\\.",
+	"admin.apikey_revoked":       "🗑 Ключ API %d відкликано\\.",
+	"admin.apikey_not_found":     "Ключ API %d не знайдено\\.",
+	"admin.apikey_error":         "Не вдалося змінити ключі API: %s",
+}
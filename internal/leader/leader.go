@@ -0,0 +1,100 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// LeaseName is the lease shared by all instances of the bot
+const LeaseName = "karta"
+
+// Store keeps leases in the shared database; implemented by *database.Database
+type Store interface {
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// Elector holds a lease in the database so that only one of several instances polls DUW
+// and sends notifications. The lease is renewed three times per TTL; a standby instance
+// takes over once the leader stops renewing it.
+type Elector struct {
+	store  Store
+	name   string
+	holder string
+	ttl    time.Duration
+}
+
+// New creates an elector competing for the named lease as holder
+func New(store Store, name, holder string, ttl time.Duration) *Elector {
+	return &Elector{store: store, name: name, holder: holder, ttl: ttl}
+}
+
+// Wait blocks until the lease is acquired or the context is cancelled
+func (e *Elector) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	logged := false
+	for {
+		acquired, err := e.store.AcquireLease(ctx, e.name, e.holder, e.ttl)
+		switch {
+		case err != nil:
+			log.Printf("Leader election: %v", err)
+		case acquired:
+			log.Printf("Leader election: %s is the leader", e.holder)
+			return nil
+		case !logged:
+			log.Printf("Leader election: another instance is the leader, %s is standing by", e.holder)
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Keep renews the lease until the context is cancelled, then releases it. It returns an
+// error as soon as the lease is lost: taken over by another instance, or not renewed
+// before it expired because the database was unreachable.
+func (e *Elector) Keep(ctx context.Context) error {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			e.release()
+			return nil
+		case <-ticker.C:
+		}
+
+		acquired, err := e.store.AcquireLease(ctx, e.name, e.holder, e.ttl)
+		switch {
+		case err != nil:
+			log.Printf("Leader election: failed to renew lease: %v", err)
+			if time.Since(renewed) >= e.ttl {
+				return fmt.Errorf("lease %s expired: %w", e.name, err)
+			}
+		case !acquired:
+			return fmt.Errorf("lease %s was taken over by another instance", e.name)
+		default:
+			renewed = time.Now()
+		}
+	}
+}
+
+// release gives up the lease so a standby instance takes over without waiting for it to
+// expire. It runs after the context of Keep is cancelled, so it does not use it.
+func (e *Elector) release() {
+	if err := e.store.ReleaseLease(context.Background(), e.name, e.holder); err != nil {
+		log.Printf("Leader election: %v", err)
+		return
+	}
+	log.Printf("Leader election: %s released the lease", e.holder)
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// Level selects which log lines are written
+type Level int32
+
+const (
+	LevelDebug Level = iota // Also every poll and its details
+	LevelInfo               // Changes, alerts, warnings and errors
+)
+
+// level is the current level; it changes when the configuration is reloaded
+var level atomic.Int32
+
+func init() {
+	level.Store(int32(LevelInfo))
+}
+
+// ParseLevel parses a level name: debug or info, info when empty
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, expected debug or info", name)
+	}
+}
+
+// String returns the level name
+func (l Level) String() string {
+	if l == LevelDebug {
+		return "debug"
+	}
+	return "info"
+}
+
+// SetLevel changes the current level; safe to call while logging
+func SetLevel(l Level) {
+	level.Store(int32(l))
+}
+
+// Debugf logs like log.Printf when the level is debug
+func Debugf(format string, args ...interface{}) {
+	if Level(level.Load()) == LevelDebug {
+		log.Output(2, fmt.Sprintf(format, args...))
+	}
+}
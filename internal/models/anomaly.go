@@ -0,0 +1,44 @@
+package models
+
+import "fmt"
+
+// MaxWaitingJump is the largest plausible change of waiting clients between two polls
+const MaxWaitingJump = 100
+
+// DetectAnomalies returns descriptions of implausible values in the current data compared
+// with the previous poll: a jump of waiting clients, served clients going down during the
+// day, or workplaces dropping to zero while the queue is open. It returns nil for
+// plausible data.
+func DetectAnomalies(previous, current *QueueData) []string {
+	var anomalies []string
+
+	// Only the drop to zero is suspicious; once confirmed, later polls with zero are not
+	if current.Status == StatusOpen && current.Workplaces == 0 && (previous == nil || previous.Workplaces > 0) {
+		anomalies = append(anomalies, "no workplaces while the queue is open")
+	}
+
+	if previous == nil {
+		return anomalies
+	}
+
+	if jump := current.WaitingClients - previous.WaitingClients; jump > MaxWaitingJump || jump < -MaxWaitingJump {
+		anomalies = append(anomalies, fmt.Sprintf("waiting clients jumped from %d to %d", previous.WaitingClients, current.WaitingClients))
+	}
+
+	// The served counter resets overnight, so only a decrease within the open day counts
+	if current.ServedClients < previous.ServedClients && previous.Status == StatusOpen && sameDay(previous, current) {
+		anomalies = append(anomalies, fmt.Sprintf("served clients went down from %d to %d", previous.ServedClients, current.ServedClients))
+	}
+
+	return anomalies
+}
+
+// sameDay reports whether both polls happened on the same local day
+func sameDay(previous, current *QueueData) bool {
+	if previous.LastUpdated.IsZero() || current.LastUpdated.IsZero() {
+		return true
+	}
+	py, pm, pd := previous.LastUpdated.Local().Date()
+	cy, cm, cd := current.LastUpdated.Local().Date()
+	return py == cy && pm == cm && pd == cd
+}
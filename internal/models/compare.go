@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field keys of QueueChanges.ChangedFields and Comparison
+const (
+	FieldName           = "name"
+	FieldServedClients  = "served_clients"
+	FieldWaitingClients = "waiting_clients"
+	FieldWorkplaces     = "workplaces"
+	FieldAvgServiceTime = "avg_service_time"
+	FieldAvgWaitTime    = "avg_wait_time"
+	FieldLastTicket     = "last_ticket"
+	FieldTicketsLeft    = "tickets_left"
+	FieldStatus         = "status"
+)
+
+// queueField reads a compared field: numeric fields have number set, the others text
+type queueField struct {
+	number func(q *QueueData) float64 // Counts, or seconds for average times
+	text   func(q *QueueData) string
+	isTime bool
+}
+
+// queueFields lists the fields that can be compared
+var queueFields = map[string]queueField{
+	FieldName:           {text: func(q *QueueData) string { return q.Name }},
+	FieldServedClients:  {number: func(q *QueueData) float64 { return float64(q.ServedClients) }},
+	FieldWaitingClients: {number: func(q *QueueData) float64 { return float64(q.WaitingClients) }},
+	FieldWorkplaces:     {number: func(q *QueueData) float64 { return float64(q.Workplaces) }},
+	FieldAvgServiceTime: {number: func(q *QueueData) float64 { return q.AvgServiceTime.Seconds() }, isTime: true},
+	FieldAvgWaitTime:    {number: func(q *QueueData) float64 { return q.AvgWaitTime.Seconds() }, isTime: true},
+	FieldLastTicket:     {text: func(q *QueueData) string { return q.LastTicket }},
+	FieldTicketsLeft:    {number: func(q *QueueData) float64 { return float64(q.TicketsLeft) }},
+	FieldStatus:         {text: func(q *QueueData) string { return q.Status }},
+}
+
+// Comparison selects the fields that count as a change of the queue and how much a
+// numeric field has to change between two polls to count
+type Comparison struct {
+	Fields     []string
+	Tolerances map[string]float64 // Changes smaller than this are ignored; seconds for average times
+}
+
+// DefaultComparison compares every field except the average times, which change on most polls
+var DefaultComparison = Comparison{Fields: []string{
+	FieldName, FieldServedClients, FieldWaitingClients, FieldWorkplaces,
+	FieldLastTicket, FieldTicketsLeft, FieldStatus,
+}}
+
+// Compare returns the changes of the compared fields between two queue states
+func (c Comparison) Compare(previous, current *QueueData) *QueueChanges {
+	changes := &QueueChanges{
+		HasChanges:    previous == nil,
+		ChangedFields: make(map[string]bool),
+		Deltas:        make(map[string]int),
+		PreviousData:  previous,
+		CurrentData:   current,
+	}
+	if previous == nil {
+		return changes
+	}
+
+	for _, key := range c.Fields {
+		field, ok := queueFields[key]
+		if !ok {
+			continue
+		}
+
+		if field.number != nil {
+			delta := field.number(current) - field.number(previous)
+			if delta == 0 || math.Abs(delta) < c.Tolerances[key] {
+				continue
+			}
+			changes.Deltas[key] = int(delta)
+		} else if field.text(previous) == field.text(current) {
+			continue
+		}
+
+		changes.HasChanges = true
+		changes.ChangedFields[key] = true
+	}
+
+	return changes
+}
+
+// formatDelta formats the change of a numeric field as " (▲5)" or " (▼1 min.)", empty for
+// text fields and unchanged values
+func formatDelta(key string, previous, current *QueueData) string {
+	field, ok := queueFields[key]
+	if !ok || field.number == nil {
+		return ""
+	}
+
+	delta := field.number(current) - field.number(previous)
+	arrow := "▲"
+	if delta < 0 {
+		arrow, delta = "▼", -delta
+	}
+
+	switch {
+	case delta == 0:
+		return ""
+	case field.isTime:
+		return fmt.Sprintf(" (%s%s)", arrow, FormatServiceTime(time.Duration(delta)*time.Second))
+	default:
+		return fmt.Sprintf(" (%s%d)", arrow, int(delta))
+	}
+}
+
+// ParseComparison parses comma-separated compared fields (empty for the default ones) and
+// tolerances like "avg_service_time=60s, waiting_clients=2". Tolerances of average times
+// are durations or plain seconds.
+func ParseComparison(fields, tolerances string) (Comparison, error) {
+	comparison := Comparison{Fields: DefaultComparison.Fields}
+
+	if strings.TrimSpace(fields) != "" {
+		comparison.Fields = nil
+		for _, key := range strings.Split(fields, ",") {
+			key = strings.ToLower(strings.TrimSpace(key))
+			if key == "" {
+				continue
+			}
+			if _, ok := queueFields[key]; !ok {
+				return Comparison{}, fmt.Errorf("unknown field %q", key)
+			}
+			comparison.Fields = append(comparison.Fields, key)
+		}
+	}
+
+	for _, entry := range strings.Split(tolerances, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(entry, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		field, ok := queueFields[key]
+		if !found || !ok || field.number == nil {
+			return Comparison{}, fmt.Errorf("invalid tolerance %q: expected <numeric field>=<value>", entry)
+		}
+
+		tolerance, err := parseTolerance(value, field.isTime)
+		if err != nil {
+			return Comparison{}, fmt.Errorf("invalid tolerance %q: %w", entry, err)
+		}
+
+		if comparison.Tolerances == nil {
+			comparison.Tolerances = make(map[string]float64)
+		}
+		comparison.Tolerances[key] = tolerance
+	}
+
+	return comparison, nil
+}
+
+// parseTolerance parses a non-negative tolerance, accepting durations for time fields
+func parseTolerance(value string, isTime bool) (float64, error) {
+	if isTime {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			return d.Seconds(), nil
+		}
+	}
+
+	tolerance, err := strconv.ParseFloat(value, 64)
+	if err != nil || tolerance < 0 {
+		return 0, fmt.Errorf("must be a non-negative number, got %q", value)
+	}
+	return tolerance, nil
+}
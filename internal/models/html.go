@@ -0,0 +1,75 @@
+package models
+
+import (
+	"html"
+	"strings"
+)
+
+// Telegram parse modes of outgoing messages
+const (
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeHTML       = "HTML"
+)
+
+// htmlTags maps the MarkdownV2 entity markers used in messages to Telegram HTML tags
+var htmlTags = map[rune]string{
+	'*': "b",
+	'_': "i",
+	'~': "s",
+	'`': "code",
+}
+
+// MarkdownToHTML renders a message written in Telegram MarkdownV2, as all catalog texts
+// and message layouts are, in Telegram HTML: escaped characters become HTML-escaped
+// text and *bold*, _italic_, ~strikethrough~ and `code` become tags. Entities crossing
+// each other, e.g. *a _b* c_, are kept balanced by closing the inner tags before the
+// outer one and reopening them after it; entities left open are closed at the end.
+func MarkdownToHTML(text string) string {
+	var builder strings.Builder
+	var open []rune // Entity markers currently open, innermost last
+	escaped := false
+
+	openIndex := func(marker rune) int {
+		for i, m := range open {
+			if m == marker {
+				return i
+			}
+		}
+		return -1
+	}
+	closeFrom := func(i int) {
+		for j := len(open) - 1; j >= i; j-- {
+			builder.WriteString("</" + htmlTags[open[j]] + ">")
+		}
+	}
+
+	for _, r := range text {
+		_, isMarker := htmlTags[r]
+		inCode := len(open) > 0 && open[len(open)-1] == '`'
+		switch {
+		case escaped:
+			builder.WriteString(html.EscapeString(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case !isMarker || (inCode && r != '`'):
+			// Entity markers are plain text inside code
+			builder.WriteString(html.EscapeString(string(r)))
+		case openIndex(r) >= 0:
+			i := openIndex(r)
+			closeFrom(i)
+			inner := open[i+1:]
+			open = append(open[:i:i], inner...)
+			for _, m := range inner {
+				builder.WriteString("<" + htmlTags[m] + ">")
+			}
+		default:
+			builder.WriteString("<" + htmlTags[r] + ">")
+			open = append(open, r)
+		}
+	}
+
+	closeFrom(0)
+
+	return builder.String()
+}
@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain text", text: "Queue 5 < 6 & open", want: "Queue 5 &lt; 6 &amp; open"},
+		{name: "escaped characters", text: `K\-123 \*not bold\* 1\.5`, want: "K-123 *not bold* 1.5"},
+		{name: "entities", text: "*bold* _italic_ ~gone~ `K123`", want: "<b>bold</b> <i>italic</i> <s>gone</s> <code>K123</code>"},
+		{name: "nested entities", text: "*a _b_ c*", want: "<b>a <i>b</i> c</b>"},
+		{name: "crossing entities", text: "*a _b* c_", want: "<b>a <i>b</i></b><i> c</i>"},
+		{name: "crossing three entities", text: "*a _b ~c* d_ e~", want: "<b>a <i>b <s>c</s></i></b><i><s> d</s></i><s> e</s>"},
+		{name: "markers inside code", text: "`a_b*c`", want: "<code>a_b*c</code>"},
+		{name: "entities left open", text: "*a _b", want: "<b>a <i>b</i></b>"},
+		{name: "HTML in entities", text: "*<script>*", want: "<b>&lt;script&gt;</b>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToHTML(tt.text); got != tt.want {
+				t.Errorf("MarkdownToHTML(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queueDataJSON is the JSON form of QueueData with average times in whole seconds
+type queueDataJSON struct {
+	Name           string    `json:"name"`
+	ServedClients  int       `json:"served_clients"`
+	WaitingClients int       `json:"waiting_clients"`
+	Workplaces     int       `json:"workplaces"`
+	AvgServiceTime int       `json:"avg_service_time"` // Seconds, 0 when unknown
+	AvgWaitTime    int       `json:"avg_wait_time"`    // Seconds, 0 when unknown
+	LastTicket     string    `json:"last_ticket"`
+	TicketsLeft    int       `json:"tickets_left"`
+	Status         string    `json:"status"`
+	LastUpdated    time.Time `json:"last_updated"`
+	LastChanged    time.Time `json:"last_changed"`
+	Office         string    `json:"office,omitempty"`
+}
+
+// legacyQueueDataJSON accepts both the current JSON form and the one written before
+// QueueData had typed fields, with numbers as strings and times like "6 min."
+type legacyQueueDataJSON struct {
+	queueDataJSON
+	ServedClients  flexibleNumber `json:"served_clients"`
+	WaitingClients flexibleNumber `json:"waiting_clients"`
+	Workplaces     flexibleNumber `json:"workplaces"`
+	AvgServiceTime flexibleNumber `json:"avg_service_time"`
+	AvgWaitTime    flexibleNumber `json:"avg_wait_time"`
+	TicketsLeft    flexibleNumber `json:"tickets_left"`
+}
+
+// flexibleNumber is a JSON number or the raw text of a legacy string value
+type flexibleNumber struct {
+	value  int
+	text   string
+	isText bool
+}
+
+// UnmarshalJSON accepts a number or a string
+func (n *flexibleNumber) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		n.isText = true
+		return json.Unmarshal(data, &n.text)
+	}
+	return json.Unmarshal(data, &n.value)
+}
+
+// count returns the number, parsing legacy text; unparsable text counts as zero
+func (n flexibleNumber) count() int {
+	if !n.isText {
+		return n.value
+	}
+	value, _ := strconv.Atoi(strings.TrimSpace(n.text))
+	return value
+}
+
+// duration returns the number as seconds, parsing legacy text like "6 min."
+func (n flexibleNumber) duration() time.Duration {
+	if !n.isText {
+		return time.Duration(n.value) * time.Second
+	}
+	return ParseServiceTime(n.text)
+}
+
+// MarshalJSON encodes queue data with average times in whole seconds
+func (q QueueData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(queueDataJSON{
+		Name:           q.Name,
+		ServedClients:  q.ServedClients,
+		WaitingClients: q.WaitingClients,
+		Workplaces:     q.Workplaces,
+		AvgServiceTime: int(q.AvgServiceTime.Seconds()),
+		AvgWaitTime:    int(q.AvgWaitTime.Seconds()),
+		LastTicket:     q.LastTicket,
+		TicketsLeft:    q.TicketsLeft,
+		Status:         q.Status,
+		LastUpdated:    q.LastUpdated,
+		LastChanged:    q.LastChanged,
+		Office:         q.Office,
+	})
+}
+
+// UnmarshalJSON decodes queue data written by MarshalJSON or by older versions
+func (q *QueueData) UnmarshalJSON(data []byte) error {
+	var decoded legacyQueueDataJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*q = QueueData{
+		Name:           decoded.Name,
+		ServedClients:  decoded.ServedClients.count(),
+		WaitingClients: decoded.WaitingClients.count(),
+		Workplaces:     decoded.Workplaces.count(),
+		AvgServiceTime: decoded.AvgServiceTime.duration(),
+		AvgWaitTime:    decoded.AvgWaitTime.duration(),
+		LastTicket:     decoded.LastTicket,
+		TicketsLeft:    decoded.TicketsLeft.count(),
+		Status:         decoded.Status,
+		LastUpdated:    decoded.LastUpdated,
+		LastChanged:    decoded.LastChanged,
+		Office:         decoded.Office,
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MessageKind is a kind of message the bot sends on its own, as opposed to command replies
+type MessageKind string
+
+const (
+	MessageLive         MessageKind = "live"         // New live status message, when the previous one cannot be edited
+	MessageStatusAlert  MessageKind = "status"       // Queue opened or closed
+	MessageTicketsAlert MessageKind = "tickets"      // Tickets left reached the user's threshold
+	MessageTicketNear   MessageKind = "near"         // The user's ticket is a few tickets away
+	MessageTicketCalled MessageKind = "called"       // The user's ticket was called
+	MessageWeeklyReport MessageKind = "weekly"       // Monday report of the past week
+	MessageReminder     MessageKind = "reminder"     // A mute ended
+	MessageAnnouncement MessageKind = "announcement" // Admin /broadcast
+	MessageSlots        MessageKind = "slots"        // Free appointment slots appeared
+	MessageCaseStatus   MessageKind = "case"         // The status of the user's case changed
+	MessageMonitoring   MessageKind = "monitoring"   // Monitoring of a queue degraded or recovered
+)
+
+// DefaultSilentMessages are sent without sound by default: the live status message is
+// refreshed all day, while alerts are worth a notification
+const DefaultSilentMessages = "live"
+
+// MessageKinds returns all message kinds
+func MessageKinds() []MessageKind {
+	return []MessageKind{
+		MessageLive, MessageStatusAlert, MessageTicketsAlert, MessageTicketNear,
+		MessageTicketCalled, MessageWeeklyReport, MessageReminder, MessageAnnouncement, MessageSlots, MessageCaseStatus,
+		MessageMonitoring,
+	}
+}
+
+// ParseMessageKinds parses a comma-separated list of message kinds; "none" is the empty list
+func ParseMessageKinds(value string) ([]MessageKind, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "none" {
+		return nil, nil
+	}
+
+	var kinds []MessageKind
+	for _, name := range strings.Split(value, ",") {
+		kind := MessageKind(strings.TrimSpace(name))
+		known := false
+		for _, k := range MessageKinds() {
+			known = known || k == kind
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown message kind %q", kind)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
@@ -2,147 +2,422 @@ package models
 
 import (
 	"fmt"
+	"log"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/schedule"
+)
+
+// DefaultOfficeName is the city of queue data without an office, recorded before offices were added
+const DefaultOfficeName = "Wrocław"
+
+// DefaultQueueName is the queue of data without a queue name, recorded before queue types were added
+const DefaultQueueName = "odbiór karty"
+
+const (
+	StatusOpen   = "Dostępna"
+	StatusClosed = "Zamknięta"
 )
 
-// QueueData represents the queue information from the DUW website
+// StatusTransition describes a change of queue availability between two polls
+type StatusTransition int
+
+const (
+	TransitionNone StatusTransition = iota
+	TransitionOpened
+	TransitionClosed
+)
+
+// String returns the transition name used in logs and webhook payloads
+func (t StatusTransition) String() string {
+	switch t {
+	case TransitionOpened:
+		return "opened"
+	case TransitionClosed:
+		return "closed"
+	default:
+		return "none"
+	}
+}
+
+// QueueData represents the queue information from the DUW website. Average times are
+// zero when DUW does not report them; see MarshalJSON for the JSON form.
 type QueueData struct {
-	Name           string    `json:"name"`
-	ServedClients  string    `json:"served_clients"`
-	WaitingClients string    `json:"waiting_clients"`
-	Workplaces     string    `json:"workplaces"`
-	AvgServiceTime string    `json:"avg_service_time"`
-	AvgWaitTime    string    `json:"avg_wait_time"`
-	LastTicket     string    `json:"last_ticket"`
-	TicketsLeft    string    `json:"tickets_left"`
-	Status         string    `json:"status"`
-	LastUpdated    time.Time `json:"last_updated"`
-	LastChanged    time.Time `json:"last_changed"`
+	Name           string // Queue of the office, e.g. "odbiór karty"
+	ServedClients  int
+	WaitingClients int
+	Workplaces     int
+	AvgServiceTime time.Duration
+	AvgWaitTime    time.Duration
+	LastTicket     string
+	TicketsLeft    int
+	Status         string
+	LastUpdated    time.Time
+	LastChanged    time.Time
+	Office         string // City of the office, Wrocław when empty
 }
 
 // QueueChanges represents changes between two queue states
 type QueueChanges struct {
 	HasChanges    bool
 	ChangedFields map[string]bool
+	Deltas        map[string]int // New minus old value of changed numeric fields; seconds for average times
 	PreviousData  *QueueData
 	CurrentData   *QueueData
 }
 
-// CompareQueues compares two QueueData instances and returns changes
-// Excludes AvgServiceTime and AvgWaitTime from comparison as per requirements
+// CompareQueues compares two QueueData instances with DefaultComparison
 func CompareQueues(previous, current *QueueData) *QueueChanges {
-	if previous == nil {
-		return &QueueChanges{
-			HasChanges:    true,
-			ChangedFields: make(map[string]bool),
-			PreviousData:  nil,
-			CurrentData:   current,
-		}
-	}
+	return DefaultComparison.Compare(previous, current)
+}
 
-	changes := &QueueChanges{
-		HasChanges:    false,
-		ChangedFields: make(map[string]bool),
-		PreviousData:  previous,
-		CurrentData:   current,
+// DetectStatusTransition reports whether the queue opened or closed between two states
+func DetectStatusTransition(previous, current *QueueData) StatusTransition {
+	if previous == nil || current == nil || previous.Status == current.Status {
+		return TransitionNone
 	}
 
-	// Compare fields excluding AvgServiceTime and AvgWaitTime
-	if previous.Name != current.Name {
-		changes.HasChanges = true
-		changes.ChangedFields["name"] = true
-	}
-	if previous.ServedClients != current.ServedClients {
-		changes.HasChanges = true
-		changes.ChangedFields["served_clients"] = true
+	switch current.Status {
+	case StatusOpen:
+		return TransitionOpened
+	case StatusClosed:
+		return TransitionClosed
+	default:
+		return TransitionNone
 	}
-	if previous.WaitingClients != current.WaitingClients {
-		changes.HasChanges = true
-		changes.ChangedFields["waiting_clients"] = true
-	}
-	if previous.Workplaces != current.Workplaces {
-		changes.HasChanges = true
-		changes.ChangedFields["workplaces"] = true
-	}
-	if previous.LastTicket != current.LastTicket {
-		changes.HasChanges = true
-		changes.ChangedFields["last_ticket"] = true
-	}
-	if previous.TicketsLeft != current.TicketsLeft {
-		changes.HasChanges = true
-		changes.ChangedFields["tickets_left"] = true
-	}
-	if previous.Status != current.Status {
-		changes.HasChanges = true
-		changes.ChangedFields["status"] = true
+}
+
+// FormatStatusAlert formats a push notification for a queue open/close transition.
+// A non-zero ticketsForecast adds the usual ticket exhaustion time to the opening alert.
+func (q *QueueData) FormatStatusAlert(lang i18n.Language, transition StatusTransition, ticketsForecast time.Time) string {
+	switch transition {
+	case TransitionOpened:
+		message := i18n.T(lang, "alert.opened", q.TicketsLeft)
+		if !ticketsForecast.IsZero() {
+			message += "\n" + formatTicketsForecast(lang, ticketsForecast)
+		}
+		return message
+	case TransitionClosed:
+		return i18n.T(lang, "alert.closed", q.ServedClients)
+	default:
+		return ""
 	}
+}
+
+// TicketsThresholdCrossed reports whether tickets left dropped to or below the threshold
+func TicketsThresholdCrossed(previousLeft, currentLeft, threshold int) bool {
+	return threshold >= 0 && previousLeft > threshold && currentLeft <= threshold
+}
 
-	return changes
+// FormatTicketsAlert formats a push notification about tickets running out
+func (q *QueueData) FormatTicketsAlert(lang i18n.Language, ticketsLeft int) string {
+	if ticketsLeft <= 0 {
+		return i18n.T(lang, "alert.tickets_exhausted")
+	}
+	return i18n.T(lang, "alert.tickets_low", ticketsLeft)
 }
 
-// FormatTelegramMessage formats queue data for Telegram message
+// FormatTelegramMessage formats queue data for Telegram message in the default language
 func (q *QueueData) FormatTelegramMessage(changes *QueueChanges) string {
 	return q.FormatTelegramMessageWithTicket(changes, "")
 }
 
+// WaitEstimate represents a history-based wait time estimate with confidence bounds
+type WaitEstimate struct {
+	Expected time.Duration
+	Low      time.Duration
+	High     time.Duration
+}
+
+// VelocityPeriod is the period queue velocities are given per
+const VelocityPeriod = 10 * time.Minute
+
+// Trend is the direction a queue measure moves in
+type Trend int
+
+const (
+	TrendSteady  Trend = iota
+	TrendUp            // Noticeably higher than before
+	TrendDown          // Noticeably lower than before
+	TrendUnknown       // No earlier measure to compare with
+)
+
+// Arrow returns the arrow of the trend, "" when unknown
+func (t Trend) Arrow() string {
+	switch t {
+	case TrendUp:
+		return "↗"
+	case TrendDown:
+		return "↘"
+	case TrendSteady:
+		return "→"
+	default:
+		return ""
+	}
+}
+
+// QueueVelocity is the recent pace of the queue in tickets served per VelocityPeriod
+type QueueVelocity struct {
+	PerPeriod float64
+	Trend     Trend // Compared with the pace before
+}
+
+// MessageOptions holds optional, per-recipient parts of the status message
+type MessageOptions struct {
+	UserTicket      string
+	Estimate        *WaitEstimate      // Overrides CalculateWaitTime when set
+	TicketsForecast time.Time          // Usual ticket exhaustion time, shown when non-zero
+	OfficeOpensAt   time.Time          // Next office opening, shown when non-zero (office closed)
+	Schedule        *schedule.Schedule // Office breaks postponing the estimated call time
+	Location        *time.Location     // Time zone of the shown times; time.Local when nil
+	Language        i18n.Language      // Defaults to i18n.DefaultLanguage when empty
+	Template        *MessageTemplate   // Custom layout; DefaultMessageTemplate when nil
+	StaleSince      time.Time          // Last successful poll while polls keep failing, marks the data outdated when non-zero
+	Yesterday       *QueueData         // The queue at the same time yesterday, compared when set
+	Velocity        *QueueVelocity     // Recent pace of the queue, shown when set
+	ClearsAt        time.Time          // Expected time the waiting queue is served, shown when non-zero
+}
+
 // FormatTelegramMessageWithTicket formats queue data for Telegram message with user ticket info
 func (q *QueueData) FormatTelegramMessageWithTicket(changes *QueueChanges, userTicket string) string {
-	var builder strings.Builder
+	return q.FormatTelegramMessageWithOptions(changes, MessageOptions{UserTicket: userTicket})
+}
 
-	builder.WriteString("🏢 *Очередь: odbiór karty \\(Wrocław\\)*\n\n")
+// FormatTelegramMessageWithOptions formats queue data for Telegram message with per-recipient options
+func (q *QueueData) FormatTelegramMessageWithOptions(changes *QueueChanges, opts MessageOptions) string {
+	view := q.messageView(changes, opts)
 
-	// Helper function to format field with emoji indicator
-	formatField := func(label, value, fieldKey string) {
-		var emoji string
-		if changes != nil && changes.ChangedFields[fieldKey] {
-			emoji = "🟢" // Changed
-		} else {
-			emoji = "⚪" // Unchanged
+	if opts.Template != nil {
+		message, err := opts.Template.Render(view)
+		if err == nil {
+			return message
 		}
-		builder.WriteString(fmt.Sprintf("%s *%s:* %s\n", emoji, label, escapeMarkdown(value)))
+		log.Printf("Falling back to the default message layout: %v", err)
 	}
 
-	formatField("Обслужено", q.ServedClients, "served_clients")
-	formatField("Ожидает", q.WaitingClients, "waiting_clients")
-	formatField("Стоек", q.Workplaces, "workplaces")
-	formatField("Среднее время", q.AvgServiceTime, "avg_service_time")
-	formatField("Последний билет", q.LastTicket, "last_ticket")
-	formatField("Осталось билетов", q.TicketsLeft, "tickets_left")
-	formatField("Статус очереди", q.Status, "status")
+	message, err := defaultTemplate.Render(view)
+	if err != nil {
+		log.Printf("Failed to render default message: %v", err)
+	}
+	return message
+}
 
-	// Show user's estimated wait time if ticket is provided
-	if userTicket != "" {
-		waitTime, err := q.CalculateWaitTime(userTicket)
-		if err == nil && waitTime > 0 {
-			hours := waitTime / 60
-			minutes := waitTime % 60
+// FormatSummary formats the one-line summary of /now with SummaryMessageTemplate; the
+// Template of the options is not used
+func (q *QueueData) FormatSummary(opts MessageOptions) string {
+	message, err := summaryTemplate.Render(q.messageView(nil, opts))
+	if err != nil {
+		log.Printf("Failed to render summary: %v", err)
+	}
+	return message
+}
+
+// messageView prepares the template data of the status message
+func (q *QueueData) messageView(changes *QueueChanges, opts MessageOptions) *MessageView {
+	userTicket := opts.UserTicket
+	lang := opts.Language
+	if lang == "" {
+		lang = i18n.DefaultLanguage
+	}
+	location := opts.Location
+	if location == nil {
+		location = time.Local
+	}
 
-			var timeStr string
-			if hours > 0 {
-				timeStr = fmt.Sprintf("%d ч\\. %d мин\\.", hours, minutes)
-			} else {
-				timeStr = fmt.Sprintf("%d мин\\.", minutes)
-			}
+	view := &MessageView{
+		Queue:    q,
+		Language: lang,
+		Title:    q.Title(lang),
+		Synced:   q.LastUpdated.In(location).Format("15:04:05"),
+		changes:  changes,
+	}
 
-			builder.WriteString(fmt.Sprintf("\n🎫 *Ваш билет %s \\- осталось:* %s", escapeMarkdown(userTicket), timeStr))
+	// Show user's estimated wait time if ticket is provided
+	if userTicket != "" && opts.Estimate != nil && opts.Estimate.Expected > 0 {
+		view.TicketInfo = i18n.T(lang, "queue.ticket_estimate",
+			EscapeMarkdown(userTicket),
+			FormatMinutes(lang, int(opts.Estimate.Expected.Minutes())),
+			FormatMinutes(lang, int(opts.Estimate.Low.Minutes())),
+			FormatMinutes(lang, int(opts.Estimate.High.Minutes())),
+			q.CallTime(opts.Estimate.Expected, opts.Schedule).In(location).Format("15:04"))
+		view.TicketWait = i18n.T(lang, "summary.ticket_wait", FormatMinutes(lang, int(opts.Estimate.Expected.Minutes())), EscapeMarkdown(userTicket))
+	} else if userTicket != "" {
+		waitTime, callTime, err := q.CalculateWaitTime(userTicket, opts.Schedule)
+		if err == nil && waitTime > 0 {
+			view.TicketInfo = i18n.T(lang, "queue.ticket_wait", EscapeMarkdown(userTicket), FormatMinutes(lang, waitTime), callTime.In(location).Format("15:04"))
+			view.TicketWait = i18n.T(lang, "summary.ticket_wait", FormatMinutes(lang, waitTime), EscapeMarkdown(userTicket))
 		} else if err == nil && waitTime == 0 {
-			builder.WriteString(fmt.Sprintf("\n🎫 *Ваш билет %s \\- ваша очередь\\!*", escapeMarkdown(userTicket)))
+			view.TicketInfo = i18n.T(lang, "queue.ticket_turn", EscapeMarkdown(userTicket))
+			view.TicketWait = i18n.T(lang, "summary.ticket_turn", EscapeMarkdown(userTicket))
 		}
 	}
 
-	// Show last sync time and last change time
-	builder.WriteString(fmt.Sprintf("\n🔄 *Синхронизация:* %s", q.LastUpdated.Format("15:04:05")))
+	if !opts.TicketsForecast.IsZero() {
+		view.TicketsForecast = formatTicketsForecast(lang, opts.TicketsForecast.In(location))
+	}
+
+	if !opts.OfficeOpensAt.IsZero() {
+		view.OfficeOpening = FormatOfficeOpening(lang, opts.OfficeOpensAt.In(location), time.Now().In(location))
+	}
+
+	if opts.Velocity != nil {
+		view.Velocity = strings.TrimSpace(i18n.T(lang, "queue.velocity",
+			EscapeMarkdown(fmt.Sprintf("%.1f", opts.Velocity.PerPeriod)), int(VelocityPeriod/time.Minute), opts.Velocity.Trend.Arrow()))
+	}
+
+	if !opts.ClearsAt.IsZero() {
+		view.ClearTime = i18n.T(lang, "queue.clear_time", opts.ClearsAt.In(location).Format("15:04"))
+	}
+
+	if opts.Yesterday != nil {
+		view.Yesterday = i18n.T(lang, "queue.yesterday", opts.Yesterday.WaitingClients, opts.Yesterday.TicketsLeft)
+	}
+
+	if !opts.StaleSince.IsZero() {
+		view.Stale = i18n.T(lang, "queue.stale", opts.StaleSince.In(location).Format("15:04"))
+	}
+
+	if !q.LastUpdated.IsZero() {
+		view.Age = FormatDataAge(lang, time.Since(q.LastUpdated))
+	}
+
 	if !q.LastChanged.IsZero() {
-		builder.WriteString(fmt.Sprintf("\n⏰ *Изменение:* %s", q.LastChanged.Format("15:04:05")))
+		view.LastChanged = q.LastChanged.In(location).Format("15:04:05")
 	}
 
-	return builder.String()
+	return view
 }
 
-// escapeMarkdown escapes special characters for Telegram MarkdownV2
-func escapeMarkdown(text string) string {
+// OfficeName returns the city of the office the queue belongs to
+func (q *QueueData) OfficeName() string {
+	if q.Office == "" {
+		return DefaultOfficeName
+	}
+	return q.Office
+}
+
+// QueueName returns the name of the queue, odbiór karty when empty
+func (q *QueueData) QueueName() string {
+	if q.Name == "" {
+		return DefaultQueueName
+	}
+	return q.Name
+}
+
+// Title returns the MarkdownV2 message title naming the queue and its office
+func (q *QueueData) Title(lang i18n.Language) string {
+	return i18n.T(lang, "queue.title", EscapeMarkdown(q.QueueName()), EscapeMarkdown(q.OfficeName()))
+}
+
+// LocalizedStatus returns the queue status translated to the given language
+func (q *QueueData) LocalizedStatus(lang i18n.Language) string {
+	switch q.Status {
+	case StatusOpen:
+		return i18n.T(lang, "status.open")
+	case StatusClosed:
+		return i18n.T(lang, "status.closed")
+	default:
+		return q.Status
+	}
+}
+
+// formatTicketsForecast formats the usual ticket exhaustion time line
+func formatTicketsForecast(lang i18n.Language, forecast time.Time) string {
+	return i18n.T(lang, "queue.tickets_forecast", forecast.Format("15:04"))
+}
+
+// FormatOfficeOpening formats the "office closed, queue opens at" notice relative to now
+func FormatOfficeOpening(lang i18n.Language, opening, now time.Time) string {
+	clock := opening.Format("15:04")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, opening.Location())
+	day := time.Date(opening.Year(), opening.Month(), opening.Day(), 0, 0, 0, 0, opening.Location())
+
+	switch {
+	case day.Equal(today):
+		return i18n.T(lang, "queue.office_opens_today", clock)
+	case day.Equal(today.AddDate(0, 0, 1)):
+		return i18n.T(lang, "queue.office_opens_tomorrow", clock)
+	default:
+		return i18n.T(lang, "queue.office_opens_on", EscapeMarkdown(opening.Format("02.01")), clock)
+	}
+}
+
+// FormatMinutes formats a number of minutes as escaped "X ч. Y мин." text in the given language
+func FormatMinutes(lang i18n.Language, totalMinutes int) string {
+	hours := totalMinutes / 60
+	minutes := totalMinutes % 60
+
+	if hours > 0 {
+		return i18n.T(lang, "duration.hours_minutes", hours, minutes)
+	}
+	return i18n.T(lang, "duration.minutes", minutes)
+}
+
+// Ages at which the freshness marker of the data turns from ⚪ to 🟡 and 🔴
+const (
+	AgingDataAge = 2 * time.Minute
+	StaleDataAge = 10 * time.Minute
+)
+
+// FormatDataAge formats how long ago the data was polled, e.g. "⚪ data 12 sec. ago",
+// colored as it ages
+func FormatDataAge(lang i18n.Language, age time.Duration) string {
+	marker := "⚪"
+	switch {
+	case age >= StaleDataAge:
+		marker = "🔴"
+	case age >= AgingDataAge:
+		marker = "🟡"
+	}
+
+	text := FormatMinutes(lang, int(age.Minutes()))
+	if age < time.Minute {
+		text = i18n.T(lang, "duration.seconds", max(int(age.Seconds()), 0))
+	}
+	return i18n.T(lang, "queue.age", marker, text)
+}
+
+// FormatServiceTime formats an average time like the DUW website: "45 s.", "6 min." or
+// "N/A" when unknown
+func FormatServiceTime(d time.Duration) string {
+	if d <= 0 {
+		return "N/A"
+	}
+	if d < time.Minute {
+		return fmt.Sprintf("%d s.", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%d min.", int(d.Minutes()))
+}
+
+// ParseServiceTime parses a time formatted by FormatServiceTime, or a plain number of
+// seconds; it returns zero for "N/A" and other texts without a number
+func ParseServiceTime(text string) time.Duration {
+	text = strings.TrimSpace(text)
+	digits := ""
+	for _, char := range text {
+		if char >= '0' && char <= '9' {
+			digits += string(char)
+		} else if digits != "" {
+			break // Stop at first non-digit after finding digits
+		}
+	}
+
+	value, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0
+	}
+	if strings.Contains(text, "min") {
+		return time.Duration(value) * time.Minute
+	}
+	return time.Duration(value) * time.Second
+}
+
+// EscapeMarkdown escapes special characters for Telegram MarkdownV2
+func EscapeMarkdown(text string) string {
 	// Characters that need to be escaped in MarkdownV2: _*[]()~`>#+-=|{}.!
 	replacer := strings.NewReplacer(
 		"_", "\\_",
@@ -167,9 +442,30 @@ func escapeMarkdown(text string) string {
 	return replacer.Replace(text)
 }
 
+// PlainText converts Telegram MarkdownV2 text to plain text by dropping escapes and bold markers
+func PlainText(text string) string {
+	var builder strings.Builder
+	escaped := false
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			builder.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
 // IsEmpty checks if queue data is empty/invalid
 func (q *QueueData) IsEmpty() bool {
-	return q.Name == "" && q.ServedClients == "" && q.WaitingClients == ""
+	return q.Name == "" && q.ServedClients == 0 && q.WaitingClients == 0
 }
 
 // Clone creates a deep copy of QueueData
@@ -189,11 +485,12 @@ func (q *QueueData) Clone() *QueueData {
 		Status:         q.Status,
 		LastUpdated:    q.LastUpdated,
 		LastChanged:    q.LastChanged,
+		Office:         q.Office,
 	}
 }
 
-// CalculateWaitTime calculates estimated wait time for a user's ticket
-func (q *QueueData) CalculateWaitTime(userTicket string) (int, error) {
+// TicketsAhead returns how many tickets will be called before the user's ticket
+func (q *QueueData) TicketsAhead(userTicket string) (int, error) {
 	if userTicket == "" || q.LastTicket == "" {
 		return 0, fmt.Errorf("missing ticket information")
 	}
@@ -209,87 +506,94 @@ func (q *QueueData) CalculateWaitTime(userTicket string) (int, error) {
 		return 0, fmt.Errorf("invalid current ticket format: %w", err)
 	}
 
-	// Calculate tickets remaining
-	ticketsRemaining := userNum - currentNum
-	if ticketsRemaining <= 0 {
+	if userNum <= currentNum {
 		return 0, nil // User's turn has passed or is current
 	}
 
-	// Parse average service time (e.g., "6 min." -> 6)
-	avgServiceMinutes, err := parseServiceTime(q.AvgServiceTime)
+	return userNum - currentNum, nil
+}
+
+// CalculateWaitTime calculates estimated wait time in minutes for a user's ticket and the
+// estimated call time, counted from the last sync and postponed by the office breaks of s
+func (q *QueueData) CalculateWaitTime(userTicket string, s *schedule.Schedule) (int, time.Time, error) {
+	// Calculate tickets remaining
+	ticketsRemaining, err := q.TicketsAhead(userTicket)
 	if err != nil {
-		return 0, fmt.Errorf("invalid service time format: %w", err)
+		return 0, time.Time{}, err
+	}
+	if ticketsRemaining <= 0 {
+		return 0, time.Time{}, nil // User's turn has passed or is current
 	}
 
-	// Parse number of workplaces (e.g., "3" -> 3)
-	workplaces, err := parseWorkplaces(q.Workplaces)
-	if err != nil {
-		return 0, fmt.Errorf("invalid workplaces format: %w", err)
+	if q.AvgServiceTime <= 0 {
+		return 0, time.Time{}, fmt.Errorf("missing service time")
 	}
 
 	// Ensure we have at least 1 workplace to avoid division by zero
+	workplaces := q.Workplaces
 	if workplaces <= 0 {
 		workplaces = 1
 	}
 
 	// Calculate estimated wait time considering parallel workplaces
 	// Total time = (tickets remaining * service time per ticket) / number of parallel workplaces
-	totalServiceTime := ticketsRemaining * avgServiceMinutes
-	estimatedWaitMinutes := totalServiceTime / workplaces
+	totalServiceTime := time.Duration(ticketsRemaining) * q.AvgServiceTime
+	estimatedWait := totalServiceTime / time.Duration(workplaces)
 
-	return estimatedWaitMinutes, nil
+	return int(estimatedWait.Minutes()), q.CallTime(estimatedWait, s), nil
 }
 
-// extractTicketNumber extracts the numeric part from a ticket string
-func extractTicketNumber(ticket string) (int, error) {
-	// Remove non-digit characters and parse
-	numStr := ""
-	for _, char := range ticket {
-		if char >= '0' && char <= '9' {
-			numStr += string(char)
-		}
-	}
-
-	if numStr == "" {
-		return 0, fmt.Errorf("no number found in ticket: %s", ticket)
+// CallTime returns the absolute time a ticket waiting for the given time is called, counted
+// from the last sync (or now without one) and postponed by the office breaks of s
+func (q *QueueData) CallTime(wait time.Duration, s *schedule.Schedule) time.Time {
+	from := q.LastUpdated
+	if from.IsZero() {
+		from = time.Now()
 	}
-
-	return strconv.Atoi(numStr)
+	return s.CallTime(from, wait)
 }
 
-// parseServiceTime extracts minutes from service time string
-func parseServiceTime(serviceTime string) (int, error) {
-	// Extract number from strings like "6 min." or "5 minutes"
-	numStr := ""
-	for _, char := range serviceTime {
-		if char >= '0' && char <= '9' {
-			numStr += string(char)
-		} else if numStr != "" {
-			break // Stop at first non-digit after finding digits
-		}
+// TicketCalled reports whether the user's ticket was called when the current ticket moved
+// from previousTicket to currentTicket. An empty or higher previous ticket (numbering
+// restarted for the day) counts as the start of the day.
+func TicketCalled(previousTicket, currentTicket, userTicket string) bool {
+	current, err := extractTicketNumber(currentTicket)
+	if err != nil {
+		return false
+	}
+	user, err := extractTicketNumber(userTicket)
+	if err != nil {
+		return false
 	}
 
-	if numStr == "" {
-		return 0, fmt.Errorf("no number found in service time: %s", serviceTime)
+	previous, err := extractTicketNumber(previousTicket)
+	if err != nil || previous > current {
+		previous = 0
 	}
 
-	return strconv.Atoi(numStr)
+	return previous < user && user <= current
 }
 
-// parseWorkplaces extracts number from workplaces string
-func parseWorkplaces(workplaces string) (int, error) {
-	// Extract number from strings like "3" or "5 стоек"
+// ticketPattern matches ticket numbers: K followed by one or more digits
+var ticketPattern = regexp.MustCompile(`^[Kk]\d+$`)
+
+// IsTicketNumber reports whether text is a ticket number, e.g. "K222"
+func IsTicketNumber(text string) bool {
+	return ticketPattern.MatchString(strings.TrimSpace(text))
+}
+
+// extractTicketNumber extracts the numeric part from a ticket string
+func extractTicketNumber(ticket string) (int, error) {
+	// Remove non-digit characters and parse
 	numStr := ""
-	for _, char := range workplaces {
+	for _, char := range ticket {
 		if char >= '0' && char <= '9' {
 			numStr += string(char)
-		} else if numStr != "" {
-			break // Stop at first non-digit after finding digits
 		}
 	}
 
 	if numStr == "" {
-		return 0, fmt.Errorf("no number found in workplaces: %s", workplaces)
+		return 0, fmt.Errorf("no number found in ticket: %s", ticket)
 	}
 
 	return strconv.Atoi(numStr)
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+
+	"karta/internal/i18n"
+)
+
+// DefaultMessageTemplate is the built-in layout of the live status message
+const DefaultMessageTemplate = `{{.Title}}
+
+{{.Marker "served_clients"}} *{{.T "queue.served"}}:* {{.Change "served_clients"}}
+{{.Marker "waiting_clients"}} *{{.T "queue.waiting"}}:* {{.Change "waiting_clients"}}
+{{.Marker "workplaces"}} *{{.T "queue.workplaces"}}:* {{.Change "workplaces"}}
+{{.Marker "avg_service_time"}} *{{.T "queue.avg_time"}}:* {{.Change "avg_service_time"}}
+{{.Marker "last_ticket"}} *{{.T "queue.last_ticket"}}:* {{.Change "last_ticket"}}
+{{.Marker "tickets_left"}} *{{.T "queue.tickets_left"}}:* {{.Change "tickets_left"}}
+{{.Marker "status"}} *{{.T "queue.status"}}:* {{.Change "status"}}
+{{with .TicketInfo}}
+{{.}}{{end}}{{with .TicketsForecast}}
+{{.}}{{end}}{{with .OfficeOpening}}
+{{.}}{{end}}{{with .Velocity}}
+{{.}}{{end}}{{with .ClearTime}}
+{{.}}{{end}}{{with .Yesterday}}
+{{.}}{{end}}{{with .Stale}}
+{{.}}{{end}}
+{{.T "queue.synced" .Synced}}{{with .Age}}
+{{.}}{{end}}{{with .LastChanged}}
+{{$.T "queue.changed" .}}{{end}}`
+
+// SummaryMessageTemplate is the one-line layout of /now, for quick glances and forwarding
+const SummaryMessageTemplate = `{{.Value "status"}}, {{if .Open}}{{.T "summary.waiting" .Queue.WaitingClients}}, {{.T "summary.tickets_left" .Queue.TicketsLeft}}{{with .TicketWait}}, {{.}}{{end}}{{else}}{{.T "summary.served" .Queue.ServedClients}}{{end}}`
+
+// MessageTemplate is a text/template rendering the live status message from a MessageView
+type MessageTemplate struct {
+	tmpl *template.Template
+}
+
+// defaultTemplate and summaryTemplate are the parsed built-in layouts
+var (
+	defaultTemplate = mustParseMessageTemplate(DefaultMessageTemplate)
+	summaryTemplate = mustParseMessageTemplate(SummaryMessageTemplate)
+)
+
+// templateFuncs are available in message templates in addition to the MessageView methods
+var templateFuncs = template.FuncMap{
+	"escape":   EscapeMarkdown,
+	"duration": FormatServiceTime,
+}
+
+// ParseMessageTemplate parses a message template and checks it by rendering sample data
+func ParseMessageTemplate(text string) (*MessageTemplate, error) {
+	tmpl, err := template.New("message").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message template: %w", err)
+	}
+
+	t := &MessageTemplate{tmpl: tmpl}
+	sample := &QueueData{Name: "odbiór karty", Status: StatusOpen, LastUpdated: time.Now(), LastChanged: time.Now()}
+	if _, err := t.Render(sample.messageView(nil, MessageOptions{UserTicket: "K001"})); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// LoadMessageTemplate reads and parses a message template file
+func LoadMessageTemplate(path string) (*MessageTemplate, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message template: %w", err)
+	}
+	return ParseMessageTemplate(string(text))
+}
+
+// mustParseMessageTemplate parses a built-in template
+func mustParseMessageTemplate(text string) *MessageTemplate {
+	return &MessageTemplate{tmpl: template.Must(template.New("message").Funcs(templateFuncs).Parse(text))}
+}
+
+// Render executes the template with the view
+func (t *MessageTemplate) Render(view *MessageView) (string, error) {
+	var buffer bytes.Buffer
+	if err := t.tmpl.Execute(&buffer, view); err != nil {
+		return "", fmt.Errorf("failed to render message template: %w", err)
+	}
+	return buffer.String(), nil
+}
+
+// MessageView is the data of a message template. Its texts are already escaped for
+// MarkdownV2; the raw values are in Queue and need the escape function.
+type MessageView struct {
+	Queue    *QueueData
+	Language i18n.Language
+	Title    string
+
+	// Optional lines, empty when not shown
+	TicketInfo      string // Wait estimate of the user's ticket
+	TicketWait      string // Short form of TicketInfo, e.g. "~35 min for K250"
+	TicketsForecast string // Usual ticket exhaustion time
+	OfficeOpening   string // Next office opening while the office is closed
+	Velocity        string // Tickets served per VelocityPeriod lately, with a trend arrow
+	ClearTime       string // Expected time the waiting queue is served
+	Yesterday       string // Waiting clients and tickets left at the same time yesterday
+	Stale           string // Warning that the data is outdated, as polls keep failing
+
+	Synced      string // Time of the last poll, 15:04:05
+	Age         string // How long ago the data was polled at render time, with a ⚪/🟡/🔴 freshness marker
+	LastChanged string // Time of the last change, empty when unknown
+
+	changes *QueueChanges
+}
+
+// T returns the catalog text of the key in the view language
+func (v *MessageView) T(key string, args ...interface{}) string {
+	return i18n.T(v.Language, key, args...)
+}
+
+// Open reports whether the queue is open
+func (v *MessageView) Open() bool {
+	return v.Queue.Status == StatusOpen
+}
+
+// IsChanged reports whether the field changed in the last update
+func (v *MessageView) IsChanged(key string) bool {
+	return v.changes != nil && v.changes.ChangedFields[key]
+}
+
+// Marker returns 🟢 for a changed field and ⚪ otherwise
+func (v *MessageView) Marker(key string) string {
+	if v.IsChanged(key) {
+		return "🟢"
+	}
+	return "⚪"
+}
+
+// Value returns the escaped current value of the field
+func (v *MessageView) Value(key string) string {
+	return EscapeMarkdown(fieldText(v.Queue, key, v.Language))
+}
+
+// Change returns the escaped "old → new (▲delta)" text of a changed field, or its value
+func (v *MessageView) Change(key string) string {
+	if !v.IsChanged(key) || v.changes.PreviousData == nil {
+		return v.Value(key)
+	}
+
+	previous := v.changes.PreviousData
+	text := fieldText(previous, key, v.Language) + " → " + fieldText(v.Queue, key, v.Language) + formatDelta(key, previous, v.Queue)
+	return EscapeMarkdown(text)
+}
+
+// fieldText returns the displayed value of a field
+func fieldText(q *QueueData, key string, lang i18n.Language) string {
+	switch key {
+	case FieldName:
+		return q.Name
+	case FieldServedClients:
+		return strconv.Itoa(q.ServedClients)
+	case FieldWaitingClients:
+		return strconv.Itoa(q.WaitingClients)
+	case FieldWorkplaces:
+		return strconv.Itoa(q.Workplaces)
+	case FieldAvgServiceTime:
+		return FormatServiceTime(q.AvgServiceTime)
+	case FieldAvgWaitTime:
+		return FormatServiceTime(q.AvgWaitTime)
+	case FieldLastTicket:
+		return q.LastTicket
+	case FieldTicketsLeft:
+		return strconv.Itoa(q.TicketsLeft)
+	case FieldStatus:
+		return q.LocalizedStatus(lang)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,171 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const (
+	DiscordAPIURL  = "https://discord.com/api/v10"
+	DiscordTimeout = 10 * time.Second
+)
+
+// discordMessage is the subset of the Discord message object used by the notifier
+type discordMessage struct {
+	ID      string `json:"id,omitempty"`
+	Content string `json:"content"`
+}
+
+// DiscordNotifier keeps a live queue status message in a Discord channel up to date
+// and posts alerts there, mirroring the Telegram bot behavior
+type DiscordNotifier struct {
+	token     string
+	channelID string
+	lang      i18n.Language
+	template  atomic.Pointer[models.MessageTemplate] // Custom status message layout, nil for the default
+	client    *http.Client
+
+	mu        sync.Mutex
+	messageID string // Live status message, edited on every update
+}
+
+// NewDiscordNotifier creates a notifier posting to a Discord channel with a bot token
+func NewDiscordNotifier(token, channelID string, lang i18n.Language) *DiscordNotifier {
+	return &DiscordNotifier{
+		token:     token,
+		channelID: channelID,
+		lang:      lang,
+		client:    &http.Client{Timeout: DiscordTimeout},
+	}
+}
+
+// SetMessageTemplate sets a custom layout of the status message (nil restores the default)
+func (d *DiscordNotifier) SetMessageTemplate(template *models.MessageTemplate) {
+	d.template.Store(template)
+}
+
+// Name implements Notifier
+func (d *DiscordNotifier) Name() string {
+	return "discord"
+}
+
+// Broadcast edits the live status message, posting a new one if there is none yet
+// or the old one can no longer be edited
+func (d *DiscordNotifier) Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	content := discordMarkdown(queueData.FormatTelegramMessageWithOptions(changes, models.MessageOptions{Language: d.lang, Template: d.template.Load()}))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.messageID != "" {
+		path := fmt.Sprintf("/channels/%s/messages/%s", d.channelID, d.messageID)
+		_, err := d.call(ctx, http.MethodPatch, path, content)
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("Failed to edit Discord message %s, posting a new one: %v", d.messageID, err)
+		d.messageID = ""
+	}
+
+	message, err := d.call(ctx, http.MethodPost, fmt.Sprintf("/channels/%s/messages", d.channelID), content)
+	if err != nil {
+		return err
+	}
+
+	d.messageID = message.ID
+	return nil
+}
+
+// SendAlert posts the alert as a separate message
+func (d *DiscordNotifier) SendAlert(ctx context.Context, queueData *models.QueueData, alert Alert) error {
+	var text string
+	switch alert.Kind {
+	case AlertStatus:
+		text = queueData.FormatStatusAlert(d.lang, alert.Transition, time.Time{})
+	case AlertTickets:
+		text = queueData.FormatTicketsAlert(d.lang, alert.CurrentLeft)
+	case AlertCalled:
+		return nil // Called tickets are personal Telegram alerts
+	default:
+		return fmt.Errorf("unknown alert kind: %s", alert.Kind)
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	_, err := d.call(ctx, http.MethodPost, fmt.Sprintf("/channels/%s/messages", d.channelID), discordMarkdown(text))
+	return err
+}
+
+// call sends a message create/edit request to the Discord API and decodes the resulting message
+func (d *DiscordNotifier) call(ctx context.Context, method, path, content string) (*discordMessage, error) {
+	body, err := json.Marshal(discordMessage{Content: content})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Discord message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, DiscordAPIURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Discord request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bot "+d.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Discord API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("Discord API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	var message discordMessage
+	if err := json.NewDecoder(resp.Body).Decode(&message); err != nil {
+		return nil, fmt.Errorf("failed to decode Discord response: %w", err)
+	}
+
+	return &message, nil
+}
+
+// discordMarkdown converts Telegram MarkdownV2 text to Discord markdown: *bold* becomes
+// **bold** and escapes are dropped except for characters Discord itself treats as markup
+func discordMarkdown(text string) string {
+	var builder strings.Builder
+	escaped := false
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			if strings.ContainsRune("*_~`>|", r) {
+				builder.WriteRune('\\')
+			}
+			builder.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			builder.WriteString("**")
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"karta/internal/models"
+)
+
+// AlertKind identifies the type of a push alert
+type AlertKind string
+
+const (
+	AlertStatus  AlertKind = "status"  // Queue opened or closed
+	AlertTickets AlertKind = "tickets" // Tickets left went down
+	AlertCalled  AlertKind = "called"  // The current ticket moved on, possibly past users' tickets
+)
+
+// Alert describes a notable queue event that deserves a dedicated notification
+type Alert struct {
+	Kind           AlertKind
+	Transition     models.StatusTransition // Set for AlertStatus
+	PreviousLeft   int                     // Set for AlertTickets
+	CurrentLeft    int                     // Set for AlertTickets
+	PreviousTicket string                  // Set for AlertCalled; the current ticket is in the queue data
+}
+
+// Notifier delivers queue updates and alerts to a notification channel
+type Notifier interface {
+	// Name returns a short channel name used in logs
+	Name() string
+	// Broadcast delivers the current queue state, called on every poll
+	Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error
+	// SendAlert delivers a dedicated alert about a notable queue event
+	SendAlert(ctx context.Context, queueData *models.QueueData, alert Alert) error
+}
+
+// Multi fans out updates and alerts to several notifiers
+type Multi []Notifier
+
+// Name implements Notifier
+func (m Multi) Name() string {
+	return "multi"
+}
+
+// Broadcast sends the update to every notifier and joins their errors
+func (m Multi) Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Broadcast(ctx, queueData, changes); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendAlert sends the alert to every notifier and joins their errors
+func (m Multi) SendAlert(ctx context.Context, queueData *models.QueueData, alert Alert) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.SendAlert(ctx, queueData, alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,182 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"karta/internal/i18n"
+	"karta/internal/models"
+)
+
+const PushTimeout = 10 * time.Second
+
+// pushPriority is a channel-agnostic notification priority
+type pushPriority int
+
+const (
+	priorityLow  pushPriority = iota // Silent queue updates
+	priorityHigh                     // Alerts that should make a sound
+)
+
+// pushMessage is a plain-text mobile push notification
+type pushMessage struct {
+	Title    string
+	Body     string
+	Priority pushPriority
+}
+
+// PushNotifier sends queue changes and alerts as mobile push notifications
+// through a push service such as ntfy or Gotify
+type PushNotifier struct {
+	name     string
+	lang     i18n.Language
+	template atomic.Pointer[models.MessageTemplate] // Custom status message layout, nil for the default
+	client   *http.Client
+	send     func(ctx context.Context, client *http.Client, message pushMessage) error
+
+	mu          sync.Mutex
+	lastChanged time.Time // LastChanged of the last pushed update
+}
+
+// NewNtfyNotifier creates a notifier publishing to an ntfy topic URL (e.g. https://ntfy.sh/karta),
+// authenticating with an access token when one is given
+func NewNtfyNotifier(topicURL, token string, lang i18n.Language) *PushNotifier {
+	return newPushNotifier("ntfy", lang, func(ctx context.Context, client *http.Client, message pushMessage) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, topicURL, strings.NewReader(message.Body))
+		if err != nil {
+			return fmt.Errorf("failed to create ntfy request: %w", err)
+		}
+
+		// Non-ASCII header values must be RFC 2047 encoded for ntfy
+		req.Header.Set("Title", mime.BEncoding.Encode("utf-8", message.Title))
+		if message.Priority == priorityHigh {
+			req.Header.Set("Priority", "high")
+		} else {
+			req.Header.Set("Priority", "low")
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		return doPush(client, req)
+	})
+}
+
+// NewGotifyNotifier creates a notifier posting to a Gotify server with an application token
+func NewGotifyNotifier(serverURL, appToken string, lang i18n.Language) *PushNotifier {
+	endpoint := strings.TrimSuffix(serverURL, "/") + "/message?token=" + url.QueryEscape(appToken)
+
+	return newPushNotifier("gotify", lang, func(ctx context.Context, client *http.Client, message pushMessage) error {
+		priority := 2
+		if message.Priority == priorityHigh {
+			priority = 8
+		}
+
+		body, err := json.Marshal(map[string]interface{}{
+			"title":    message.Title,
+			"message":  message.Body,
+			"priority": priority,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal Gotify message: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create Gotify request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		return doPush(client, req)
+	})
+}
+
+// newPushNotifier creates a push notifier with the given delivery function
+func newPushNotifier(name string, lang i18n.Language, send func(context.Context, *http.Client, pushMessage) error) *PushNotifier {
+	return &PushNotifier{
+		name:   name,
+		lang:   lang,
+		client: &http.Client{Timeout: PushTimeout},
+		send:   send,
+	}
+}
+
+// SetMessageTemplate sets a custom layout of the status message (nil restores the default)
+func (p *PushNotifier) SetMessageTemplate(template *models.MessageTemplate) {
+	p.template.Store(template)
+}
+
+// Name implements Notifier
+func (p *PushNotifier) Name() string {
+	return p.name
+}
+
+// Broadcast pushes a silent notification when the queue data changed since the last push
+func (p *PushNotifier) Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	p.mu.Lock()
+	if queueData.LastChanged.Equal(p.lastChanged) {
+		p.mu.Unlock()
+		return nil
+	}
+	p.lastChanged = queueData.LastChanged
+	p.mu.Unlock()
+
+	title := models.PlainText(queueData.Title(p.lang))
+	body := models.PlainText(queueData.FormatTelegramMessageWithOptions(changes, models.MessageOptions{Language: p.lang, Template: p.template.Load()}))
+
+	return p.send(ctx, p.client, pushMessage{
+		Title:    title,
+		Body:     strings.TrimSpace(strings.TrimPrefix(body, title)),
+		Priority: priorityLow,
+	})
+}
+
+// SendAlert pushes an alert with high priority, using its first line as the title
+func (p *PushNotifier) SendAlert(ctx context.Context, queueData *models.QueueData, alert Alert) error {
+	var text string
+	switch alert.Kind {
+	case AlertStatus:
+		text = queueData.FormatStatusAlert(p.lang, alert.Transition, time.Time{})
+	case AlertTickets:
+		text = queueData.FormatTicketsAlert(p.lang, alert.CurrentLeft)
+	case AlertCalled:
+		return nil // Called tickets are personal Telegram alerts
+	default:
+		return fmt.Errorf("unknown alert kind: %s", alert.Kind)
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	title, body, _ := strings.Cut(models.PlainText(text), "\n")
+	return p.send(ctx, p.client, pushMessage{
+		Title:    title,
+		Body:     strings.TrimSpace(body),
+		Priority: priorityHigh,
+	})
+}
+
+// doPush sends a push request and checks the response status
+func doPush(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("push service returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"karta/internal/models"
+)
+
+const (
+	WebhookTimeout   = 10 * time.Second
+	WebhookUserAgent = "karta-webhook/1.0"
+)
+
+// Webhook event names sent in the "event" field of the payload
+const (
+	EventQueueUpdate  = "queue_update"
+	EventStatusAlert  = "status_alert"
+	EventTicketsAlert = "tickets_alert"
+)
+
+// WebhookPayload is the JSON body posted to the webhook URL
+type WebhookPayload struct {
+	Event         string            `json:"event"`
+	Queue         *models.QueueData `json:"queue"`
+	ChangedFields []string          `json:"changed_fields,omitempty"`
+	Deltas        map[string]int    `json:"deltas,omitempty"` // New minus old value of changed numeric fields
+	Transition    string            `json:"transition,omitempty"`
+	TicketsLeft   *int              `json:"tickets_left,omitempty"`
+	SentAt        time.Time         `json:"sent_at"`
+}
+
+// WebhookNotifier posts queue events as JSON to a generic HTTP endpoint
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+
+	mu          sync.Mutex
+	lastChanged time.Time // LastChanged of the last posted update
+}
+
+// NewWebhookNotifier creates a notifier posting to the given URL
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: WebhookTimeout},
+	}
+}
+
+// Name implements Notifier
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Broadcast posts the queue state when it changed since the last posted update.
+// Unlike Telegram there is no live message to refresh, so unchanged polls are skipped.
+func (w *WebhookNotifier) Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	w.mu.Lock()
+	if queueData.LastChanged.Equal(w.lastChanged) {
+		w.mu.Unlock()
+		return nil
+	}
+	w.lastChanged = queueData.LastChanged
+	w.mu.Unlock()
+
+	payload := WebhookPayload{
+		Event: EventQueueUpdate,
+		Queue: queueData,
+	}
+	if changes != nil {
+		for field := range changes.ChangedFields {
+			payload.ChangedFields = append(payload.ChangedFields, field)
+		}
+		sort.Strings(payload.ChangedFields)
+		if len(changes.Deltas) > 0 {
+			payload.Deltas = changes.Deltas
+		}
+	}
+
+	return w.post(ctx, payload)
+}
+
+// SendAlert posts a status or tickets alert
+func (w *WebhookNotifier) SendAlert(ctx context.Context, queueData *models.QueueData, alert Alert) error {
+	payload := WebhookPayload{Queue: queueData}
+
+	switch alert.Kind {
+	case AlertStatus:
+		payload.Event = EventStatusAlert
+		payload.Transition = alert.Transition.String()
+	case AlertTickets:
+		payload.Event = EventTicketsAlert
+		ticketsLeft := alert.CurrentLeft
+		payload.TicketsLeft = &ticketsLeft
+	case AlertCalled:
+		return nil // Called tickets are personal Telegram alerts
+	default:
+		return fmt.Errorf("unknown alert kind: %s", alert.Kind)
+	}
+
+	return w.post(ctx, payload)
+}
+
+// post sends the payload as JSON and checks the response status
+func (w *WebhookNotifier) post(ctx context.Context, payload WebhookPayload) error {
+	payload.SentAt = time.Now()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", WebhookUserAgent)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
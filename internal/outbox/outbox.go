@@ -0,0 +1,260 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/models"
+	"karta/internal/notifier"
+)
+
+const (
+	KindBroadcast = "broadcast"
+
+	DispatchInterval = time.Second // Pending intents are picked up at least this often
+	BatchSize        = 50
+	MaxAttempts      = 8
+	RetryDelay       = 10 * time.Second // First retry delay, doubling with every attempt
+	MaxRetryDelay    = 10 * time.Minute
+	CleanupInterval  = time.Hour
+	Retention        = 24 * time.Hour // Delivered and failed intents are kept this long
+)
+
+// Store persists the outbox; implemented by *database.Database
+type Store interface {
+	EnqueueNotification(ctx context.Context, entry database.OutboxEntry) (bool, error)
+	PendingNotifications(ctx context.Context, now time.Time, limit int) ([]database.OutboxEntry, error)
+	CompleteNotification(ctx context.Context, id int64, status string) error
+	SupersedeNotifications(ctx context.Context, entry database.OutboxEntry) (int64, error)
+	RetryNotification(ctx context.Context, id int64, attempts int, next time.Time, lastError string) error
+	DeleteFinishedNotifications(ctx context.Context, before time.Time) (int64, error)
+}
+
+// intent is the payload of an outbox entry
+type intent struct {
+	QueueData *models.QueueData    `json:"queue_data"`
+	Changes   *models.QueueChanges `json:"changes,omitempty"`
+	Alert     *notifier.Alert      `json:"alert,omitempty"`
+}
+
+// Outbox stores queue updates and alerts as intents in the database, one per channel, and
+// delivers them from a dispatcher goroutine with retries. Parsing never waits for a
+// channel, and intents survive restarts until they are delivered.
+type Outbox struct {
+	store    Store
+	channels map[string]notifier.Notifier
+	names    []string // Channel names in delivery order
+	wake     chan struct{}
+}
+
+// New creates an outbox delivering to the given channels, identified by their names
+func New(store Store, channels ...notifier.Notifier) *Outbox {
+	o := &Outbox{
+		store:    store,
+		channels: make(map[string]notifier.Notifier),
+		wake:     make(chan struct{}, 1),
+	}
+	for _, channel := range channels {
+		o.channels[channel.Name()] = channel
+		o.names = append(o.names, channel.Name())
+	}
+	return o
+}
+
+// Notifier returns a notifier writing intents for the named channels, or for all channels
+// when no names are given
+func (o *Outbox) Notifier(names ...string) notifier.Notifier {
+	if len(names) == 0 {
+		names = o.names
+	}
+	return &writer{outbox: o, names: names}
+}
+
+// Run delivers pending intents until the context is cancelled
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(DispatchInterval)
+	defer ticker.Stop()
+
+	cleanup := time.NewTicker(CleanupInterval)
+	defer cleanup.Stop()
+
+	log.Printf("Starting notification outbox for %v", o.names)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Notification outbox stopped")
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		case <-cleanup.C:
+			if deleted, err := o.store.DeleteFinishedNotifications(ctx, time.Now().Add(-Retention)); err != nil {
+				log.Printf("Failed to clean notification outbox: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Removed %d finished notifications from the outbox", deleted)
+			}
+			continue
+		}
+
+		o.dispatch(ctx, time.Now())
+	}
+}
+
+// enqueue stores an intent for the channel and wakes the dispatcher
+func (o *Outbox) enqueue(ctx context.Context, channel, kind, dedupKey string, payload intent) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	entry := database.OutboxEntry{Channel: channel, Kind: kind, Office: payload.QueueData.Office, DedupKey: dedupKey, Payload: string(data)}
+	added, err := o.store.EnqueueNotification(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if !added {
+		log.Printf("Skipping duplicate notification %s", dedupKey)
+		return nil
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// dispatch delivers the pending intents due at now. Only the latest queue update of each
+// channel and office is delivered; it supersedes older pending ones.
+func (o *Outbox) dispatch(ctx context.Context, now time.Time) {
+	entries, err := o.store.PendingNotifications(ctx, now, BatchSize)
+	if err != nil {
+		log.Printf("Failed to read notification outbox: %v", err)
+		return
+	}
+
+	latest := make(map[string]int64) // Channel and office -> ID of the newest queue update
+	for _, entry := range entries {
+		if entry.Kind == KindBroadcast {
+			latest[entry.Channel+"/"+entry.Office] = entry.ID
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Kind == KindBroadcast {
+			if latest[entry.Channel+"/"+entry.Office] != entry.ID {
+				continue // Superseded below
+			}
+			if _, err := o.store.SupersedeNotifications(ctx, entry); err != nil {
+				log.Printf("Failed to supersede older notifications of %s: %v", entry.Channel, err)
+			}
+		}
+
+		var payload intent
+		if err := json.Unmarshal([]byte(entry.Payload), &payload); err != nil || payload.QueueData == nil {
+			log.Printf("Dropping unreadable notification %d: %v", entry.ID, err)
+			o.complete(ctx, entry, database.OutboxFailed)
+			continue
+		}
+
+		channel, ok := o.channels[entry.Channel]
+		if !ok {
+			log.Printf("Dropping notification %d for unknown channel %s", entry.ID, entry.Channel)
+			o.complete(ctx, entry, database.OutboxFailed)
+			continue
+		}
+
+		if err := deliver(ctx, channel, entry.Kind, payload); err != nil {
+			if ctx.Err() != nil {
+				// Not an attempt: the intent stays due and is delivered after the restart
+				log.Printf("Delivery of %s notification %d to %s interrupted by shutdown: %v", entry.Kind, entry.ID, entry.Channel, err)
+				return
+			}
+			o.retry(ctx, entry, now, err)
+			continue
+		}
+		o.complete(ctx, entry, database.OutboxDelivered)
+	}
+}
+
+// deliver sends an intent through its channel
+func deliver(ctx context.Context, channel notifier.Notifier, kind string, payload intent) error {
+	if kind == KindBroadcast {
+		return channel.Broadcast(ctx, payload.QueueData, payload.Changes)
+	}
+	if payload.Alert == nil {
+		return fmt.Errorf("%s notification without an alert", kind)
+	}
+	return channel.SendAlert(ctx, payload.QueueData, *payload.Alert)
+}
+
+// complete stores the final status of an intent
+func (o *Outbox) complete(ctx context.Context, entry database.OutboxEntry, status string) {
+	if err := o.store.CompleteNotification(ctx, entry.ID, status); err != nil {
+		log.Printf("Failed to update notification %d: %v", entry.ID, err)
+	}
+}
+
+// retry schedules another attempt of a failed intent with exponential backoff, giving up
+// after MaxAttempts
+func (o *Outbox) retry(ctx context.Context, entry database.OutboxEntry, now time.Time, deliveryErr error) {
+	attempts := entry.Attempts + 1
+	if attempts >= MaxAttempts {
+		log.Printf("Giving up %s notification %d for %s after %d attempts: %v", entry.Kind, entry.ID, entry.Channel, attempts, deliveryErr)
+		o.complete(ctx, entry, database.OutboxFailed)
+		return
+	}
+
+	delay := RetryDelay << (attempts - 1)
+	if delay > MaxRetryDelay {
+		delay = MaxRetryDelay
+	}
+
+	log.Printf("Failed to deliver %s notification %d to %s (attempt %d), retrying in %v: %v", entry.Kind, entry.ID, entry.Channel, attempts, delay, deliveryErr)
+	if err := o.store.RetryNotification(ctx, entry.ID, attempts, now.Add(delay), deliveryErr.Error()); err != nil {
+		log.Printf("Failed to reschedule notification %d: %v", entry.ID, err)
+	}
+}
+
+// writer is the notifier side of the outbox, turning updates and alerts into intents
+type writer struct {
+	outbox *Outbox
+	names  []string
+}
+
+// Name implements notifier.Notifier
+func (w *writer) Name() string {
+	return "outbox"
+}
+
+// Broadcast stores the queue update for every channel of the writer. Storing is not
+// cancelled, so a poll accepted during shutdown is still delivered after the restart.
+func (w *writer) Broadcast(ctx context.Context, queueData *models.QueueData, changes *models.QueueChanges) error {
+	var errs []error
+	for _, name := range w.names {
+		key := fmt.Sprintf("%s:%s:%s:%d", name, KindBroadcast, queueData.Office, queueData.LastUpdated.UnixNano())
+		if err := w.outbox.enqueue(context.WithoutCancel(ctx), name, KindBroadcast, key, intent{QueueData: queueData, Changes: changes}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendAlert stores the alert for every channel of the writer. The same alert about the
+// same poll is only stored once.
+func (w *writer) SendAlert(ctx context.Context, queueData *models.QueueData, alert notifier.Alert) error {
+	var errs []error
+	for _, name := range w.names {
+		key := fmt.Sprintf("%s:%s:%s:%d:%d:%d:%d:%s", name, alert.Kind, queueData.Office, queueData.LastUpdated.UnixNano(),
+			alert.Transition, alert.PreviousLeft, alert.CurrentLeft, alert.PreviousTicket)
+		if err := w.outbox.enqueue(context.WithoutCancel(ctx), name, string(alert.Kind), key, intent{QueueData: queueData, Alert: &alert}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
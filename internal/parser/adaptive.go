@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"time"
+
+	"karta/internal/models"
+	"karta/internal/schedule"
+)
+
+// PollingPolicy adapts the polling interval to queue activity: the configured interval
+// while the queue is open and changing, slower when it is quiet, closed or the office is shut
+type PollingPolicy struct {
+	QuietInterval  time.Duration // Queue open but unchanged for QuietAfter, or closed during office hours
+	QuietAfter     time.Duration
+	ClosedInterval time.Duration      // Queue closed (or office shut when a schedule is set)
+	Schedule       *schedule.Schedule // Office hours; nil disables office-hours awareness
+}
+
+// SetPollingPolicy enables adaptive polling with the given policy (nil disables it)
+func (p *QueueParser) SetPollingPolicy(policy *PollingPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policy = policy
+}
+
+// recordQueueState remembers the last parsed queue state and when it last changed.
+// Must be called with p.mu held.
+func (p *QueueParser) recordQueueState(data *models.QueueData, now time.Time) {
+	if p.lastData == nil || models.CompareQueues(p.lastData, data).HasChanges {
+		p.lastChange = now
+	}
+	p.lastData = data.Clone()
+}
+
+// interval returns the polling interval for the queue state, never shorter than base
+func (pp *PollingPolicy) interval(base time.Duration, data *models.QueueData, lastChange, now time.Time) time.Duration {
+	if pp == nil || data == nil {
+		return base
+	}
+
+	var interval time.Duration
+	switch {
+	case pp.Schedule != nil && !pp.Schedule.IsOpen(now):
+		// Wake up in time for the opening so the open alert is not delayed
+		interval = pp.ClosedInterval
+		if opening, ok := pp.Schedule.NextOpening(now); ok && opening.Sub(now) < interval {
+			interval = opening.Sub(now)
+		}
+	case data.Status != models.StatusOpen && pp.Schedule != nil:
+		interval = pp.QuietInterval
+	case data.Status != models.StatusOpen:
+		interval = pp.ClosedInterval
+	case now.Sub(lastChange) >= pp.QuietAfter:
+		interval = pp.QuietInterval
+	default:
+		interval = base
+	}
+
+	if interval < base {
+		return base
+	}
+	return interval
+}
@@ -0,0 +1,45 @@
+package parser
+
+import "time"
+
+const (
+	FetchAttempts = 3           // Requests per poll before giving up
+	FetchBackoff  = time.Second // Delay before the first retry, doubled after each one
+
+	BreakerThreshold   = 3               // Consecutive failed polls before the breaker opens
+	BreakerMaxInterval = 5 * time.Minute // Upper bound of the widened polling interval
+)
+
+// circuitBreaker widens the polling interval while the DUW API keeps failing
+type circuitBreaker struct {
+	failures int // Consecutive failed polls
+}
+
+// record registers a poll result and reports whether the breaker just opened or recovered
+func (b *circuitBreaker) record(success bool) (opened, recovered bool) {
+	if success {
+		recovered = b.failures >= BreakerThreshold
+		b.failures = 0
+		return false, recovered
+	}
+
+	b.failures++
+	return b.failures == BreakerThreshold, false
+}
+
+// interval returns the base interval, doubled for every failed poll past the threshold
+func (b *circuitBreaker) interval(base time.Duration) time.Duration {
+	if b.failures < BreakerThreshold || base >= BreakerMaxInterval {
+		return base
+	}
+
+	widened := base
+	for i := BreakerThreshold; i <= b.failures && widened < BreakerMaxInterval; i++ {
+		widened *= 2
+	}
+
+	if widened > BreakerMaxInterval {
+		return BreakerMaxInterval
+	}
+	return widened
+}
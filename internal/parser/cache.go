@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaxResponseSize caps the DUW response bodies read and cached
+const MaxResponseSize = 4 << 20
+
+// CachedResponse is the last good upstream response of a source
+type CachedResponse struct {
+	Body        []byte
+	ContentType string
+	FetchedAt   time.Time // Last time upstream confirmed the body (200 or 304)
+}
+
+// ResponseCacher is implemented by sources keeping their last good upstream response
+type ResponseCacher interface {
+	CachedResponse() (CachedResponse, bool)
+}
+
+// responseCache keeps the validators and body of the last good response, so requests can
+// be made conditional and the body served while upstream is down
+type responseCache struct {
+	mu           sync.RWMutex
+	etag         string
+	lastModified string
+	response     CachedResponse
+	decoded      *APIResponse
+}
+
+// setConditional adds If-None-Match/If-Modified-Since headers for the cached response
+func (c *responseCache) setConditional(req *http.Request) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.decoded == nil {
+		return
+	}
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+	if c.lastModified != "" {
+		req.Header.Set("If-Modified-Since", c.lastModified)
+	}
+}
+
+// notModified returns the cached response after upstream answered 304
+func (c *responseCache) notModified(now time.Time) (*APIResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.decoded == nil {
+		return nil, false
+	}
+	c.response.FetchedAt = now
+	return c.decoded, true
+}
+
+// unchanged returns the cached response when a 200 body is identical to it, so it need not be decoded again
+func (c *responseCache) unchanged(body []byte, now time.Time) (*APIResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.decoded == nil || string(c.response.Body) != string(body) {
+		return nil, false
+	}
+	c.response.FetchedAt = now
+	return c.decoded, true
+}
+
+// store remembers a good response with its validators
+func (c *responseCache) store(resp *http.Response, body []byte, decoded *APIResponse, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.etag = resp.Header.Get("ETag")
+	c.lastModified = resp.Header.Get("Last-Modified")
+	c.response = CachedResponse{Body: body, ContentType: resp.Header.Get("Content-Type"), FetchedAt: now}
+	c.decoded = decoded
+}
+
+// get returns the cached response
+func (c *responseCache) get() (CachedResponse, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.response, c.decoded != nil
+}
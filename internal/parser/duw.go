@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"karta/internal/logging"
+	"karta/internal/models"
+)
+
+const (
+	DUWStatusURL = "https://rezerwacje.duw.pl/app/webroot/status_kolejek/query.php?status"
+	UserAgent    = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+// APIResponse represents the JSON response from DUW API
+type APIResponse struct {
+	Result map[string][]QueueItem `json:"result"`
+}
+
+// QueueItem represents a single queue item from the API
+type QueueItem struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	TicketCount        int    `json:"ticket_count"`
+	TicketsServed      int    `json:"tickets_served"`
+	Workplaces         int    `json:"workplaces"`
+	AverageWaitTime    int    `json:"average_wait_time"`    // in seconds
+	AverageServiceTime int    `json:"average_service_time"` // in seconds
+	TicketValue        string `json:"ticket_value"`
+	TicketsLeft        int    `json:"tickets_left"`
+	Enabled            bool   `json:"enabled"`
+	Active             bool   `json:"active"`
+	Location           string `json:"location"`
+}
+
+// DUWSource fetches a queue of a DUW office branch from the DUW JSON API
+type DUWSource struct {
+	client    *http.Client
+	statusURL string
+	section   string // Section of the API response, e.g. "Wrocław"
+	queue     string // Queue of the section, e.g. "odbiór karty"
+	cache     responseCache
+}
+
+// NewDUWSource creates a source for a queue of the given section of the DUW status API
+func NewDUWSource(section, queue string) *DUWSource {
+	// DUW_STATUS_URL points the parser at another endpoint, e.g. the mock server from testserver
+	statusURL := DUWStatusURL
+	if override := os.Getenv("DUW_STATUS_URL"); override != "" {
+		log.Printf("Using DUW status URL %s", override)
+		statusURL = override
+	}
+
+	return &DUWSource{
+		client:    NewHTTPClient(),
+		statusURL: statusURL,
+		section:   section,
+		queue:     queue,
+	}
+}
+
+// NewHTTPClient creates the HTTP client for DUW requests, sending them through the
+// proxies from the environment when configured
+func NewHTTPClient() *http.Client {
+	tr := &http.Transport{
+		TLSClientConfig: newTLSConfig(),
+	}
+
+	var transport http.RoundTripper = tr
+	if proxies := proxiesFromEnv(); len(proxies) > 0 {
+		rotation := os.Getenv("DUW_PROXY_ROTATION")
+		if rotation != ProxyRotationFailover {
+			rotation = ProxyRotationRoundRobin
+		}
+
+		names := make([]string, len(proxies))
+		for i, proxyURL := range proxies {
+			names[i] = proxyURL.Redacted()
+		}
+		log.Printf("Using proxies for DUW requests (%s): %s", rotation, strings.Join(names, ", "))
+
+		transport = newProxyTransport(tr, proxies, rotation)
+	} else {
+		log.Println("Proxy not configured, using direct connection")
+	}
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: tlsDiagnostics{transport},
+	}
+}
+
+// Fetch requests the DUW API once and extracts the tracked queue
+func (s *DUWSource) Fetch(ctx context.Context) (*models.QueueData, error) {
+	apiResponse, err := s.fetchAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	queueData, err := extractQueueDataFromAPI(apiResponse, s.section, s.queue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract queue data: %w", err)
+	}
+	return queueData, nil
+}
+
+// fetchAPI performs a single request to the DUW API and decodes the response
+func (s *DUWSource) fetchAPI(ctx context.Context) (*APIResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.statusURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	s.cache.setConditional(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Upstream confirmed the cached response, nothing to decode
+	if resp.StatusCode == http.StatusNotModified {
+		if cached, ok := s.cache.notModified(time.Now()); ok {
+			return cached, nil
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	if cached, ok := s.cache.unchanged(body, time.Now()); ok {
+		return cached, nil
+	}
+
+	var apiResponse APIResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	s.cache.store(resp, body, &apiResponse, time.Now())
+	return &apiResponse, nil
+}
+
+// CachedResponse returns the last good DUW API response
+func (s *DUWSource) CachedResponse() (CachedResponse, bool) {
+	return s.cache.get()
+}
+
+// extractQueueDataFromAPI extracts the data of a queue of the section from the API response
+func extractQueueDataFromAPI(apiResponse *APIResponse, section, queueName string) (*models.QueueData, error) {
+	// Look for the office queues
+	officeQueues, exists := apiResponse.Result[section]
+	if !exists {
+		return nil, fmt.Errorf("%s section not found in API response: %w", section, ErrQueueMissing)
+	}
+
+	var names []string
+	for _, queue := range officeQueues {
+		names = append(names, queue.Name)
+		if MatchesQueue(queue.Name, queueName) {
+			logging.Debugf("Found '%s' queue: %+v", queueName, queue)
+
+			// Determine status
+			status := models.StatusOpen
+			if !queue.Enabled || !queue.Active {
+				status = models.StatusClosed
+			}
+
+			queueData := &models.QueueData{
+				Name:           queueName, // The configured name, whatever spelling DUW uses
+				ServedClients:  queue.TicketsServed,
+				WaitingClients: queue.TicketCount,
+				Workplaces:     queue.Workplaces,
+				AvgServiceTime: seconds(queue.AverageServiceTime),
+				AvgWaitTime:    seconds(queue.AverageWaitTime),
+				LastTicket:     queue.TicketValue,
+				TicketsLeft:    queue.TicketsLeft,
+				Status:         status,
+			}
+
+			logging.Debugf("Extracted queue data: %+v", queueData)
+			return queueData, nil
+		}
+	}
+
+	return nil, fmt.Errorf("queue '%s' not found in %s section (queues: %s): %w", queueName, section, strings.Join(names, ", "), ErrQueueMissing)
+}
+
+// seconds converts an API time in seconds to a duration, zero when not reported
+func seconds(value int) time.Duration {
+	if value <= 0 {
+		return 0
+	}
+	return time.Duration(value) * time.Second
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"karta/internal/models"
+)
+
+// FallbackSource reads from a primary source and switches to a fallback after the primary
+// fails a number of times in a row. The primary is still tried first on every fetch, so
+// the fallback is left as soon as the primary recovers.
+type FallbackSource struct {
+	primary   QueueSource
+	fallback  QueueSource
+	threshold int
+
+	mu       sync.Mutex
+	failures int // Consecutive primary failures
+}
+
+// NewFallbackSource creates a source using fallback after threshold consecutive primary failures
+func NewFallbackSource(primary, fallback QueueSource, threshold int) *FallbackSource {
+	return &FallbackSource{
+		primary:   primary,
+		fallback:  fallback,
+		threshold: threshold,
+	}
+}
+
+// Fetch reads from the primary source, or from the fallback while the primary keeps failing
+func (s *FallbackSource) Fetch(ctx context.Context) (*models.QueueData, error) {
+	queueData, err := s.primary.Fetch(ctx)
+
+	s.mu.Lock()
+	if err == nil {
+		if s.failures >= s.threshold {
+			log.Printf("Primary queue source recovered after %d failures", s.failures)
+		}
+		s.failures = 0
+		s.mu.Unlock()
+		return queueData, nil
+	}
+	s.failures++
+	failures := s.failures
+	s.mu.Unlock()
+
+	if failures < s.threshold || ctx.Err() != nil {
+		return nil, err
+	}
+
+	if failures == s.threshold {
+		log.Printf("Primary queue source failed %d times in a row, using the fallback: %v", failures, err)
+	}
+
+	queueData, fallbackErr := s.fallback.Fetch(ctx)
+	if fallbackErr != nil {
+		log.Printf("Fallback queue source failed: %v", fallbackErr)
+		return nil, err
+	}
+	return queueData, nil
+}
+
+// CachedResponse returns the last good response of the primary source, when it keeps one
+func (s *FallbackSource) CachedResponse() (CachedResponse, bool) {
+	if cacher, ok := s.primary.(ResponseCacher); ok {
+		return cacher.CachedResponse()
+	}
+	return CachedResponse{}, false
+}
@@ -0,0 +1,312 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"karta/internal/logging"
+	"karta/internal/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const DUWStatusPageURL = "https://rezerwacje.duw.pl/status_kolejek/"
+
+// Status page columns, matched by fragments of the lowercased header text
+const (
+	columnName        = "name"
+	columnLastTicket  = "last_ticket"
+	columnWaiting     = "waiting"
+	columnServed      = "served"
+	columnWorkplaces  = "workplaces"
+	columnTicketsLeft = "tickets_left"
+	columnServiceTime = "service_time"
+	columnWaitTime    = "wait_time"
+)
+
+// HTMLSource scrapes a queue of a DUW office branch from the public status page, for when
+// the JSON API breaks or changes
+type HTMLSource struct {
+	client  *http.Client
+	pageURL string
+	section string // Section heading of the office, e.g. "Wrocław"
+	queue   string // Queue of the section, e.g. "odbiór karty"
+}
+
+// NewHTMLSource creates a source for a queue of the given section of the DUW status page
+func NewHTMLSource(section, queue string) *HTMLSource {
+	// DUW_STATUS_PAGE_URL points the scraper at another page, e.g. the mock server from testserver
+	pageURL := DUWStatusPageURL
+	if override := os.Getenv("DUW_STATUS_PAGE_URL"); override != "" {
+		log.Printf("Using DUW status page URL %s", override)
+		pageURL = override
+	}
+
+	return &HTMLSource{
+		client:  NewHTTPClient(),
+		pageURL: pageURL,
+		section: section,
+		queue:   queue,
+	}
+}
+
+// Fetch requests the status page once and extracts the tracked queue
+func (s *HTMLSource) Fetch(ctx context.Context) (*models.QueueData, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch status page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	queueData, err := extractQueueDataFromHTML(doc, s.section, s.queue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract queue data: %w", err)
+	}
+	return queueData, nil
+}
+
+// extractQueueDataFromHTML finds the row of the queue in the table of the section.
+// A table belongs to the section named by its caption or, without one, by the closest
+// heading before it.
+func extractQueueDataFromHTML(doc *html.Node, section, queue string) (*models.QueueData, error) {
+	var tables []*html.Node
+	var sections []string
+	heading := ""
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				heading = nodeText(n)
+				return
+			case atom.Table:
+				section := heading
+				if caption := findChild(n, atom.Caption); caption != nil {
+					section = nodeText(caption)
+				}
+				tables = append(tables, n)
+				sections = append(sections, section)
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	found := false
+	for i, table := range tables {
+		if !strings.Contains(sections[i], section) {
+			continue
+		}
+		found = true
+
+		queueData, err := extractQueueRow(table, queue)
+		if err != nil {
+			return nil, err
+		}
+		if queueData != nil {
+			logging.Debugf("Extracted queue data from status page: %+v", queueData)
+			return queueData, nil
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%s section not found in status page: %w", section, ErrQueueMissing)
+	}
+	return nil, fmt.Errorf("queue '%s' not found in %s section: %w", queue, section, ErrQueueMissing)
+}
+
+// extractQueueRow maps the table columns by their headers and converts the row of the
+// queue; it returns nil when the table has no such row
+func extractQueueRow(table *html.Node, queue string) (*models.QueueData, error) {
+	var columns map[string]int
+
+	for _, row := range findAll(table, atom.Tr) {
+		cells, header := rowCells(row)
+		if header {
+			columns = mapColumns(cells)
+			continue
+		}
+		if columns == nil {
+			continue
+		}
+
+		nameIndex, ok := columns[columnName]
+		if !ok || nameIndex >= len(cells) || !MatchesQueue(cells[nameIndex], queue) {
+			continue
+		}
+
+		for _, required := range []string{columnWaiting, columnServed} {
+			if _, ok := columns[required]; !ok {
+				return nil, fmt.Errorf("status page table has no %s column", required)
+			}
+		}
+
+		cell := func(column string) string {
+			if index, ok := columns[column]; ok && index < len(cells) {
+				return cells[index]
+			}
+			return ""
+		}
+
+		return &models.QueueData{
+			Name:           queue, // The configured name, whatever spelling the page uses
+			ServedClients:  cellNumber(cell(columnServed)),
+			WaitingClients: cellNumber(cell(columnWaiting)),
+			Workplaces:     cellNumber(cell(columnWorkplaces)),
+			AvgServiceTime: models.ParseServiceTime(cell(columnServiceTime)),
+			AvgWaitTime:    models.ParseServiceTime(cell(columnWaitTime)),
+			LastTicket:     cell(columnLastTicket),
+			TicketsLeft:    cellNumber(cell(columnTicketsLeft)),
+			Status:         rowStatus(row, cells),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// mapColumns returns the index of each known column in a header row
+func mapColumns(headers []string) map[string]int {
+	columns := make(map[string]int)
+	for i, header := range headers {
+		header = strings.ToLower(header)
+
+		var column string
+		switch {
+		case strings.Contains(header, "czas obsługi"):
+			column = columnServiceTime
+		case strings.Contains(header, "czas oczekiwania"):
+			column = columnWaitTime
+		case strings.Contains(header, "obsłużon"):
+			column = columnServed
+		case strings.Contains(header, "oczekując"), strings.Contains(header, "w kolejce"):
+			column = columnWaiting
+		case strings.Contains(header, "stanowisk"):
+			column = columnWorkplaces
+		case strings.Contains(header, "pozostał"):
+			column = columnTicketsLeft
+		case strings.Contains(header, "numer"), strings.Contains(header, "bilet"):
+			column = columnLastTicket
+		case strings.Contains(header, "kolejka"), strings.Contains(header, "nazwa"):
+			column = columnName
+		default:
+			continue
+		}
+
+		if _, exists := columns[column]; !exists {
+			columns[column] = i
+		}
+	}
+	return columns
+}
+
+// rowStatus reports a queue as closed when its row is marked inactive or says it is closed
+func rowStatus(row *html.Node, cells []string) string {
+	for _, attr := range row.Attr {
+		if attr.Key == "class" && (strings.Contains(attr.Val, "inactive") || strings.Contains(attr.Val, "disabled")) {
+			return models.StatusClosed
+		}
+	}
+
+	for _, cell := range cells {
+		lower := strings.ToLower(cell)
+		if strings.Contains(lower, "nieczynn") || strings.Contains(lower, "zamknię") {
+			return models.StatusClosed
+		}
+	}
+	return models.StatusOpen
+}
+
+// cellNumber returns the number of a numeric cell, 0 when it has none (e.g. "-")
+func cellNumber(text string) int {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, text)
+
+	number, _ := strconv.Atoi(digits)
+	return number
+}
+
+// rowCells returns the texts of a row's cells and whether it is a header row
+func rowCells(row *html.Node) ([]string, bool) {
+	var cells []string
+	header := false
+	for c := row.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+			continue
+		}
+		if c.DataAtom == atom.Th {
+			header = true
+		}
+		cells = append(cells, nodeText(c))
+	}
+	return cells, header
+}
+
+// nodeText returns the text content of a node with whitespace collapsed
+func nodeText(n *html.Node) string {
+	var builder strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			builder.WriteString(n.Data)
+			builder.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// findChild returns the first direct child element of the given type
+func findChild(n *html.Node, a atom.Atom) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			return c
+		}
+	}
+	return nil
+}
+
+// findAll returns all descendant elements of the given type in document order
+func findAll(n *html.Node, a atom.Atom) []*html.Node {
+	var found []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			found = append(found, c)
+		}
+		found = append(found, findAll(c, a)...)
+	}
+	return found
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"karta/internal/logging"
+	"karta/internal/models"
+)
+
+const MUWStatusURL = "https://kolejka.mazowieckie.pl/api/v1/status"
+
+// MUWResponse represents the JSON response from the queue status API of the Masovian
+// office (MUW)
+type MUWResponse struct {
+	Updated string     `json:"updated"` // RFC 3339 time of the snapshot
+	Queues  []MUWQueue `json:"queues"`
+}
+
+// MUWQueue represents a single queue of the MUW status API
+type MUWQueue struct {
+	Symbol        string `json:"symbol"` // Ticket prefix, e.g. "K"
+	Label         string `json:"label"`
+	Waiting       int    `json:"waiting"`
+	ServedToday   int    `json:"served_today"`
+	Desks         int    `json:"desks"`
+	CurrentTicket string `json:"current_ticket"`
+	AvgWait       string `json:"avg_wait"`    // HH:MM:SS
+	AvgService    string `json:"avg_service"` // HH:MM:SS
+	TicketsLeft   int    `json:"tickets_left"`
+	State         string `json:"state"` // "open", "paused" or "closed"
+}
+
+// MUWSource fetches a queue of the Masovian office in Warsaw from the MUW status API.
+// The response schema has not been checked against a recorded response of the live API
+// yet, so no office in Offices uses it.
+type MUWSource struct {
+	client    *http.Client
+	statusURL string
+	queue     string // Queue label, e.g. "odbiór karty"
+
+	mu     sync.Mutex
+	status string // Status of the last poll, kept while the queue is paused
+}
+
+// NewMUWSource creates a source for a queue of the MUW status API
+func NewMUWSource(queue string) *MUWSource {
+	// MUW_STATUS_URL points the parser at another endpoint, e.g. a local mock
+	statusURL := MUWStatusURL
+	if override := os.Getenv("MUW_STATUS_URL"); override != "" {
+		log.Printf("Using MUW status URL %s", override)
+		statusURL = override
+	}
+
+	return &MUWSource{
+		client:    newMUWHTTPClient(),
+		statusURL: statusURL,
+		queue:     queue,
+		status:    models.StatusOpen,
+	}
+}
+
+// newMUWHTTPClient creates the HTTP client for MUW requests. The TLS settings and proxies
+// of DUW requests (DUW_CERT_PINS, DUW_CA_BUNDLE, DUW_PROXIES) are not applied, as they are
+// about another server.
+func newMUWHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// Fetch requests the MUW API once and extracts the tracked queue
+func (s *MUWSource) Fetch(ctx context.Context) (*models.QueueData, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.statusURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	var response MUWResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queueData, err := extractQueueDataFromMUW(&response, s.queue, s.status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract queue data: %w", err)
+	}
+	s.status = queueData.Status
+	return queueData, nil
+}
+
+// extractQueueDataFromMUW extracts the data of a queue from the MUW API response; a paused
+// queue keeps the status of the previous poll
+func extractQueueDataFromMUW(response *MUWResponse, queueName, previousStatus string) (*models.QueueData, error) {
+	var names []string
+	for _, queue := range response.Queues {
+		names = append(names, queue.Label)
+		if !MatchesQueue(queue.Label, queueName) {
+			continue
+		}
+		logging.Debugf("Found '%s' queue: %+v", queueName, queue)
+
+		avgWait, err := parseClock(queue.AvgWait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid average wait time: %w", err)
+		}
+		avgService, err := parseClock(queue.AvgService)
+		if err != nil {
+			return nil, fmt.Errorf("invalid average service time: %w", err)
+		}
+
+		// A pause, e.g. a break of the desks, neither opens nor closes the queue, so it
+		// sends no status alerts
+		var status string
+		switch queue.State {
+		case "open":
+			status = models.StatusOpen
+		case "paused":
+			status = previousStatus
+		default:
+			status = models.StatusClosed
+		}
+
+		queueData := &models.QueueData{
+			Name:           queueName, // The configured name, whatever spelling MUW uses
+			ServedClients:  queue.ServedToday,
+			WaitingClients: queue.Waiting,
+			Workplaces:     queue.Desks,
+			AvgServiceTime: avgService,
+			AvgWaitTime:    avgWait,
+			LastTicket:     queue.CurrentTicket,
+			TicketsLeft:    queue.TicketsLeft,
+			Status:         status,
+		}
+
+		logging.Debugf("Extracted queue data: %+v", queueData)
+		return queueData, nil
+	}
+
+	return nil, fmt.Errorf("queue '%s' not found in MUW response (queues: %s): %w", queueName, strings.Join(names, ", "), ErrQueueMissing)
+}
+
+// parseClock parses an HH:MM:SS duration of the MUW API, zero when not reported
+func parseClock(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	var hours, minutes, secs int
+	if _, err := fmt.Sscanf(value, "%d:%d:%d", &hours, &minutes, &secs); err != nil {
+		return 0, fmt.Errorf("failed to parse %q: %w", value, err)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + seconds(secs), nil
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"karta/internal/models"
+)
+
+const muwResponse = `{
+	"updated": "2026-03-10T10:15:00+01:00",
+	"queues": [
+		{"symbol": "W", "label": "Złożenie wniosku", "waiting": 30, "served_today": 51, "desks": 4,
+		 "current_ticket": "W052", "avg_wait": "00:41:10", "avg_service": "00:09:00", "tickets_left": 0, "state": "open"},
+		{"symbol": "K", "label": "Odbiór  karty", "waiting": 12, "served_today": 40, "desks": 3,
+		 "current_ticket": "K041", "avg_wait": "00:25:00", "avg_service": "00:06:30", "tickets_left": 15, "state": "open"},
+		{"symbol": "D", "label": "Odbiór decyzji", "waiting": 0, "served_today": 8, "desks": 1,
+		 "current_ticket": "D008", "avg_wait": "", "avg_service": "", "tickets_left": 5, "state": "paused"}
+	]
+}`
+
+// serveMUW points the MUW source at a local server replying with status and body
+func serveMUW(t *testing.T, status int, body string) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("MUW_STATUS_URL", server.URL)
+}
+
+func TestMUWSource(t *testing.T) {
+	serveMUW(t, http.StatusOK, muwResponse)
+
+	tests := []struct {
+		name  string
+		queue string
+		want  models.QueueData
+	}{
+		{
+			name:  "open queue",
+			queue: QueueCardPickup,
+			want: models.QueueData{
+				Name: QueueCardPickup, ServedClients: 40, WaitingClients: 12, Workplaces: 3,
+				AvgServiceTime: 6*time.Minute + 30*time.Second, AvgWaitTime: 25 * time.Minute,
+				LastTicket: "K041", TicketsLeft: 15, Status: models.StatusOpen,
+			},
+		},
+		{
+			name:  "paused queue without times",
+			queue: QueueDecision,
+			want: models.QueueData{
+				Name: QueueDecision, ServedClients: 8, Workplaces: 1,
+				LastTicket: "D008", TicketsLeft: 5, Status: models.StatusOpen,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queueData, err := NewMUWSource(tt.queue).Fetch(context.Background())
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			if *queueData != tt.want {
+				t.Errorf("Fetch = %+v, want %+v", *queueData, tt.want)
+			}
+		})
+	}
+}
+
+func TestMUWSourceErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMissing bool
+	}{
+		{name: "missing queue", status: http.StatusOK, body: `{"queues": []}`, wantMissing: true},
+		{name: "malformed JSON", status: http.StatusOK, body: `{"queues": [`},
+		{name: "invalid time", status: http.StatusOK, body: `{"queues": [{"label": "odbiór karty", "avg_wait": "soon"}]}`},
+		{name: "server error", status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveMUW(t, tt.status, tt.body)
+
+			_, err := NewMUWSource(QueueCardPickup).Fetch(context.Background())
+			if err == nil {
+				t.Fatal("Fetch succeeded")
+			}
+			if missing := errors.Is(err, ErrQueueMissing); missing != tt.wantMissing {
+				t.Errorf("Fetch error %v, want a missing queue: %v", err, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestMUWSourcePause(t *testing.T) {
+	// A pause keeps the status of the previous poll, whatever it was
+	states := []string{"open", "paused", "closed", "paused", "paused", "open"}
+	want := []string{
+		models.StatusOpen, models.StatusOpen, models.StatusClosed,
+		models.StatusClosed, models.StatusClosed, models.StatusOpen,
+	}
+
+	poll := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"queues": [{"label": "odbiór karty", "state": %q}]}`, states[poll])
+		poll++
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("MUW_STATUS_URL", server.URL)
+
+	source := NewMUWSource(QueueCardPickup)
+	for i := range states {
+		queueData, err := source.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("poll %d failed: %v", i+1, err)
+		}
+		if queueData.Status != want[i] {
+			t.Errorf("poll %d of a %s queue: status %s, want %s", i+1, states[i], queueData.Status, want[i])
+		}
+	}
+}
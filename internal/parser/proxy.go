@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ProxyCooldown = 5 * time.Minute // A failed proxy is skipped for this long
+
+	ProxyRotationRoundRobin = "round-robin" // Every request goes through the next proxy
+	ProxyRotationFailover   = "failover"    // Requests stick to one proxy until it fails
+)
+
+// proxiesFromEnv returns the outbound proxies configured in the environment: DUW_PROXIES
+// lists http://, https:// and socks5:// URLs, the older USE_SOCKS5_PROXY settings add a
+// single SOCKS5 proxy. Invalid entries are logged and skipped.
+func proxiesFromEnv() []*url.URL {
+	var proxies []*url.URL
+
+	for _, value := range strings.Split(os.Getenv("DUW_PROXIES"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+
+		proxyURL, err := url.Parse(value)
+		if err != nil || proxyURL.Host == "" {
+			log.Printf("Skipping invalid proxy URL in DUW_PROXIES: %q", value)
+			continue
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+			proxies = append(proxies, proxyURL)
+		default:
+			log.Printf("Skipping proxy with unsupported scheme %q in DUW_PROXIES", proxyURL.Scheme)
+		}
+	}
+
+	proxyHost := os.Getenv("SOCKS5_PROXY_HOST")
+	proxyPort := os.Getenv("SOCKS5_PROXY_PORT")
+	if os.Getenv("USE_SOCKS5_PROXY") == "true" && proxyHost != "" && proxyPort != "" {
+		proxyURL := &url.URL{Scheme: "socks5", Host: net.JoinHostPort(proxyHost, proxyPort)}
+		if user := os.Getenv("SOCKS5_PROXY_USER"); user != "" {
+			proxyURL.User = url.UserPassword(user, os.Getenv("SOCKS5_PROXY_PASSWORD"))
+		}
+		proxies = append(proxies, proxyURL)
+	}
+
+	return proxies
+}
+
+// proxyPool picks the proxy of each request, skipping proxies that failed recently
+type proxyPool struct {
+	proxies  []*url.URL
+	rotation string
+
+	mu          sync.Mutex
+	next        int
+	failedUntil []time.Time
+}
+
+// newProxyPool creates a pool rotating across the proxies with the given rotation mode
+func newProxyPool(proxies []*url.URL, rotation string) *proxyPool {
+	return &proxyPool{
+		proxies:     proxies,
+		rotation:    rotation,
+		failedUntil: make([]time.Time, len(proxies)),
+	}
+}
+
+// pick returns the index of the proxy for the next request. When every proxy failed
+// recently, the one coming out of its cooldown first is used.
+func (p *proxyPool) pick(now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best := p.next
+	for i := 0; i < len(p.proxies); i++ {
+		index := (p.next + i) % len(p.proxies)
+		if !now.Before(p.failedUntil[index]) {
+			best = index
+			break
+		}
+		if p.failedUntil[index].Before(p.failedUntil[best]) {
+			best = index
+		}
+	}
+
+	p.next = best
+	if p.rotation == ProxyRotationRoundRobin {
+		p.next = (best + 1) % len(p.proxies)
+	}
+	return best
+}
+
+// markFailed puts a proxy on cooldown and moves failover rotation to the next one
+func (p *proxyPool) markFailed(index int, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failedUntil[index] = now.Add(ProxyCooldown)
+	if p.next == index {
+		p.next = (index + 1) % len(p.proxies)
+	}
+}
+
+// proxyKey carries the proxy picked for a request from RoundTrip to Transport.Proxy
+type proxyKey struct{}
+
+// proxyTransport sends requests through the proxies of a pool. A proxy is put on
+// cooldown when the connection fails or the site answers as if the proxy is throttled
+// or blocked (403, 407, 429).
+type proxyTransport struct {
+	base *http.Transport
+	pool *proxyPool
+}
+
+// newProxyTransport routes the requests of the base transport through the proxies;
+// loopback hosts, such as the mock DUW server, are always reached directly
+func newProxyTransport(base *http.Transport, proxies []*url.URL, rotation string) *proxyTransport {
+	base.Proxy = func(req *http.Request) (*url.URL, error) {
+		if host := req.URL.Hostname(); host == "localhost" || net.ParseIP(host).IsLoopback() {
+			return nil, nil
+		}
+		proxyURL, _ := req.Context().Value(proxyKey{}).(*url.URL)
+		return proxyURL, nil
+	}
+	return &proxyTransport{base: base, pool: newProxyPool(proxies, rotation)}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	index := t.pool.pick(time.Now())
+	proxyURL := t.pool.proxies[index]
+
+	resp, err := t.base.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyKey{}, proxyURL)))
+
+	var reason string
+	switch {
+	case err != nil:
+		if req.Context().Err() != nil {
+			return nil, err // Cancelled by the caller, not the proxy's fault
+		}
+		reason = err.Error()
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusProxyAuthRequired || resp.StatusCode == http.StatusTooManyRequests:
+		reason = fmt.Sprintf("status code %d", resp.StatusCode)
+	}
+
+	if reason != "" && len(t.pool.proxies) > 1 {
+		log.Printf("Proxy %s failed (%s), skipping it for %v", proxyURL.Redacted(), reason, ProxyCooldown)
+		t.pool.markFailed(index, time.Now())
+	}
+	return resp, err
+}
@@ -2,232 +2,201 @@ package parser
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net"
-	"net/http"
-	"net/url"
-	"os"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
-	"golang.org/x/net/proxy"
 	"karta/internal/models"
 )
 
-const (
-	DUWStatusURL = "https://rezerwacje.duw.pl/app/webroot/status_kolejek/query.php?status"
-	UserAgent    = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
-)
-
-// APIResponse represents the JSON response from DUW API
-type APIResponse struct {
-	Result map[string][]QueueItem `json:"result"`
-}
-
-// QueueItem represents a single queue item from the API
-type QueueItem struct {
-	ID                 int    `json:"id"`
-	Name               string `json:"name"`
-	TicketCount        int    `json:"ticket_count"`
-	TicketsServed      int    `json:"tickets_served"`
-	Workplaces         int    `json:"workplaces"`
-	AverageWaitTime    int    `json:"average_wait_time"`    // in seconds
-	AverageServiceTime int    `json:"average_service_time"` // in seconds
-	TicketValue        string `json:"ticket_value"`
-	TicketsLeft        int    `json:"tickets_left"`
-	Enabled            bool   `json:"enabled"`
-	Active             bool   `json:"active"`
-	Location           string `json:"location"`
-}
-
-// QueueParser handles parsing of DUW queue status page
+// QueueParser polls a queue source, adapting the polling interval to the queue state and outages
 type QueueParser struct {
-	client *http.Client
+	source     QueueSource
+	intervalCh chan time.Duration
+
+	mu          sync.RWMutex
+	interval    time.Duration
+	lastAttempt time.Time // Start of the last poll
+	lastSuccess time.Time // Last poll that returned queue data
+	breaker     circuitBreaker
+	policy      *PollingPolicy
+	lastData    *models.QueueData // Last parsed state for the polling policy
+	lastChange  time.Time         // When the parsed state last changed
+
+	heartbeat         func() // Called by the monitoring loop while it runs, nil for none
+	heartbeatInterval time.Duration
 }
 
-// NewQueueParser creates a new queue parser instance
-func NewQueueParser() *QueueParser {
-	// Create HTTP client with insecure TLS config for problematic SSL certificates
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+// NewQueueParser creates a queue parser polling the given source
+func NewQueueParser(source QueueSource) *QueueParser {
+	return &QueueParser{
+		source:     source,
+		intervalCh: make(chan time.Duration, 1),
 	}
+}
 
-	// Check if SOCKS5 proxy should be used
-	useSocks5 := os.Getenv("USE_SOCKS5_PROXY")
-	proxyHost := os.Getenv("SOCKS5_PROXY_HOST")
-	proxyPort := os.Getenv("SOCKS5_PROXY_PORT")
-	proxyUser := os.Getenv("SOCKS5_PROXY_USER")
-	proxyPassword := os.Getenv("SOCKS5_PROXY_PASSWORD")
-
-	if useSocks5 == "true" && proxyHost != "" && proxyPort != "" {
-		log.Printf("Configuring SOCKS5 proxy: %s:%s", proxyHost, proxyPort)
-
-		// Create SOCKS5 proxy URL with authentication
-		var proxyURL *url.URL
-		var err error
-
-		if proxyUser != "" && proxyPassword != "" {
-			proxyURL, err = url.Parse(fmt.Sprintf("socks5://%s:%s@%s:%s", proxyUser, proxyPassword, proxyHost, proxyPort))
-		} else {
-			proxyURL, err = url.Parse(fmt.Sprintf("socks5://%s:%s", proxyHost, proxyPort))
+// ParseQueueData fetches queue data from the source, retrying failed requests with
+// exponential backoff. A response without the queue is returned right away: the source
+// answered, it just does not publish the queue.
+func (p *QueueParser) ParseQueueData(ctx context.Context) (*models.QueueData, error) {
+	var queueData *models.QueueData
+	var err error
+
+	backoff := FetchBackoff
+	for attempt := 1; ; attempt++ {
+		queueData, err = p.source.Fetch(ctx)
+		if err == nil || errors.Is(err, ErrQueueMissing) || attempt == FetchAttempts || ctx.Err() != nil {
+			break
 		}
 
-		if err != nil {
-			log.Printf("Failed to parse SOCKS5 proxy URL: %v", err)
-		} else {
-			// Create SOCKS5 dialer
-			dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
-			if err != nil {
-				log.Printf("Failed to create SOCKS5 dialer: %v", err)
-			} else {
-				// Use SOCKS5 proxy for transport
-				tr.Dial = func(network, addr string) (net.Conn, error) {
-					// Only use proxy for DUW requests
-					if strings.Contains(addr, "rezerwacje.duw.pl") {
-						log.Printf("Using SOCKS5 proxy for DUW request to: %s", addr)
-						return dialer.Dial(network, addr)
-					}
-					// Use direct connection for everything else
-					return net.Dial(network, addr)
-				}
-				log.Println("SOCKS5 proxy configured successfully for DUW requests")
-			}
+		log.Printf("Queue source request failed (attempt %d/%d), retrying in %v: %v", attempt, FetchAttempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
 		}
-	} else {
-		log.Println("SOCKS5 proxy not configured, using direct connection")
+		backoff *= 2
 	}
-
-	return &QueueParser{
-		client: &http.Client{
-			Timeout:   30 * time.Second,
-			Transport: tr,
-		},
+	if err != nil {
+		return nil, err
 	}
+
+	queueData.LastUpdated = time.Now()
+	return queueData, nil
 }
 
-// ParseQueueData fetches and parses queue data from DUW API
-func (p *QueueParser) ParseQueueData(ctx context.Context) (*models.QueueData, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", DUWStatusURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// CachedResponse returns the last good upstream response of the source, when it keeps one
+func (p *QueueParser) CachedResponse() (CachedResponse, bool) {
+	if cacher, ok := p.source.(ResponseCacher); ok {
+		return cacher.CachedResponse()
 	}
+	return CachedResponse{}, false
+}
 
-	req.Header.Set("User-Agent", UserAgent)
-	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+// SetHeartbeat makes the monitoring loop call beat every interval, between polls, so a
+// watchdog notices a loop stuck in a poll or callback. Must be called before StartMonitoring.
+func (p *QueueParser) SetHeartbeat(interval time.Duration, beat func()) {
+	p.heartbeat = beat
+	p.heartbeatInterval = interval
+}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch API: %w", err)
-	}
-	defer resp.Body.Close()
+// StartMonitoring starts continuous monitoring of queue data
+func (p *QueueParser) StartMonitoring(ctx context.Context, interval time.Duration, callback func(*models.QueueData, error)) {
+	p.setCurrentInterval(interval)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	// Parse immediately on start, then wait the effective interval after each poll
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
-	var apiResponse APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	var heartbeat <-chan time.Time
+	if p.heartbeat != nil {
+		ticker := time.NewTicker(p.heartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
 	}
 
-	queueData, err := p.extractQueueDataFromAPI(&apiResponse)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract queue data: %w", err)
-	}
+	log.Printf("Starting queue monitoring with %v interval", interval)
 
-	queueData.LastUpdated = time.Now()
-	return queueData, nil
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Queue monitoring stopped")
+			return
+		case newInterval := <-p.intervalCh:
+			p.setCurrentInterval(newInterval)
+			timer.Reset(p.EffectiveInterval())
+			log.Printf("Monitoring interval changed to %v", newInterval)
+		case <-timer.C:
+			p.poll(ctx, callback)
+			timer.Reset(p.EffectiveInterval())
+		case <-heartbeat:
+			p.heartbeat()
+		}
+	}
 }
 
-// extractQueueDataFromAPI extracts queue data from the API response
-func (p *QueueParser) extractQueueDataFromAPI(apiResponse *APIResponse) (*models.QueueData, error) {
-	// Look for Wrocław queues
-	wroclawQueues, exists := apiResponse.Result["Wrocław"]
-	if !exists {
-		return nil, fmt.Errorf("Wrocław section not found in API response")
+// poll fetches queue data once, records the attempt for health checks and passes the result on
+func (p *QueueParser) poll(ctx context.Context, callback func(*models.QueueData, error)) {
+	p.mu.Lock()
+	p.lastAttempt = time.Now()
+	p.mu.Unlock()
+
+	data, err := p.ParseQueueData(ctx)
+
+	p.mu.Lock()
+	if err == nil {
+		p.lastSuccess = time.Now()
+		p.recordQueueState(data, p.lastSuccess)
 	}
+	// A missing queue is a valid response of a reachable source, not a failure
+	opened, recovered := p.breaker.record(err == nil || errors.Is(err, ErrQueueMissing))
+	interval := p.effectiveInterval(time.Now())
+	p.mu.Unlock()
 
-	// Find the "odbiór karty" queue
-	for _, queue := range wroclawQueues {
-		if queue.Name == "odbiór karty" {
-			log.Printf("Found 'odbiór karty' queue: %+v", queue)
-
-			// Convert time from seconds to human-readable format
-			avgServiceTime := formatTime(queue.AverageServiceTime)
-			avgWaitTime := formatTime(queue.AverageWaitTime)
-
-			// Determine status
-			status := "Dostępna"
-			if !queue.Enabled || !queue.Active {
-				status = "Zamknięta"
-			}
-
-			queueData := &models.QueueData{
-				Name:           queue.Name,
-				ServedClients:  strconv.Itoa(queue.TicketsServed),
-				WaitingClients: strconv.Itoa(queue.TicketCount),
-				Workplaces:     strconv.Itoa(queue.Workplaces),
-				AvgServiceTime: avgServiceTime,
-				AvgWaitTime:    avgWaitTime,
-				LastTicket:     queue.TicketValue,
-				TicketsLeft:    strconv.Itoa(queue.TicketsLeft),
-				Status:         status,
-			}
-
-			log.Printf("Extracted queue data: %+v", queueData)
-			return queueData, nil
-		}
+	if opened {
+		log.Printf("DUW API keeps failing, polling every %v until it recovers", interval)
+	} else if recovered {
+		log.Printf("DUW API recovered, polling every %v again", interval)
 	}
 
-	return nil, fmt.Errorf("queue 'odbiór karty' not found in Wrocław section")
+	callback(data, err)
 }
 
-// formatTime converts seconds to human-readable format
-func formatTime(seconds int) string {
-	if seconds <= 0 {
-		return "N/A"
-	}
+// EffectiveInterval returns the delay before the next poll: the configured interval
+// adapted by the polling policy and widened by the circuit breaker during an outage
+func (p *QueueParser) EffectiveInterval() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.effectiveInterval(time.Now())
+}
 
-	if seconds < 60 {
-		return fmt.Sprintf("%d s.", seconds)
-	}
+// effectiveInterval computes the next polling delay. Must be called with p.mu held.
+func (p *QueueParser) effectiveInterval(now time.Time) time.Duration {
+	return p.breaker.interval(p.policy.interval(p.interval, p.lastData, p.lastChange, now))
+}
 
-	minutes := seconds / 60
-	return fmt.Sprintf("%d min.", minutes)
+// LastAttempt returns the start time of the last poll (zero before the first one)
+func (p *QueueParser) LastAttempt() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.lastAttempt
 }
 
-// StartMonitoring starts continuous monitoring of queue data
-func (p *QueueParser) StartMonitoring(ctx context.Context, interval time.Duration, callback func(*models.QueueData, error)) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// LastSuccess returns the time of the last successful poll (zero if none succeeded yet)
+func (p *QueueParser) LastSuccess() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.lastSuccess
+}
 
-	log.Printf("Starting queue monitoring with %v interval", interval)
+// SetInterval changes the polling interval of a running monitoring loop
+func (p *QueueParser) SetInterval(interval time.Duration) {
+	// Drop a pending change that was not applied yet, the latest one wins
+	select {
+	case <-p.intervalCh:
+	default:
+	}
+	p.intervalCh <- interval
+}
 
-	// Parse immediately on start
-	go func() {
-		data, err := p.ParseQueueData(ctx)
-		callback(data, err)
-	}()
+// Interval returns the configured polling interval
+func (p *QueueParser) Interval() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.interval
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Queue monitoring stopped")
-			return
-		case <-ticker.C:
-			data, err := p.ParseQueueData(ctx)
-			callback(data, err)
-		}
-	}
+// setCurrentInterval records the polling interval in effect
+func (p *QueueParser) setCurrentInterval(interval time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.interval = interval
 }
 
-// ValidateQueueData performs basic validation on parsed queue data
-func ValidateQueueData(data *models.QueueData) error {
+// ValidateQueueData performs basic validation on parsed data of the given queue
+func ValidateQueueData(data *models.QueueData, queue string) error {
 	if data == nil {
 		return fmt.Errorf("queue data is nil")
 	}
@@ -236,7 +205,7 @@ func ValidateQueueData(data *models.QueueData) error {
 		return fmt.Errorf("queue name is empty")
 	}
 
-	if !strings.Contains(strings.ToLower(data.Name), "odbiór karty") {
+	if !MatchesQueue(data.Name, queue) {
 		return fmt.Errorf("invalid queue name: %s", data.Name)
 	}
 
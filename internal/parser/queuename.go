@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrQueueMissing is returned by the sources when the response lacks the monitored queue
+// or the section of its office
+var ErrQueueMissing = errors.New("queue missing")
+
+// QueueAliases maps a normalized queue name to other names the queue is published under,
+// e.g. after DUW renamed it; set from QUEUE_ALIASES before the sources are created
+var QueueAliases map[string][]string
+
+// polishLetters folds the Polish diacritics of lowercased names
+var polishLetters = strings.NewReplacer("ą", "a", "ć", "c", "ę", "e", "ł", "l", "ń", "n", "ó", "o", "ś", "s", "ź", "z", "ż", "z")
+
+// NormalizeQueueName lowercases a queue name, folds Polish diacritics and collapses
+// whitespace, so "Odbiór  Karty" and "odbior karty" compare equal
+func NormalizeQueueName(name string) string {
+	return strings.Join(strings.Fields(polishLetters.Replace(strings.ToLower(name))), " ")
+}
+
+// MatchesQueue reports whether a published queue name is the given queue or one of its
+// aliases, after normalization
+func MatchesQueue(name, queue string) bool {
+	name = NormalizeQueueName(name)
+	if name == NormalizeQueueName(queue) {
+		return true
+	}
+	for _, alias := range QueueAliases[NormalizeQueueName(queue)] {
+		if name == NormalizeQueueName(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseQueueAliases parses a comma-separated list of queue=alias|alias entries, e.g.
+// "odbiór karty=wydawanie kart|odbiór kart pobytu", into QueueAliases form
+func ParseQueueAliases(value string) (map[string][]string, error) {
+	aliases := make(map[string][]string)
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		queue, names, ok := strings.Cut(entry, "=")
+		queue = NormalizeQueueName(queue)
+		if !ok || queue == "" {
+			return nil, fmt.Errorf("invalid queue alias %q: expected queue=alias", strings.TrimSpace(entry))
+		}
+		for _, name := range strings.Split(names, "|") {
+			if name = strings.TrimSpace(name); name != "" {
+				aliases[queue] = append(aliases[queue], name)
+			}
+		}
+		if len(aliases[queue]) == 0 {
+			return nil, fmt.Errorf("queue alias of %q has no names", queue)
+		}
+	}
+	return aliases, nil
+}
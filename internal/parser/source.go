@@ -0,0 +1,15 @@
+package parser
+
+import (
+	"context"
+
+	"karta/internal/models"
+)
+
+// QueueSource provides the current state of the tracked queue. DUWSource reads the DUW
+// JSON API and MUWSource the Masovian one; other sources (HTML fallback, fixtures, other
+// offices) plug in the same way.
+type QueueSource interface {
+	// Fetch makes a single attempt to read the queue; retries and backoff are up to the caller
+	Fetch(ctx context.Context) (*models.QueueData, error)
+}
@@ -0,0 +1,229 @@
+package testserver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"karta/internal/parser"
+)
+
+const (
+	Section   = "Wrocław"
+	QueueName = "odbiór karty"
+)
+
+// Response is a scripted reply of the mock DUW API
+type Response struct {
+	Status      int    // HTTP status, 200 when zero
+	Body        string // Raw response body
+	ContentType string // application/json for 200, text/html otherwise when empty
+	Delay       time.Duration
+}
+
+// Server is a mock of the DUW status API replying with a script of responses in order
+// and repeating the last one once the script is exhausted
+type Server struct {
+	mu       sync.Mutex
+	script   []Response
+	next     int
+	requests int
+}
+
+// New creates a mock server replying with the given responses
+func New(script ...Response) *Server {
+	return &Server{script: script}
+}
+
+// Start serves the mock on a random local port; point DUW_STATUS_URL at its URL
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// Push appends responses to the script
+func (s *Server) Push(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = append(s.script, responses...)
+}
+
+// Requests returns the number of requests served so far
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// ServeHTTP replies with the next scripted response
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	response := Error(http.StatusServiceUnavailable)
+	if len(s.script) > 0 {
+		response = s.script[s.next]
+		if s.next < len(s.script)-1 {
+			s.next++
+		}
+	}
+	s.mu.Unlock()
+
+	if response.Delay > 0 {
+		select {
+		case <-time.After(response.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	contentType := response.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+		if status != http.StatusOK {
+			contentType = "text/html"
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	// Like the DUW web server, good responses carry an ETag and unchanged ones get a 304
+	if status == http.StatusOK {
+		sum := sha256.Sum256([]byte(response.Body))
+		etag := fmt.Sprintf(`"%x"`, sum[:8])
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(response.Body)); err != nil {
+		log.Printf("Mock DUW server failed to write response: %v", err)
+	}
+}
+
+// Queue returns an open "odbiór karty" queue item
+func Queue(served, waiting, workplaces, ticketsLeft int, lastTicket string) parser.QueueItem {
+	return parser.QueueItem{
+		ID:                 1,
+		Name:               QueueName,
+		TicketCount:        waiting,
+		TicketsServed:      served,
+		Workplaces:         workplaces,
+		AverageWaitTime:    20 * 60,
+		AverageServiceTime: 6 * 60,
+		TicketValue:        lastTicket,
+		TicketsLeft:        ticketsLeft,
+		Enabled:            true,
+		Active:             true,
+		Location:           Section,
+	}
+}
+
+// Closed returns a copy of the queue item marked as inactive
+func Closed(item parser.QueueItem) parser.QueueItem {
+	item.Active = false
+	return item
+}
+
+// JSON returns a valid response with the items in the Wrocław section
+func JSON(items ...parser.QueueItem) Response {
+	return Sections(map[string][]parser.QueueItem{Section: items})
+}
+
+// Sections returns a valid response with the given sections
+func Sections(sections map[string][]parser.QueueItem) Response {
+	body, err := json.Marshal(parser.APIResponse{Result: sections})
+	if err != nil {
+		panic(err) // Plain structs always marshal
+	}
+	return Response{Body: string(body)}
+}
+
+// HTML returns a status page with the items in a Wrocław section table, in the markup
+// expected by parser.HTMLSource
+func HTML(items ...parser.QueueItem) Response {
+	var rows strings.Builder
+	for _, item := range items {
+		class := ""
+		if !item.Enabled || !item.Active {
+			class = ` class="inactive"`
+		}
+		fmt.Fprintf(&rows, "<tr%s><td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d min.</td><td>%d min.</td><td>%d</td></tr>\n",
+			class, html.EscapeString(item.Name), html.EscapeString(item.TicketValue), item.TicketCount, item.TicketsServed,
+			item.Workplaces, item.AverageWaitTime/60, item.AverageServiceTime/60, item.TicketsLeft)
+	}
+
+	body := "<html><body>\n<h2>" + Section + "</h2>\n<table>\n" +
+		"<tr><th>Kolejka</th><th>Aktualny numer</th><th>Liczba osób w kolejce</th><th>Obsłużono</th>" +
+		"<th>Czynne stanowiska</th><th>Średni czas oczekiwania</th><th>Średni czas obsługi</th><th>Pozostało biletów</th></tr>\n" +
+		rows.String() + "</table>\n</body></html>"
+	return Response{Body: body, ContentType: "text/html; charset=utf-8"}
+}
+
+// MissingSection returns a valid response without the Wrocław section
+func MissingSection() Response {
+	return Sections(map[string][]parser.QueueItem{"Opole": {Queue(10, 5, 2, 30, "K010")}})
+}
+
+// MissingQueue returns a Wrocław section without the "odbiór karty" queue
+func MissingQueue() Response {
+	other := Queue(10, 5, 2, 30, "A010")
+	other.Name = "składanie wniosków"
+	return JSON(other)
+}
+
+// Malformed returns a truncated JSON body
+func Malformed() Response {
+	return Response{Body: `{"result": {"Wrocław": [{"id": 1, "name": "odbi`}
+}
+
+// Error returns an error status with a short HTML body, like the DUW web server
+func Error(status int) Response {
+	return Response{Status: status, Body: "<html><body>" + http.StatusText(status) + "</body></html>"}
+}
+
+// Day returns a script of an office day: the queue opens, serves clients at the given
+// workplaces until the tickets run out, and closes. Each response is one poll.
+func Day(tickets, workplaces int) []Response {
+	var script []Response
+	served, waiting := 0, 0
+
+	for served < tickets {
+		// Clients arrive faster than they are served in the morning
+		if issued := served + waiting; issued < tickets {
+			waiting += min(5, tickets-issued)
+		}
+		if waiting > 0 {
+			step := min(workplaces, waiting)
+			served += step
+			waiting -= step
+		}
+
+		left := tickets - served - waiting
+		lastTicket := ""
+		if served > 0 {
+			lastTicket = ticketName(served)
+		}
+		script = append(script, JSON(Queue(served, waiting, workplaces, left, lastTicket)))
+	}
+
+	script = append(script, JSON(Closed(Queue(served, 0, 0, 0, ticketName(served)))))
+	return script
+}
+
+// ticketName formats a ticket number like the DUW ticket machine, e.g. K007
+func ticketName(number int) string {
+	return fmt.Sprintf("K%03d", number)
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CertExpiryWarning is how long before the DUW certificate expires a warning is logged (once a day)
+const CertExpiryWarning = 14 * 24 * time.Hour
+
+// InsecureTLS disables certificate verification of DUW requests; set by the --insecure flag
+var InsecureTLS bool
+
+// newTLSConfig builds the TLS configuration of DUW requests from the environment:
+//   - DUW_CA_BUNDLE adds the CAs of a PEM file to the system pool
+//   - DUW_CERT_PINS additionally requires a verified chain with a certificate whose public
+//     key SHA-256 (base64) is listed; pins never replace CA and host name verification, so
+//     chains the system pool cannot verify need their CA in DUW_CA_BUNDLE
+//
+// Certificates close to expiry are logged either way.
+func newTLSConfig() *tls.Config {
+	config := &tls.Config{}
+
+	if InsecureTLS {
+		log.Println("WARNING: TLS certificate verification of DUW requests is disabled (--insecure)")
+		config.InsecureSkipVerify = true
+		return config
+	}
+
+	if bundle := os.Getenv("DUW_CA_BUNDLE"); bundle != "" {
+		pool, err := loadCABundle(bundle)
+		if err != nil {
+			log.Printf("Failed to load DUW_CA_BUNDLE, using the system CAs only: %v", err)
+		} else {
+			log.Printf("Using CA bundle %s for DUW requests", bundle)
+			config.RootCAs = pool
+		}
+	}
+
+	var pins []string
+	for _, pin := range strings.Split(os.Getenv("DUW_CERT_PINS"), ",") {
+		if pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/"); pin != "" {
+			pins = append(pins, pin)
+		}
+	}
+	if len(pins) > 0 {
+		log.Printf("Pinning DUW certificates to %d public keys", len(pins))
+	}
+
+	var mu sync.Mutex
+	var lastWarning time.Time
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("server sent no certificate")
+		}
+
+		server := state.ServerName
+		if server == "" {
+			server = "the DUW server" // Connections to IP addresses send no server name
+		}
+
+		// Only chains verified against the CAs count: a certificate the server merely sent
+		// along proves nothing, as anyone can append a public pinned certificate
+		if len(pins) > 0 && !matchesPin(state.VerifiedChains, pins) {
+			return fmt.Errorf("certificate of %s matches none of DUW_CERT_PINS, its public key pin is %s",
+				server, publicKeyPin(state.PeerCertificates[0]))
+		}
+
+		leaf := state.PeerCertificates[0]
+		if until := time.Until(leaf.NotAfter); until < CertExpiryWarning {
+			mu.Lock()
+			if time.Since(lastWarning) >= 24*time.Hour {
+				lastWarning = time.Now()
+				log.Printf("WARNING: certificate of %s expires on %s (in %v)", server,
+					leaf.NotAfter.Format(time.RFC3339), until.Round(time.Hour))
+			}
+			mu.Unlock()
+		}
+		return nil
+	}
+
+	return config
+}
+
+// loadCABundle returns the system CA pool extended with the certificates of a PEM file
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// publicKeyPin returns the base64 SHA-256 of a certificate's public key, as used in DUW_CERT_PINS
+func publicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// matchesPin reports whether any certificate of the verified chains has a pinned public key
+func matchesPin(chains [][]*x509.Certificate, pins []string) bool {
+	for _, chain := range chains {
+		for _, cert := range chain {
+			pin := publicKeyPin(cert)
+			for _, expected := range pins {
+				if pin == expected {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// tlsDiagnostics logs why certificate verification of a request failed, so operators can
+// tell an expired certificate from an unknown CA or a wrong host name
+type tlsDiagnostics struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t tlsDiagnostics) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		logCertificateError(req.URL.Host, err)
+	}
+	return resp, err
+}
+
+// logCertificateError describes certificate verification errors with the offending certificate
+func logCertificateError(host string, err error) {
+	var invalid x509.CertificateInvalidError
+	var unknown x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+
+	switch {
+	case errors.As(err, &invalid) && invalid.Cert != nil:
+		log.Printf("TLS verification of %s failed: %v (subject %q, valid %s - %s)", host, err, invalid.Cert.Subject,
+			invalid.Cert.NotBefore.Format(time.RFC3339), invalid.Cert.NotAfter.Format(time.RFC3339))
+	case errors.As(err, &unknown) && unknown.Cert != nil:
+		log.Printf("TLS verification of %s failed: %v (issuer %q); add the CA with DUW_CA_BUNDLE or pin the key %s with DUW_CERT_PINS",
+			host, err, unknown.Cert.Issuer, publicKeyPin(unknown.Cert))
+	case errors.As(err, &hostname) && hostname.Certificate != nil:
+		log.Printf("TLS verification of %s failed: %v (certificate names %v)", host, err, hostname.Certificate.DNSNames)
+	}
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert is a certificate with its key, signed by its parent or by itself
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a CA when parent is nil, a leaf for 127.0.0.1 otherwise
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key}
+}
+
+// serveTLS starts a server presenting the leaf followed by the other certificates
+func serveTLS(t *testing.T, leaf *testCert, chain ...*testCert) string {
+	t.Helper()
+
+	certificate := tls.Certificate{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}
+	for _, c := range chain {
+		certificate.Certificate = append(certificate.Certificate, c.cert.Raw)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// trustCAs points DUW_CA_BUNDLE at a PEM file with the CAs
+func trustCAs(t *testing.T, cas ...*testCert) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	var bundle []byte
+	for _, ca := range cas {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})...)
+	}
+	if err := os.WriteFile(path, bundle, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DUW_CA_BUNDLE", path)
+}
+
+func TestTLSCertificatePins(t *testing.T) {
+	duwCA := newTestCert(t, "DUW CA", nil)
+	duwLeaf := newTestCert(t, "rezerwacje.duw.pl", duwCA)
+	otherCA := newTestCert(t, "Other CA", nil)
+	otherLeaf := newTestCert(t, "attacker", otherCA)
+	selfSigned := newTestCert(t, "Attacker CA", nil)
+	attackerLeaf := newTestCert(t, "attacker", selfSigned)
+
+	tests := []struct {
+		name    string
+		trusted []*testCert // CAs of DUW_CA_BUNDLE
+		pins    string
+		leaf    *testCert
+		chain   []*testCert
+		wantOK  bool
+	}{
+		{name: "pinned CA", trusted: []*testCert{duwCA}, pins: publicKeyPin(duwCA.cert), leaf: duwLeaf, wantOK: true},
+		{name: "pinned leaf", trusted: []*testCert{duwCA}, pins: "sha256/" + publicKeyPin(duwLeaf.cert), leaf: duwLeaf, wantOK: true},
+		{name: "no pins", trusted: []*testCert{duwCA}, leaf: duwLeaf, wantOK: true},
+		{name: "verified chain without a pin", trusted: []*testCert{duwCA}, pins: publicKeyPin(otherCA.cert), leaf: duwLeaf},
+		{name: "pin without a trusted CA", pins: publicKeyPin(duwCA.cert), leaf: duwLeaf, chain: []*testCert{duwCA}},
+		{
+			name: "pinned certificate appended to an untrusted chain", trusted: []*testCert{duwCA}, pins: publicKeyPin(duwCA.cert),
+			leaf: attackerLeaf, chain: []*testCert{selfSigned, duwCA},
+		},
+		{
+			name: "pinned certificate appended to a chain of another trusted CA", trusted: []*testCert{duwCA, otherCA}, pins: publicKeyPin(duwCA.cert),
+			leaf: otherLeaf, chain: []*testCert{otherCA, duwCA},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DUW_CA_BUNDLE", "")
+			if len(tt.trusted) > 0 {
+				trustCAs(t, tt.trusted...)
+			}
+			t.Setenv("DUW_CERT_PINS", tt.pins)
+			url := serveTLS(t, tt.leaf, tt.chain...)
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: newTLSConfig()}}
+			req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if ok := err == nil; ok != tt.wantOK {
+				t.Errorf("request error %v, want a connection: %v", err, tt.wantOK)
+			}
+		})
+	}
+}
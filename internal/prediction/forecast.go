@@ -0,0 +1,91 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ForecastLookback is how far back history is used to forecast ticket exhaustion
+const ForecastLookback = 28 * 24 * time.Hour
+
+// ExhaustionSource provides the moments tickets ran out on previous days
+type ExhaustionSource interface {
+	GetTicketsExhaustedTimes(ctx context.Context, since time.Time) ([]time.Time, error)
+}
+
+// ExhaustionForecaster estimates what time of day tickets usually run out
+type ExhaustionForecaster struct {
+	source ExhaustionSource
+
+	mu       sync.Mutex
+	day      time.Time // Local day the cached forecast was computed for
+	forecast time.Time
+	ok       bool
+}
+
+// NewExhaustionForecaster creates a new ticket exhaustion forecaster
+func NewExhaustionForecaster(source ExhaustionSource) *ExhaustionForecaster {
+	return &ExhaustionForecaster{source: source}
+}
+
+// Forecast returns today's expected ticket exhaustion time, computed once per day.
+// The result is false when there is no history to base the forecast on.
+func (f *ExhaustionForecaster) Forecast(ctx context.Context, now time.Time) (time.Time, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if f.day.Equal(today) {
+		return f.forecast, f.ok, nil
+	}
+
+	times, err := f.source.GetTicketsExhaustedTimes(ctx, now.Add(-ForecastLookback))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to load exhaustion history: %w", err)
+	}
+
+	offset, ok := ForecastExhaustion(times, now.Weekday(), now.Location())
+	f.day = today
+	f.ok = ok
+	f.forecast = today.Add(offset)
+
+	if ok {
+		log.Printf("Tickets are forecast to run out by %s", f.forecast.Format("15:04"))
+	}
+	return f.forecast, f.ok, nil
+}
+
+// ForecastExhaustion averages the time of day tickets ran out on the same weekday,
+// falling back to all weekdays when there is no history for it
+func ForecastExhaustion(times []time.Time, weekday time.Weekday, loc *time.Location) (time.Duration, bool) {
+	byWeekday := make(map[time.Weekday][]time.Duration)
+	var all []time.Duration
+
+	for _, t := range times {
+		local := t.In(loc)
+		midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		offset := local.Sub(midnight)
+
+		byWeekday[local.Weekday()] = append(byWeekday[local.Weekday()], offset)
+		all = append(all, offset)
+	}
+
+	offsets := byWeekday[weekday]
+	if len(offsets) == 0 {
+		offsets = all
+	}
+	if len(offsets) == 0 {
+		return 0, false
+	}
+
+	var sum time.Duration
+	for _, offset := range offsets {
+		sum += offset
+	}
+
+	// Round to 5 minutes, the forecast is only a rough guide
+	return (sum / time.Duration(len(offsets))).Round(5 * time.Minute), true
+}
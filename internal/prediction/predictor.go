@@ -0,0 +1,187 @@
+package prediction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"karta/internal/models"
+	"karta/internal/stats"
+)
+
+const (
+	// ChunkDuration is the length of the intervals the service rate is measured over
+	ChunkDuration = 10 * time.Minute
+	// MinChunks is the minimum number of measured intervals required for a prediction
+	MinChunks = 3
+	// RefreshInterval is how often the cached service rate is recomputed
+	RefreshInterval = 5 * time.Minute
+)
+
+// ErrInsufficientHistory is returned when there is not enough history to estimate the rate
+var ErrInsufficientHistory = errors.New("insufficient history for prediction")
+
+// HistorySource provides queue history for prediction
+type HistorySource interface {
+	GetQueueDataSince(ctx context.Context, since time.Time) ([]*models.QueueData, error)
+}
+
+// ServiceRate represents the smoothed service rate in tickets per minute per workplace
+type ServiceRate struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Chunks int     `json:"chunks"`
+}
+
+// Predictor estimates personal wait times from recent queue history
+type Predictor struct {
+	source HistorySource
+	window time.Duration
+
+	mu          sync.Mutex
+	rate        *ServiceRate
+	refreshedAt time.Time
+
+	velocity    models.QueueVelocity
+	hasVelocity bool
+	velocityAt  time.Time // Time the cached velocity was measured at
+}
+
+// NewPredictor creates a new predictor using the last window of history
+func NewPredictor(source HistorySource, window time.Duration) *Predictor {
+	return &Predictor{
+		source: source,
+		window: window,
+	}
+}
+
+// Rate returns the cached service rate, recomputing it when it is older than RefreshInterval
+func (p *Predictor) Rate(ctx context.Context) (*ServiceRate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.refreshedAt.IsZero() && time.Since(p.refreshedAt) < RefreshInterval {
+		if p.rate == nil {
+			return nil, ErrInsufficientHistory
+		}
+		return p.rate, nil
+	}
+
+	history, err := p.source.GetQueueDataSince(ctx, time.Now().Add(-p.window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	p.refreshedAt = time.Now()
+	p.rate, err = EstimateServiceRate(history)
+	if err != nil {
+		p.rate = nil
+		return nil, err
+	}
+
+	log.Printf("Service rate updated: %.3f ± %.3f tickets/min/workplace (%d intervals)", p.rate.Mean, p.rate.StdDev, p.rate.Chunks)
+	return p.rate, nil
+}
+
+// EstimateWait estimates the wait time for a user's ticket with confidence bounds
+func (p *Predictor) EstimateWait(ctx context.Context, queueData *models.QueueData, userTicket string) (*models.WaitEstimate, error) {
+	ticketsAhead, err := queueData.TicketsAhead(userTicket)
+	if err != nil {
+		return nil, err
+	}
+	if ticketsAhead <= 0 {
+		return &models.WaitEstimate{}, nil
+	}
+
+	workplaces := queueData.Workplaces
+	if workplaces <= 0 {
+		workplaces = 1
+	}
+
+	rate, err := p.Rate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return rate.Estimate(ticketsAhead, workplaces), nil
+}
+
+// Estimate computes the wait for the given number of tickets ahead and open workplaces.
+// Bounds use the rate one standard deviation above and below the mean.
+func (r *ServiceRate) Estimate(ticketsAhead, workplaces int) *models.WaitEstimate {
+	waitFor := func(rate float64) time.Duration {
+		minutes := float64(ticketsAhead) / (rate * float64(workplaces))
+		return time.Duration(minutes * float64(time.Minute))
+	}
+
+	// Keep the slow bound meaningful when the spread exceeds the mean
+	slowRate := math.Max(r.Mean-r.StdDev, r.Mean/4)
+	fastRate := r.Mean + r.StdDev
+
+	return &models.WaitEstimate{
+		Expected: waitFor(r.Mean),
+		Low:      waitFor(fastRate),
+		High:     waitFor(slowRate),
+	}
+}
+
+// EstimateServiceRate computes the smoothed service rate from consecutive history samples.
+// Samples are split into ChunkDuration intervals of continuous polling while the queue is open,
+// and the rate of each interval is tickets served per minute per workplace.
+func EstimateServiceRate(history []*models.QueueData) (*ServiceRate, error) {
+	var rates []float64
+	var chunkServed, chunkWorkplaceMinutes float64
+	var chunkElapsed time.Duration
+
+	for i := 1; i < len(history); i++ {
+		previous, current := history[i-1], history[i]
+
+		gap := current.LastUpdated.Sub(previous.LastUpdated)
+		prevServed, curServed := previous.ServedClients, current.ServedClients
+		workplaces := previous.Workplaces
+
+		if !stats.Continuous(previous, current) || workplaces <= 0 {
+			// Discard the partial interval on any discontinuity
+			chunkServed, chunkWorkplaceMinutes, chunkElapsed = 0, 0, 0
+			continue
+		}
+
+		chunkServed += float64(curServed - prevServed)
+		chunkWorkplaceMinutes += gap.Minutes() * float64(workplaces)
+		chunkElapsed += gap
+
+		if chunkElapsed >= ChunkDuration {
+			rates = append(rates, chunkServed/chunkWorkplaceMinutes)
+			chunkServed, chunkWorkplaceMinutes, chunkElapsed = 0, 0, 0
+		}
+	}
+
+	if len(rates) < MinChunks {
+		return nil, ErrInsufficientHistory
+	}
+
+	var sum float64
+	for _, rate := range rates {
+		sum += rate
+	}
+	mean := sum / float64(len(rates))
+	if mean <= 0 {
+		return nil, ErrInsufficientHistory
+	}
+
+	var variance float64
+	for _, rate := range rates {
+		variance += (rate - mean) * (rate - mean)
+	}
+	variance /= float64(len(rates))
+
+	return &ServiceRate{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance),
+		Chunks: len(rates),
+	}, nil
+}
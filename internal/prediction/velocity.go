@@ -0,0 +1,88 @@
+package prediction
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"karta/internal/models"
+	"karta/internal/stats"
+)
+
+const (
+	// VelocityWindow is the recent history the queue velocity is measured over; the trend
+	// compares it with the window before
+	VelocityWindow = 30 * time.Minute
+	// MinVelocitySpan is the shortest continuous open-queue history a velocity is shown for
+	MinVelocitySpan = 15 * time.Minute
+	// VelocityTrendThreshold is the relative change of the velocity shown as a trend
+	VelocityTrendThreshold = 0.1
+	// VelocityRefreshInterval is how often the cached velocity is recomputed
+	VelocityRefreshInterval = time.Minute
+)
+
+// Velocity returns the cached queue velocity, recomputing it when it is older than
+// VelocityRefreshInterval. The result is false without enough recent history.
+func (p *Predictor) Velocity(ctx context.Context, now time.Time) (models.QueueVelocity, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.velocityAt.IsZero() && now.Sub(p.velocityAt) < VelocityRefreshInterval {
+		return p.velocity, p.hasVelocity, nil
+	}
+
+	history, err := p.source.GetQueueDataSince(ctx, now.Add(-2*VelocityWindow))
+	if err != nil {
+		return models.QueueVelocity{}, false, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	p.velocityAt = now
+	p.velocity, p.hasVelocity = MeasureVelocity(history, now)
+	return p.velocity, p.hasVelocity, nil
+}
+
+// MeasureVelocity computes tickets served per models.VelocityPeriod over the last
+// VelocityWindow and its trend against the window before, from history sorted oldest first
+func MeasureVelocity(history []*models.QueueData, now time.Time) (models.QueueVelocity, bool) {
+	current, ok := windowVelocity(history, now.Add(-VelocityWindow), now)
+	if !ok {
+		return models.QueueVelocity{}, false
+	}
+
+	velocity := models.QueueVelocity{PerPeriod: current}
+	if previous, ok := windowVelocity(history, now.Add(-2*VelocityWindow), now.Add(-VelocityWindow)); ok && previous > 0 {
+		switch change := (current - previous) / previous; {
+		case change >= VelocityTrendThreshold:
+			velocity.Trend = models.TrendUp
+		case change <= -VelocityTrendThreshold:
+			velocity.Trend = models.TrendDown
+		}
+	} else {
+		velocity.Trend = models.TrendUnknown
+	}
+	return velocity, true
+}
+
+// windowVelocity returns the tickets served per models.VelocityPeriod in [from, to), over
+// the continuous open-queue samples
+func windowVelocity(history []*models.QueueData, from, to time.Time) (float64, bool) {
+	var served int
+	var span time.Duration
+
+	var previous *models.QueueData
+	for _, current := range history {
+		if current.LastUpdated.Before(from) || !current.LastUpdated.Before(to) {
+			continue
+		}
+		if previous != nil && stats.Continuous(previous, current) {
+			served += current.ServedClients - previous.ServedClients
+			span += current.LastUpdated.Sub(previous.LastUpdated)
+		}
+		previous = current
+	}
+
+	if span < MinVelocitySpan {
+		return 0, false
+	}
+	return float64(served) / float64(span) * float64(models.VelocityPeriod), true
+}
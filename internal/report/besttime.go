@@ -0,0 +1,120 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/stats"
+)
+
+// ProfileLookback is how much hourly history the service profile is built from
+const ProfileLookback = 14 * 24 * time.Hour
+
+// HourlySource provides hourly aggregated history; implemented by *database.Database
+type HourlySource interface {
+	GetHourlyHistory(ctx context.Context, since time.Time) ([]database.HistoryBucket, error)
+}
+
+// Slot is one hour of the week in the service profile
+type Slot struct {
+	Weekday    time.Weekday
+	Hour       int     // Local start hour, 0-23
+	Days       int     // Days with history in this hour
+	AvgWaiting float64 // Mean waiting clients
+	Throughput float64 // Mean tickets served in the hour
+}
+
+// Wait returns the expected wait of a client arriving in the slot: the clients already
+// waiting, served at the slot's throughput
+func (s Slot) Wait() time.Duration {
+	if s.Throughput <= 0 {
+		return 0
+	}
+	return time.Duration(s.AvgWaiting / s.Throughput * float64(time.Hour))
+}
+
+// Profile is the hour-of-week profile of the service throughput, holding only the hours
+// tickets were served in
+type Profile []Slot
+
+// LoadProfile builds the profile from the hourly history of the last ProfileLookback
+func LoadProfile(ctx context.Context, source HourlySource, now time.Time) (Profile, error) {
+	buckets, err := source.GetHourlyHistory(ctx, now.Add(-ProfileLookback))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hourly history: %w", err)
+	}
+	return BuildProfile(buckets), nil
+}
+
+// BuildProfile averages the hourly buckets and the tickets served in them by weekday and hour
+func BuildProfile(buckets []database.HistoryBucket) Profile {
+	type key struct {
+		weekday time.Weekday
+		hour    int
+	}
+	slots := make(map[key]*Slot)
+
+	for _, bucket := range stats.HourlyThroughputOf(buckets) {
+		k := key{bucket.Period.Weekday(), bucket.Period.Hour()}
+		slot, ok := slots[k]
+		if !ok {
+			slot = &Slot{Weekday: k.weekday, Hour: k.hour}
+			slots[k] = slot
+		}
+		slot.Days++
+		slot.AvgWaiting += bucket.AvgWaiting
+		slot.Throughput += float64(bucket.Served)
+	}
+
+	var profile Profile
+	for _, slot := range slots {
+		slot.AvgWaiting /= float64(slot.Days)
+		slot.Throughput /= float64(slot.Days)
+		if slot.Throughput > 0 {
+			profile = append(profile, *slot)
+		}
+	}
+
+	sort.Slice(profile, func(i, j int) bool {
+		if profile[i].Weekday != profile[j].Weekday {
+			return weekdayIndex(profile[i].Weekday) < weekdayIndex(profile[j].Weekday)
+		}
+		return profile[i].Hour < profile[j].Hour
+	})
+	return profile
+}
+
+// Best returns up to n slots with the shortest expected wait, the higher throughput first on ties
+func (p Profile) Best(n int) []Slot {
+	best := append([]Slot(nil), p...)
+	sort.SliceStable(best, func(i, j int) bool {
+		if best[i].Wait() != best[j].Wait() {
+			return best[i].Wait() < best[j].Wait()
+		}
+		return best[i].Throughput > best[j].Throughput
+	})
+
+	if len(best) > n {
+		best = best[:n]
+	}
+	return best
+}
+
+// On returns the slots of a weekday starting at fromHour or later
+func (p Profile) On(weekday time.Weekday, fromHour int) Profile {
+	var slots Profile
+	for _, slot := range p {
+		if slot.Weekday == weekday && slot.Hour >= fromHour {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// weekdayIndex orders weekdays from Monday
+func weekdayIndex(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
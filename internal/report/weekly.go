@@ -0,0 +1,109 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"karta/internal/database"
+)
+
+// Source provides the aggregated history reports are computed from; implemented by *database.Database
+type Source interface {
+	GetDailyHistory(ctx context.Context, since time.Time) ([]database.HistoryBucket, error)
+	GetTicketsExhaustedTimes(ctx context.Context, since time.Time) ([]time.Time, error)
+}
+
+// Week summarizes the history of one Monday-to-Sunday week
+type Week struct {
+	Start         time.Time
+	Days          int                    // Days with history
+	Served        int                    // Tickets served over the week
+	Busiest       database.HistoryBucket // Day with the most tickets served
+	ServiceTime   time.Duration          // Mean of the daily average service times, 0 without reports
+	ExhaustedDays int                    // Days the tickets ran out
+	Exhaustion    time.Duration          // Mean time of day the tickets ran out
+}
+
+// End returns the start of the following week
+func (w Week) End() time.Time {
+	return w.Start.AddDate(0, 0, 7)
+}
+
+// Weekly is the report of a week compared with the week before
+type Weekly struct {
+	Week     Week
+	Previous Week
+}
+
+// WeekStart returns midnight of the Monday of the week t falls in
+func WeekStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()-weekdayIndex(t.Weekday()), 0, 0, 0, 0, t.Location())
+}
+
+// BuildWeekly computes the report of the last full week before now. The history must
+// reach two weeks back for the comparison; a previous week without data has Days 0.
+func BuildWeekly(ctx context.Context, source Source, now time.Time) (*Weekly, error) {
+	start := WeekStart(now).AddDate(0, 0, -7)
+	previousStart := start.AddDate(0, 0, -7)
+
+	buckets, err := source.GetDailyHistory(ctx, previousStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load daily history: %w", err)
+	}
+
+	exhausted, err := source.GetTicketsExhaustedTimes(ctx, previousStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tickets exhausted times: %w", err)
+	}
+
+	return &Weekly{
+		Week:     summarize(start, buckets, exhausted),
+		Previous: summarize(previousStart, buckets, exhausted),
+	}, nil
+}
+
+// summarize builds the summary of the week starting at start from daily buckets and
+// ticket exhaustion times, ignoring those outside the week
+func summarize(start time.Time, buckets []database.HistoryBucket, exhausted []time.Time) Week {
+	week := Week{Start: start}
+	end := week.End()
+
+	var serviceTotal time.Duration
+	var serviceDays int
+	for _, bucket := range buckets {
+		if bucket.Period.Before(start) || !bucket.Period.Before(end) {
+			continue
+		}
+
+		week.Days++
+		week.Served += bucket.MaxServed // The served counter restarts every day
+		if bucket.MaxServed > week.Busiest.MaxServed {
+			week.Busiest = bucket
+		}
+		if bucket.AvgServiceTime > 0 {
+			serviceTotal += bucket.AvgServiceTime
+			serviceDays++
+		}
+	}
+	if serviceDays > 0 {
+		week.ServiceTime = serviceTotal / time.Duration(serviceDays)
+	}
+
+	var exhaustionTotal time.Duration
+	for _, t := range exhausted {
+		local := t.In(start.Location())
+		if local.Before(start) || !local.Before(end) {
+			continue
+		}
+
+		midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+		exhaustionTotal += local.Sub(midnight)
+		week.ExhaustedDays++
+	}
+	if week.ExhaustedDays > 0 {
+		week.Exhaustion = (exhaustionTotal / time.Duration(week.ExhaustedDays)).Round(5 * time.Minute)
+	}
+
+	return week
+}
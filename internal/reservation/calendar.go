@@ -0,0 +1,160 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"karta/internal/parser"
+)
+
+const (
+	// DefaultCalendarURL is the calendar endpoint of rezerwacje.duw.pl listing the bookable
+	// days of a service; {service} is replaced by the service ID
+	DefaultCalendarURL = "https://rezerwacje.duw.pl/reservations/pol/queues/{service}/dates"
+
+	dateLayout = "2006-01-02"
+)
+
+// Service is a service bookable on rezerwacje.duw.pl whose calendar is monitored
+type Service struct {
+	Name string // Name used in /slots, e.g. "pobyt"
+	ID   string // ID of the service in the calendar URL
+}
+
+// ParseServices parses a comma-separated list of name=ID pairs, e.g. "pobyt=12,odbior=15"
+func ParseServices(value string) ([]Service, error) {
+	var services []Service
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		name, id, ok := strings.Cut(pair, "=")
+		name, id = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(id)
+		if !ok || name == "" || id == "" {
+			return nil, fmt.Errorf("invalid service %q: expected name=ID", pair)
+		}
+		if strings.ContainsAny(name, " ,") || seen[name] {
+			return nil, fmt.Errorf("invalid or duplicate service name %q", name)
+		}
+		seen[name] = true
+		services = append(services, Service{Name: name, ID: id})
+	}
+	return services, nil
+}
+
+// Day is a day with free appointment slots
+type Day struct {
+	Date time.Time // Midnight UTC of the day
+	Free int       // Number of free slots, 0 when the calendar only lists the day as bookable
+}
+
+// Client reads the reservation calendars
+type Client struct {
+	client      *http.Client
+	calendarURL string
+}
+
+// NewClient creates a client for the calendar URL, DefaultCalendarURL when empty. Requests go
+// through the same proxies and TLS settings as the queue status requests.
+func NewClient(calendarURL string) *Client {
+	if calendarURL == "" {
+		calendarURL = DefaultCalendarURL
+	}
+	return &Client{client: parser.NewHTTPClient(), calendarURL: calendarURL}
+}
+
+// FreeDays requests the calendar of a service once and returns its days with free slots
+func (c *Client) FreeDays(ctx context.Context, service Service) ([]Day, error) {
+	url := strings.ReplaceAll(c.calendarURL, "{service}", service.ID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", parser.UserAgent)
+	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar of %s: %w", service.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code of %s calendar: %d", service.Name, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, parser.MaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar of %s: %w", service.Name, err)
+	}
+
+	days, err := parseCalendar(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse calendar of %s: %w", service.Name, err)
+	}
+	return days, nil
+}
+
+// calendarDay is a day object of the calendar; the count of free slots goes by several names
+type calendarDay struct {
+	Date  string `json:"date"`
+	Free  *int   `json:"free"`
+	Slots *int   `json:"slots"`
+	Count *int   `json:"count"`
+}
+
+// parseCalendar parses a calendar response: a JSON list of bookable days, either as
+// "2006-01-02" strings or as objects with the date and the number of free slots. Days
+// without free slots are left out; the others are sorted by date.
+func parseCalendar(body []byte) ([]Day, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("expected a list of days: %w", err)
+	}
+
+	var days []Day
+	for _, entry := range entries {
+		var day calendarDay
+		if err := json.Unmarshal(entry, &day.Date); err != nil {
+			if err := json.Unmarshal(entry, &day); err != nil {
+				return nil, fmt.Errorf("invalid day %s", entry)
+			}
+		}
+
+		// Dates may come with a time of day, which is not needed
+		value := strings.TrimSpace(day.Date)
+		if len(value) > len(dateLayout) {
+			value = value[:len(dateLayout)]
+		}
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", day.Date)
+		}
+
+		free, counted := 0, false
+		for _, count := range []*int{day.Free, day.Slots, day.Count} {
+			if count != nil {
+				free, counted = *count, true
+				break
+			}
+		}
+		if counted && free <= 0 {
+			continue // Listed but fully booked
+		}
+
+		days = append(days, Day{Date: date, Free: free})
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, nil
+}
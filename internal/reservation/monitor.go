@@ -0,0 +1,117 @@
+package reservation
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultPollInterval = 5 * time.Minute
+
+// Availability is the latest calendar state of a service
+type Availability struct {
+	Days      []Day     // Days with free slots
+	CheckedAt time.Time // Last successful poll (zero before the first one)
+}
+
+// Monitor polls the calendars of the services and reports days with free slots that
+// were not free at the previous poll
+type Monitor struct {
+	client   *Client
+	services []Service
+
+	mu     sync.RWMutex
+	latest map[string]Availability // By service name
+}
+
+// NewMonitor creates a monitor of the services' calendars
+func NewMonitor(client *Client, services []Service) *Monitor {
+	return &Monitor{client: client, services: services, latest: make(map[string]Availability)}
+}
+
+// Services returns the monitored services in their configured order
+func (m *Monitor) Services() []Service {
+	return m.services
+}
+
+// FindService returns the monitored service with the given name, ignoring case
+func (m *Monitor) FindService(name string) (Service, bool) {
+	for _, service := range m.services {
+		if strings.EqualFold(service.Name, strings.TrimSpace(name)) {
+			return service, true
+		}
+	}
+	return Service{}, false
+}
+
+// Latest returns the latest calendar state of a service
+func (m *Monitor) Latest(name string) Availability {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latest[name]
+}
+
+// Run polls every interval until the context is cancelled, calling onNewSlots with the
+// days that got free slots since the previous poll. The first poll of each service only
+// records the state, so a restart does not repeat alerts.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration, onNewSlots func(context.Context, Service, []Day)) {
+	log.Printf("Starting appointment slot monitoring of %d services with %v interval", len(m.services), interval)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Appointment slot monitoring stopped")
+			return
+		case <-timer.C:
+			for _, service := range m.services {
+				m.poll(ctx, service, onNewSlots)
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
+// poll checks the calendar of a service once
+func (m *Monitor) poll(ctx context.Context, service Service, onNewSlots func(context.Context, Service, []Day)) {
+	days, err := m.client.FreeDays(ctx, service)
+	if err != nil {
+		log.Printf("Failed to check appointment slots: %v", err)
+		return
+	}
+
+	m.mu.Lock()
+	previous, seen := m.latest[service.Name]
+	m.latest[service.Name] = Availability{Days: days, CheckedAt: time.Now()}
+	m.mu.Unlock()
+
+	if !seen {
+		log.Printf("Appointment slots of %s: %d days with free slots", service.Name, len(days))
+		return
+	}
+
+	if added := newDays(previous.Days, days); len(added) > 0 {
+		log.Printf("New appointment slots of %s on %d days", service.Name, len(added))
+		onNewSlots(ctx, service, added)
+	}
+}
+
+// newDays returns the days of current that were not free in previous
+func newDays(previous, current []Day) []Day {
+	free := make(map[time.Time]bool, len(previous))
+	for _, day := range previous {
+		free[day.Date] = true
+	}
+
+	var added []Day
+	for _, day := range current {
+		if !free[day.Date] {
+			added = append(added, day)
+		}
+	}
+	return added
+}
@@ -0,0 +1,162 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// Tables whose rows the cleanup deletes, as reported by Deleted
+const (
+	TableHistory      = "queue_history"
+	TableAggregates   = "history_hourly"
+	TableOutages      = "outages"
+	TableCommandUsage = "command_usage"
+)
+
+// Policy decides which rows the cleanup deletes. Zero caps are disabled.
+type Policy struct {
+	History        time.Duration // Queue history is kept this long, then downsampled to hourly aggregates
+	Aggregates     time.Duration // Hourly aggregates are kept this long, 0 forever
+	HistoryMaxRows int           // Only the newest queue history entries are kept beyond this
+	MaxSize        int64         // Bytes; the oldest queue history goes while the database is larger
+	Outages        time.Duration // Ended DUW API outages are kept this long
+	CommandUsage   time.Duration // Daily command counts are kept this long
+}
+
+// Store deletes old rows; implemented by database.Store
+type Store interface {
+	Size(ctx context.Context) (int64, error)
+	GetHistoryCount(ctx context.Context) (int, error)
+	CleanOldHistory(ctx context.Context, olderThan time.Duration) (int64, error)
+	TrimHistory(ctx context.Context, keep int) (int64, error)
+	CleanOldAggregates(ctx context.Context, olderThan time.Duration) (int64, error)
+	CleanOldOutages(ctx context.Context, olderThan time.Duration) (int64, error)
+	CleanOldCommandUsage(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// Cleaner applies a retention policy and counts the deleted rows. Safe for concurrent use.
+type Cleaner struct {
+	store  Store
+	policy Policy
+
+	mu      sync.Mutex
+	deleted map[string]int64
+}
+
+// New creates a cleaner applying the policy to the store
+func New(store Store, policy Policy) *Cleaner {
+	return &Cleaner{
+		store:   store,
+		policy:  policy,
+		deleted: map[string]int64{TableHistory: 0, TableAggregates: 0, TableOutages: 0, TableCommandUsage: 0},
+	}
+}
+
+// Clean deletes the rows the policy does not keep. A failed step does not stop the others;
+// their errors are returned together.
+func (c *Cleaner) Clean(ctx context.Context) error {
+	start := time.Now()
+	var errs []error
+
+	steps := []struct {
+		table  string
+		reason string
+		clean  func(ctx context.Context) (int64, error)
+	}{
+		{TableHistory, "older than " + c.policy.History.String(), func(ctx context.Context) (int64, error) {
+			return c.store.CleanOldHistory(ctx, c.policy.History)
+		}},
+		{TableHistory, "over the row cap", c.trimRows},
+		{TableHistory, "over the size cap", c.trimSize},
+		{TableAggregates, "older than " + c.policy.Aggregates.String(), c.cleanAggregates},
+		{TableOutages, "older than " + c.policy.Outages.String(), func(ctx context.Context) (int64, error) {
+			return c.store.CleanOldOutages(ctx, c.policy.Outages)
+		}},
+		{TableCommandUsage, "older than " + c.policy.CommandUsage.String(), func(ctx context.Context) (int64, error) {
+			return c.store.CleanOldCommandUsage(ctx, c.policy.CommandUsage)
+		}},
+	}
+
+	var total int64
+	for _, step := range steps {
+		deleted, err := step.clean(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("Cleaned %d %s rows %s", deleted, step.table, step.reason)
+			c.count(step.table, deleted)
+			total += deleted
+		}
+	}
+
+	log.Printf("Retention cleanup deleted %d rows in %v", total, time.Since(start).Round(time.Millisecond))
+	return errors.Join(errs...)
+}
+
+// Deleted returns the rows deleted by the cleanup since the start, by table
+func (c *Cleaner) Deleted() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deleted := make(map[string]int64, len(c.deleted))
+	for table, count := range c.deleted {
+		deleted[table] = count
+	}
+	return deleted
+}
+
+// count adds deleted rows of a table
+func (c *Cleaner) count(table string, deleted int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.deleted[table] += deleted
+}
+
+// cleanAggregates deletes the hourly aggregates older than their retention, if limited
+func (c *Cleaner) cleanAggregates(ctx context.Context) (int64, error) {
+	if c.policy.Aggregates <= 0 {
+		return 0, nil
+	}
+	return c.store.CleanOldAggregates(ctx, c.policy.Aggregates)
+}
+
+// trimRows keeps the newest queue history entries up to the row cap
+func (c *Cleaner) trimRows(ctx context.Context) (int64, error) {
+	if c.policy.HistoryMaxRows <= 0 {
+		return 0, nil
+	}
+	return c.store.TrimHistory(ctx, c.policy.HistoryMaxRows)
+}
+
+// trimSize deletes the oldest queue history while the database is over the size cap. The
+// history is assumed to take most of the space, so the share of entries deleted is the
+// share of the size over the cap.
+func (c *Cleaner) trimSize(ctx context.Context) (int64, error) {
+	if c.policy.MaxSize <= 0 {
+		return 0, nil
+	}
+
+	size, err := c.store.Size(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if size <= c.policy.MaxSize {
+		return 0, nil
+	}
+
+	count, err := c.store.GetHistoryCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	keep := int(float64(count) * float64(c.policy.MaxSize) / float64(size))
+	log.Printf("WARNING: Database size %d bytes exceeds the cap of %d bytes, keeping the newest %d of %d history entries",
+		size, c.policy.MaxSize, keep, count)
+	return c.store.TrimHistory(ctx, keep)
+}
@@ -0,0 +1,242 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxLookahead bounds the search for the next opening (e.g. with all days closed)
+const MaxLookahead = 366
+
+// Range is the opening time range of a day as durations since midnight
+type Range struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// Schedule describes office opening hours per weekday, daily breaks and closed holidays.
+// A nil Schedule means the office is always open.
+type Schedule struct {
+	hours    map[time.Weekday]Range
+	breaks   []Range         // Daily breaks (e.g. lunch) sorted by start time
+	holidays map[string]bool // "2006-01-02" for one-off dates, "01-02" for yearly ones
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Parse builds a schedule from opening hours like "mon 08:00-17:00, tue-fri 08:00-15:00",
+// comma-separated holidays like "2025-11-11, 12-25" (MM-DD repeats every year) and daily
+// breaks like "12:00-12:30". Empty hours return a nil schedule.
+func Parse(hours, holidays, breaks string) (*Schedule, error) {
+	if strings.TrimSpace(hours) == "" {
+		return nil, nil
+	}
+
+	s := &Schedule{
+		hours:    make(map[time.Weekday]Range),
+		holidays: make(map[string]bool),
+	}
+
+	for _, entry := range strings.Split(hours, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		days, timeRange, ok := strings.Cut(entry, " ")
+		if !ok {
+			return nil, fmt.Errorf("expected \"<days> HH:MM-HH:MM\", got %q", entry)
+		}
+
+		dayList, err := parseDays(days)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := parseRange(timeRange)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, day := range dayList {
+			s.hours[day] = r
+		}
+	}
+
+	for _, holiday := range strings.Split(holidays, ",") {
+		holiday = strings.TrimSpace(holiday)
+		if holiday == "" {
+			continue
+		}
+
+		if _, err := time.Parse("2006-01-02", holiday); err != nil {
+			if _, err := time.Parse("01-02", holiday); err != nil {
+				return nil, fmt.Errorf("invalid holiday %q: expected YYYY-MM-DD or MM-DD", holiday)
+			}
+		}
+		s.holidays[holiday] = true
+	}
+
+	for _, entry := range strings.Split(breaks, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		r, err := parseRange(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid break: %w", err)
+		}
+		s.breaks = append(s.breaks, r)
+	}
+	sort.Slice(s.breaks, func(i, j int) bool { return s.breaks[i].Open < s.breaks[j].Open })
+
+	return s, nil
+}
+
+// IsOpen reports whether the office is open at the given time
+func (s *Schedule) IsOpen(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+
+	r, ok := s.dayRange(t)
+	if !ok {
+		return false
+	}
+
+	sinceMidnight := t.Sub(startOfDay(t))
+	return sinceMidnight >= r.Open && sinceMidnight < r.Close
+}
+
+// NextOpening returns the next opening time after t, or false if the schedule has none
+func (s *Schedule) NextOpening(t time.Time) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+
+	day := startOfDay(t)
+	for i := 0; i <= MaxLookahead; i++ {
+		date := day.AddDate(0, 0, i)
+		r, ok := s.dayRange(date)
+		if !ok {
+			continue
+		}
+
+		opening := date.Add(r.Open)
+		if opening.After(t) {
+			return opening, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// CallTime returns when a ticket waiting for the given serving time from t is called.
+// Nobody is served during breaks, so breaks between t and the call postpone it.
+func (s *Schedule) CallTime(t time.Time, wait time.Duration) time.Time {
+	if s == nil {
+		return t.Add(wait)
+	}
+
+	day := startOfDay(t)
+	for _, r := range s.breaks {
+		breakStart, breakEnd := day.Add(r.Open), day.Add(r.Close)
+		if !breakEnd.After(t) {
+			continue
+		}
+
+		if breakStart.After(t) {
+			if !t.Add(wait).After(breakStart) {
+				break
+			}
+			wait -= breakStart.Sub(t)
+		}
+		t = breakEnd
+	}
+
+	return t.Add(wait)
+}
+
+// dayRange returns the opening hours of the day of t, false on closed days and holidays
+func (s *Schedule) dayRange(t time.Time) (Range, bool) {
+	if s.holidays[t.Format("2006-01-02")] || s.holidays[t.Format("01-02")] {
+		return Range{}, false
+	}
+
+	r, ok := s.hours[t.Weekday()]
+	return r, ok
+}
+
+// parseDays parses "mon", "mon-fri" or "sat-sun" into weekdays
+func parseDays(value string) ([]time.Weekday, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	firstText, lastText, isRange := strings.Cut(value, "-")
+	first, ok := weekdays[firstText]
+	if !ok {
+		return nil, fmt.Errorf("invalid weekday %q", firstText)
+	}
+	if !isRange {
+		return []time.Weekday{first}, nil
+	}
+
+	last, ok := weekdays[lastText]
+	if !ok {
+		return nil, fmt.Errorf("invalid weekday %q", lastText)
+	}
+
+	var days []time.Weekday
+	for day := first; ; day = (day + 1) % 7 {
+		days = append(days, day)
+		if day == last {
+			break
+		}
+	}
+	return days, nil
+}
+
+// parseRange parses "HH:MM-HH:MM" into times of day
+func parseRange(value string) (Range, error) {
+	openText, closeText, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return Range{}, fmt.Errorf("expected HH:MM-HH:MM, got %q", value)
+	}
+
+	open, err := parseTimeOfDay(openText)
+	if err != nil {
+		return Range{}, err
+	}
+	closeTime, err := parseTimeOfDay(closeText)
+	if err != nil {
+		return Range{}, err
+	}
+	if closeTime <= open {
+		return Range{}, fmt.Errorf("closing time %s must be after opening time %s", closeText, openText)
+	}
+
+	return Range{Open: open, Close: closeTime}, nil
+}
+
+// parseTimeOfDay parses "HH:MM" into a duration since midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", value, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// startOfDay returns local midnight of the day of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"karta/internal/models"
+)
+
+// LatestSource loads the latest stored queue data, implemented by database.Store
+type LatestSource interface {
+	GetLatestQueueData(ctx context.Context) (*models.QueueData, error)
+}
+
+// Update is an accepted poll of the queue as published to the subscribers
+type Update struct {
+	Queue    *models.QueueData
+	Previous *models.QueueData    // Data of the update before, nil for the first one
+	Changes  *models.QueueChanges // What this poll changed
+	Shown    *models.QueueChanges // Changes highlighted until the next change, nil for none
+}
+
+// Handler reacts to an update of the queue
+type Handler func(update Update)
+
+// Queue is the current state of an office queue: the latest accepted data, when it last
+// changed and the changes to highlight. The monitor updates it with every accepted poll;
+// until the first one Current falls back to the store, if any. Safe for concurrent use.
+type Queue struct {
+	source LatestSource // nil for offices without a history
+
+	mu          sync.RWMutex
+	current     *models.QueueData
+	updated     bool // current comes from an update, not the store
+	lastChanged time.Time
+	changes     *models.QueueChanges
+	handlers    []Handler
+}
+
+// NewQueue creates the state of a queue, reading through to the given store on cold start;
+// source may be nil
+func NewQueue(source LatestSource) *Queue {
+	return &Queue{source: source}
+}
+
+// Update records an accepted poll compared with the previous one, at the given time. It
+// sets the LastChanged time of queueData, which the first poll and any change move to now,
+// and runs the subscribers before returning.
+func (q *Queue) Update(queueData *models.QueueData, changes *models.QueueChanges, now time.Time) Update {
+	q.mu.Lock()
+	update := Update{Queue: queueData, Changes: changes}
+	if q.updated {
+		update.Previous = q.current
+	}
+
+	// Changes stay highlighted until the next change
+	update.Shown = q.changes
+	switch {
+	case update.Previous == nil:
+		q.lastChanged = now
+		q.changes = nil // No changes to highlight on first run
+	case changes.HasChanges:
+		q.lastChanged = now
+		q.changes = changes
+	}
+	if changes.HasChanges {
+		update.Shown = changes
+	}
+	queueData.LastChanged = q.lastChanged
+
+	q.current = queueData.Clone()
+	q.updated = true
+	handlers := q.handlers
+	q.mu.Unlock()
+
+	for _, handler := range handlers {
+		run(handler, update)
+	}
+	return update
+}
+
+// Last returns a copy of the data of the last update, or nil before the first one
+func (q *Queue) Last() *models.QueueData {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if !q.updated {
+		return nil
+	}
+	return q.current.Clone()
+}
+
+// Current returns a copy of the latest queue data, from the store before the first
+// update, or nil before any data exists
+func (q *Queue) Current(ctx context.Context) (*models.QueueData, error) {
+	q.mu.RLock()
+	current := q.current
+	q.mu.RUnlock()
+	if current != nil || q.source == nil {
+		return current.Clone(), nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Another reader or the monitor may have set it in the meantime. An empty store is
+	// read again next time, as the first poll may have been saved by then.
+	if q.current == nil {
+		stored, err := q.source.GetLatestQueueData(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load latest queue data: %w", err)
+		}
+		q.current = stored
+	}
+	return q.current.Clone(), nil
+}
+
+// Changes returns the changes highlighted until the next change, nil for none
+func (q *Queue) Changes() *models.QueueChanges {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.changes
+}
+
+// LastChanged returns when the queue last changed, zero before the first update
+func (q *Queue) LastChanged() time.Time {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.lastChanged
+}
+
+// Subscribe registers a handler run synchronously after every update, in subscription order
+func (q *Queue) Subscribe(handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers = append(q.handlers, handler)
+}
+
+// run calls the handler, recovering from panics so one subscriber cannot break the others
+func run(handler Handler, update Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Queue state handler panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+	handler(update)
+}
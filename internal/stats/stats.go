@@ -0,0 +1,226 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/models"
+)
+
+const (
+	// MaxSampleGap is the largest gap between two history records still treated as continuous
+	// data; unchanged polls are only stored as periodic snapshots (5 minutes by default)
+	MaxSampleGap = 10 * time.Minute
+	// MinThroughputSpan is the shortest serving period a throughput is given for
+	MinThroughputSpan = 15 * time.Minute
+)
+
+// Source is the history store the aggregations read from, implemented by database.Store
+type Source interface {
+	GetHourlyHistory(ctx context.Context, since time.Time) ([]database.HistoryBucket, error)
+	GetQueueDataBetween(ctx context.Context, from, to time.Time) ([]*models.QueueData, error)
+}
+
+// QueueStats aggregates the queue history of a store
+type QueueStats struct {
+	source Source
+}
+
+// New creates the aggregations over the given history store
+func New(source Source) *QueueStats {
+	return &QueueStats{source: source}
+}
+
+// HourThroughput is an hourly history bucket with the tickets served in the hour
+type HourThroughput struct {
+	database.HistoryBucket
+	Served int
+}
+
+// Peak is the highest number of waiting clients in a range and the hour it was reached in
+type Peak struct {
+	Hour    time.Time
+	Waiting int
+}
+
+// HourlyThroughput returns the tickets served in each local hour of [from, to) with history
+func (s *QueueStats) HourlyThroughput(ctx context.Context, from, to time.Time) ([]HourThroughput, error) {
+	buckets, err := s.hourlyBuckets(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return HourlyThroughputOf(buckets), nil
+}
+
+// DailyPeak returns the peak of waiting clients on the local day of the given time; false
+// without history that day
+func (s *QueueStats) DailyPeak(ctx context.Context, day time.Time) (Peak, bool, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	buckets, err := s.hourlyBuckets(ctx, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		return Peak{}, false, err
+	}
+	peak, ok := PeakOf(buckets)
+	return peak, ok, nil
+}
+
+// ServiceTimePercentiles returns the given percentiles (0-100) of the average service
+// time reported while the queue was open in [from, to); false without reports
+func (s *QueueStats) ServiceTimePercentiles(ctx context.Context, from, to time.Time, percentiles ...float64) ([]time.Duration, bool, error) {
+	history, err := s.source.GetQueueDataBetween(ctx, from, to)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load history: %w", err)
+	}
+	values, ok := ServiceTimePercentilesOf(history, percentiles...)
+	return values, ok, nil
+}
+
+// hourlyBuckets returns the hourly buckets starting in [from, to)
+func (s *QueueStats) hourlyBuckets(ctx context.Context, from, to time.Time) ([]database.HistoryBucket, error) {
+	buckets, err := s.source.GetHourlyHistory(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hourly history: %w", err)
+	}
+
+	var inRange []database.HistoryBucket
+	for _, bucket := range buckets {
+		if bucket.Period.Before(to) {
+			inRange = append(inRange, bucket)
+		}
+	}
+	return inRange, nil
+}
+
+// HourlyThroughputOf computes the tickets served in each of the hourly buckets. The served
+// counter is cumulative over the day, so they are the increase over the previous bucket of
+// the same day, or the whole count after a reset.
+func HourlyThroughputOf(buckets []database.HistoryBucket) []HourThroughput {
+	throughput := make([]HourThroughput, len(buckets))
+	var previous database.HistoryBucket
+	for i, bucket := range buckets {
+		served := bucket.MaxServed
+		if sameDay(previous.Period, bucket.Period) && served >= previous.MaxServed {
+			served -= previous.MaxServed
+		}
+		previous = bucket
+		throughput[i] = HourThroughput{HistoryBucket: bucket, Served: served}
+	}
+	return throughput
+}
+
+// PeakOf returns the peak of waiting clients over the buckets, the earliest on ties
+func PeakOf(buckets []database.HistoryBucket) (Peak, bool) {
+	if len(buckets) == 0 {
+		return Peak{}, false
+	}
+
+	peak := Peak{Hour: buckets[0].Period, Waiting: buckets[0].MaxWaiting}
+	for _, bucket := range buckets[1:] {
+		if bucket.MaxWaiting > peak.Waiting {
+			peak = Peak{Hour: bucket.Period, Waiting: bucket.MaxWaiting}
+		}
+	}
+	return peak, true
+}
+
+// ServiceTimePercentilesOf returns the given percentiles (0-100) of the average service
+// time reported by the open-queue records, by linear interpolation; false without reports
+func ServiceTimePercentilesOf(history []*models.QueueData, percentiles ...float64) ([]time.Duration, bool) {
+	var times []time.Duration
+	for _, queueData := range history {
+		if queueData.Status == models.StatusOpen && queueData.AvgServiceTime > 0 {
+			times = append(times, queueData.AvgServiceTime)
+		}
+	}
+	if len(times) == 0 {
+		return nil, false
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	values := make([]time.Duration, len(percentiles))
+	for i, percentile := range percentiles {
+		rank := math.Max(0, math.Min(100, percentile)) / 100 * float64(len(times)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		fraction := rank - float64(lower)
+		values[i] = times[lower] + time.Duration(fraction*float64(times[upper]-times[lower]))
+	}
+	return values, true
+}
+
+// MeanServiceTime returns the mean reported service time of open-queue records since the
+// given time
+func MeanServiceTime(history []*models.QueueData, since time.Time) (time.Duration, bool) {
+	var total time.Duration
+	var count int
+
+	for _, queueData := range history {
+		if queueData.LastUpdated.Before(since) || queueData.Status != models.StatusOpen {
+			continue
+		}
+
+		serviceTime := queueData.AvgServiceTime
+		if serviceTime <= 0 {
+			continue
+		}
+
+		total += serviceTime
+		count++
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+	return total / time.Duration(count), true
+}
+
+// Throughput returns tickets served per hour between the first served ticket of the day
+// and the moment the served count last increased
+func Throughput(history []*models.QueueData) (float64, bool) {
+	var first, last *models.QueueData
+	var firstServed, lastServed int
+
+	for _, queueData := range history {
+		served := queueData.ServedClients
+		if served <= 0 {
+			continue
+		}
+
+		if first == nil {
+			first, firstServed = queueData, served
+		}
+		if served > lastServed {
+			last, lastServed = queueData, served
+		}
+	}
+
+	if first == nil || last == nil {
+		return 0, false
+	}
+
+	span := last.LastUpdated.Sub(first.LastUpdated)
+	if span < MinThroughputSpan {
+		return 0, false
+	}
+	return float64(lastServed-firstServed) / span.Hours(), true
+}
+
+// Continuous reports whether two consecutive records of the open queue are close enough to
+// measure the service between them: polled at most MaxSampleGap apart on the same day,
+// without the served counter going back
+func Continuous(previous, current *models.QueueData) bool {
+	gap := current.LastUpdated.Sub(previous.LastUpdated)
+	return gap > 0 && gap <= MaxSampleGap &&
+		previous.Status == models.StatusOpen && current.Status == models.StatusOpen &&
+		sameDay(previous.LastUpdated.Local(), current.LastUpdated.Local()) &&
+		current.ServedClients >= previous.ServedClients
+}
+
+// sameDay reports whether two times fall on the same day of their location
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
@@ -0,0 +1,252 @@
+package stats
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"karta/internal/database"
+	"karta/internal/models"
+)
+
+// day is the local day the test history is recorded on
+var day = time.Date(2026, time.March, 10, 0, 0, 0, 0, time.Local)
+
+// sample is a history record of the test day, at hh:mm
+type sample struct {
+	at      string
+	served  int
+	waiting int
+	service time.Duration
+	closed  bool
+}
+
+// seed returns an in-memory store with the samples as history
+func seed(t *testing.T, samples ...sample) *QueueStats {
+	t.Helper()
+
+	var records []database.HistoryRecord
+	for _, s := range samples {
+		at, err := time.ParseInLocation("2006-01-02 15:04", day.Format("2006-01-02")+" "+s.at, time.Local)
+		if err != nil {
+			t.Fatalf("invalid sample time %q: %v", s.at, err)
+		}
+
+		status := models.StatusOpen
+		if s.closed {
+			status = models.StatusClosed
+		}
+		queue := &models.QueueData{
+			Name:           models.DefaultQueueName,
+			ServedClients:  s.served,
+			WaitingClients: s.waiting,
+			AvgServiceTime: s.service,
+			Status:         status,
+			LastUpdated:    at,
+		}
+		records = append(records, database.HistoryRecord{RecordedAt: at, Queue: queue})
+	}
+
+	db := database.NewMemory()
+	if _, err := db.ImportQueueHistory(context.Background(), records); err != nil {
+		t.Fatalf("failed to seed history: %v", err)
+	}
+	return New(db)
+}
+
+// hour returns the given hour of the test day
+func hour(h int) time.Time {
+	return day.Add(time.Duration(h) * time.Hour)
+}
+
+func TestHourlyThroughput(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []sample
+		to      time.Time
+		want    map[time.Time]int // Served by hour
+	}{
+		{
+			name: "empty history",
+			to:   hour(24),
+			want: map[time.Time]int{},
+		},
+		{
+			name:    "single sample",
+			samples: []sample{{at: "09:10", served: 4}},
+			to:      hour(24),
+			want:    map[time.Time]int{hour(9): 4},
+		},
+		{
+			name: "cumulative counter",
+			samples: []sample{
+				{at: "09:05", served: 2}, {at: "09:50", served: 10},
+				{at: "10:20", served: 15}, {at: "10:55", served: 22},
+				{at: "11:30", served: 30},
+			},
+			to:   hour(24),
+			want: map[time.Time]int{hour(9): 10, hour(10): 12, hour(11): 8},
+		},
+		{
+			name:    "counter reset within the day",
+			samples: []sample{{at: "09:30", served: 40}, {at: "10:30", served: 3}},
+			to:      hour(24),
+			want:    map[time.Time]int{hour(9): 40, hour(10): 3},
+		},
+		{
+			name:    "hours from the end of the range left out",
+			samples: []sample{{at: "09:30", served: 5}, {at: "10:30", served: 9}},
+			to:      hour(10),
+			want:    map[time.Time]int{hour(9): 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throughput, err := seed(t, tt.samples...).HourlyThroughput(context.Background(), day, tt.to)
+			if err != nil {
+				t.Fatalf("HourlyThroughput failed: %v", err)
+			}
+
+			got := make(map[time.Time]int)
+			for _, h := range throughput {
+				got[h.Period] = h.Served
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HourlyThroughput = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDailyPeak(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []sample
+		want    Peak
+		wantOK  bool
+	}{
+		{
+			name: "empty history",
+		},
+		{
+			name:    "single sample",
+			samples: []sample{{at: "11:15", waiting: 7}},
+			want:    Peak{Hour: hour(11), Waiting: 7},
+			wantOK:  true,
+		},
+		{
+			name: "highest hour",
+			samples: []sample{
+				{at: "08:30", waiting: 12}, {at: "09:30", waiting: 31},
+				{at: "09:45", waiting: 18}, {at: "12:10", waiting: 20},
+			},
+			want:   Peak{Hour: hour(9), Waiting: 31},
+			wantOK: true,
+		},
+		{
+			name:    "earliest hour on ties",
+			samples: []sample{{at: "10:30", waiting: 25}, {at: "14:30", waiting: 25}},
+			want:    Peak{Hour: hour(10), Waiting: 25},
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peak, ok, err := seed(t, tt.samples...).DailyPeak(context.Background(), hour(15))
+			if err != nil {
+				t.Fatalf("DailyPeak failed: %v", err)
+			}
+			if ok != tt.wantOK || !peak.Hour.Equal(tt.want.Hour) || peak.Waiting != tt.want.Waiting {
+				t.Errorf("DailyPeak = %+v, %v, want %+v, %v", peak, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDailyPeakOtherDay(t *testing.T) {
+	stats := seed(t, sample{at: "10:00", waiting: 40})
+
+	if peak, ok, err := stats.DailyPeak(context.Background(), day.AddDate(0, 0, 1)); err != nil || ok {
+		t.Errorf("DailyPeak of the next day = %+v, %v, %v, want no peak", peak, ok, err)
+	}
+}
+
+func TestServiceTimePercentiles(t *testing.T) {
+	minutes := func(values ...float64) []time.Duration {
+		durations := make([]time.Duration, len(values))
+		for i, value := range values {
+			durations[i] = time.Duration(value * float64(time.Minute))
+		}
+		return durations
+	}
+	five := []sample{
+		{at: "09:00", service: 8 * time.Minute}, {at: "09:10", service: 4 * time.Minute},
+		{at: "09:20", service: 6 * time.Minute}, {at: "09:30", service: 5 * time.Minute},
+		{at: "09:40", service: 7 * time.Minute},
+	}
+
+	tests := []struct {
+		name        string
+		samples     []sample
+		percentiles []float64
+		want        []time.Duration
+		wantOK      bool
+	}{
+		{
+			name:        "empty history",
+			percentiles: []float64{50},
+		},
+		{
+			name:        "single sample",
+			samples:     []sample{{at: "09:00", service: 6 * time.Minute}},
+			percentiles: []float64{0, 50, 90},
+			want:        minutes(6, 6, 6),
+			wantOK:      true,
+		},
+		{
+			name:        "exact ranks",
+			samples:     five,
+			percentiles: []float64{0, 25, 50, 100},
+			want:        minutes(4, 5, 6, 8),
+			wantOK:      true,
+		},
+		{
+			name:        "interpolated and clamped",
+			samples:     five,
+			percentiles: []float64{90, 150, -10},
+			want:        minutes(7.6, 8, 4),
+			wantOK:      true,
+		},
+		{
+			name: "closed queue and missing reports ignored",
+			samples: []sample{
+				{at: "08:00", service: 30 * time.Minute, closed: true},
+				{at: "09:00"},
+				{at: "09:10", service: 3 * time.Minute},
+			},
+			percentiles: []float64{50},
+			want:        minutes(3),
+			wantOK:      true,
+		},
+		{
+			name:        "only closed queue",
+			samples:     []sample{{at: "08:00", service: 30 * time.Minute, closed: true}},
+			percentiles: []float64{50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, ok, err := seed(t, tt.samples...).ServiceTimePercentiles(context.Background(), day, hour(24), tt.percentiles...)
+			if err != nil {
+				t.Fatalf("ServiceTimePercentiles failed: %v", err)
+			}
+			if ok != tt.wantOK || !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("ServiceTimePercentiles = %v, %v, want %v, %v", values, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
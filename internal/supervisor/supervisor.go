@@ -0,0 +1,140 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	MinBackoff = time.Second      // Delay before the first restart
+	MaxBackoff = 5 * time.Minute  // The delay doubles with every restart up to this
+	StableRun  = 10 * time.Minute // A run this long resets the delay
+)
+
+// errExited is the cause of a restart after a task returned without an error
+var errExited = errors.New("exited unexpectedly")
+
+// Restart describes a restart of a supervised task
+type Restart struct {
+	Name     string
+	Restarts int           // Restarts of the task so far, including this one
+	Cause    error         // Returned error, recovered panic or an unexpected exit
+	Delay    time.Duration // Backoff before the task runs again
+}
+
+// Supervisor runs long-lived tasks in goroutines and restarts those returning or panicking
+// before their context is cancelled, with exponential backoff. Safe for concurrent use.
+type Supervisor struct {
+	onRestart func(ctx context.Context, restart Restart) // Called before every restart, may be nil
+	wg        sync.WaitGroup
+
+	mu       sync.Mutex
+	restarts map[string]int
+}
+
+// New creates a supervisor reporting restarts to onRestart, which may be nil
+func New(onRestart func(ctx context.Context, restart Restart)) *Supervisor {
+	return &Supervisor{onRestart: onRestart, restarts: make(map[string]int)}
+}
+
+// Go runs the named task in a goroutine until the context is cancelled
+func (s *Supervisor) Go(ctx context.Context, name string, task func(ctx context.Context) error) {
+	s.mu.Lock()
+	if _, exists := s.restarts[name]; !exists {
+		s.restarts[name] = 0
+	}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.supervise(ctx, name, task)
+	}()
+}
+
+// Wait blocks until all tasks have stopped
+func (s *Supervisor) Wait() {
+	s.wg.Wait()
+}
+
+// Restarts returns the number of restarts of every supervised task
+func (s *Supervisor) Restarts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restarts := make(map[string]int, len(s.restarts))
+	for name, count := range s.restarts {
+		restarts[name] = count
+	}
+	return restarts
+}
+
+// TotalRestarts returns the restarts of all tasks together
+func (s *Supervisor) TotalRestarts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var total int
+	for _, count := range s.restarts {
+		total += count
+	}
+	return total
+}
+
+// supervise runs the task until the context is cancelled, restarting it after failures
+func (s *Supervisor) supervise(ctx context.Context, name string, task func(ctx context.Context) error) {
+	delay := MinBackoff
+	for {
+		started := time.Now()
+		err := run(ctx, name, task)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) >= StableRun {
+			delay = MinBackoff
+		}
+		if err == nil {
+			err = errExited
+		}
+
+		s.mu.Lock()
+		s.restarts[name]++
+		restart := Restart{Name: name, Restarts: s.restarts[name], Cause: err, Delay: delay}
+		s.mu.Unlock()
+
+		log.Printf("WARNING: %s stopped: %v; restarting in %v (restart %d)", name, err, delay, restart.Restarts)
+		if s.onRestart != nil {
+			s.onRestart(ctx, restart)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > MaxBackoff {
+			delay = MaxBackoff
+		}
+	}
+}
+
+// run calls the task, turning a panic into an error
+func run(ctx context.Context, name string, task func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Recovered from panic in %s: %v\n%s", name, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return task(ctx)
+}
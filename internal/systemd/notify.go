@@ -0,0 +1,64 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notify messages understood by systemd, see sd_notify(3)
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Notify sends states to the service manager through NOTIFY_SOCKET. Without the socket,
+// e.g. outside a Type=notify unit, it does nothing and returns false.
+func Notify(states ...string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	// A leading @ names a socket in the abstract namespace
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to the notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	message := strings.Join(states, "\n")
+	if _, err := conn.Write([]byte(message)); err != nil {
+		return false, fmt.Errorf("failed to send %q to the notify socket: %w", message, err)
+	}
+	return true, nil
+}
+
+// Status returns the message setting the status line shown by systemctl status
+func Status(status string) string {
+	return "STATUS=" + status
+}
+
+// WatchdogInterval returns the watchdog timeout of the unit (WatchdogSec), when systemd
+// expects watchdog pings from this process
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	// WATCHDOG_PID names the process the watchdog is meant for when set
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
@@ -0,0 +1,97 @@
+package webapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxAge is how long the init data of a Mini App launch is accepted, after which the user
+// has to reopen the app
+const MaxAge = 24 * time.Hour
+
+var (
+	ErrInvalidHash = errors.New("init data signature does not match")
+	ErrExpired     = errors.New("init data expired")
+)
+
+// User is the Telegram user who opened the Mini App, as described in its init data
+type User struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// Validate checks the init data Telegram passes to a Mini App (Telegram.WebApp.initData)
+// against the bot token and returns its user. The data is signed with
+// HMAC-SHA256(HMAC-SHA256("WebAppData", token), data-check-string), so it cannot be forged
+// by the client.
+func Validate(initData, botToken string, now time.Time) (*User, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse init data: %w", err)
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, ErrInvalidHash
+	}
+	expected, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, ErrInvalidHash
+	}
+	if !hmac.Equal(sign(values, botToken), expected) {
+		return nil, ErrInvalidHash
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth_date in init data: %w", err)
+	}
+	if now.Sub(time.Unix(authDate, 0)) > MaxAge {
+		return nil, ErrExpired
+	}
+
+	var user User
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil {
+		return nil, fmt.Errorf("invalid user in init data: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("init data has no user")
+	}
+	return &user, nil
+}
+
+// sign computes the signature of the init data fields: all but the hash, sorted by key
+// and joined as key=value lines
+func sign(values url.Values, botToken string) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + "=" + values.Get(key)
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	return mac.Sum(nil)
+}